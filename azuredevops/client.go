@@ -0,0 +1,397 @@
+package azuredevops
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"devops-metrics/config"
+	"devops-metrics/fetchstats"
+	"devops-metrics/replay"
+)
+
+// apiVersion is sent with every request; Azure DevOps rejects calls without it
+const apiVersion = "7.1"
+
+// pageSize is the $top value used for list endpoints
+const pageSize = 100
+
+// Client handles Azure DevOps API operations using direct HTTP calls
+type Client struct {
+	config     config.Config
+	stats      *fetchstats.Recorder
+	httpClient HTTPClient
+	progress   func(fetched int)
+}
+
+// HTTPClient sends API requests; *http.Client satisfies it, and tests can substitute a stub
+type HTTPClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// NewClient creates a new Azure DevOps client
+func NewClient(config config.Config) Client {
+	return Client{
+		config:     config,
+		httpClient: replay.NewHTTPClient("azuredevops", config),
+	}
+}
+
+// WithStats returns a copy of the client that records request statistics into r
+func (c Client) WithStats(r *fetchstats.Recorder) Client {
+	c.stats = r
+	return c
+}
+
+// WithProgress returns a copy of the client that calls fn with the running count of items
+// after each page of a list fetch
+func (c Client) WithProgress(fn func(fetched int)) Client {
+	c.progress = fn
+	return c
+}
+
+// reportProgress passes the running count of fetched items to the progress callback, if any
+func (c Client) reportProgress(fetched int) {
+	if c.progress != nil {
+		c.progress(fetched)
+	}
+}
+
+// WithHTTPClient returns a copy of the client that sends its requests through h
+func (c Client) WithHTTPClient(h HTTPClient) Client {
+	c.httpClient = h
+	return c
+}
+
+// Azure DevOps API response structures
+type azureIdentity struct {
+	DisplayName string `json:"displayName"`
+	UniqueName  string `json:"uniqueName"`
+}
+
+type azureGitUserDate struct {
+	Name  string    `json:"name"`
+	Email string    `json:"email"`
+	Date  time.Time `json:"date"`
+}
+
+type azureCommitsResponse struct {
+	Value []struct {
+		CommitID string           `json:"commitId"`
+		Author   azureGitUserDate `json:"author"`
+		Comment  string           `json:"comment"`
+	} `json:"value"`
+}
+
+type azureReviewer struct {
+	azureIdentity
+	Vote int `json:"vote"` // 10 approved, 5 approved with suggestions, 0 no vote, -5 waiting, -10 rejected
+}
+
+type azureCommitRef struct {
+	CommitID string `json:"commitId"`
+}
+
+type azurePullRequest struct {
+	PullRequestID         int             `json:"pullRequestId"`
+	Status                string          `json:"status"` // active, abandoned, completed
+	CreatedBy             azureIdentity   `json:"createdBy"`
+	CreationDate          time.Time       `json:"creationDate"`
+	ClosedDate            *time.Time      `json:"closedDate"`
+	ClosedBy              *azureIdentity  `json:"closedBy"`
+	Title                 string          `json:"title"`
+	TargetRefName         string          `json:"targetRefName"`
+	Reviewers             []azureReviewer `json:"reviewers"`
+	LastMergeSourceCommit *azureCommitRef `json:"lastMergeSourceCommit"`
+	LastMergeCommit       *azureCommitRef `json:"lastMergeCommit"`
+	Labels                []struct {
+		Name string `json:"name"`
+	} `json:"labels"`
+}
+
+type azurePullRequestsResponse struct {
+	Value []azurePullRequest `json:"value"`
+}
+
+// pager walks a list endpoint. Endpoints that return an x-ms-continuationtoken header are
+// followed by token; the others are paged with $skip until a short page comes back.
+type pager struct {
+	prefix string // Prepended to $top and $skip; the commits resource expects "searchCriteria."
+	skip   int
+	token  string
+	tokens bool // The endpoint has handed out a continuation token, so only tokens continue it
+}
+
+// apply sets the paging parameters of the next request
+func (p pager) apply(params url.Values) {
+	params.Set(p.prefix+"$top", strconv.Itoa(pageSize))
+	params.Del(p.prefix + "$skip")
+	params.Del("continuationToken")
+	if p.token != "" {
+		params.Set("continuationToken", p.token)
+	} else if p.skip > 0 {
+		params.Set(p.prefix+"$skip", strconv.Itoa(p.skip))
+	}
+}
+
+// advance records a page of count items and reports whether another page follows
+func (p *pager) advance(header http.Header, count int) bool {
+	p.skip += count
+	p.token = header.Get("x-ms-continuationtoken")
+	if p.token != "" {
+		p.tokens = true
+		return true
+	}
+	return !p.tokens && count >= pageSize
+}
+
+// makeRequest makes an authenticated GET request, backing off on 429 responses, and also
+// returns the response headers for continuation tokens
+func (c Client) makeRequest(ctx context.Context, url string) ([]byte, http.Header, error) {
+	const maxRetries = 5
+	const baseDelay = 1 * time.Second
+
+	for attempt := 0; attempt <= maxRetries; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+		if err != nil {
+			return nil, nil, err
+		}
+		// Personal access tokens are sent as the password of basic auth with an empty user
+		req.SetBasicAuth("", c.config.AzurePAT)
+		req.Header.Set("Accept", "application/json")
+
+		start := time.Now()
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			c.stats.RecordRequest("azuredevops", 0, time.Since(start), err)
+			return nil, nil, err
+		}
+
+		if resp.StatusCode == http.StatusTooManyRequests && attempt < maxRetries {
+			resp.Body.Close()
+			c.stats.RecordRequest("azuredevops", 0, time.Since(start), nil)
+			c.stats.RecordRetry("azuredevops")
+			select {
+			case <-ctx.Done():
+				return nil, nil, ctx.Err()
+			case <-time.After(time.Duration(baseDelay.Nanoseconds() * (1 << attempt))):
+			}
+			continue
+		}
+
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err == nil {
+			switch {
+			case resp.StatusCode == http.StatusNonAuthoritativeInfo:
+				// An invalid or expired token is answered with a 203 sign-in page instead of 401
+				err = fmt.Errorf("authentication failed: check AZURE_PAT")
+			case !c.config.IsSuccessStatus(resp.StatusCode):
+				err = fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
+			}
+		}
+		c.stats.RecordRequest("azuredevops", len(body), time.Since(start), err)
+		if err != nil {
+			return nil, nil, err
+		}
+		return body, resp.Header, nil
+	}
+
+	return nil, nil, fmt.Errorf("API request failed after %d attempts", maxRetries+1)
+}
+
+// FetchCommits retrieves commits on the default branch within the analysis window. The
+// commits endpoint reports changed file counts, not lines, so line counts are left empty.
+func (c Client) FetchCommits(ctx context.Context) ([]Commit, error) {
+	commits := []Commit{}
+	since, until := c.config.Window()
+	params := url.Values{}
+	params.Set("searchCriteria.fromDate", since.Format(time.RFC3339))
+	params.Set("searchCriteria.toDate", until.Format(time.RFC3339))
+
+	p := pager{prefix: "searchCriteria."}
+	for page := 1; ; page++ {
+		p.apply(params)
+		body, header, err := c.makeRequest(ctx, c.repoURL("commits", params))
+		if err != nil {
+			if page == 1 {
+				return nil, fmt.Errorf("error fetching commits: %w", err)
+			}
+			slog.Warn("Stopping commits", "provider", "azuredevops", "repo", c.repoName(), "page", page, "error", err)
+			break
+		}
+
+		var response azureCommitsResponse
+		if err := json.Unmarshal(body, &response); err != nil {
+			if page == 1 {
+				return nil, fmt.Errorf("error parsing commits: %w", err)
+			}
+			slog.Warn("Stopping commits, page could not be parsed", "provider", "azuredevops", "repo", c.repoName(), "page", page, "error", err)
+			break
+		}
+
+		for _, commit := range response.Value {
+			author := commit.Author.Name
+			if author == "" {
+				author = c.config.AuthorOrFallback("", commit.Author.Email)
+			}
+			commits = append(commits, Commit{
+				Hash:    commit.CommitID,
+				Author:  author,
+				Date:    commit.Author.Date,
+				Message: commit.Comment,
+				Repo:    c.repoName(),
+			})
+			if max := c.config.CommitCap(); max > 0 && len(commits) >= max {
+				c.truncated("commits", max)
+				return commits, nil
+			}
+		}
+
+		c.reportProgress(len(commits))
+		if !p.advance(header, len(response.Value)) {
+			break
+		}
+	}
+
+	return commits, nil
+}
+
+// FetchPRs retrieves pull requests created within the analysis window, in any state
+func (c Client) FetchPRs(ctx context.Context) ([]PullRequest, error) {
+	prs := []PullRequest{}
+	since, until := c.config.Window()
+	params := url.Values{}
+	params.Set("searchCriteria.status", "all")
+	params.Set("searchCriteria.queryTimeRangeType", "created")
+	params.Set("searchCriteria.minTime", since.Format(time.RFC3339))
+	params.Set("searchCriteria.maxTime", until.Format(time.RFC3339))
+
+	var p pager
+	for page := 1; ; page++ {
+		p.apply(params)
+		body, header, err := c.makeRequest(ctx, c.repoURL("pullrequests", params))
+		if err != nil {
+			if page == 1 {
+				return nil, fmt.Errorf("error fetching pull requests: %w", err)
+			}
+			slog.Warn("Stopping pull requests", "provider", "azuredevops", "repo", c.repoName(), "page", page, "error", err)
+			break
+		}
+
+		var response azurePullRequestsResponse
+		if err := json.Unmarshal(body, &response); err != nil {
+			if page == 1 {
+				return nil, fmt.Errorf("error parsing pull requests: %w", err)
+			}
+			slog.Warn("Stopping pull requests, page could not be parsed", "provider", "azuredevops", "repo", c.repoName(), "page", page, "error", err)
+			break
+		}
+
+		for _, pr := range response.Value {
+			prs = append(prs, toPullRequest(pr, c.repoName()))
+			if max := c.config.PRCap(); max > 0 && len(prs) >= max {
+				c.truncated("PRs", max)
+				return prs, nil
+			}
+		}
+
+		c.reportProgress(len(prs))
+		if !p.advance(header, len(response.Value)) {
+			break
+		}
+	}
+
+	return prs, nil
+}
+
+// toPullRequest converts an API pull request to the shared pull request shape. Completed
+// pull requests count as merged at their close date; abandoned ones as closed.
+func toPullRequest(pr azurePullRequest, repo string) PullRequest {
+	result := PullRequest{
+		ID:         fmt.Sprintf("%d", pr.PullRequestID),
+		Author:     pr.CreatedBy.DisplayName,
+		CreatedAt:  pr.CreationDate,
+		BaseBranch: strings.TrimPrefix(pr.TargetRefName, "refs/heads/"),
+		Title:      pr.Title,
+		Repo:       repo,
+		Status:     "OPEN",
+	}
+
+	switch pr.Status {
+	case "completed":
+		result.Status = "MERGED"
+		result.MergedAt = pr.ClosedDate
+		if pr.ClosedBy != nil {
+			result.MergedBy = pr.ClosedBy.DisplayName
+		}
+	case "abandoned":
+		result.Status = "CLOSED"
+		result.ClosedAt = pr.ClosedDate
+	}
+
+	// Requested reviewers who have not voted yet have not reviewed
+	for _, reviewer := range pr.Reviewers {
+		if reviewer.Vote == 0 {
+			continue
+		}
+		result.Reviewers = append(result.Reviewers, reviewer.DisplayName)
+		if reviewer.Vote >= 5 {
+			result.Approvers = append(result.Approvers, reviewer.DisplayName)
+		}
+	}
+	for _, label := range pr.Labels {
+		result.Labels = append(result.Labels, label.Name)
+	}
+	for _, ref := range []*azureCommitRef{pr.LastMergeSourceCommit, pr.LastMergeCommit} {
+		if ref != nil && ref.CommitID != "" {
+			result.CommitHashes = append(result.CommitHashes, ref.CommitID)
+		}
+	}
+
+	return result
+}
+
+// repoURL returns the URL of a Git API resource of the configured repository, with params
+// and the required api-version
+func (c Client) repoURL(resource string, params url.Values) string {
+	query := url.Values{}
+	for key, values := range params {
+		query[key] = values
+	}
+	query.Set("api-version", apiVersion)
+	return fmt.Sprintf("%s/%s/%s/_apis/git/repositories/%s/%s?%s",
+		c.getBaseURL(),
+		url.PathEscape(c.config.AzureOrg),
+		url.PathEscape(c.config.AzureProject),
+		url.PathEscape(c.config.AzureRepo),
+		resource,
+		query.Encode(),
+	)
+}
+
+// getBaseURL returns the Azure DevOps URL, defaulting to the cloud service
+func (c Client) getBaseURL() string {
+	if c.config.AzureURL == "" {
+		return "https://dev.azure.com"
+	}
+	return strings.TrimSuffix(c.config.AzureURL, "/")
+}
+
+// truncated reports that fetching kind stopped at the configured cap, so results are incomplete
+func (c Client) truncated(kind string, max int) {
+	slog.Warn("Stopped fetching at the configured cap; results are truncated", "provider", "azuredevops", "repo", c.repoName(), "kind", kind, "count", max)
+	c.stats.RecordTruncated("azuredevops", kind)
+}
+
+// repoName returns the project/repository identifier used to tag fetched data
+func (c Client) repoName() string {
+	return c.config.AzureProject + "/" + c.config.AzureRepo
+}
@@ -0,0 +1,137 @@
+package azuredevops
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"devops-metrics/config"
+)
+
+// listServer serves total items of a list resource, paged the way the real service does:
+// commits read searchCriteria.$top and searchCriteria.$skip, pull requests read $top and
+// $skip or, when tokens is set, a continuation token handed out in x-ms-continuationtoken
+type listServer struct {
+	total    int
+	tokens   bool
+	requests []string // Raw query of each list request
+}
+
+func (s *listServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	s.requests = append(s.requests, r.URL.RawQuery)
+
+	commits := strings.HasSuffix(r.URL.Path, "/commits")
+	prefix := ""
+	if commits {
+		prefix = "searchCriteria."
+	}
+	top, _ := strconv.Atoi(q.Get(prefix + "$top"))
+	if top == 0 {
+		top = 100
+	}
+	skip, _ := strconv.Atoi(q.Get(prefix + "$skip"))
+	if s.tokens {
+		skip, _ = strconv.Atoi(q.Get("continuationToken"))
+	}
+	end := min(skip+top, s.total)
+	if s.tokens && end < s.total {
+		w.Header().Set("x-ms-continuationtoken", strconv.Itoa(end))
+	}
+
+	now := time.Now().UTC().Add(-time.Hour).Format(time.RFC3339)
+	var items []string
+	for i := skip; i < end; i++ {
+		if commits {
+			items = append(items, fmt.Sprintf(`{"commitId":"c%d","author":{"name":"dev","date":%q},"comment":"change"}`, i, now))
+		} else {
+			items = append(items, fmt.Sprintf(`{"pullRequestId":%d,"status":"active","createdBy":{"displayName":"dev"},"creationDate":%q}`, i, now))
+		}
+	}
+	fmt.Fprintf(w, `{"count":%d,"value":[%s]}`, len(items), strings.Join(items, ","))
+}
+
+func newTestClient(srv *httptest.Server) Client {
+	cfg := config.Config{AzureURL: srv.URL, AzureOrg: "acme", AzureProject: "proj", AzureRepo: "api", DaysToAnalyze: 30}
+	return NewClient(cfg).WithHTTPClient(srv.Client())
+}
+
+func TestFetchCommitsPages(t *testing.T) {
+	tests := []struct {
+		name         string
+		total        int
+		wantRequests int
+	}{
+		{"single short page", 40, 1},
+		{"several pages", 250, 3},
+		{"exact multiple of the page size", 200, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &listServer{total: tt.total}
+			srv := httptest.NewServer(s)
+			defer srv.Close()
+
+			commits, err := newTestClient(srv).FetchCommits(context.Background())
+			if err != nil {
+				t.Fatalf("FetchCommits() error = %v", err)
+			}
+			seen := make(map[string]bool)
+			for _, c := range commits {
+				seen[c.Hash] = true
+			}
+			if len(commits) != tt.total || len(seen) != tt.total {
+				t.Errorf("got %d commits (%d distinct), want %d", len(commits), len(seen), tt.total)
+			}
+			if len(s.requests) != tt.wantRequests {
+				t.Errorf("made %d requests, want %d", len(s.requests), tt.wantRequests)
+			}
+			for _, query := range s.requests {
+				if q, _ := url.ParseQuery(query); q.Has("$top") || q.Has("$skip") {
+					t.Errorf("commits request %q uses unprefixed paging parameters", query)
+				}
+			}
+		})
+	}
+}
+
+func TestFetchPRsPages(t *testing.T) {
+	tests := []struct {
+		name         string
+		total        int
+		tokens       bool
+		wantRequests int
+	}{
+		{"skip paging", 150, false, 2},
+		{"continuation tokens", 230, true, 3},
+		{"continuation token on a full last page", 200, true, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &listServer{total: tt.total, tokens: tt.tokens}
+			srv := httptest.NewServer(s)
+			defer srv.Close()
+
+			prs, err := newTestClient(srv).FetchPRs(context.Background())
+			if err != nil {
+				t.Fatalf("FetchPRs() error = %v", err)
+			}
+			seen := make(map[string]bool)
+			for _, pr := range prs {
+				seen[pr.ID] = true
+			}
+			if len(prs) != tt.total || len(seen) != tt.total {
+				t.Errorf("got %d PRs (%d distinct), want %d", len(prs), len(seen), tt.total)
+			}
+			if len(s.requests) != tt.wantRequests {
+				t.Errorf("made %d requests, want %d", len(s.requests), tt.wantRequests)
+			}
+		})
+	}
+}
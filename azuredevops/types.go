@@ -0,0 +1,38 @@
+package azuredevops
+
+import "time"
+
+// types.go - Data structures for Azure DevOps integration
+
+// Commit represents a git commit
+type Commit struct {
+	Hash         string    `json:"hash"`
+	Author       string    `json:"author"`
+	Date         time.Time `json:"date"`
+	Message      string    `json:"message"`
+	LinesAdded   int       `json:"lines_added"`
+	LinesDeleted int       `json:"lines_deleted"`
+	Repo         string    `json:"repo,omitempty"`
+}
+
+// PullRequest represents an Azure DevOps pull request
+type PullRequest struct {
+	ID            string     `json:"id"`
+	Author        string     `json:"author"`
+	CreatedAt     time.Time  `json:"created_at"`
+	MergedAt      *time.Time `json:"merged_at,omitempty"`
+	ClosedAt      *time.Time `json:"closed_at,omitempty"`
+	FirstReviewAt *time.Time `json:"first_review_at,omitempty"`
+	LinesChanged  int        `json:"lines_changed"`
+	Reviewers     []string   `json:"reviewers"`
+	CommentCount  int        `json:"comment_count"`
+	Approvers     []string   `json:"approvers,omitempty"`
+	MergedBy      string     `json:"merged_by,omitempty"`
+	BaseBranch    string     `json:"base_branch,omitempty"`
+	Title         string     `json:"title,omitempty"`
+	Labels        []string   `json:"labels,omitempty"`
+	CommitHashes  []string   `json:"commit_hashes,omitempty"` // Source and merge commits of the pull request
+	UpdatedAt     *time.Time `json:"updated_at,omitempty"`
+	Repo          string     `json:"repo,omitempty"`
+	Status        string     `json:"status"`
+}
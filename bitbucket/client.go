@@ -1,20 +1,45 @@
 package bitbucket
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
+	"sync"
 	"time"
+
 	"devops-metrics/config"
+	"devops-metrics/fetchstats"
+	"devops-metrics/gitlocal"
+	"devops-metrics/pathfilter"
+	"devops-metrics/replay"
 )
 
 // Client handles Bitbucket API operations
 type Client struct {
-	config config.Config
+	config     config.Config
+	stats      *fetchstats.Recorder
+	httpClient HTTPClient
+	progress   func(fetched int)
+}
+
+// HTTPClient sends API requests; *http.Client satisfies it, and tests can substitute a stub
+type HTTPClient interface {
+	Do(req *http.Request) (*http.Response, error)
 }
 
 // Bitbucket API responses
+type bitbucketRepoResponse struct {
+	Slug     string `json:"slug"`
+	Archived bool   `json:"archived"`
+	Origin   *struct {
+		Slug string `json:"slug"`
+	} `json:"origin"`
+}
+
 type bitbucketBranchesResponse struct {
 	Size        int `json:"size"`
 	Limit       int `json:"limit"`
@@ -49,35 +74,63 @@ type bitbucketCommitsResponse struct {
 }
 
 type bitbucketPRsResponse struct {
-	Size        int `json:"size"`
-	Limit       int `json:"limit"`
-	IsLastPage  bool `json:"isLastPage"`
-	Start       int `json:"start"`
-	Values      []struct {
-		ID          int    `json:"id"`
-		Title       string `json:"title"`
-		State       string `json:"state"` // OPEN, MERGED, DECLINED
-		CreatedDate int64  `json:"createdDate"`
-		UpdatedDate int64  `json:"updatedDate"`
-		ClosedDate  int64  `json:"closedDate"`
-		Author      struct {
-			User struct {
-				Name string `json:"name"`
-			} `json:"user"`
-		} `json:"author"`
-		Reviewers []struct {
-			User struct {
-				Name string `json:"name"`
-			} `json:"user"`
-			Approved bool `json:"approved"`
-		} `json:"reviewers"`
-	} `json:"values"`
-	NextPageStart int `json:"nextPageStart"`
+	Size          int           `json:"size"`
+	Limit         int           `json:"limit"`
+	IsLastPage    bool          `json:"isLastPage"`
+	Start         int           `json:"start"`
+	Values        []bitbucketPR `json:"values"`
+	NextPageStart int           `json:"nextPageStart"`
+}
+
+type bitbucketPR struct {
+	ID          int    `json:"id"`
+	Title       string `json:"title"`
+	State       string `json:"state"` // OPEN, MERGED, DECLINED
+	CreatedDate int64  `json:"createdDate"`
+	UpdatedDate int64  `json:"updatedDate"`
+	ClosedDate  int64  `json:"closedDate"`
+	Author      struct {
+		User struct {
+			Name string `json:"name"`
+		} `json:"user"`
+	} `json:"author"`
+	Reviewers []struct {
+		User struct {
+			Name string `json:"name"`
+		} `json:"user"`
+		Approved bool `json:"approved"`
+	} `json:"reviewers"`
+	Properties struct {
+		CommentCount int `json:"commentCount"`
+	} `json:"properties"`
+	ToRef struct {
+		DisplayID string `json:"displayId"`
+	} `json:"toRef"`
+}
+
+type bitbucketActivity struct {
+	Action      string `json:"action"` // OPENED, APPROVED, REVIEWED, COMMENTED, MERGED, ...
+	CreatedDate int64  `json:"createdDate"`
+	User        struct {
+		Name string `json:"name"`
+	} `json:"user"`
+}
+
+type bitbucketActivitiesResponse struct {
+	IsLastPage    bool                `json:"isLastPage"`
+	Values        []bitbucketActivity `json:"values"`
+	NextPageStart int                 `json:"nextPageStart"`
+}
+
+type bitbucketDiffPath struct {
+	ToString string `json:"toString"`
 }
 
 type bitbucketPRDiffResponse struct {
 	Diffs []struct {
-		Hunks []struct {
+		Source      *bitbucketDiffPath `json:"source"`
+		Destination *bitbucketDiffPath `json:"destination"`
+		Hunks       []struct {
 			Segments []struct {
 				Type  string `json:"type"` // ADDED, REMOVED, CONTEXT
 				Lines []struct {
@@ -91,17 +144,44 @@ type bitbucketPRDiffResponse struct {
 // NewClient creates a new Bitbucket client
 func NewClient(config config.Config) Client {
 	return Client{
-		config: config,
+		config:     config,
+		httpClient: replay.NewHTTPClient("bitbucket", config),
+	}
+}
+
+// WithStats returns a copy of the client that records request statistics into r
+func (c Client) WithStats(r *fetchstats.Recorder) Client {
+	c.stats = r
+	return c
+}
+
+// WithProgress returns a copy of the client that calls fn with the running count of items
+// after each page of a list fetch
+func (c Client) WithProgress(fn func(fetched int)) Client {
+	c.progress = fn
+	return c
+}
+
+// reportProgress passes the running count of fetched items to the progress callback, if any
+func (c Client) reportProgress(fetched int) {
+	if c.progress != nil {
+		c.progress(fetched)
 	}
 }
 
+// WithHTTPClient returns a copy of the client that sends its requests through h
+func (c Client) WithHTTPClient(h HTTPClient) Client {
+	c.httpClient = h
+	return c
+}
+
 // makeRequest makes an HTTP request with proper authentication and exponential backoff for 429 errors
-func (c Client) makeRequest(url, method, username, token string) ([]byte, error) {
+func (c Client) makeRequest(ctx context.Context, url, method, username, token string) ([]byte, error) {
 	const maxRetries = 5
 	const baseDelay = 1 * time.Second
 
 	for attempt := 0; attempt <= maxRetries; attempt++ {
-		req, err := http.NewRequest(method, url, nil)
+		req, err := http.NewRequestWithContext(ctx, method, url, nil)
 		if err != nil {
 			return nil, err
 		}
@@ -112,55 +192,115 @@ func (c Client) makeRequest(url, method, username, token string) ([]byte, error)
 			req.Header.Set("Authorization", "Bearer "+token)
 		}
 
-		client := &http.Client{Timeout: 30 * time.Second}
-		resp, err := client.Do(req)
+		start := time.Now()
+		resp, err := c.httpClient.Do(req)
 		if err != nil {
+			c.stats.RecordRequest("bitbucket", 0, time.Since(start), err)
 			return nil, err
 		}
 		defer resp.Body.Close()
 
-		if resp.StatusCode == http.StatusOK {
-			return io.ReadAll(resp.Body)
+		if c.config.IsSuccessStatus(resp.StatusCode) {
+			body, err := io.ReadAll(resp.Body)
+			c.stats.RecordRequest("bitbucket", len(body), time.Since(start), err)
+			return body, err
 		}
 
 		if resp.StatusCode == http.StatusTooManyRequests && attempt < maxRetries {
+			c.stats.RecordRequest("bitbucket", 0, time.Since(start), nil)
+			c.stats.RecordRetry("bitbucket")
 			// Exponential backoff with jitter
 			delay := time.Duration(baseDelay.Nanoseconds() * (1 << attempt))
 			// Add jitter (up to 50%)
 			jitter := time.Duration(time.Now().UnixNano()%int64(time.Second/2)) % (delay / 2)
-			time.Sleep(delay + jitter)
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(delay + jitter):
+			}
 			continue
 		}
 
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
+		err = &APIError{StatusCode: resp.StatusCode, Body: string(body)}
+		c.stats.RecordRequest("bitbucket", len(body), time.Since(start), err)
+		return nil, err
 	}
 
 	return nil, fmt.Errorf("API request failed after %d attempts", maxRetries+1)
 }
 
-// FetchCommits retrieves commits from all branches in Bitbucket
-func (c Client) FetchCommits() ([]Commit, error) {
+// FetchRepoInfo retrieves the archived/fork flags for the configured repository.
+// Forks are identified by the presence of an origin repository.
+func (c Client) FetchRepoInfo(ctx context.Context) (RepoInfo, error) {
+	url := fmt.Sprintf("%s/rest/api/1.0/projects/%s/repos/%s",
+		c.config.BitbucketURL,
+		c.config.BitbucketProject,
+		c.config.BitbucketRepo,
+	)
+
+	body, err := c.makeRequest(ctx, url, "GET", "", c.config.BitbucketToken)
+	if err != nil {
+		return RepoInfo{}, c.notFoundOr(fmt.Errorf("error fetching repository: %w", err))
+	}
+
+	var repo bitbucketRepoResponse
+	if err := json.Unmarshal(body, &repo); err != nil {
+		return RepoInfo{}, fmt.Errorf("error parsing repository response: %w", err)
+	}
+
+	return RepoInfo{
+		Name:     c.repoName(),
+		Archived: repo.Archived,
+		Fork:     repo.Origin != nil,
+	}, nil
+}
+
+// FetchCommits retrieves commits from all branches in Bitbucket, or from the
+// local clone when BitbucketGitDir is configured
+func (c Client) FetchCommits(ctx context.Context) ([]Commit, error) {
+	if c.config.BitbucketGitDir != "" {
+		return c.fetchLocalCommits(ctx)
+	}
+
+	// Make sure the repository exists so an empty result means "no activity", not "misconfigured"
+	if _, err := c.FetchRepoInfo(ctx); err != nil {
+		return nil, err
+	}
+
 	// Get all branches first
-	branches, err := c.getBranches()
+	branches, err := c.getBranches(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("error fetching branches: %w", err)
 	}
+	if len(branches) == 0 {
+		slog.Info("Repository has no branches", "provider", "bitbucket", "repo", c.repoName())
+		return []Commit{}, nil
+	}
 
-	var allCommits []Commit
-	since := time.Now().AddDate(0, 0, -c.config.DaysToAnalyze)
+	allCommits := []Commit{}
+	since, until := c.config.Window()
 
 	// Process branches starting with those that have the most recent commits
 	for _, branch := range branches {
-		branchCommits, shouldContinue, err := c.fetchCommitsFromBranch(branch, since)
+		remaining := 0
+		if max := c.config.CommitCap(); max > 0 {
+			remaining = max - len(allCommits)
+		}
+		branchCommits, shouldContinue, err := c.fetchCommitsFromBranch(ctx, branch, since, until, len(allCommits), remaining)
 		if err != nil {
 			// Log error but continue with other branches
-			fmt.Printf("Error fetching commits from branch %s: %v\n", branch.DisplayID, err)
+			slog.Error("Error fetching commits from branch", "provider", "bitbucket", "repo", c.repoName(), "branch", branch.DisplayID, "error", err)
 			continue
 		}
 
 		allCommits = append(allCommits, branchCommits...)
 
+		if max := c.config.CommitCap(); max > 0 && len(allCommits) >= max {
+			c.truncated("commits", max)
+			break
+		}
+
 		// If no commits in time range were found in this branch and we already have commits,
 		// we can skip remaining branches (assuming branches are sorted by latest activity)
 		if !shouldContinue && len(allCommits) > 0 {
@@ -168,6 +308,10 @@ func (c Client) FetchCommits() ([]Commit, error) {
 		}
 	}
 
+	if c.config.FetchCommitLineCounts {
+		c.fetchCommitLineCounts(ctx, allCommits)
+	}
+
 	return allCommits, nil
 }
 
@@ -178,7 +322,7 @@ type BranchWithActivity struct {
 }
 
 // getBranches retrieves all branches from the repository and sorts them by activity
-func (c Client) getBranches() ([]BranchWithActivity, error) {
+func (c Client) getBranches(ctx context.Context) ([]BranchWithActivity, error) {
 	var branches []BranchWithActivity
 	start := 0
 	limit := 100
@@ -192,14 +336,19 @@ func (c Client) getBranches() ([]BranchWithActivity, error) {
 			start,
 		)
 
-		body, err := c.makeRequest(url, "GET", "", c.config.BitbucketToken)
+		body, err := c.makeRequest(ctx, url, "GET", "", c.config.BitbucketToken)
 		if err != nil {
 			return nil, fmt.Errorf("error fetching branches: %w", err)
 		}
 
 		var response bitbucketBranchesResponse
 		if err := json.Unmarshal(body, &response); err != nil {
-			return nil, fmt.Errorf("error parsing branches response: %w", err)
+			if start == 0 {
+				return nil, fmt.Errorf("error parsing branches response: %w", err)
+			}
+			// Keep the pages already fetched rather than discarding them for one truncated page
+			slog.Warn("Stopping branch listing, page could not be parsed", "provider", "bitbucket", "repo", c.repoName(), "start", start, "error", err)
+			break
 		}
 
 		for _, branch := range response.Values {
@@ -219,8 +368,10 @@ func (c Client) getBranches() ([]BranchWithActivity, error) {
 	return branches, nil
 }
 
-// fetchCommitsFromBranch retrieves commits from a specific branch and returns whether to continue checking other branches
-func (c Client) fetchCommitsFromBranch(branch BranchWithActivity, since time.Time) ([]Commit, bool, error) {
+// fetchCommitsFromBranch retrieves commits from a specific branch and returns whether to continue checking other branches.
+// At most max commits are returned when max is positive; fetched is the number of commits
+// already collected from other branches, for progress reporting.
+func (c Client) fetchCommitsFromBranch(ctx context.Context, branch BranchWithActivity, since, until time.Time, fetched, max int) ([]Commit, bool, error) {
 	var commits []Commit
 	start := 0
 	limit := 100
@@ -236,14 +387,18 @@ func (c Client) fetchCommitsFromBranch(branch BranchWithActivity, since time.Tim
 			branch.ID,
 		)
 
-		body, err := c.makeRequest(url, "GET", "", c.config.BitbucketToken)
+		body, err := c.makeRequest(ctx, url, "GET", "", c.config.BitbucketToken)
 		if err != nil {
 			return nil, true, fmt.Errorf("error fetching commits for branch %s: %w", branch.DisplayID, err)
 		}
 
 		var response bitbucketCommitsResponse
 		if err := json.Unmarshal(body, &response); err != nil {
-			return nil, true, fmt.Errorf("error parsing commits response for branch %s: %w", branch.DisplayID, err)
+			if start == 0 {
+				return nil, true, fmt.Errorf("error parsing commits response for branch %s: %w", branch.DisplayID, err)
+			}
+			slog.Warn("Stopping commits for branch, page could not be parsed", "provider", "bitbucket", "repo", c.repoName(), "branch", branch.DisplayID, "start", start, "error", err)
+			return commits, true, nil
 		}
 
 		for _, commit := range response.Values {
@@ -254,18 +409,26 @@ func (c Client) fetchCommitsFromBranch(branch BranchWithActivity, since time.Tim
 			}
 
 			hasRecentCommits = true
+			if !commitDate.Before(until) {
+				// Newer than the window; keep paging back towards it
+				continue
+			}
 			commits = append(commits, Commit{
 				Hash:    commit.ID,
 				Author:  commit.Author.Name,
 				Date:    commitDate,
 				Message: commit.Message,
-				// Note: Bitbucket API doesn't provide line counts directly
-				// You'd need to fetch diff for each commit for accurate counts
+				// The commit list has no line counts; see fetchCommitLineCounts
 				LinesAdded:   0,
 				LinesDeleted: 0,
+				Repo:         c.repoName(),
 			})
+			if max > 0 && len(commits) >= max {
+				return commits, hasRecentCommits, nil
+			}
 		}
 
+		c.reportProgress(fetched + len(commits))
 		if response.IsLastPage {
 			break
 		}
@@ -276,12 +439,14 @@ func (c Client) fetchCommitsFromBranch(branch BranchWithActivity, since time.Tim
 }
 
 // FetchPRs retrieves pull requests from Bitbucket
-func (c Client) FetchPRs() ([]PullRequest, error) {
-	var prs []PullRequest
+func (c Client) FetchPRs(ctx context.Context) ([]PullRequest, error) {
+	var listed []bitbucketPR
 	start := 0
 	limit := 100
 	states := []string{"ALL"}
+	ignore := pathfilter.New(c.config.IgnoreFiles)
 
+states:
 	for _, state := range states {
 		start = 0
 		for {
@@ -294,95 +459,432 @@ func (c Client) FetchPRs() ([]PullRequest, error) {
 				start,
 			)
 
-			body, err := c.makeRequest(url, "GET", "", c.config.BitbucketToken)
+			body, err := c.makeRequest(ctx, url, "GET", "", c.config.BitbucketToken)
 			if err != nil {
-				return nil, fmt.Errorf("error fetching PRs: %w", err)
+				return nil, c.notFoundOr(fmt.Errorf("error fetching PRs: %w", err))
 			}
 
 			var response bitbucketPRsResponse
 			if err := json.Unmarshal(body, &response); err != nil {
-				return nil, fmt.Errorf("error parsing PRs response: %w", err)
+				if len(listed) == 0 {
+					return nil, fmt.Errorf("error parsing PRs response: %w", err)
+				}
+				slog.Warn("Stopping pull requests, page could not be parsed", "provider", "bitbucket", "repo", c.repoName(), "state", state, "start", start, "error", err)
+				break
 			}
 
 			for _, pr := range response.Values {
 				createdAt := time.Unix(pr.CreatedDate/1000, 0)
-				since := time.Now().AddDate(0, 0, -c.config.DaysToAnalyze)
-
-				if createdAt.Before(since) {
+				if !c.config.InWindow(createdAt) {
 					continue
 				}
 
-				var mergedAt, closedAt, firstReviewAt *time.Time
-				status := pr.State
-
-				if pr.ClosedDate > 0 {
-					t := time.Unix(pr.ClosedDate/1000, 0)
-					if status == "MERGED" {
-						mergedAt = &t
-					} else {
-						closedAt = &t
-					}
+				listed = append(listed, pr)
+				if max := c.config.PRCap(); max > 0 && len(listed) >= max {
+					c.truncated("PRs", max)
+					break states
 				}
+			}
 
-				// Find first review time
-				for _, reviewer := range pr.Reviewers {
-					if reviewer.Approved && firstReviewAt == nil {
-						// Approximate with updated date
-						t := time.Unix(pr.UpdatedDate/1000, 0)
-						firstReviewAt = &t
-						break
-					}
-				}
+			c.reportProgress(len(listed))
+			if response.IsLastPage {
+				break
+			}
+			start = response.NextPageStart
+		}
+	}
 
-				var reviewers []string
-				for _, reviewer := range pr.Reviewers {
-					reviewers = append(reviewers, reviewer.User.Name)
-				}
+	// Diffs and other per-PR lookups are the slow part, so convert in parallel
+	return dedupePRs(c.toPullRequests(ctx, listed, ignore)), nil
+}
+
+// toPullRequests converts PRs using up to MaxConcurrency concurrent workers, each of which
+// makes its own diff and detail requests. Results keep the order of the input.
+func (c Client) toPullRequests(ctx context.Context, listed []bitbucketPR, ignore pathfilter.Matcher) []PullRequest {
+	prs := make([]PullRequest, len(listed))
+	sem := make(chan struct{}, c.config.Concurrency())
+	var wg sync.WaitGroup
+
+	for i, pr := range listed {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, pr bitbucketPR) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			prs[i] = c.toPullRequest(ctx, pr, ignore)
+		}(i, pr)
+	}
+	wg.Wait()
+
+	return prs
+}
+
+// FetchPRsByID retrieves specific pull requests regardless of the analysis window.
+// PRs that cannot be fetched are reported and skipped.
+func (c Client) FetchPRsByID(ctx context.Context, ids []int) ([]PullRequest, error) {
+	listed := []bitbucketPR{}
+	ignore := pathfilter.New(c.config.IgnoreFiles)
+
+	for _, id := range ids {
+		url := fmt.Sprintf("%s/rest/api/1.0/projects/%s/repos/%s/pull-requests/%d",
+			c.config.BitbucketURL,
+			c.config.BitbucketProject,
+			c.config.BitbucketRepo,
+			id,
+		)
+
+		body, err := c.makeRequest(ctx, url, "GET", "", c.config.BitbucketToken)
+		if err != nil {
+			slog.Warn("Skipping pull request", "provider", "bitbucket", "repo", c.repoName(), "pr", id, "error", err)
+			continue
+		}
+
+		var pr bitbucketPR
+		if err := json.Unmarshal(body, &pr); err != nil {
+			slog.Warn("Skipping pull request, response could not be parsed", "provider", "bitbucket", "repo", c.repoName(), "pr", id, "error", err)
+			continue
+		}
+
+		listed = append(listed, pr)
+	}
+
+	return c.toPullRequests(ctx, listed, ignore), nil
+}
+
+// toPullRequest converts an API pull request into a PullRequest, fetching its diff
+// (and merge actor, when configured) for line counts
+func (c Client) toPullRequest(ctx context.Context, pr bitbucketPR, ignore pathfilter.Matcher) PullRequest {
+	createdAt := time.Unix(pr.CreatedDate/1000, 0)
+
+	var mergedAt, closedAt, firstReviewAt *time.Time
+	status := pr.State
+
+	if pr.ClosedDate > 0 {
+		t := time.Unix(pr.ClosedDate/1000, 0)
+		if status == "MERGED" {
+			mergedAt = &t
+		} else {
+			closedAt = &t
+		}
+	}
+
+	updatedAt := time.Unix(pr.UpdatedDate/1000, 0)
+
+	// The activity stream holds both the first review and who merged
+	activities := c.fetchActivities(ctx, pr.ID)
+	firstReviewAt = firstReview(activities, pr.Author.User.Name)
+
+	var reviewers, approvers []string
+	for _, reviewer := range pr.Reviewers {
+		reviewers = append(reviewers, reviewer.User.Name)
+		if reviewer.Approved {
+			approvers = append(approvers, reviewer.User.Name)
+		}
+	}
+
+	var mergedBy string
+	if c.config.FetchMergeActor && status == "MERGED" {
+		mergedBy = mergedByActivity(activities)
+	}
+
+	var firstCommitAt *time.Time
+	var commitHashes []string
+	if c.config.FetchPRCommits {
+		firstCommitAt, commitHashes = c.fetchPRCommits(ctx, pr.ID)
+	}
+
+	// Fetch diff to get line counts
+	linesChanged, linesIgnored := 0, 0
+	diffURL := fmt.Sprintf("%s/rest/api/1.0/projects/%s/repos/%s/pull-requests/%d/diff",
+		c.config.BitbucketURL,
+		c.config.BitbucketProject,
+		c.config.BitbucketRepo,
+		pr.ID,
+	)
+
+	diffBody, err := c.makeRequest(ctx, diffURL, "GET", "", c.config.BitbucketToken)
+	if err == nil {
+		var diffResp bitbucketPRDiffResponse
+		if err := json.Unmarshal(diffBody, &diffResp); err == nil {
+			added, removed, ignored := diffResp.lineCounts(ignore)
+			linesChanged, linesIgnored = added+removed, ignored
+		}
+	}
+
+	return PullRequest{
+		ID:            fmt.Sprintf("PR-%d", pr.ID),
+		Author:        pr.Author.User.Name,
+		CreatedAt:     createdAt,
+		MergedAt:      mergedAt,
+		ClosedAt:      closedAt,
+		FirstReviewAt: firstReviewAt,
+		LinesChanged:  linesChanged,
+		LinesIgnored:  linesIgnored,
+		Status:        status,
+		Reviewers:     reviewers,
+		CommentCount:  pr.Properties.CommentCount,
+		Approvers:     approvers,
+		MergedBy:      mergedBy,
+		FirstCommitAt: firstCommitAt,
+		BaseBranch:    pr.ToRef.DisplayID,
+		Title:         pr.Title,
+		CommitHashes:  commitHashes,
+		UpdatedAt:     &updatedAt,
+		Repo:          c.repoName(),
+	}
+}
+
+// dedupePRs keeps one record per PR ID, in first-seen order, so overlapping state queries
+// don't double-count
+func dedupePRs(candidates []PullRequest) []PullRequest {
+	var prs []PullRequest
+	// Index of each PR in prs by ID
+	seen := make(map[string]int)
+	for _, candidate := range candidates {
+		if i, ok := seen[candidate.ID]; ok {
+			if completeness(candidate) > completeness(prs[i]) {
+				prs[i] = candidate
+			}
+			continue
+		}
+		seen[candidate.ID] = len(prs)
+		prs = append(prs, candidate)
+	}
+	return prs
+}
+
+// completeness scores how much of a PR's lifecycle a record captures; when the same PR
+// comes back from several state queries the record with the higher score is kept
+func completeness(pr PullRequest) int {
+	score := len(pr.Reviewers) + len(pr.Approvers)
+	if pr.MergedAt != nil || pr.ClosedAt != nil {
+		score += 100
+	}
+	if pr.FirstReviewAt != nil {
+		score += 10
+	}
+	if pr.LinesChanged > 0 || pr.LinesIgnored > 0 {
+		score += 10
+	}
+	if pr.MergedBy != "" {
+		score++
+	}
+	return score
+}
+
+// fetchLocalCommits reads commits with accurate line counts from the configured local clone
+func (c Client) fetchLocalCommits(ctx context.Context) ([]Commit, error) {
+	since, until := c.config.Window()
+	localCommits, err := gitlocal.NewClient(c.config.BitbucketGitDir, pathfilter.New(c.config.IgnoreFiles)).FetchCommits(ctx, since, until)
+	if err != nil {
+		return nil, err
+	}
+
+	commits := make([]Commit, len(localCommits))
+	for i, lc := range localCommits {
+		commits[i] = Commit{
+			Hash:         lc.Hash,
+			Author:       lc.Author,
+			Date:         lc.Date,
+			Message:      lc.Message,
+			LinesAdded:   lc.LinesAdded,
+			LinesDeleted: lc.LinesDeleted,
+			LinesIgnored: lc.LinesIgnored,
+			Repo:         c.repoName(),
+		}
+	}
+	if max := c.config.CommitCap(); max > 0 && len(commits) > max {
+		c.truncated("commits", max)
+		commits = commits[:max]
+	}
+	return commits, nil
+}
+
+// truncated reports that fetching kind stopped at the configured cap, so results are incomplete
+func (c Client) truncated(kind string, max int) {
+	slog.Warn("Stopped fetching at the configured cap; results are truncated", "provider", "bitbucket", "repo", c.repoName(), "kind", kind, "count", max)
+	c.stats.RecordTruncated("bitbucket", kind)
+}
 
-				// Fetch diff to get line counts
-				linesChanged := 0
-				diffURL := fmt.Sprintf("%s/rest/api/1.0/projects/%s/repos/%s/pull-requests/%d/diff",
-					c.config.BitbucketURL,
-					c.config.BitbucketProject,
-					c.config.BitbucketRepo,
-					pr.ID,
-				)
-
-				diffBody, err := c.makeRequest(diffURL, "GET", "", c.config.BitbucketToken)
-				if err == nil {
-					var diffResp bitbucketPRDiffResponse
-					if err := json.Unmarshal(diffBody, &diffResp); err == nil {
-						for _, diff := range diffResp.Diffs {
-							for _, hunk := range diff.Hunks {
-								for _, segment := range hunk.Segments {
-									if segment.Type == "ADDED" || segment.Type == "REMOVED" {
-										linesChanged += len(segment.Lines)
-									}
-								}
-							}
-						}
-					}
+// repoName returns the project/repo identifier used to tag fetched data
+func (c Client) repoName() string {
+	return c.config.BitbucketProject + "/" + c.config.BitbucketRepo
+}
+
+// lineCounts counts added and removed lines in a diff. Lines in files matched by ignore are
+// counted as ignored instead.
+func (d bitbucketPRDiffResponse) lineCounts(ignore pathfilter.Matcher) (added, removed, ignored int) {
+	for _, diff := range d.Diffs {
+		skip := ignore.Match(diffPath(diff.Source, diff.Destination))
+		for _, hunk := range diff.Hunks {
+			for _, segment := range hunk.Segments {
+				switch {
+				case segment.Type != "ADDED" && segment.Type != "REMOVED":
+					continue
+				case skip:
+					ignored += len(segment.Lines)
+				case segment.Type == "ADDED":
+					added += len(segment.Lines)
+				default:
+					removed += len(segment.Lines)
 				}
+			}
+		}
+	}
+	return added, removed, ignored
+}
 
-				prs = append(prs, PullRequest{
-					ID:            fmt.Sprintf("PR-%d", pr.ID),
-					Author:        pr.Author.User.Name,
-					CreatedAt:     createdAt,
-					MergedAt:      mergedAt,
-					ClosedAt:      closedAt,
-					FirstReviewAt: firstReviewAt,
-					LinesChanged:  linesChanged,
-					Status:        status,
-					Reviewers:     reviewers,
-				})
+// fetchCommitLineCounts fills in line counts for commits from their diffs, using up to
+// MaxConcurrency concurrent requests. Commits whose diff cannot be fetched keep zero counts.
+func (c Client) fetchCommitLineCounts(ctx context.Context, commits []Commit) {
+	ignore := pathfilter.New(c.config.IgnoreFiles)
+	sem := make(chan struct{}, c.config.Concurrency())
+	var wg sync.WaitGroup
+
+	for i := range commits {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(commit *Commit) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			url := fmt.Sprintf("%s/rest/api/1.0/projects/%s/repos/%s/commits/%s/diff",
+				c.config.BitbucketURL,
+				c.config.BitbucketProject,
+				c.config.BitbucketRepo,
+				commit.Hash,
+			)
+			body, err := c.makeRequest(ctx, url, "GET", "", c.config.BitbucketToken)
+			if err != nil {
+				return
+			}
+			var diffResp bitbucketPRDiffResponse
+			if err := json.Unmarshal(body, &diffResp); err != nil {
+				return
 			}
+			commit.LinesAdded, commit.LinesDeleted, commit.LinesIgnored = diffResp.lineCounts(ignore)
+		}(&commits[i])
+	}
+	wg.Wait()
+}
 
-			if response.IsLastPage {
-				break
+// diffPath returns the file path of a diff entry, preferring the destination for renames/additions
+func diffPath(source, destination *bitbucketDiffPath) string {
+	if destination != nil && destination.ToString != "" {
+		return destination.ToString
+	}
+	if source != nil {
+		return source.ToString
+	}
+	return ""
+}
+
+// fetchActivities returns a PR's activity stream, as far as it could be read
+func (c Client) fetchActivities(ctx context.Context, prID int) []bitbucketActivity {
+	var activities []bitbucketActivity
+	start := 0
+	for {
+		url := fmt.Sprintf("%s/rest/api/1.0/projects/%s/repos/%s/pull-requests/%d/activities?limit=100&start=%d",
+			c.config.BitbucketURL,
+			c.config.BitbucketProject,
+			c.config.BitbucketRepo,
+			prID,
+			start,
+		)
+
+		body, err := c.makeRequest(ctx, url, "GET", "", c.config.BitbucketToken)
+		if err != nil {
+			return activities
+		}
+
+		var response bitbucketActivitiesResponse
+		if err := json.Unmarshal(body, &response); err != nil {
+			return activities
+		}
+		activities = append(activities, response.Values...)
+
+		if response.IsLastPage {
+			return activities
+		}
+		start = response.NextPageStart
+	}
+}
+
+// firstReview returns when someone other than the author first approved, marked as needing
+// work or commented on the PR, or nil when nobody has. The stream is newest first, so every
+// activity is checked rather than stopping at the first match.
+func firstReview(activities []bitbucketActivity, author string) *time.Time {
+	var first *time.Time
+	for _, activity := range activities {
+		switch activity.Action {
+		case "APPROVED", "REVIEWED", "COMMENTED":
+		default:
+			continue
+		}
+		if activity.User.Name == author {
+			continue
+		}
+		t := time.Unix(activity.CreatedDate/1000, 0)
+		if first == nil || t.Before(*first) {
+			first = &t
+		}
+	}
+	return first
+}
+
+// mergedByActivity returns the user who merged the PR according to its activity stream, or "" if unavailable
+func mergedByActivity(activities []bitbucketActivity) string {
+	for _, activity := range activities {
+		if activity.Action == "MERGED" {
+			return activity.User.Name
+		}
+	}
+	return ""
+}
+
+// fetchPRCommits returns the author date of the earliest commit on the PR's branch and the
+// hashes of the PR's commits, as far as they could be read
+func (c Client) fetchPRCommits(ctx context.Context, prID int) (first *time.Time, hashes []string) {
+	start := 0
+	for {
+		url := fmt.Sprintf("%s/rest/api/1.0/projects/%s/repos/%s/pull-requests/%d/commits?limit=100&start=%d",
+			c.config.BitbucketURL,
+			c.config.BitbucketProject,
+			c.config.BitbucketRepo,
+			prID,
+			start,
+		)
+
+		body, err := c.makeRequest(ctx, url, "GET", "", c.config.BitbucketToken)
+		if err != nil {
+			return first, hashes
+		}
+
+		var response bitbucketCommitsResponse
+		if err := json.Unmarshal(body, &response); err != nil {
+			return first, hashes
+		}
+
+		for _, commit := range response.Values {
+			hashes = append(hashes, commit.ID)
+			t := time.Unix(commit.AuthorTimestamp/1000, 0)
+			if first == nil || t.Before(*first) {
+				first = &t
 			}
-			start = response.NextPageStart
 		}
+
+		if response.IsLastPage {
+			return first, hashes
+		}
+		start = response.NextPageStart
 	}
+}
 
-	return prs, nil
-}
\ No newline at end of file
+// notFoundOr converts a 404 from the API into a NotFoundError for the configured repository
+func (c Client) notFoundOr(err error) error {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+		return &NotFoundError{Project: c.config.BitbucketProject, Repo: c.config.BitbucketRepo}
+	}
+	return err
+}
@@ -0,0 +1,392 @@
+package bitbucket
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"devops-metrics/config"
+	"devops-metrics/pathfilter"
+)
+
+func TestDedupePRs(t *testing.T) {
+	merged := time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+	open := func(id string) PullRequest { return PullRequest{ID: id, Status: "OPEN"} }
+	done := func(id string) PullRequest {
+		return PullRequest{ID: id, Status: "MERGED", MergedAt: &merged, Reviewers: []string{"bob"}, MergedBy: "bob"}
+	}
+	tests := []struct {
+		name       string
+		candidates []PullRequest
+		want       string // ID:status of each kept PR
+	}{
+		{"empty", nil, "[]"},
+		{"distinct", []PullRequest{open("PR-1"), done("PR-2")}, "[PR-1:OPEN PR-2:MERGED]"},
+		{"overlapping states keep the complete record", []PullRequest{open("PR-1"), open("PR-2"), done("PR-1")}, "[PR-1:MERGED PR-2:OPEN]"},
+		{"less complete duplicate is dropped", []PullRequest{done("PR-1"), open("PR-1"), open("PR-1")}, "[PR-1:MERGED]"},
+		{"equal records keep the first", []PullRequest{{ID: "PR-3", Title: "first"}, {ID: "PR-3", Title: "second"}}, "[PR-3:first]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := []string{}
+			for _, pr := range dedupePRs(tt.candidates) {
+				label := pr.Status
+				if label == "" {
+					label = pr.Title
+				}
+				got = append(got, pr.ID+":"+label)
+			}
+			if fmt.Sprint(got) != tt.want {
+				t.Errorf("dedupePRs = %v, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetBranchesBadPage(t *testing.T) {
+	tests := []struct {
+		name    string
+		bad     int // Page start answered with a truncated body; -1 for none
+		want    int
+		wantErr bool
+	}{
+		{"all pages parse", -1, 3, false},
+		{"later page is truncated", 2, 2, false},
+		{"first page is truncated", 0, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				start, _ := strconv.Atoi(r.URL.Query().Get("start"))
+				if start == tt.bad {
+					fmt.Fprint(w, `{"values":[{"id":"refs/heads/tru`)
+					return
+				}
+				fmt.Fprintf(w, `{"values":[{"id":"refs/heads/b%d","displayId":"b%d"}],"isLastPage":%t,"nextPageStart":%d}`,
+					start, start, start == 2, start+1)
+			}))
+			defer srv.Close()
+
+			cfg := config.Config{BitbucketURL: srv.URL, BitbucketProject: "PROJ", BitbucketRepo: "api"}
+			branches, err := NewClient(cfg).WithHTTPClient(srv.Client()).getBranches(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("getBranches() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(branches) != tt.want {
+				t.Errorf("got %d branches, want %d", len(branches), tt.want)
+			}
+		})
+	}
+}
+
+func TestToPullRequestsConcurrencyCap(t *testing.T) {
+	tests := []struct {
+		name           string
+		maxConcurrency int
+		wantCap        int32
+	}{
+		{"configured cap", 3, 3},
+		{"default cap", 0, config.DefaultMaxConcurrency},
+		{"sequential", 1, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var inFlight, peak atomic.Int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n := inFlight.Add(1)
+				defer inFlight.Add(-1)
+				for {
+					p := peak.Load()
+					if n <= p || peak.CompareAndSwap(p, n) {
+						break
+					}
+				}
+				time.Sleep(5 * time.Millisecond)
+				fmt.Fprint(w, `{"values":[],"isLastPage":true}`)
+			}))
+			defer srv.Close()
+
+			cfg := config.Config{BitbucketURL: srv.URL, BitbucketProject: "PROJ", BitbucketRepo: "api", MaxConcurrency: tt.maxConcurrency}
+			listed := make([]bitbucketPR, 20)
+			for i := range listed {
+				listed[i].ID = i + 1
+				listed[i].State = "OPEN"
+			}
+
+			prs := NewClient(cfg).WithHTTPClient(srv.Client()).toPullRequests(context.Background(), listed, pathfilter.New(nil))
+			if got := peak.Load(); got > tt.wantCap {
+				t.Errorf("peak concurrent requests = %d, want at most %d", got, tt.wantCap)
+			}
+			if tt.wantCap > 1 && peak.Load() < 2 {
+				t.Errorf("peak concurrent requests = %d, want requests in parallel", peak.Load())
+			}
+			for i, pr := range prs {
+				if want := fmt.Sprintf("PR-%d", i+1); pr.ID != want {
+					t.Fatalf("prs[%d].ID = %s, want %s (input order)", i, pr.ID, want)
+				}
+			}
+		})
+	}
+}
+
+// cannedClient answers requests with the body stored under the request path, and 404 for
+// any other path
+type cannedClient map[string]string
+
+func (c cannedClient) Do(req *http.Request) (*http.Response, error) {
+	body, ok := c[req.URL.Path]
+	status := http.StatusOK
+	if !ok {
+		body, status = `{"errors":[{"message":"not found"}]}`, http.StatusNotFound
+	}
+	return &http.Response{
+		StatusCode: status,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Request:    req,
+	}, nil
+}
+
+func TestFetchPRsCanned(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	ms := func(hoursAgo int) int64 { return now.Add(-time.Duration(hoursAgo) * time.Hour).UnixMilli() }
+	prs := "/rest/api/1.0/projects/PROJ/repos/api/pull-requests"
+	responses := cannedClient{
+		prs: fmt.Sprintf(`{"isLastPage":true,"values":[
+			{"id":1,"title":"Add endpoint","state":"MERGED","createdDate":%d,"updatedDate":%d,"closedDate":%d,
+			 "author":{"user":{"name":"alice"}},"reviewers":[{"user":{"name":"bob"},"approved":true},{"user":{"name":"carol"},"approved":false}],
+			 "properties":{"commentCount":4},"toRef":{"displayId":"main"}},
+			{"id":2,"title":"Spike","state":"DECLINED","createdDate":%d,"updatedDate":%d,"closedDate":%d,
+			 "author":{"user":{"name":"bob"}},"toRef":{"displayId":"develop"}},
+			{"id":3,"title":"Ancient","state":"OPEN","createdDate":%d,"updatedDate":%d,
+			 "author":{"user":{"name":"carol"}},"toRef":{"displayId":"main"}}
+		]}`, ms(48), ms(10), ms(10), ms(30), ms(20), ms(20), ms(24*90), ms(24*90)),
+		prs + "/1/activities": fmt.Sprintf(`{"isLastPage":true,"values":[
+			{"action":"MERGED","createdDate":%d,"user":{"name":"bob"}},
+			{"action":"APPROVED","createdDate":%d,"user":{"name":"bob"}},
+			{"action":"COMMENTED","createdDate":%d,"user":{"name":"alice"}}
+		]}`, ms(10), ms(40), ms(45)),
+		prs + "/1/diff": `{"diffs":[{"destination":{"toString":"api/handler.go"},"hunks":[{"segments":[
+			{"type":"ADDED","lines":[{"line":"a"},{"line":"b"},{"line":"c"}]},
+			{"type":"REMOVED","lines":[{"line":"d"}]},
+			{"type":"CONTEXT","lines":[{"line":"e"}]}
+		]}]}]}`,
+		prs + "/2/activities": `{"isLastPage":true,"values":[]}`,
+	}
+	hours := func(h int) string { return now.Add(-time.Duration(h) * time.Hour).UTC().Format(time.RFC3339) }
+	when := func(t *time.Time) string {
+		if t == nil {
+			return "-"
+		}
+		return t.UTC().Format(time.RFC3339)
+	}
+
+	cfg := config.Config{BitbucketURL: "https://bitbucket.example.com", BitbucketProject: "PROJ", BitbucketRepo: "api", DaysToAnalyze: 30, FetchMergeActor: true}
+	got, err := NewClient(cfg).WithHTTPClient(responses).FetchPRs(context.Background())
+	if err != nil {
+		t.Fatalf("FetchPRs() error = %v", err)
+	}
+
+	tests := []struct {
+		id   string
+		want string
+	}{
+		{"PR-1", "alice MERGED main merged=" + hours(10) + " closed=- review=" + hours(40) + " lines=4 reviewers=[bob carol] approvers=[bob] by=bob comments=4 repo=PROJ/api"},
+		{"PR-2", "bob DECLINED develop merged=- closed=" + hours(20) + " review=- lines=0 reviewers=[] approvers=[] by= comments=0 repo=PROJ/api"},
+	}
+	if len(got) != len(tests) {
+		t.Fatalf("got %d PRs, want %d (PR-3 is outside the window)", len(got), len(tests))
+	}
+	for i, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			pr := got[i]
+			if pr.ID != tt.id {
+				t.Fatalf("ID = %s, want %s", pr.ID, tt.id)
+			}
+			summary := fmt.Sprintf("%s %s %s merged=%s closed=%s review=%s lines=%d reviewers=%v approvers=%v by=%s comments=%d repo=%s",
+				pr.Author, pr.Status, pr.BaseBranch, when(pr.MergedAt), when(pr.ClosedAt), when(pr.FirstReviewAt),
+				pr.LinesChanged, pr.Reviewers, pr.Approvers, pr.MergedBy, pr.CommentCount, pr.Repo)
+			if summary != tt.want {
+				t.Errorf("PR =\n  %s\nwant\n  %s", summary, tt.want)
+			}
+		})
+	}
+}
+
+func TestFirstReview(t *testing.T) {
+	base := time.Date(2025, 1, 6, 9, 0, 0, 0, time.UTC)
+	at := func(hours int) int64 { return base.Add(time.Duration(hours) * time.Hour).UnixMilli() }
+
+	tests := []struct {
+		name       string
+		activities string
+		want       string
+	}{
+		{
+			name: "earliest review in a newest-first stream",
+			activities: fmt.Sprintf(`[
+				{"action":"MERGED","createdDate":%d,"user":{"name":"bob"}},
+				{"action":"APPROVED","createdDate":%d,"user":{"name":"bob"}},
+				{"action":"COMMENTED","createdDate":%d,"user":{"name":"carol"}},
+				{"action":"REVIEWED","createdDate":%d,"user":{"name":"dave"}},
+				{"action":"OPENED","createdDate":%d,"user":{"name":"alice"}}
+			]`, at(30), at(20), at(8), at(12), at(0)),
+			want: base.Add(8 * time.Hour).Format(time.RFC3339),
+		},
+		{
+			name: "author's own comments are ignored",
+			activities: fmt.Sprintf(`[
+				{"action":"APPROVED","createdDate":%d,"user":{"name":"bob"}},
+				{"action":"COMMENTED","createdDate":%d,"user":{"name":"alice"}}
+			]`, at(5), at(1)),
+			want: base.Add(5 * time.Hour).Format(time.RFC3339),
+		},
+		{
+			name: "no review activity",
+			activities: fmt.Sprintf(`[
+				{"action":"MERGED","createdDate":%d,"user":{"name":"bob"}},
+				{"action":"RESCOPED","createdDate":%d,"user":{"name":"alice"}},
+				{"action":"OPENED","createdDate":%d,"user":{"name":"alice"}}
+			]`, at(3), at(2), at(0)),
+			want: "-",
+		},
+		{
+			name:       "empty stream",
+			activities: `[]`,
+			want:       "-",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var activities []bitbucketActivity
+			if err := json.Unmarshal([]byte(tt.activities), &activities); err != nil {
+				t.Fatalf("bad fixture: %v", err)
+			}
+			got := "-"
+			if first := firstReview(activities, "alice"); first != nil {
+				got = first.UTC().Format(time.RFC3339)
+			}
+			if got != tt.want {
+				t.Errorf("firstReview() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFetchActivitiesPages(t *testing.T) {
+	var starts []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := r.URL.Query().Get("start")
+		starts = append(starts, start)
+		switch start {
+		case "0":
+			fmt.Fprint(w, `{"isLastPage":false,"nextPageStart":2,"values":[
+				{"action":"MERGED","createdDate":3000,"user":{"name":"bob"}},
+				{"action":"APPROVED","createdDate":2000,"user":{"name":"bob"}}]}`)
+		case "2":
+			fmt.Fprint(w, `{"isLastPage":true,"values":[
+				{"action":"COMMENTED","createdDate":1000,"user":{"name":"carol"}}]}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	cfg := config.Config{BitbucketURL: srv.URL, BitbucketProject: "PROJ", BitbucketRepo: "api"}
+	activities := NewClient(cfg).fetchActivities(context.Background(), 1)
+
+	if fmt.Sprint(starts) != "[0 2]" {
+		t.Errorf("requested starts %v, want [0 2]", starts)
+	}
+	if len(activities) != 3 {
+		t.Fatalf("got %d activities, want 3", len(activities))
+	}
+	if first := firstReview(activities, "alice"); first == nil || first.UnixMilli() != 1000 {
+		t.Errorf("firstReview() = %v, want the comment on the second page", first)
+	}
+}
+
+// pagedServer serves 1,000 branches and one branch's 1,000 commits in pages of 100, linked by
+// isLastPage/nextPageStart
+func pagedServer() http.HandlerFunc {
+	now := time.Now().Add(-time.Hour)
+	branchPages := make([]string, 10)
+	commitPages := make([]string, 10)
+	for p := range branchPages {
+		var branches, commits strings.Builder
+		for i := p * 100; i < (p+1)*100; i++ {
+			if i > p*100 {
+				branches.WriteString(",")
+				commits.WriteString(",")
+			}
+			fmt.Fprintf(&branches, `{"id":"refs/heads/b%d","displayId":"b%d","latestCommit":{"id":"%040d"}}`, i, i, i)
+			fmt.Fprintf(&commits, `{"id":"%040d","displayId":"%011d","author":{"name":"dev-%d","emailAddress":"dev@example.com"},"authorTimestamp":%d,"message":"fix: change %d"}`,
+				i, i, i%10, now.Add(-time.Duration(i)*time.Minute).UnixMilli(), i)
+		}
+		last := p == len(branchPages)-1
+		branchPages[p] = fmt.Sprintf(`{"values":[%s],"isLastPage":%t,"nextPageStart":%d}`, branches.String(), last, (p+1)*100)
+		commitPages[p] = fmt.Sprintf(`{"values":[%s],"isLastPage":%t,"nextPageStart":%d}`, commits.String(), last, (p+1)*100)
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		start, _ := strconv.Atoi(r.URL.Query().Get("start"))
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/branches"):
+			fmt.Fprint(w, branchPages[start/100])
+		case strings.HasSuffix(r.URL.Path, "/commits"):
+			if r.URL.Query().Get("until") != "refs/heads/b0" {
+				fmt.Fprint(w, `{"values":[],"isLastPage":true}`)
+				return
+			}
+			fmt.Fprint(w, commitPages[start/100])
+		default:
+			fmt.Fprint(w, `{"slug":"api"}`)
+		}
+	}
+}
+
+// BenchmarkGetBranches measures paging through and parsing 1,000 branches
+func BenchmarkGetBranches(b *testing.B) {
+	srv := httptest.NewServer(pagedServer())
+	defer srv.Close()
+	cfg := config.Config{BitbucketURL: srv.URL, BitbucketProject: "PROJ", BitbucketRepo: "api"}
+	client := NewClient(cfg).WithHTTPClient(srv.Client())
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		branches, err := client.getBranches(ctx)
+		if err != nil || len(branches) != 1000 {
+			b.Fatalf("got %d branches, err %v", len(branches), err)
+		}
+	}
+}
+
+// BenchmarkFetchCommitsFromBranch measures paging through and parsing one branch's 1,000 commits
+func BenchmarkFetchCommitsFromBranch(b *testing.B) {
+	srv := httptest.NewServer(pagedServer())
+	defer srv.Close()
+	cfg := config.Config{BitbucketURL: srv.URL, BitbucketProject: "PROJ", BitbucketRepo: "api", DaysToAnalyze: 30}
+	client := NewClient(cfg).WithHTTPClient(srv.Client())
+	branch := BranchWithActivity{ID: "refs/heads/b0", DisplayID: "b0"}
+	since, until := cfg.Window()
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		commits, _, err := client.fetchCommitsFromBranch(ctx, branch, since, until, 0, 0)
+		if err != nil || len(commits) != 1000 {
+			b.Fatalf("got %d commits, err %v", len(commits), err)
+		}
+	}
+}
@@ -0,0 +1,24 @@
+package bitbucket
+
+import "fmt"
+
+// APIError is returned when the Bitbucket API responds with an unexpected status
+type APIError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("API request failed with status %d: %s", e.StatusCode, e.Body)
+}
+
+// NotFoundError indicates the configured project or repository does not exist or is not
+// visible with the configured token, as opposed to a valid repository with no activity
+type NotFoundError struct {
+	Project string
+	Repo    string
+}
+
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("bitbucket repository %s/%s not found or not accessible (check bitbucket_project, bitbucket_repo and token permissions)", e.Project, e.Repo)
+}
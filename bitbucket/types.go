@@ -12,17 +12,40 @@ type Commit struct {
 	Message      string    `json:"message"`
 	LinesAdded   int       `json:"lines_added"`
 	LinesDeleted int       `json:"lines_deleted"`
+	LinesIgnored int       `json:"lines_ignored,omitempty"`
+	Repo         string    `json:"repo,omitempty"`
 }
 
 // PullRequest represents a pull request
 type PullRequest struct {
-	ID            string     `json:"id"`
-	Author        string     `json:"author"`
-	CreatedAt     time.Time  `json:"created_at"`
-	MergedAt      *time.Time `json:"merged_at,omitempty"`
-	ClosedAt      *time.Time `json:"closed_at,omitempty"`
-	FirstReviewAt *time.Time `json:"first_review_at,omitempty"`
-	LinesChanged  int        `json:"lines_changed"`
-	Reviewers     []string   `json:"reviewers"`
-	Status        string     `json:"status"`
-}
\ No newline at end of file
+	ID             string     `json:"id"`
+	Author         string     `json:"author"`
+	CreatedAt      time.Time  `json:"created_at"`
+	MergedAt       *time.Time `json:"merged_at,omitempty"`
+	ClosedAt       *time.Time `json:"closed_at,omitempty"`
+	FirstReviewAt  *time.Time `json:"first_review_at,omitempty"`
+	LinesChanged   int        `json:"lines_changed"`
+	LinesIgnored   int        `json:"lines_ignored,omitempty"`
+	Reviewers      []string   `json:"reviewers"`
+	CommentCount   int        `json:"comment_count"`
+	ReviewComments *int       `json:"review_comments,omitempty"` // Inline review comments on the diff; nil unless counted (with FETCH_REVIEW_COMMENTS)
+	ReviewCycles   int        `json:"review_cycles"`
+	Approvers      []string   `json:"approvers,omitempty"`
+	MergedBy       string     `json:"merged_by,omitempty"`
+	DraftHours     float64    `json:"draft_hours,omitempty"` // Time spent as a draft before/while review was possible
+	FirstCommitAt  *time.Time `json:"first_commit_at,omitempty"`
+	BaseBranch     string     `json:"base_branch,omitempty"`
+	Title          string     `json:"title,omitempty"`
+	Labels         []string   `json:"labels,omitempty"`
+	CommitHashes   []string   `json:"commit_hashes,omitempty"` // Branch commits (with FETCH_PR_COMMITS); Bitbucket does not report the merge commit
+	UpdatedAt      *time.Time `json:"updated_at,omitempty"`
+	Repo           string     `json:"repo,omitempty"`
+	Status         string     `json:"status"`
+}
+
+// RepoInfo holds repository metadata used to decide whether a repo is analyzed
+type RepoInfo struct {
+	Name     string `json:"name"`
+	Archived bool   `json:"archived"`
+	Fork     bool   `json:"fork"`
+}
@@ -0,0 +1,196 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"devops-metrics/config"
+)
+
+// DiskCache stores raw fetch results on disk so that back-to-back runs can
+// reuse data instead of refetching everything from the APIs
+type DiskCache struct {
+	dir string
+	ttl time.Duration
+}
+
+// diskEntry is the on-disk envelope around a cached payload
+type diskEntry struct {
+	Key       string          `json:"key"`
+	FetchedAt time.Time       `json:"fetched_at"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// NewDiskCache creates a disk cache rooted at dir with the given TTL
+func NewDiskCache(dir string, ttl time.Duration) (*DiskCache, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("error creating cache directory: %w", err)
+	}
+	return &DiskCache{dir: dir, ttl: ttl}, nil
+}
+
+// fetchSettings are the config values that change what a fetch returns. They are hashed into
+// every key, so changing any of them misses the cache instead of serving data fetched for
+// other settings. Hosts are included so the same repository name on two servers does not
+// share an entry.
+type fetchSettings struct {
+	BitbucketURL          string    `json:"bitbucket_url"`
+	GitHubURL             string    `json:"github_url"`
+	GitLabURL             string    `json:"gitlab_url"`
+	AzureURL              string    `json:"azure_url"`
+	AzureOrg              string    `json:"azure_org"`
+	JiraURL               string    `json:"jira_url"`
+	IsJiraCloud           bool      `json:"is_jira_cloud"`
+	Days                  int       `json:"days"`
+	WindowStart           time.Time `json:"window_start"`
+	WindowEnd             time.Time `json:"window_end"`
+	BitbucketGitDir       string    `json:"bitbucket_git_dir"`
+	GitHubGitDir          string    `json:"github_git_dir"`
+	GitHubPRSearch        string    `json:"github_pr_search"`
+	GitHubUseGraphQL      bool      `json:"github_use_graphql"`
+	IgnoreFiles           []string  `json:"ignore_files"`
+	UnknownAuthor         string    `json:"unknown_author"`
+	FetchMergeActor       bool      `json:"fetch_merge_actor"`
+	FetchPRCommits        bool      `json:"fetch_pr_commits"`
+	FetchDraftTime        bool      `json:"fetch_draft_time"`
+	FetchReviewComments   bool      `json:"fetch_review_comments"`
+	FetchCommitLineCounts bool      `json:"fetch_commit_line_counts"`
+	MaxCommits            int       `json:"max_commits"`
+	MaxPRs                int       `json:"max_prs"`
+	MaxIssues             int       `json:"max_issues"`
+	MaxItems              int       `json:"max_items"`
+	JiraJQL               string    `json:"jira_jql"`
+	JiraSprint            string    `json:"jira_sprint"`
+	JiraStoryPointField   string    `json:"jira_story_point_field"`
+	JiraSprintField       string    `json:"jira_sprint_field"`
+	SubtaskMode           string    `json:"subtask_mode"`
+
+	ReviewStatesCountingAsReview []string `json:"review_states_counting_as_review"`
+	SuccessStatuses              []int    `json:"success_statuses"`
+}
+
+// Key builds a cache key from the provider, repository and data kind, plus a hash of the
+// analysis window and the other settings that shape the fetch
+func Key(provider, repo, kind string, cfg config.Config) string {
+	settings, _ := json.Marshal(fetchSettings{
+		BitbucketURL:          cfg.BitbucketURL,
+		GitHubURL:             cfg.GitHubURL,
+		GitLabURL:             cfg.GitLabURL,
+		AzureURL:              cfg.AzureURL,
+		AzureOrg:              cfg.AzureOrg,
+		JiraURL:               cfg.JiraURL,
+		IsJiraCloud:           cfg.IsJiraCloud,
+		Days:                  cfg.DaysToAnalyze,
+		WindowStart:           cfg.WindowStart,
+		WindowEnd:             cfg.WindowEnd,
+		BitbucketGitDir:       cfg.BitbucketGitDir,
+		GitHubGitDir:          cfg.GitHubGitDir,
+		GitHubPRSearch:        cfg.GitHubPRSearch,
+		GitHubUseGraphQL:      cfg.GitHubUseGraphQL,
+		IgnoreFiles:           cfg.IgnoreFiles,
+		UnknownAuthor:         cfg.UnknownAuthor,
+		FetchMergeActor:       cfg.FetchMergeActor,
+		FetchPRCommits:        cfg.FetchPRCommits,
+		FetchDraftTime:        cfg.FetchDraftTime,
+		FetchReviewComments:   cfg.FetchReviewComments,
+		FetchCommitLineCounts: cfg.FetchCommitLineCounts,
+		MaxCommits:            cfg.MaxCommits,
+		MaxPRs:                cfg.MaxPRs,
+		MaxIssues:             cfg.MaxIssues,
+		MaxItems:              cfg.MaxItems,
+		JiraJQL:               cfg.JiraJQL,
+		JiraSprint:            cfg.JiraSprint,
+		JiraStoryPointField:   cfg.JiraStoryPointField,
+		JiraSprintField:       cfg.JiraSprintField,
+		SubtaskMode:           cfg.SubtaskMode,
+
+		ReviewStatesCountingAsReview: cfg.ReviewStatesCountingAsReview,
+		SuccessStatuses:              cfg.SuccessStatuses,
+	})
+	sum := sha256.Sum256(settings)
+	return fmt.Sprintf("%s:%s:%s:%s", provider, repo, kind, hex.EncodeToString(sum[:8]))
+}
+
+// path returns the file path used to store the given key
+func (c *DiskCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:8])+".json")
+}
+
+// Load reads a cached value into v. It returns false if the entry is missing or expired.
+func (c *DiskCache) Load(key string, v interface{}) (bool, error) {
+	data, err := os.ReadFile(c.path(key))
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	var entry diskEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return false, fmt.Errorf("error parsing cache entry: %w", err)
+	}
+	if entry.Key != key || time.Since(entry.FetchedAt) > c.ttl {
+		return false, nil
+	}
+
+	if err := json.Unmarshal(entry.Data, v); err != nil {
+		return false, fmt.Errorf("error parsing cached data: %w", err)
+	}
+	return true, nil
+}
+
+// Save writes v to the cache under the given key
+func (c *DiskCache) Save(key string, v interface{}) error {
+	payload, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(diskEntry{
+		Key:       key,
+		FetchedAt: time.Now(),
+		Data:      payload,
+	})
+	if err != nil {
+		return err
+	}
+
+	// Write to a temp file first so a crashed run never leaves a truncated entry
+	tmp := c.path(key) + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, c.path(key))
+}
+
+// Fetch returns the cached value for key if present, otherwise calls fetch and caches the result.
+// A nil cache always calls fetch.
+func (c *DiskCache) Fetch(key string, v interface{}, fetch func() error) (bool, error) {
+	if c != nil {
+		hit, err := c.Load(key, v)
+		if err != nil {
+			slog.Warn("Ignoring unreadable cache entry", "key", key, "error", err)
+		} else if hit {
+			return true, nil
+		}
+	}
+
+	if err := fetch(); err != nil {
+		return false, err
+	}
+
+	if c != nil {
+		if err := c.Save(key, v); err != nil {
+			slog.Warn("Could not write cache entry", "key", key, "error", err)
+		}
+	}
+	return false, nil
+}
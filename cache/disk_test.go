@@ -0,0 +1,226 @@
+package cache
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"devops-metrics/config"
+)
+
+// clientPackages are the packages whose fetches are cached under Key
+var clientPackages = []string{"azuredevops", "bitbucket", "github", "gitlab", "gitlocal", "jira"}
+
+// keyExempt are config fields the clients read that do not change what a fetch returns
+var keyExempt = map[string]string{
+	"BitbucketToken":   "credential",
+	"GitHubToken":      "credential",
+	"GitHubAuthScheme": "credential",
+	"GitLabToken":      "credential",
+	"AzurePAT":         "credential",
+	"JiraUsername":     "credential",
+	"JiraToken":        "credential",
+	"MaxConcurrency":   "request pacing",
+	"GitHubMaxRetries": "request pacing",
+	"BitbucketProject": "part of the repo argument",
+	"BitbucketRepo":    "part of the repo argument",
+	"GitHubOwner":      "part of the repo argument",
+	"GitHubRepo":       "part of the repo argument",
+	"GitLabProjectID":  "the repo argument",
+	"AzureProject":     "part of the repo argument",
+	"AzureRepo":        "part of the repo argument",
+	"JiraProject":      "the repo argument",
+}
+
+// clientConfigFields returns the config fields read by the client packages, directly or
+// through Config methods
+func clientConfigFields(t *testing.T) []string {
+	t.Helper()
+	configType := reflect.TypeOf(config.Config{})
+	isField := func(name string) bool { _, ok := configType.FieldByName(name); return ok }
+	isMethod := func(name string) bool { _, ok := configType.MethodByName(name); return ok }
+
+	// Fields and methods each Config method uses on its receiver
+	methodUses := make(map[string][]string)
+	for _, file := range parseDir(t, "../config") {
+		for _, decl := range file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Recv == nil || len(fn.Recv.List[0].Names) == 0 {
+				continue
+			}
+			recv := fn.Recv.List[0].Names[0].Name
+			ast.Inspect(fn.Body, func(n ast.Node) bool {
+				if sel, ok := n.(*ast.SelectorExpr); ok {
+					if id, ok := sel.X.(*ast.Ident); ok && id.Name == recv {
+						methodUses[fn.Name.Name] = append(methodUses[fn.Name.Name], sel.Sel.Name)
+					}
+				}
+				return true
+			})
+		}
+	}
+
+	fields := make(map[string]bool)
+	var use func(name string)
+	use = func(name string) {
+		switch {
+		case isField(name):
+			fields[name] = true
+		case isMethod(name) && !fields["method:"+name]:
+			fields["method:"+name] = true
+			for _, used := range methodUses[name] {
+				use(used)
+			}
+		}
+	}
+	for _, pkg := range clientPackages {
+		for _, file := range parseDir(t, filepath.Join("..", pkg)) {
+			ast.Inspect(file, func(n ast.Node) bool {
+				sel, ok := n.(*ast.SelectorExpr)
+				if !ok {
+					return true
+				}
+				// c.config.X, or X on a config value named config or cfg
+				switch x := sel.X.(type) {
+				case *ast.SelectorExpr:
+					if x.Sel.Name == "config" {
+						use(sel.Sel.Name)
+					}
+				case *ast.Ident:
+					if x.Name == "config" || x.Name == "cfg" {
+						use(sel.Sel.Name)
+					}
+				}
+				return true
+			})
+		}
+	}
+
+	var names []string
+	for name := range fields {
+		if !strings.HasPrefix(name, "method:") {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// parseDir parses the non-test Go files in dir
+func parseDir(t *testing.T, dir string) []*ast.File {
+	t.Helper()
+	paths, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var files []*ast.File
+	for _, path := range paths {
+		if strings.HasSuffix(path, "_test.go") {
+			continue
+		}
+		file, err := parser.ParseFile(token.NewFileSet(), path, nil, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, file)
+	}
+	return files
+}
+
+// setNonZero sets v to a value different from its zero value
+func setNonZero(t *testing.T, name string, v reflect.Value) {
+	t.Helper()
+	switch {
+	case v.Type() == reflect.TypeOf(time.Time{}):
+		v.Set(reflect.ValueOf(time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)))
+	case v.Kind() == reflect.String:
+		v.SetString("changed")
+	case v.Kind() == reflect.Bool:
+		v.SetBool(true)
+	case v.Kind() == reflect.Int:
+		v.SetInt(7)
+	case v.Kind() == reflect.Slice:
+		elem := reflect.New(v.Type().Elem()).Elem()
+		setNonZero(t, name, elem)
+		v.Set(reflect.Append(reflect.MakeSlice(v.Type(), 0, 1), elem))
+	default:
+		t.Fatalf("%s: no test value for %s", name, v.Type())
+	}
+}
+
+func TestKeyCoversFetchSettings(t *testing.T) {
+	base := config.Config{DaysToAnalyze: 30}
+	want := Key("github", "acme/api", "prs", base)
+
+	fields := clientConfigFields(t)
+	if len(fields) < 20 {
+		t.Fatalf("found only %d config fields read by the clients: %v", len(fields), fields)
+	}
+	for _, name := range fields {
+		if _, ok := keyExempt[name]; ok {
+			continue
+		}
+		t.Run(name, func(t *testing.T) {
+			cfg := base
+			setNonZero(t, name, reflect.ValueOf(&cfg).Elem().FieldByName(name))
+			if got := Key("github", "acme/api", "prs", cfg); got == want {
+				t.Errorf("Key() ignores %s, which the clients read; add it to fetchSettings or keyExempt", name)
+			}
+		})
+	}
+
+	// Settings that only affect reporting keep the key
+	cfg := base
+	cfg.NumberLocale, cfg.StalePRDays = "de", 3
+	if got := Key("github", "acme/api", "prs", cfg); got != want {
+		t.Errorf("Key() = %q after a reporting change, want %q", got, want)
+	}
+}
+
+func TestDiskCacheFetch(t *testing.T) {
+	c, err := NewDiskCache(t.TempDir(), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fetches := 0
+	fetch := func(v *[]string, value string) func() error {
+		return func() error {
+			fetches++
+			*v = []string{value}
+			return nil
+		}
+	}
+
+	key30 := Key("jira", "PROJ", "issues", config.Config{DaysToAnalyze: 30})
+	key7 := Key("jira", "PROJ", "issues", config.Config{DaysToAnalyze: 7})
+	steps := []struct {
+		key        string
+		value      string
+		wantCached bool
+		want       string
+	}{
+		{key30, "first", false, "first"},
+		{key30, "second", true, "first"},
+		{key7, "third", false, "third"},
+		{key30, "fourth", true, "first"},
+	}
+	for i, step := range steps {
+		var got []string
+		cached, err := c.Fetch(step.key, &got, fetch(&got, step.value))
+		if err != nil {
+			t.Fatalf("step %d: Fetch() error = %v", i, err)
+		}
+		if cached != step.wantCached || len(got) != 1 || got[0] != step.want {
+			t.Errorf("step %d: Fetch() = %v, cached %v; want %q, cached %v", i, got, cached, step.want, step.wantCached)
+		}
+	}
+	if fetches != 2 {
+		t.Errorf("%d fetches, want 2", fetches)
+	}
+}
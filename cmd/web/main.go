@@ -1,9 +1,8 @@
 package main
 
 import (
-	"flag"
-	"log"
 	"devops-metrics/web"
+	"flag"
 )
 
 func main() {
@@ -15,4 +14,4 @@ func main() {
 	// Create and start the server
 	server := web.NewServer()
 	server.Start(port)
-}
\ No newline at end of file
+}
@@ -2,29 +2,265 @@ package config
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
+	"path"
+	"path/filepath"
+	"regexp"
 	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
 )
 
 // Config represents the application configuration
 type Config struct {
-	BitbucketURL      string `json:"bitbucket_url"`       // e.g., https://bitbucket.company.com
-	BitbucketToken    string `json:"bitbucket_token"`     // Personal access token
-	BitbucketProject  string `json:"bitbucket_project"`   // Project key
-	BitbucketRepo     string `json:"bitbucket_repo"`      // Repository slug
-	GitHubURL       string `json:"github_url"`          // e.g., https://github.com
-	GitHubToken     string `json:"github_token"`        // Personal access token
-	GitHubOwner     string `json:"github_owner"`        // Repository owner (user or org)
-	GitHubRepo      string `json:"github_repo"`         // Repository name
-	JiraURL         string `json:"jira_url"`            // e.g., https://jira.company.com or https://yoursite.atlassian.net
-	JiraUsername    string `json:"jira_username"`       // Email for cloud, username for DC
-	JiraToken       string `json:"jira_token"`          // API token for cloud, password for DC
-	JiraProject     string `json:"jira_project"`        // Project key
-	DaysToAnalyze   int    `json:"days_to_analyze"`     // Number of days to look back
-	IsJiraCloud     bool   `json:"is_jira_cloud"`       // true for Cloud, false for DC
-}
-
-// LoadConfig loads configuration from file or environment variables
+	BitbucketURL        string   `json:"bitbucket_url" yaml:"bitbucket_url"`                 // e.g., https://bitbucket.company.com
+	BitbucketToken      string   `json:"bitbucket_token" yaml:"bitbucket_token"`             // Personal access token
+	BitbucketProject    string   `json:"bitbucket_project" yaml:"bitbucket_project"`         // Project key
+	BitbucketRepo       string   `json:"bitbucket_repo" yaml:"bitbucket_repo"`               // Repository slug
+	BitbucketGitDir     string   `json:"bitbucket_git_dir" yaml:"bitbucket_git_dir"`         // Optional local clone used for commit metrics instead of the API
+	GitHubURL           string   `json:"github_url" yaml:"github_url"`                       // e.g., https://github.com
+	GitHubToken         string   `json:"github_token" yaml:"github_token"`                   // Personal access token
+	GitHubOwner         string   `json:"github_owner" yaml:"github_owner"`                   // Repository owner (user or org)
+	GitHubRepo          string   `json:"github_repo" yaml:"github_repo"`                     // Repository name
+	GitHubGitDir        string   `json:"github_git_dir" yaml:"github_git_dir"`               // Optional local clone used for commit metrics instead of the API
+	GitHubPRSearch      string   `json:"github_pr_search" yaml:"github_pr_search"`           // Search query (e.g. "author:alice org:acme") used instead of the repo PR list
+	GitHubMaxRetries    int      `json:"github_max_retries" yaml:"github_max_retries"`       // Retries of a rate-limited GitHub request (default 5)
+	GitHubUseGraphQL    bool     `json:"github_use_graphql" yaml:"github_use_graphql"`       // Fetch PRs with their reviews through the GraphQL API instead of one REST call per PR
+	GitHubAuthScheme    string   `json:"github_auth_scheme" yaml:"github_auth_scheme"`       // Authorization scheme for GitHubToken: "token" or "bearer"; detected from the token prefix when empty
+	GitLabURL           string   `json:"gitlab_url" yaml:"gitlab_url"`                       // e.g., https://gitlab.company.com (defaults to https://gitlab.com)
+	GitLabToken         string   `json:"gitlab_token" yaml:"gitlab_token"`                   // Personal or project access token with read_api scope
+	GitLabProjectID     string   `json:"gitlab_project_id" yaml:"gitlab_project_id"`         // Numeric project ID or full path (group/project)
+	AzureURL            string   `json:"azure_url" yaml:"azure_url"`                         // Azure DevOps Server collection URL (defaults to https://dev.azure.com)
+	AzureOrg            string   `json:"azure_org" yaml:"azure_org"`                         // Organization (or collection) name
+	AzureProject        string   `json:"azure_project" yaml:"azure_project"`                 // Project name
+	AzureRepo           string   `json:"azure_repo" yaml:"azure_repo"`                       // Repository name or ID
+	AzurePAT            string   `json:"azure_pat" yaml:"azure_pat"`                         // Personal access token with Code (Read) scope
+	JiraURL             string   `json:"jira_url" yaml:"jira_url"`                           // e.g., https://jira.company.com or https://yoursite.atlassian.net
+	JiraUsername        string   `json:"jira_username" yaml:"jira_username"`                 // Email for cloud, username for DC
+	JiraToken           string   `json:"jira_token" yaml:"jira_token"`                       // API token for cloud, password for DC
+	JiraProject         string   `json:"jira_project" yaml:"jira_project"`                   // Project key
+	DaysToAnalyze       int      `json:"days_to_analyze" yaml:"days_to_analyze"`             // Number of days to look back
+	IsJiraCloud         bool     `json:"is_jira_cloud" yaml:"is_jira_cloud"`                 // true for Cloud, false for DC
+	TicketPattern       string   `json:"ticket_pattern" yaml:"ticket_pattern"`               // Regex matching ticket keys in commit messages
+	ExcludeArchived     bool     `json:"exclude_archived" yaml:"exclude_archived"`           // Skip repositories that are archived
+	ExcludeForks        bool     `json:"exclude_forks" yaml:"exclude_forks"`                 // Skip repositories that are forks
+	ExcludeRepos        []string `json:"exclude_repos" yaml:"exclude_repos"`                 // Glob patterns (e.g. "acme/experiment-*", "*-mirror") of repositories never analyzed
+	MinSampleSize       int      `json:"min_sample_size" yaml:"min_sample_size"`             // Fewer data points than this are flagged as low confidence
+	FetchMergeActor     bool     `json:"fetch_merge_actor" yaml:"fetch_merge_actor"`         // Fetch who merged each PR for self-merge detection (one extra request per merged GitHub PR; Bitbucket reads the activity stream it already fetches)
+	SmoothingWindow     int      `json:"smoothing_window" yaml:"smoothing_window"`           // Rolling-average window in days for trend series (0 or 1 disables smoothing)
+	IgnoreFiles         []string `json:"ignore_files" yaml:"ignore_files"`                   // Glob patterns (e.g. "*.lock", "dist/**") excluded from line counts
+	NumberLocale        string   `json:"number_locale" yaml:"number_locale"`                 // Number formatting for console/CSV output: en, de, fr, ch (JSON is unaffected)
+	UnknownAuthor       string   `json:"unknown_author" yaml:"unknown_author"`               // Author name for commits without a login or name; "email" uses the commit email when present
+	TeamSize            int      `json:"team_size" yaml:"team_size"`                         // Headcount for per-capita metrics; defaults to the number of active contributors
+	ReviewTeam          []string `json:"review_team" yaml:"review_team"`                     // Usernames of a reviewer group whose review load is reported separately
+	WeekendDays         []string `json:"weekend_days" yaml:"weekend_days"`                   // Non-working weekdays for business-day lead time (default Saturday, Sunday)
+	Holidays            []string `json:"holidays" yaml:"holidays"`                           // Non-working dates (YYYY-MM-DD) for business-day lead time
+	MetricsCacheSize    int      `json:"metrics_cache_size" yaml:"metrics_cache_size"`       // Max computed metric responses the web server keeps in memory
+	CacheTTLSeconds     int      `json:"cache_ttl_seconds" yaml:"cache_ttl_seconds"`         // How long the web server serves a computed metrics response from memory (default 300)
+	FetchPRCommits      bool     `json:"fetch_pr_commits" yaml:"fetch_pr_commits"`           // Read each PR's commits to measure branch lifetime (extra API call per PR)
+	FetchDraftTime      bool     `json:"fetch_draft_time" yaml:"fetch_draft_time"`           // Read GitHub PR timelines to measure time spent as a draft (extra API call per PR)
+	FetchReviewComments bool     `json:"fetch_review_comments" yaml:"fetch_review_comments"` // Count inline review comments on GitHub PRs (extra API call per PR)
+	ExcludeAuthors      []string `json:"exclude_authors" yaml:"exclude_authors"`             // Authors left out of commit and PR metrics: exact names, bracketed suffixes like "[bot]" or globs such as "renovate*"
+	ExcludeDraftTime    bool     `json:"exclude_draft_time" yaml:"exclude_draft_time"`       // Subtract draft time from PR cycle time
+	StaleStoryDays      int      `json:"stale_story_days" yaml:"stale_story_days"`           // Open stories without a status change for this many days are reported as stale
+	StalePRDays         int      `json:"stale_pr_days" yaml:"stale_pr_days"`                 // Open PRs without an update for this many days are reported as idle
+	MaxPRAgeDays        int      `json:"max_pr_age_days" yaml:"max_pr_age_days"`             // Open PRs older than this are reported as aged, however recently they were updated
+	SuccessStatuses     []int    `json:"success_statuses" yaml:"success_statuses"`           // HTTP statuses accepted from the APIs (default: any 2xx)
+	MaxCommits          int      `json:"max_commits" yaml:"max_commits"`                     // Stop fetching commits after this many per run (0 = unlimited)
+	MaxPRs              int      `json:"max_prs" yaml:"max_prs"`                             // Stop fetching pull requests after this many per run (0 = unlimited)
+	MaxIssues           int      `json:"max_issues" yaml:"max_issues"`                       // Stop fetching Jira issues after this many per run (0 = unlimited)
+	MaxItems            int      `json:"max_items" yaml:"max_items"`                         // Safety cap for any paginated fetch without a more specific cap (0 = unlimited)
+	FailureKeywords     []string `json:"failure_keywords" yaml:"failure_keywords"`           // Commit message keywords marking a failed change (default revert, hotfix, rollback)
+	FailureWindowDays   int      `json:"failure_window_days" yaml:"failure_window_days"`     // Days after a merge within which a failure commit is attributed to that PR (default 7)
+	SubtaskMode         string   `json:"subtask_mode" yaml:"subtask_mode"`                   // Jira sub-task handling: include (default), exclude, rollup or separate
+	MaxConcurrency      int      `json:"max_concurrency" yaml:"max_concurrency"`             // Max concurrent per-PR API requests (default 5)
+	Sinks               []string `json:"sinks" yaml:"sinks"`                                 // Output destinations as kind:target (file, slack, http, pushgateway); defaults to metrics.json and metrics.csv
+
+	RequiredApprovals     map[string]int `json:"required_approvals" yaml:"required_approvals"`             // Approvals required per base-branch glob (e.g. "main": 1, "release/*": 2)
+	FetchCommitLineCounts bool           `json:"fetch_commit_line_counts" yaml:"fetch_commit_line_counts"` // Read each Bitbucket commit's diff for line counts (extra API call per commit)
+	RawCommitsFile        string         `json:"raw_commits_file" yaml:"raw_commits_file"`                 // Write the fetched commits as JSON to this file
+	EnrichCommits         bool           `json:"enrich_commits" yaml:"enrich_commits"`                     // Attach PR title, labels and reviewers to commits in the raw commits file
+	TrendsFile            string         `json:"trends_file" yaml:"trends_file"`                           // Write per-ISO-week commit, PR and story metrics as JSON to this file
+	SnapshotDB            string         `json:"snapshot_db" yaml:"snapshot_db"`                           // SQLite database that -save writes runs to and /api/history reads (default metrics.db)
+	InputDir              string         `json:"input_dir" yaml:"input_dir"`                               // Replay raw API responses saved with DumpDir instead of calling the APIs
+	DumpDir               string         `json:"dump_dir" yaml:"dump_dir"`                                 // Save every raw API response under this directory for later replay with InputDir
+
+	HTTPSinkHeaders        map[string]string `json:"http_sink_headers" yaml:"http_sink_headers"`                 // Extra headers (e.g. Authorization) sent by http sinks
+	HTTPSinkRetries        int               `json:"http_sink_retries" yaml:"http_sink_retries"`                 // Retries after a network error, 429 or 5xx from an http sink
+	HTTPSinkTimeoutSeconds int               `json:"http_sink_timeout_seconds" yaml:"http_sink_timeout_seconds"` // Per-attempt timeout for http sinks (default 30)
+	WebhookURL             string            `json:"webhook_url" yaml:"webhook_url"`                             // Dashboard endpoint the metrics JSON is POSTed to after each run
+	WebhookHeaders         map[string]string `json:"webhook_headers" yaml:"webhook_headers"`                     // Extra headers (e.g. Authorization) sent with the webhook POST
+	SlackWebhookURL        string            `json:"slack_webhook_url" yaml:"slack_webhook_url"`                 // Slack incoming webhook that receives a metrics digest after each run
+
+	JiraStoryPointField string `json:"jira_story_point_field" yaml:"jira_story_point_field"` // Jira custom field holding story points (default customfield_10016)
+	JiraJQL             string `json:"jira_jql" yaml:"jira_jql"`                             // Base JQL for issue fetching instead of the whole project; the window's created dates are added unless it constrains created
+	JiraSprintField     string `json:"jira_sprint_field" yaml:"jira_sprint_field"`           // Jira custom field holding an issue's sprints (default customfield_10020)
+	JiraSprint          string `json:"jira_sprint" yaml:"jira_sprint"`                       // Sprint ID or name to analyze instead of the date window
+
+	CommitMessagePattern string `json:"commit_message_pattern" yaml:"commit_message_pattern"` // Regex a compliant commit subject must match (default Conventional Commits)
+
+	LogFormat string `json:"log_format" yaml:"log_format"` // Log output: text (default, human-readable) or json (one object per line for log aggregators)
+	LogLevel  string `json:"log_level" yaml:"log_level"`   // Minimum log level: debug, info (default), warn or error
+
+	Timezone string `json:"timezone" yaml:"timezone"` // IANA zone (e.g. "Europe/Berlin") commit times are converted to before bucketing into days and hours (default UTC)
+
+	AuthorTeams map[string]string `json:"author_teams" yaml:"author_teams"` // Team of each author for team rollups (author: team)
+	GitHubTeams []string          `json:"github_teams" yaml:"github_teams"` // Team slugs in the GitHub owner org whose members are assigned to teams automatically
+
+	AuthorAliases map[string][]string `json:"author_aliases" yaml:"author_aliases"` // Other names of each person across providers (canonical name: [aliases]); per-author metrics are merged under the canonical name
+
+	ExcludeAuthorPatterns []string `json:"exclude_author_patterns" yaml:"exclude_author_patterns"` // Regular expressions of authors left out of commit and PR metrics
+
+	ReviewStatesCountingAsReview []string `json:"review_states_counting_as_review" yaml:"review_states_counting_as_review"` // GitHub review states that count as a PR's first review (default APPROVED, CHANGES_REQUESTED, COMMENTED)
+
+	ScoreWeights ScoreWeights `json:"score_weights" yaml:"score_weights"` // Weights of the productivity score's sub-metrics (default equal)
+
+	AllowedOrigins []string `json:"allowed_origins" yaml:"allowed_origins"` // Browser origins allowed to call /api/* cross-origin (e.g. https://dash.company.com, or *); CORS is off when empty
+
+	// WindowStart and WindowEnd override the DaysToAnalyze window for a single request;
+	// they are never read from the config file
+	WindowStart time.Time `json:"-" yaml:"-"`
+	WindowEnd   time.Time `json:"-" yaml:"-"`
+}
+
+// DefaultUnknownAuthor is the author bucket for commits with no identifiable author
+const DefaultUnknownAuthor = "unknown"
+
+// DefaultStaleStoryDays is the idle period after which an open story counts as stale
+const DefaultStaleStoryDays = 30
+
+// DefaultStalePRDays is the idle period after which an open PR counts as stale
+const DefaultStalePRDays = 7
+
+// DefaultMaxPRAgeDays is the age after which an open PR counts as stale regardless of updates
+const DefaultMaxPRAgeDays = 30
+
+// DefaultJiraStoryPointField is the custom field Jira Cloud uses for story points by default
+const DefaultJiraStoryPointField = "customfield_10016"
+
+// DefaultJiraSprintField is the custom field Jira Cloud uses for sprints by default
+const DefaultJiraSprintField = "customfield_10020"
+
+// DefaultSnapshotDB is the SQLite database for saved runs when SnapshotDB is not set
+const DefaultSnapshotDB = "metrics.db"
+
+// DefaultMetricsCacheSize bounds the web server's in-memory metrics cache
+const DefaultMetricsCacheSize = 64
+
+// DefaultCacheTTLSeconds is how long the web server's metrics cache entries live when CacheTTLSeconds is not set
+const DefaultCacheTTLSeconds = 300
+
+// DefaultHTTPSinkRetries is the number of retries used for http sinks in the sample configuration
+// and when configuring from the environment
+const DefaultHTTPSinkRetries = 3
+
+// DefaultGitHubMaxRetries is how often a rate-limited GitHub request is retried when GitHubMaxRetries is not set
+const DefaultGitHubMaxRetries = 5
+
+// DefaultMaxConcurrency bounds concurrent per-PR requests when MaxConcurrency is not configured
+const DefaultMaxConcurrency = 5
+
+// DefaultSmoothingWindow is the rolling-average window used in the sample configuration
+const DefaultSmoothingWindow = 7
+
+// DefaultMinSampleSize is used when MinSampleSize is not configured
+const DefaultMinSampleSize = 3
+
+// Jira sub-task treatments for SubtaskMode. Include counts sub-tasks as stories, exclude drops
+// them, rollup adds their estimate and effort to the parent, and separate reports them on their own.
+const (
+	SubtaskInclude  = "include"
+	SubtaskExclude  = "exclude"
+	SubtaskRollup   = "rollup"
+	SubtaskSeparate = "separate"
+)
+
+// DefaultFailureWindowDays is how long after a merge a failure is attributed to that PR when
+// FailureWindowDays is not set
+const DefaultFailureWindowDays = 7
+
+// DefaultFailureKeywords mark commits that revert or patch a failed change
+var DefaultFailureKeywords = []string{"revert", "hotfix", "rollback"}
+
+// DefaultReviewStates are the GitHub review states that count as a first review when
+// ReviewStatesCountingAsReview is not set
+var DefaultReviewStates = []string{"APPROVED", "CHANGES_REQUESTED", "COMMENTED"}
+
+// ScoreWeights weights the normalized sub-metrics that make up the productivity score. A zero
+// weight leaves a sub-metric out; when every weight is zero, DefaultScoreWeights apply.
+type ScoreWeights struct {
+	Throughput       float64 `json:"throughput" yaml:"throughput"`
+	MergeSuccess     float64 `json:"merge_success" yaml:"merge_success"`
+	EstimateAccuracy float64 `json:"estimate_accuracy" yaml:"estimate_accuracy"`
+	CycleTime        float64 `json:"cycle_time" yaml:"cycle_time"`
+}
+
+// DefaultScoreWeights weight every sub-metric of the productivity score equally
+var DefaultScoreWeights = ScoreWeights{Throughput: 1, MergeSuccess: 1, EstimateAccuracy: 1, CycleTime: 1}
+
+// Weights returns the configured score weights, or DefaultScoreWeights when none are set
+func (c Config) Weights() ScoreWeights {
+	if c.ScoreWeights == (ScoreWeights{}) {
+		return DefaultScoreWeights
+	}
+	return c.ScoreWeights
+}
+
+// LargeDaysToAnalyze is the look-back above which Validate warns that fetches may be very large
+const LargeDaysToAnalyze = 365
+
+// DefaultTicketPattern matches Jira-style ticket keys such as PROJ-123
+const DefaultTicketPattern = `[A-Z][A-Z0-9]+-\d+`
+
+// DefaultCommitMessagePattern is the Conventional Commits header a compliant commit subject starts with
+const DefaultCommitMessagePattern = `^(feat|fix|docs|chore|refactor|test|style|perf)(\(.+\))?!?:`
+
+// ConfigFiles are the config file names looked for, in order of preference
+var ConfigFiles = []string{"config.json", "config.yaml", "config.yml"}
+
+// FindConfigFile returns the first of ConfigFiles that exists, or config.json when none does
+func FindConfigFile() string {
+	for _, name := range ConfigFiles {
+		if _, err := os.Stat(name); err == nil {
+			return name
+		}
+	}
+	return ConfigFiles[0]
+}
+
+// isYAML reports whether filename has a YAML extension
+func isYAML(filename string) bool {
+	ext := strings.ToLower(filepath.Ext(filename))
+	return ext == ".yaml" || ext == ".yml"
+}
+
+// defaultConfig returns the settings used when neither the config file nor the environment
+// sets them
+func defaultConfig() Config {
+	return Config{
+		DaysToAnalyze:    30,
+		MinSampleSize:    DefaultMinSampleSize,
+		SmoothingWindow:  DefaultSmoothingWindow,
+		MetricsCacheSize: DefaultMetricsCacheSize,
+		CacheTTLSeconds:  DefaultCacheTTLSeconds,
+		StaleStoryDays:   DefaultStaleStoryDays,
+		StalePRDays:      DefaultStalePRDays,
+		MaxPRAgeDays:     DefaultMaxPRAgeDays,
+		MaxConcurrency:   DefaultMaxConcurrency,
+		HTTPSinkRetries:  DefaultHTTPSinkRetries,
+
+		FailureWindowDays: DefaultFailureWindowDays,
+		RequiredApprovals: make(map[string]int),
+		AuthorTeams:       make(map[string]string),
+		AuthorAliases:     make(map[string][]string),
+	}
+}
+
+// LoadConfig loads configuration from file or environment variables. Files ending in
+// .yaml or .yml are parsed as YAML, anything else as JSON.
 func LoadConfig(filename string) (Config, error) {
 	// Try loading from file first
 	if _, err := os.Stat(filename); err == nil {
@@ -32,63 +268,628 @@ func LoadConfig(filename string) (Config, error) {
 		if err != nil {
 			return Config{}, err
 		}
-		var config Config
-		if err := json.Unmarshal(data, &config); err != nil {
+		// Settings the file leaves out keep their defaults
+		config := defaultConfig()
+		if isYAML(filename) {
+			err = yaml.Unmarshal(data, &config)
+		} else {
+			err = json.Unmarshal(data, &config)
+		}
+		if err != nil {
 			return Config{}, err
 		}
 		return config, nil
 	}
 
 	// Fall back to environment variables
-	config := Config{
-		BitbucketURL:     os.Getenv("BITBUCKET_URL"),
-		BitbucketToken:   os.Getenv("BITBUCKET_TOKEN"),
-		BitbucketProject: os.Getenv("BITBUCKET_PROJECT"),
-		BitbucketRepo:    os.Getenv("BITBUCKET_REPO"),
-		GitHubURL:       os.Getenv("GITHUB_URL"),
-		GitHubToken:     os.Getenv("GITHUB_TOKEN"),
-		GitHubOwner:     os.Getenv("GITHUB_OWNER"),
-		GitHubRepo:      os.Getenv("GITHUB_REPO"),
-		JiraURL:         os.Getenv("JIRA_URL"),
-		JiraUsername:     os.Getenv("JIRA_USERNAME"),
-		JiraToken:        os.Getenv("JIRA_TOKEN"),
-		JiraProject:      os.Getenv("JIRA_PROJECT"),
-		DaysToAnalyze:    30,
-		IsJiraCloud:      os.Getenv("JIRA_IS_CLOUD") == "true",
-	}
+	config := defaultConfig()
+	config.BitbucketURL = os.Getenv("BITBUCKET_URL")
+	config.BitbucketToken = os.Getenv("BITBUCKET_TOKEN")
+	config.BitbucketProject = os.Getenv("BITBUCKET_PROJECT")
+	config.BitbucketRepo = os.Getenv("BITBUCKET_REPO")
+	config.BitbucketGitDir = os.Getenv("BITBUCKET_GIT_DIR")
+	config.GitHubURL = os.Getenv("GITHUB_URL")
+	config.GitHubToken = os.Getenv("GITHUB_TOKEN")
+	config.GitHubOwner = os.Getenv("GITHUB_OWNER")
+	config.GitHubRepo = os.Getenv("GITHUB_REPO")
+	config.GitHubGitDir = os.Getenv("GITHUB_GIT_DIR")
+	config.GitHubUseGraphQL = os.Getenv("GITHUB_USE_GRAPHQL") == "true"
+	config.GitHubPRSearch = os.Getenv("GITHUB_PR_SEARCH")
+	config.GitHubAuthScheme = os.Getenv("GITHUB_AUTH_SCHEME")
+	config.GitLabURL = os.Getenv("GITLAB_URL")
+	config.GitLabToken = os.Getenv("GITLAB_TOKEN")
+	config.GitLabProjectID = os.Getenv("GITLAB_PROJECT_ID")
+	config.AzureURL = os.Getenv("AZURE_URL")
+	config.AzureOrg = os.Getenv("AZURE_ORG")
+	config.AzureProject = os.Getenv("AZURE_PROJECT")
+	config.AzureRepo = os.Getenv("AZURE_REPO")
+	config.AzurePAT = os.Getenv("AZURE_PAT")
+	config.JiraURL = os.Getenv("JIRA_URL")
+	config.JiraUsername = os.Getenv("JIRA_USERNAME")
+	config.JiraToken = os.Getenv("JIRA_TOKEN")
+	config.JiraProject = os.Getenv("JIRA_PROJECT")
+	config.IsJiraCloud = os.Getenv("JIRA_IS_CLOUD") == "true"
+	config.TicketPattern = os.Getenv("TICKET_PATTERN")
+	config.CommitMessagePattern = os.Getenv("COMMIT_MESSAGE_PATTERN")
+	config.ExcludeArchived = os.Getenv("EXCLUDE_ARCHIVED") == "true"
+	config.ExcludeForks = os.Getenv("EXCLUDE_FORKS") == "true"
+	config.ExcludeRepos = splitList(os.Getenv("EXCLUDE_REPOS"))
+	config.ExcludeAuthors = splitList(os.Getenv("EXCLUDE_AUTHORS"))
+	config.FetchMergeActor = os.Getenv("FETCH_MERGE_ACTOR") == "true"
+	config.FetchDraftTime = os.Getenv("FETCH_DRAFT_TIME") == "true"
+	config.FetchReviewComments = os.Getenv("FETCH_REVIEW_COMMENTS") == "true"
+	config.FetchPRCommits = os.Getenv("FETCH_PR_COMMITS") == "true"
+	config.ExcludeDraftTime = os.Getenv("EXCLUDE_DRAFT_TIME") == "true"
+	config.IgnoreFiles = splitList(os.Getenv("IGNORE_FILES"))
+	config.NumberLocale = os.Getenv("NUMBER_LOCALE")
+	config.ReviewTeam = splitList(os.Getenv("REVIEW_TEAM"))
+	config.UnknownAuthor = os.Getenv("UNKNOWN_AUTHOR")
+	config.Sinks = splitList(os.Getenv("SINKS"))
+	config.WeekendDays = splitList(os.Getenv("WEEKEND_DAYS"))
+	config.Holidays = splitList(os.Getenv("HOLIDAYS"))
+	config.FailureKeywords = splitList(os.Getenv("FAILURE_KEYWORDS"))
+	config.SubtaskMode = os.Getenv("SUBTASK_MODE")
+
+	config.FetchCommitLineCounts = os.Getenv("FETCH_COMMIT_LINE_COUNTS") == "true"
+	config.RawCommitsFile = os.Getenv("RAW_COMMITS_FILE")
+	config.EnrichCommits = os.Getenv("ENRICH_COMMITS") == "true"
+	config.TrendsFile = os.Getenv("TRENDS_FILE")
+	config.SnapshotDB = os.Getenv("SNAPSHOT_DB")
+	config.InputDir = os.Getenv("INPUT_DIR")
+	config.DumpDir = os.Getenv("DUMP_DIR")
+
+	config.HTTPSinkHeaders = splitHeaders(os.Getenv("HTTP_SINK_HEADERS"))
+	config.WebhookURL = os.Getenv("WEBHOOK_URL")
+	config.WebhookHeaders = splitHeaders(os.Getenv("WEBHOOK_HEADERS"))
+	config.SlackWebhookURL = os.Getenv("SLACK_WEBHOOK_URL")
+
+	config.JiraStoryPointField = os.Getenv("JIRA_STORY_POINT_FIELD")
+	config.JiraJQL = os.Getenv("JIRA_JQL")
+	config.JiraSprintField = os.Getenv("JIRA_SPRINT_FIELD")
+	config.JiraSprint = os.Getenv("JIRA_SPRINT")
+
+	config.LogFormat = os.Getenv("LOG_FORMAT")
+	config.LogLevel = os.Getenv("LOG_LEVEL")
+
+	config.Timezone = os.Getenv("TIMEZONE")
+
+	config.GitHubTeams = splitList(os.Getenv("GITHUB_TEAMS"))
+
+	config.ReviewStatesCountingAsReview = splitList(os.Getenv("REVIEW_STATES"))
+
+	config.AllowedOrigins = splitList(os.Getenv("ALLOWED_ORIGINS"))
+
+	config.ExcludeAuthorPatterns = splitList(os.Getenv("EXCLUDE_AUTHOR_PATTERNS"))
 
 	if days := os.Getenv("DAYS_TO_ANALYZE"); days != "" {
 		if d, err := strconv.Atoi(days); err == nil {
 			config.DaysToAnalyze = d
 		}
 	}
+	if n := os.Getenv("MIN_SAMPLE_SIZE"); n != "" {
+		if v, err := strconv.Atoi(n); err == nil {
+			config.MinSampleSize = v
+		}
+	}
+	if n := os.Getenv("SMOOTHING_WINDOW"); n != "" {
+		if v, err := strconv.Atoi(n); err == nil {
+			config.SmoothingWindow = v
+		}
+	}
+	for _, code := range splitList(os.Getenv("SUCCESS_STATUSES")) {
+		if v, err := strconv.Atoi(code); err == nil {
+			config.SuccessStatuses = append(config.SuccessStatuses, v)
+		}
+	}
+	if n := os.Getenv("TEAM_SIZE"); n != "" {
+		if v, err := strconv.Atoi(n); err == nil {
+			config.TeamSize = v
+		}
+	}
+	if n := os.Getenv("STALE_STORY_DAYS"); n != "" {
+		if v, err := strconv.Atoi(n); err == nil {
+			config.StaleStoryDays = v
+		}
+	}
+	if n := os.Getenv("STALE_PR_DAYS"); n != "" {
+		if v, err := strconv.Atoi(n); err == nil {
+			config.StalePRDays = v
+		}
+	}
+	if n := os.Getenv("MAX_PR_AGE_DAYS"); n != "" {
+		if v, err := strconv.Atoi(n); err == nil {
+			config.MaxPRAgeDays = v
+		}
+	}
+	for _, item := range splitList(os.Getenv("REQUIRED_APPROVALS")) {
+		if pattern, n, ok := strings.Cut(item, ":"); ok {
+			if v, err := strconv.Atoi(strings.TrimSpace(n)); err == nil {
+				config.RequiredApprovals[strings.TrimSpace(pattern)] = v
+			}
+		}
+	}
+	for _, item := range splitList(os.Getenv("AUTHOR_TEAMS")) {
+		if author, team, ok := strings.Cut(item, ":"); ok {
+			config.AuthorTeams[strings.TrimSpace(author)] = strings.TrimSpace(team)
+		}
+	}
+	// AUTHOR_ALIASES="John Doe:jdoe|john-doe,..." lists each person's aliases separated by |
+	for _, item := range splitList(os.Getenv("AUTHOR_ALIASES")) {
+		name, aliases, ok := strings.Cut(item, ":")
+		if !ok {
+			continue
+		}
+		name = strings.TrimSpace(name)
+		for _, alias := range strings.Split(aliases, "|") {
+			if alias = strings.TrimSpace(alias); alias != "" {
+				config.AuthorAliases[name] = append(config.AuthorAliases[name], alias)
+			}
+		}
+	}
+	for _, item := range splitList(os.Getenv("SCORE_WEIGHTS")) {
+		name, value, ok := strings.Cut(item, ":")
+		weight, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if !ok || err != nil {
+			continue
+		}
+		switch strings.TrimSpace(name) {
+		case "throughput":
+			config.ScoreWeights.Throughput = weight
+		case "merge_success":
+			config.ScoreWeights.MergeSuccess = weight
+		case "estimate_accuracy":
+			config.ScoreWeights.EstimateAccuracy = weight
+		case "cycle_time":
+			config.ScoreWeights.CycleTime = weight
+		}
+	}
+	if n := os.Getenv("HTTP_SINK_RETRIES"); n != "" {
+		if v, err := strconv.Atoi(n); err == nil {
+			config.HTTPSinkRetries = v
+		}
+	}
+	if n := os.Getenv("HTTP_SINK_TIMEOUT_SECONDS"); n != "" {
+		if v, err := strconv.Atoi(n); err == nil {
+			config.HTTPSinkTimeoutSeconds = v
+		}
+	}
+	if n := os.Getenv("GITHUB_MAX_RETRIES"); n != "" {
+		if v, err := strconv.Atoi(n); err == nil {
+			config.GitHubMaxRetries = v
+		}
+	}
+	if n := os.Getenv("MAX_CONCURRENCY"); n != "" {
+		if v, err := strconv.Atoi(n); err == nil {
+			config.MaxConcurrency = v
+		}
+	}
+	if n := os.Getenv("FAILURE_WINDOW_DAYS"); n != "" {
+		if v, err := strconv.Atoi(n); err == nil {
+			config.FailureWindowDays = v
+		}
+	}
+	if n := os.Getenv("MAX_COMMITS"); n != "" {
+		if v, err := strconv.Atoi(n); err == nil {
+			config.MaxCommits = v
+		}
+	}
+	if n := os.Getenv("MAX_PRS"); n != "" {
+		if v, err := strconv.Atoi(n); err == nil {
+			config.MaxPRs = v
+		}
+	}
+	if n := os.Getenv("MAX_ISSUES"); n != "" {
+		if v, err := strconv.Atoi(n); err == nil {
+			config.MaxIssues = v
+		}
+	}
+	if n := os.Getenv("MAX_ITEMS"); n != "" {
+		if v, err := strconv.Atoi(n); err == nil {
+			config.MaxItems = v
+		}
+	}
+	if n := os.Getenv("METRICS_CACHE_SIZE"); n != "" {
+		if v, err := strconv.Atoi(n); err == nil {
+			config.MetricsCacheSize = v
+		}
+	}
+	if n := os.Getenv("CACHE_TTL_SECONDS"); n != "" {
+		if v, err := strconv.Atoi(n); err == nil {
+			config.CacheTTLSeconds = v
+		}
+	}
 
 	return config, nil
 }
 
-// CreateSampleConfig creates a sample configuration file
-func CreateSampleConfig() error {
-	config := Config{
-		BitbucketURL:     "https://bitbucket.company.com",
-		BitbucketToken:   "your-bitbucket-token",
-		BitbucketProject: "PROJECT",
-		BitbucketRepo:    "repository-slug",
-		GitHubURL:       "https://github.com",
-		GitHubToken:     "your-github-token",
-		GitHubOwner:     "your-organization",
-		GitHubRepo:      "repository-name",
-		JiraURL:         "https://jira.company.com",
-		JiraUsername:     "your-username",
-		JiraToken:        "your-jira-token",
-		JiraProject:      "PROJ",
-		DaysToAnalyze:    30,
-		IsJiraCloud:      false,
+// splitList splits a comma-separated environment value into trimmed, non-empty items
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
 	}
+	return items
+}
 
-	data, err := json.MarshalIndent(config, "", "  ")
+// splitHeaders parses a comma-separated list of "Name: value" pairs into a header map
+func splitHeaders(value string) map[string]string {
+	headers := make(map[string]string)
+	for _, item := range splitList(value) {
+		if name, v, ok := strings.Cut(item, ":"); ok {
+			headers[strings.TrimSpace(name)] = strings.TrimSpace(v)
+		}
+	}
+	return headers
+}
+
+// RepoExclusionReason returns why a repository with the given flags should be skipped,
+// or an empty string if it should be analyzed
+func (c Config) RepoExclusionReason(archived, fork bool) string {
+	if archived && c.ExcludeArchived {
+		return "archived"
+	}
+	if fork && c.ExcludeForks {
+		return "fork"
+	}
+	return ""
+}
+
+// RequiredApprovalsFor returns how many approvals PRs into branch require: the highest
+// requirement among the matching RequiredApprovals patterns. ok is false when no pattern matches.
+func (c Config) RequiredApprovalsFor(branch string) (required int, ok bool) {
+	for pattern, n := range c.RequiredApprovals {
+		if matched, _ := path.Match(pattern, branch); matched && (!ok || n > required) {
+			required, ok = n, true
+		}
+	}
+	return required, ok
+}
+
+// Validate checks settings that would otherwise silently produce empty or runaway results.
+// It returns an error for unusable values and warnings for values that are merely risky.
+func (c Config) Validate() (warnings []string, err error) {
+	if c.DaysToAnalyze <= 0 {
+		return nil, fmt.Errorf("days_to_analyze must be positive, got %d", c.DaysToAnalyze)
+	}
+	if c.DaysToAnalyze > LargeDaysToAnalyze {
+		warnings = append(warnings, fmt.Sprintf("days_to_analyze is %d; windows over %d days can trigger very large fetches", c.DaysToAnalyze, LargeDaysToAnalyze))
+	}
+	// Only PRs opened inside the window are fetched, so none can be older than the window
+	if c.MaxPRAgeDays > 0 && c.MaxPRAgeDays >= c.DaysToAnalyze {
+		warnings = append(warnings, fmt.Sprintf("max_pr_age_days (%d) is not below days_to_analyze (%d); only PRs opened in the window are fetched, so no PR can be reported as aged", c.MaxPRAgeDays, c.DaysToAnalyze))
+	}
+	switch strings.ToLower(c.GitHubAuthScheme) {
+	case "", "token", "bearer":
+	default:
+		return nil, fmt.Errorf("invalid github_auth_scheme %q: use token or bearer", c.GitHubAuthScheme)
+	}
+	if c.Timezone != "" {
+		if _, err := time.LoadLocation(c.Timezone); err != nil {
+			return nil, fmt.Errorf("invalid timezone %q: %w", c.Timezone, err)
+		}
+	}
+	if c.TicketPattern != "" {
+		if _, err := regexp.Compile(c.TicketPattern); err != nil {
+			return nil, fmt.Errorf("invalid ticket_pattern %q: %w", c.TicketPattern, err)
+		}
+	}
+	if c.CommitMessagePattern != "" {
+		if _, err := regexp.Compile(c.CommitMessagePattern); err != nil {
+			return nil, fmt.Errorf("invalid commit_message_pattern %q: %w", c.CommitMessagePattern, err)
+		}
+	}
+	if c.InputDir != "" && c.DumpDir != "" {
+		return nil, fmt.Errorf("use either input_dir or dump_dir, not both")
+	}
+	if c.InputDir != "" {
+		if info, err := os.Stat(c.InputDir); err != nil || !info.IsDir() {
+			return nil, fmt.Errorf("input_dir %q is not a directory", c.InputDir)
+		}
+	}
+	for _, pattern := range c.ExcludeAuthorPatterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return nil, fmt.Errorf("invalid exclude_author_patterns entry %q: %w", pattern, err)
+		}
+	}
+	// Aliases are matched case-insensitively, so one listed under two people is ambiguous
+	aliasOf := make(map[string]string)
+	for canonical, aliases := range c.AuthorAliases {
+		for _, alias := range aliases {
+			key := strings.ToLower(alias)
+			if other, ok := aliasOf[key]; ok && other != canonical {
+				first, second := other, canonical
+				if second < first {
+					first, second = second, first
+				}
+				return nil, fmt.Errorf("author_aliases lists %q under both %q and %q", alias, first, second)
+			}
+			aliasOf[key] = canonical
+		}
+	}
+	switch c.SubtaskMode {
+	case "", SubtaskInclude, SubtaskExclude, SubtaskRollup, SubtaskSeparate:
+	default:
+		return nil, fmt.Errorf("invalid subtask_mode %q: use include, exclude, rollup or separate", c.SubtaskMode)
+	}
+	if c.FailureWindowDays < 0 {
+		return nil, fmt.Errorf("failure_window_days must not be negative, got %d", c.FailureWindowDays)
+	}
+	w := c.ScoreWeights
+	if w.Throughput < 0 || w.MergeSuccess < 0 || w.EstimateAccuracy < 0 || w.CycleTime < 0 {
+		return nil, fmt.Errorf("score_weights must not be negative")
+	}
+	return warnings, nil
+}
+
+// ReportLocation returns the zone dates are bucketed in: Timezone, or UTC when it is not set,
+// so the buckets don't depend on the host's zone
+func (c Config) ReportLocation() *time.Location {
+	loc, err := time.LoadLocation(c.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// Window returns the analysis window [since, until): WindowStart and WindowEnd when set,
+// otherwise the last DaysToAnalyze days up to now
+func (c Config) Window() (since, until time.Time) {
+	until = c.WindowEnd
+	if until.IsZero() {
+		until = time.Now()
+	}
+	since = c.WindowStart
+	if since.IsZero() {
+		since = until.AddDate(0, 0, -c.DaysToAnalyze)
+	}
+	return since, until
+}
+
+// InWindow reports whether t falls within the analysis window
+func (c Config) InWindow(t time.Time) bool {
+	since, until := c.Window()
+	return !t.Before(since) && t.Before(until)
+}
+
+// WithWindow returns a copy of the config whose analysis window is overridden by days (a
+// look-back in days) or by since and until (inclusive YYYY-MM-DD dates in the report zone, so
+// days line up with the commit buckets). Empty values keep the configured window; until
+// defaults to today when only since is given.
+func (c Config) WithWindow(days, since, until string) (Config, error) {
+	if days != "" && (since != "" || until != "") {
+		return c, fmt.Errorf("use either days or since/until, not both")
+	}
+
+	if days != "" {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			return c, fmt.Errorf("invalid days: must be a positive integer")
+		}
+		c.DaysToAnalyze = n
+		return c, nil
+	}
+
+	if since == "" && until == "" {
+		return c, nil
+	}
+	if since == "" {
+		return c, fmt.Errorf("until requires since")
+	}
+
+	loc := c.ReportLocation()
+	start, err := time.ParseInLocation("2006-01-02", since, loc)
+	if err != nil {
+		return c, fmt.Errorf("invalid since: use YYYY-MM-DD")
+	}
+	end := time.Now()
+	if until != "" {
+		t, err := time.ParseInLocation("2006-01-02", until, loc)
+		if err != nil {
+			return c, fmt.Errorf("invalid until: use YYYY-MM-DD")
+		}
+		// Include the whole final day
+		end = t.AddDate(0, 0, 1)
+	}
+	if !start.Before(end) {
+		return c, fmt.Errorf("since must not be after until")
+	}
+
+	c.WindowStart = start
+	c.WindowEnd = end
+	return c, nil
+}
+
+// WithTeamMemberships returns a copy of the config whose AuthorTeams maps each member in
+// memberships (author: team) to their fetched team, keeping the configured team only for
+// authors that are not in any fetched team
+func (c Config) WithTeamMemberships(memberships map[string]string) Config {
+	teams := make(map[string]string, len(c.AuthorTeams)+len(memberships))
+	for author, team := range c.AuthorTeams {
+		teams[author] = team
+	}
+	for author, team := range memberships {
+		teams[author] = team
+	}
+	c.AuthorTeams = teams
+	return c
+}
+
+// Concurrency returns MaxConcurrency, or DefaultMaxConcurrency when it is not set
+func (c Config) Concurrency() int {
+	if c.MaxConcurrency <= 0 {
+		return DefaultMaxConcurrency
+	}
+	return c.MaxConcurrency
+}
+
+// FailureWindow returns FailureWindowDays as a duration, or DefaultFailureWindowDays when it
+// is not set
+func (c Config) FailureWindow() time.Duration {
+	days := c.FailureWindowDays
+	if days <= 0 {
+		days = DefaultFailureWindowDays
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// CommitCap returns MaxCommits, or MaxItems when it is not set (0 = unlimited)
+func (c Config) CommitCap() int {
+	return capOr(c.MaxCommits, c.MaxItems)
+}
+
+// PRCap returns MaxPRs, or MaxItems when it is not set (0 = unlimited)
+func (c Config) PRCap() int {
+	return capOr(c.MaxPRs, c.MaxItems)
+}
+
+// IssueCap returns MaxIssues, or MaxItems when it is not set (0 = unlimited)
+func (c Config) IssueCap() int {
+	return capOr(c.MaxIssues, c.MaxItems)
+}
+
+func capOr(specific, general int) int {
+	if specific > 0 {
+		return specific
+	}
+	return general
+}
+
+// SnapshotPath returns SnapshotDB, or DefaultSnapshotDB when it is not set
+func (c Config) SnapshotPath() string {
+	if c.SnapshotDB == "" {
+		return DefaultSnapshotDB
+	}
+	return c.SnapshotDB
+}
+
+// IsSuccessStatus reports whether an API response status should be treated as success:
+// one of SuccessStatuses when configured, otherwise any 2xx status
+func (c Config) IsSuccessStatus(code int) bool {
+	if len(c.SuccessStatuses) == 0 {
+		return code >= 200 && code < 300
+	}
+	for _, ok := range c.SuccessStatuses {
+		if code == ok {
+			return true
+		}
+	}
+	return false
+}
+
+// RepoNameExclusionReason returns why the repository fullName ("owner/repo") matches
+// ExcludeRepos, or an empty string if it doesn't. Patterns without a slash match the repo name alone.
+func (c Config) RepoNameExclusionReason(fullName string) string {
+	repo := fullName
+	if i := strings.LastIndex(fullName, "/"); i >= 0 {
+		repo = fullName[i+1:]
+	}
+
+	for _, pattern := range c.ExcludeRepos {
+		name := fullName
+		if !strings.Contains(pattern, "/") {
+			name = repo
+		}
+		if ok, _ := path.Match(pattern, name); ok {
+			return fmt.Sprintf("matches exclude_repos pattern %q", pattern)
+		}
+	}
+	return ""
+}
+
+// CreateSampleConfig creates a sample JSON configuration file
+func CreateSampleConfig() error {
+	data, err := json.MarshalIndent(sampleConfig(), "", "  ")
 	if err != nil {
 		return err
 	}
 
 	return os.WriteFile("config.sample.json", data, 0644)
-}
\ No newline at end of file
+}
+
+// CreateSampleConfigYAML creates a sample YAML configuration file
+func CreateSampleConfigYAML() error {
+	data, err := yaml.Marshal(sampleConfig())
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile("config.sample.yaml", data, 0644)
+}
+
+// sampleConfig returns the configuration written by the sample config files
+func sampleConfig() Config {
+	return Config{
+		BitbucketURL:      "https://bitbucket.company.com",
+		BitbucketToken:    "your-bitbucket-token",
+		BitbucketProject:  "PROJECT",
+		BitbucketRepo:     "repository-slug",
+		GitHubURL:         "https://github.com",
+		GitHubToken:       "your-github-token",
+		GitHubOwner:       "your-organization",
+		GitHubRepo:        "repository-name",
+		GitLabURL:         "https://gitlab.com",
+		GitLabToken:       "your-gitlab-token",
+		GitLabProjectID:   "group/project",
+		AzureOrg:          "your-organization",
+		AzureProject:      "your-project",
+		AzureRepo:         "repository-name",
+		AzurePAT:          "your-azure-pat",
+		JiraURL:           "https://jira.company.com",
+		JiraUsername:      "your-username",
+		JiraToken:         "your-jira-token",
+		JiraProject:       "PROJ",
+		DaysToAnalyze:     30,
+		IsJiraCloud:       false,
+		TicketPattern:     DefaultTicketPattern,
+		MinSampleSize:     DefaultMinSampleSize,
+		SmoothingWindow:   DefaultSmoothingWindow,
+		MetricsCacheSize:  DefaultMetricsCacheSize,
+		CacheTTLSeconds:   DefaultCacheTTLSeconds,
+		StaleStoryDays:    DefaultStaleStoryDays,
+		StalePRDays:       DefaultStalePRDays,
+		MaxPRAgeDays:      DefaultMaxPRAgeDays,
+		MaxConcurrency:    DefaultMaxConcurrency,
+		IgnoreFiles:       []string{"*.lock", "package-lock.json", "go.sum", "dist/**", "vendor/**", "*.min.js"},
+		WeekendDays:       []string{"Saturday", "Sunday"},
+		Holidays:          []string{"2025-12-25", "2026-01-01"},
+		Sinks:             []string{"file:metrics.json", "file:metrics.csv"},
+		UnknownAuthor:     DefaultUnknownAuthor,
+		FailureKeywords:   DefaultFailureKeywords,
+		FailureWindowDays: DefaultFailureWindowDays,
+		SubtaskMode:       SubtaskInclude,
+
+		HTTPSinkRetries:        DefaultHTTPSinkRetries,
+		HTTPSinkTimeoutSeconds: 30,
+
+		JiraStoryPointField: DefaultJiraStoryPointField,
+		JiraSprintField:     DefaultJiraSprintField,
+
+		CommitMessagePattern: DefaultCommitMessagePattern,
+
+		ReviewStatesCountingAsReview: DefaultReviewStates,
+
+		ScoreWeights: DefaultScoreWeights,
+	}
+}
+
+// AuthorOrFallback returns author, or the configured fallback when author is blank.
+// With the "email" fallback the given email is used when present.
+func (c Config) AuthorOrFallback(author, email string) string {
+	if author = strings.TrimSpace(author); author != "" {
+		return author
+	}
+
+	fallback := c.UnknownAuthor
+	if fallback == "email" {
+		if email = strings.TrimSpace(email); email != "" {
+			return email
+		}
+		fallback = ""
+	}
+	if fallback == "" {
+		fallback = DefaultUnknownAuthor
+	}
+	return fallback
+}
@@ -0,0 +1,266 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestValidateWarnsWhenAgedPRsCannotOccur(t *testing.T) {
+	tests := []struct {
+		name          string
+		daysToAnalyze int
+		maxPRAgeDays  int
+		wantWarning   bool
+	}{
+		{"defaults", 30, DefaultMaxPRAgeDays, true},
+		{"age beyond window", 30, 45, true},
+		{"age inside window", 90, 30, false},
+		{"aged PRs disabled", 30, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			warnings, err := Config{DaysToAnalyze: tt.daysToAnalyze, MaxPRAgeDays: tt.maxPRAgeDays}.Validate()
+			if err != nil {
+				t.Fatalf("Validate() error = %v", err)
+			}
+			got := false
+			for _, w := range warnings {
+				if strings.Contains(w, "max_pr_age_days") {
+					got = true
+				}
+			}
+			if got != tt.wantWarning {
+				t.Errorf("max_pr_age_days warning = %v, want %v (warnings: %q)", got, tt.wantWarning, warnings)
+			}
+		})
+	}
+}
+
+// writeConfig writes content to a config file with the given name in a temporary directory
+func writeConfig(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadConfigFileKeepsDefaults(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		want    int
+	}{
+		{"json without smoothing_window", "config.json", `{"github_owner": "acme"}`, DefaultSmoothingWindow},
+		{"yaml without smoothing_window", "config.yaml", "github_owner: acme\n", DefaultSmoothingWindow},
+		{"json with smoothing_window", "config.json", `{"smoothing_window": 3}`, 3},
+		{"yaml with smoothing_window", "config.yml", "smoothing_window: 14\n", 14},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := LoadConfig(writeConfig(t, tt.file, tt.content))
+			if err != nil {
+				t.Fatalf("LoadConfig() error = %v", err)
+			}
+			if cfg.SmoothingWindow != tt.want {
+				t.Errorf("SmoothingWindow = %d, want %d", cfg.SmoothingWindow, tt.want)
+			}
+			if cfg.DaysToAnalyze != 30 || cfg.MetricsCacheSize != DefaultMetricsCacheSize {
+				t.Errorf("DaysToAnalyze, MetricsCacheSize = %d, %d; want defaults", cfg.DaysToAnalyze, cfg.MetricsCacheSize)
+			}
+		})
+	}
+}
+
+func TestLoadConfigEnvDefaults(t *testing.T) {
+	t.Setenv("SMOOTHING_WINDOW", "")
+	cfg, err := LoadConfig(filepath.Join(t.TempDir(), "missing.json"))
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if cfg.SmoothingWindow != DefaultSmoothingWindow {
+		t.Errorf("SmoothingWindow = %d, want %d", cfg.SmoothingWindow, DefaultSmoothingWindow)
+	}
+
+	t.Setenv("SMOOTHING_WINDOW", "5")
+	if cfg, _ = LoadConfig(filepath.Join(t.TempDir(), "missing.json")); cfg.SmoothingWindow != 5 {
+		t.Errorf("SmoothingWindow = %d, want 5 from SMOOTHING_WINDOW", cfg.SmoothingWindow)
+	}
+}
+
+func TestLoadConfigFileStaleDays(t *testing.T) {
+	tests := []struct {
+		name      string
+		content   string
+		wantStory int
+		wantPR    int
+	}{
+		{"omitted", "github_owner: acme\n", DefaultStaleStoryDays, DefaultStalePRDays},
+		{"set", "stale_story_days: 21\nstale_pr_days: 3\n", 21, 3},
+		{"explicit zero disables", "stale_story_days: 0\nstale_pr_days: 0\n", 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := LoadConfig(writeConfig(t, "config.yaml", tt.content))
+			if err != nil {
+				t.Fatalf("LoadConfig() error = %v", err)
+			}
+			if cfg.StaleStoryDays != tt.wantStory || cfg.StalePRDays != tt.wantPR {
+				t.Errorf("StaleStoryDays, StalePRDays = %d, %d; want %d, %d",
+					cfg.StaleStoryDays, cfg.StalePRDays, tt.wantStory, tt.wantPR)
+			}
+		})
+	}
+}
+
+func TestValidateRejects(t *testing.T) {
+	tests := []struct {
+		name    string
+		change  func(*Config)
+		wantErr string // Empty when the config is valid
+	}{
+		{"defaults", func(c *Config) {}, ""},
+		{"failure window", func(c *Config) { c.FailureWindowDays = 14 }, ""},
+		{"failure window unset", func(c *Config) { c.FailureWindowDays = 0 }, ""},
+		{"negative failure window", func(c *Config) { c.FailureWindowDays = -1 }, "failure_window_days"},
+		{"ticket pattern", func(c *Config) { c.TicketPattern = `#\d+` }, ""},
+		{"invalid ticket pattern", func(c *Config) { c.TicketPattern = `[A-Z+-\d+` }, "ticket_pattern"},
+		{"subtask mode", func(c *Config) { c.SubtaskMode = SubtaskRollup }, ""},
+		{"unknown subtask mode", func(c *Config) { c.SubtaskMode = "rolllup" }, "subtask_mode"},
+		{"subtask mode is case-sensitive", func(c *Config) { c.SubtaskMode = "Exclude" }, "subtask_mode"},
+		{"commit message pattern", func(c *Config) { c.CommitMessagePattern = `^[A-Z]+-\d+ ` }, ""},
+		{"invalid commit message pattern", func(c *Config) { c.CommitMessagePattern = `^(feat|fix` }, "commit_message_pattern"},
+		{"author aliases", func(c *Config) { c.AuthorAliases = map[string][]string{"Ann": {"ann", "asmith"}, "Bob": {"bob"}} }, ""},
+		{"alias repeated under one person", func(c *Config) { c.AuthorAliases = map[string][]string{"Ann": {"asmith", "ASmith"}} }, ""},
+		{"alias under two people", func(c *Config) { c.AuthorAliases = map[string][]string{"Ann": {"asmith"}, "Al": {"ASmith"}} }, "author_aliases"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := defaultConfig()
+			tt.change(&cfg)
+			_, err := cfg.Validate()
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Errorf("Validate() error = %v, want none", err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Errorf("Validate() error = %v, want one mentioning %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWithWindow(t *testing.T) {
+	tests := []struct {
+		name               string
+		timezone           string
+		days, since, until string
+		wantStart, wantEnd string // RFC3339 in UTC; empty when the window is relative
+		wantDays           int
+		wantErr            bool
+	}{
+		{"days", "", "7", "", "", "", "", 7, false},
+		{"unchanged", "", "", "", "", "", "", 30, false},
+		{"dates default to UTC", "", "", "2026-03-01", "2026-03-31", "2026-03-01T00:00:00Z", "2026-04-01T00:00:00Z", 30, false},
+		{"dates in the report zone", "Europe/Berlin", "", "2026-03-01", "2026-03-31", "2026-02-28T23:00:00Z", "2026-03-31T22:00:00Z", 30, false},
+		{"dates in a zone behind UTC", "America/New_York", "", "2026-03-01", "2026-03-01", "2026-03-01T05:00:00Z", "2026-03-02T05:00:00Z", 30, false},
+		{"days and dates", "", "7", "2026-03-01", "", "", "", 0, true},
+		{"until alone", "", "", "", "2026-03-31", "", "", 0, true},
+		{"bad date", "", "", "03/01/2026", "", "", "", 0, true},
+		{"reversed", "", "", "2026-03-31", "2026-03-01", "", "", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Config{DaysToAnalyze: 30, Timezone: tt.timezone}
+			got, err := cfg.WithWindow(tt.days, tt.since, tt.until)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("WithWindow() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got.DaysToAnalyze != tt.wantDays {
+				t.Errorf("DaysToAnalyze = %d, want %d", got.DaysToAnalyze, tt.wantDays)
+			}
+			format := func(t time.Time) string {
+				if t.IsZero() {
+					return ""
+				}
+				return t.UTC().Format(time.RFC3339)
+			}
+			if start, end := format(got.WindowStart), format(got.WindowEnd); start != tt.wantStart || end != tt.wantEnd {
+				t.Errorf("window = [%s, %s), want [%s, %s)", start, end, tt.wantStart, tt.wantEnd)
+			}
+		})
+	}
+}
+
+func TestLoadConfigJSONAndYAMLAgree(t *testing.T) {
+	sample := sampleConfig()
+	sampleJSON, err := json.Marshal(sample)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sampleYAML, err := yaml.Marshal(sample)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		json, yaml string
+	}{
+		{"sample config", string(sampleJSON), string(sampleYAML)},
+		{
+			name: "hand-written",
+			json: `{
+				"github_owner": "acme",
+				"github_repo": "api",
+				"days_to_analyze": 14,
+				"is_jira_cloud": true,
+				"exclude_repos": ["acme/experiment-*", "*-mirror"],
+				"success_statuses": [200, 203],
+				"ticket_pattern": "[A-Z]+-\\d+"
+			}`,
+			yaml: `
+github_owner: acme
+github_repo: api
+days_to_analyze: 14
+is_jira_cloud: true
+exclude_repos:
+  - acme/experiment-*
+  - "*-mirror"
+success_statuses: [200, 203]
+ticket_pattern: '[A-Z]+-\d+'
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fromJSON, err := LoadConfig(writeConfig(t, "config.json", tt.json))
+			if err != nil {
+				t.Fatalf("LoadConfig(json) error = %v", err)
+			}
+			for _, name := range []string{"config.yaml", "config.yml"} {
+				fromYAML, err := LoadConfig(writeConfig(t, name, tt.yaml))
+				if err != nil {
+					t.Fatalf("LoadConfig(%s) error = %v", name, err)
+				}
+				// A JSON null and an empty YAML list both mean "none", so compare the
+				// settings rather than nil-ness
+				want, _ := yaml.Marshal(fromJSON)
+				got, _ := yaml.Marshal(fromYAML)
+				if string(got) != string(want) {
+					t.Errorf("%s differs from JSON:\n json: %s\n yaml: %s", name, want, got)
+				}
+			}
+		})
+	}
+}
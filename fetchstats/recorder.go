@@ -0,0 +1,150 @@
+package fetchstats
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// ProviderStats holds request counters for a single provider
+type ProviderStats struct {
+	Requests       int      `json:"requests"`
+	Errors         int      `json:"errors"`
+	Retries        int      `json:"retries"`
+	Bytes          int64    `json:"bytes"`
+	TotalLatencyMs float64  `json:"total_latency_ms"`
+	AvgLatencyMs   float64  `json:"avg_latency_ms"`
+	Truncated      []string `json:"truncated,omitempty"` // Data kinds whose fetch stopped at a configured cap
+}
+
+// Recorder accumulates API request statistics per provider. It is safe for
+// concurrent use, and a nil Recorder silently discards everything.
+type Recorder struct {
+	mu        sync.Mutex
+	providers map[string]*ProviderStats
+}
+
+// NewRecorder creates an empty stats recorder
+func NewRecorder() *Recorder {
+	return &Recorder{
+		providers: make(map[string]*ProviderStats),
+	}
+}
+
+// provider returns the stats entry for name, creating it if needed. Callers must hold mu.
+func (r *Recorder) provider(name string) *ProviderStats {
+	p, ok := r.providers[name]
+	if !ok {
+		p = &ProviderStats{}
+		r.providers[name] = p
+	}
+	return p
+}
+
+// RecordRequest records one completed HTTP request
+func (r *Recorder) RecordRequest(provider string, bytes int, latency time.Duration, err error) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	p := r.provider(provider)
+	p.Requests++
+	p.Bytes += int64(bytes)
+	p.TotalLatencyMs += float64(latency) / float64(time.Millisecond)
+	if err != nil {
+		p.Errors++
+	}
+}
+
+// RecordRetry records that a request is being retried
+func (r *Recorder) RecordRetry(provider string) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.provider(provider).Retries++
+}
+
+// RecordTruncated records that fetching kind (e.g. "commits") stopped at a configured cap
+func (r *Recorder) RecordTruncated(provider, kind string) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	p := r.provider(provider)
+	if !contains(p.Truncated, kind) {
+		p.Truncated = append(p.Truncated, kind)
+	}
+}
+
+// TruncatedFetches lists every capped fetch as "provider:kind", sorted
+func (r *Recorder) TruncatedFetches() []string {
+	var fetches []string
+	for name, s := range r.Snapshot() {
+		for _, kind := range s.Truncated {
+			fetches = append(fetches, name+":"+kind)
+		}
+	}
+	sort.Strings(fetches)
+	return fetches
+}
+
+// Merge adds the counters from other into r
+func (r *Recorder) Merge(other *Recorder) {
+	if r == nil || other == nil {
+		return
+	}
+	snapshot := other.Snapshot()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for name, s := range snapshot {
+		p := r.provider(name)
+		p.Requests += s.Requests
+		p.Errors += s.Errors
+		p.Retries += s.Retries
+		p.Bytes += s.Bytes
+		p.TotalLatencyMs += s.TotalLatencyMs
+		for _, kind := range s.Truncated {
+			if !contains(p.Truncated, kind) {
+				p.Truncated = append(p.Truncated, kind)
+			}
+		}
+	}
+}
+
+// contains reports whether list includes value
+func contains(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// Snapshot returns a copy of the current stats keyed by provider
+func (r *Recorder) Snapshot() map[string]ProviderStats {
+	result := make(map[string]ProviderStats)
+	if r == nil {
+		return result
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for name, p := range r.providers {
+		s := *p
+		s.Truncated = append([]string(nil), p.Truncated...)
+		if s.Requests > 0 {
+			s.AvgLatencyMs = s.TotalLatencyMs / float64(s.Requests)
+		}
+		result[name] = s
+	}
+	return result
+}
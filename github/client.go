@@ -1,27 +1,72 @@
 package github
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"devops-metrics/config"
+	"devops-metrics/fetchstats"
+	"devops-metrics/gitlocal"
+	"devops-metrics/pathfilter"
+	"devops-metrics/replay"
 )
 
 // Client handles GitHub API operations using direct HTTP calls
 type Client struct {
-	config config.Config
+	config     config.Config
+	stats      *fetchstats.Recorder
+	httpClient HTTPClient
+	progress   func(fetched int)
+}
+
+// HTTPClient sends API requests; *http.Client satisfies it, and tests can substitute a stub
+type HTTPClient interface {
+	Do(req *http.Request) (*http.Response, error)
 }
 
 // NewClient creates a new GitHub client
 func NewClient(config config.Config) Client {
 	return Client{
-		config: config,
+		config:     config,
+		httpClient: replay.NewHTTPClient("github", config),
+	}
+}
+
+// WithStats returns a copy of the client that records request statistics into r
+func (c Client) WithStats(r *fetchstats.Recorder) Client {
+	c.stats = r
+	return c
+}
+
+// WithProgress returns a copy of the client that calls fn with the running count of items
+// after each page of a list fetch
+func (c Client) WithProgress(fn func(fetched int)) Client {
+	c.progress = fn
+	return c
+}
+
+// reportProgress passes the running count of fetched items to the progress callback, if any
+func (c Client) reportProgress(fetched int) {
+	if c.progress != nil {
+		c.progress(fetched)
 	}
 }
 
+// WithHTTPClient returns a copy of the client that sends its requests through h
+func (c Client) WithHTTPClient(h HTTPClient) Client {
+	c.httpClient = h
+	return c
+}
+
 // GitHub API response structures
 type githubCommitsResponse struct {
 	Hash    string `json:"sha"`
@@ -38,6 +83,12 @@ type githubCommitsResponse struct {
 	} `json:"commit"`
 }
 
+type githubRepoResponse struct {
+	FullName string `json:"full_name"`
+	Archived bool   `json:"archived"`
+	Fork     bool   `json:"fork"`
+}
+
 type githubBranchesResponse struct {
 	Name string `json:"name"`
 }
@@ -56,6 +107,42 @@ type githubPRsResponse struct {
 	Additions    int       `json:"additions"`
 	Deletions    int       `json:"deletions"`
 	ChangedFiles int       `json:"changed_files"`
+	Draft        bool       `json:"draft"`
+	Base         struct {
+		Ref string `json:"ref"`
+	} `json:"base"`
+	Labels []struct {
+		Name string `json:"name"`
+	} `json:"labels"`
+	MergeCommitSHA string `json:"merge_commit_sha"`
+}
+
+type githubDeploymentsResponse struct {
+	ID          int    `json:"id"`
+	SHA         string `json:"sha"`
+	Ref         string `json:"ref"`
+	Environment string `json:"environment"`
+	Creator     *struct {
+		Login string `json:"login"`
+	} `json:"creator"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type githubTimelineEvent struct {
+	Event     string    `json:"event"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type githubPRDetailResponse struct {
+	MergedBy *struct {
+		Login string `json:"login"`
+	} `json:"merged_by"`
+}
+
+type githubPRFilesResponse struct {
+	Filename  string `json:"filename"`
+	Additions int    `json:"additions"`
+	Deletions int    `json:"deletions"`
 }
 
 type githubReviewsResponse struct {
@@ -63,67 +150,237 @@ type githubReviewsResponse struct {
 		Login string `json:"login"`
 	} `json:"user"`
 	State       string    `json:"state"`
+	Body        string    `json:"body"`
 	SubmittedAt time.Time `json:"submitted_at"`
 }
 
 // makeRequest makes an HTTP request with proper authentication
-func (c Client) makeRequest(url string) ([]byte, error) {
-	client := &http.Client{Timeout: 30 * time.Second}
-	req, err := http.NewRequest("GET", url, nil)
+func (c Client) makeRequest(ctx context.Context, url string) ([]byte, error) {
+	body, _, err := c.makeRequestWithHeaders(ctx, url)
+	return body, err
+}
+
+// makePagedRequest fetches one page of a list endpoint and returns the URL of the next page
+// from the Link header, or "" on the last page
+func (c Client) makePagedRequest(ctx context.Context, url string) ([]byte, string, error) {
+	body, header, err := c.makeRequestWithHeaders(ctx, url)
 	if err != nil {
-		return nil, err
+		return nil, "", err
+	}
+	return body, nextPageURL(header.Get("Link")), nil
+}
+
+// nextPageURL extracts the rel="next" URL from a Link header such as
+// <https://api.github.com/...&page=2>; rel="next", <...&page=5>; rel="last"
+func nextPageURL(link string) string {
+	for _, part := range strings.Split(link, ",") {
+		target, params, ok := strings.Cut(strings.TrimSpace(part), ";")
+		if !ok || !strings.HasPrefix(target, "<") || !strings.HasSuffix(target, ">") {
+			continue
+		}
+		for _, param := range strings.Split(params, ";") {
+			if strings.TrimSpace(param) == `rel="next"` {
+				return strings.TrimSuffix(strings.TrimPrefix(target, "<"), ">")
+			}
+		}
+	}
+	return ""
+}
+
+// Waits between retries of a rate-limited core API request
+const (
+	rateLimitBaseBackoff = 1 * time.Second
+	rateLimitMaxBackoff  = 5 * time.Minute // Primary limits can reset up to an hour away; don't sleep that long
+)
+
+// makeRequestWithHeaders makes an HTTP request with proper authentication and also returns
+// the response headers. Rate-limited requests (429, or 403 with Retry-After or an exhausted
+// X-RateLimit-Remaining, which covers secondary limits too) are retried up to GitHubMaxRetries
+// times, waiting as long as the headers ask.
+func (c Client) makeRequestWithHeaders(ctx context.Context, url string) ([]byte, http.Header, error) {
+	return c.send(ctx, "GET", url, nil)
+}
+
+// send performs a request with payload as its body (nil for none), with the authentication
+// and rate-limit retries described on makeRequestWithHeaders
+func (c Client) send(ctx context.Context, method, url string, payload []byte) ([]byte, http.Header, error) {
+	maxRetries := c.config.GitHubMaxRetries
+	if maxRetries <= 0 {
+		maxRetries = config.DefaultGitHubMaxRetries
+	}
+
+	for attempt := 0; ; attempt++ {
+		var reqBody io.Reader
+		if payload != nil {
+			reqBody = bytes.NewReader(payload)
+		}
+		req, err := http.NewRequestWithContext(ctx, method, url, reqBody)
+		if err != nil {
+			return nil, nil, err
+		}
+		if payload != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+
+		req.Header.Set("Authorization", c.authorization())
+		req.Header.Set("Accept", "application/vnd.github.v3+json")
+		req.Header.Set("User-Agent", "devops-metrics")
+
+		start := time.Now()
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			c.stats.RecordRequest("github", 0, time.Since(start), err)
+			return nil, nil, err
+		}
+
+		body, readErr := io.ReadAll(resp.Body)
+		resp.Body.Close()
+
+		if c.config.IsSuccessStatus(resp.StatusCode) {
+			c.stats.RecordRequest("github", len(body), time.Since(start), readErr)
+			return body, resp.Header, readErr
+		}
+
+		if isRateLimited(resp) && attempt < maxRetries {
+			c.stats.RecordRequest("github", len(body), time.Since(start), nil)
+			c.stats.RecordRetry("github")
+			if err := sleep(ctx, rateLimitBackoff(resp.Header, attempt, rateLimitBaseBackoff, rateLimitMaxBackoff)); err != nil {
+				return nil, nil, err
+			}
+			continue
+		}
+
+		err = fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
+		c.stats.RecordRequest("github", len(body), time.Since(start), err)
+		return nil, nil, err
+	}
+}
+
+// bearerTokenPrefixes mark fine-grained PATs, OAuth tokens and GitHub App user and
+// installation tokens, which GitHub documents with the Bearer scheme
+var bearerTokenPrefixes = []string{"github_pat_", "gho_", "ghu_", "ghs_"}
+
+// authorization builds the Authorization header value for GitHubToken using
+// GitHubAuthScheme, or the scheme suggested by the token's prefix when it is not set
+func (c Client) authorization() string {
+	scheme := strings.ToLower(c.config.GitHubAuthScheme)
+	if scheme == "" {
+		scheme = "token"
+		for _, prefix := range bearerTokenPrefixes {
+			if strings.HasPrefix(c.config.GitHubToken, prefix) {
+				scheme = "bearer"
+				break
+			}
+		}
+	}
+
+	if scheme == "bearer" {
+		return "Bearer " + c.config.GitHubToken
+	}
+	return "token " + c.config.GitHubToken
+}
+
+// isRateLimited reports whether GitHub throttled the request: 429, or 403 with a Retry-After
+// header (secondary limits) or no remaining quota (primary limit)
+func isRateLimited(resp *http.Response) bool {
+	return resp.StatusCode == http.StatusTooManyRequests ||
+		(resp.StatusCode == http.StatusForbidden && (resp.Header.Get("Retry-After") != "" || resp.Header.Get("X-RateLimit-Remaining") == "0"))
+}
+
+// rateLimitBackoff picks the wait before retrying a rate-limited request, preferring the
+// server's Retry-After or X-RateLimit-Reset hints and falling back to exponential backoff
+// from base. The wait is kept between one second and max.
+func rateLimitBackoff(header http.Header, attempt int, base, max time.Duration) time.Duration {
+	delay := base * time.Duration(1<<attempt)
+
+	if secs, err := strconv.Atoi(header.Get("Retry-After")); err == nil {
+		delay = time.Duration(secs) * time.Second
+	} else if reset, err := strconv.ParseInt(header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+		delay = time.Until(time.Unix(reset, 0)) + time.Second
+	}
+
+	if delay > max {
+		delay = max
+	}
+	if delay < time.Second {
+		delay = time.Second
 	}
+	return delay
+}
 
-	req.Header.Set("Authorization", "token "+c.config.GitHubToken)
-	req.Header.Set("Accept", "application/vnd.github.v3+json")
-	req.Header.Set("User-Agent", "devops-metrics")
+// sleep waits for d, returning early with the context's error if ctx is cancelled first
+func sleep(ctx context.Context, d time.Duration) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(d):
+		return nil
+	}
+}
 
-	resp, err := client.Do(req)
+// FetchRepoInfo retrieves the archived/fork flags for the configured repository
+func (c Client) FetchRepoInfo(ctx context.Context) (RepoInfo, error) {
+	repoURL := fmt.Sprintf("%s/repos/%s/%s", c.getBaseURL(), c.config.GitHubOwner, c.config.GitHubRepo)
+	body, err := c.makeRequest(ctx, repoURL)
 	if err != nil {
-		return nil, err
+		return RepoInfo{}, fmt.Errorf("error fetching repository: %w", err)
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
+	var repo githubRepoResponse
+	if err := json.Unmarshal(body, &repo); err != nil {
+		return RepoInfo{}, fmt.Errorf("error parsing repository: %w", err)
 	}
 
-	return io.ReadAll(resp.Body)
+	return RepoInfo{
+		Name:     repo.FullName,
+		Archived: repo.Archived,
+		Fork:     repo.Fork,
+	}, nil
 }
 
-// FetchCommits retrieves commits from GitHub
-func (c Client) FetchCommits() ([]Commit, error) {
+// FetchCommits retrieves commits from GitHub, or from the local clone when
+// GitHubGitDir is configured
+func (c Client) FetchCommits(ctx context.Context) ([]Commit, error) {
+	if c.config.GitHubGitDir != "" {
+		return c.fetchLocalCommits(ctx)
+	}
+
 	var commits []Commit
-	since := time.Now().AddDate(0, 0, -c.config.DaysToAnalyze)
-	
+	since, until := c.config.Window()
+
 	// Get all branches first
-	branchesURL := fmt.Sprintf("%s/repos/%s/%s/branches", c.getBaseURL(), c.config.GitHubOwner, c.config.GitHubRepo)
-	branchBody, err := c.makeRequest(branchesURL)
-	if err != nil {
-		return nil, fmt.Errorf("error fetching branches: %w", err)
-	}
-	
 	var branches []githubBranchesResponse
-	if err := json.Unmarshal(branchBody, &branches); err != nil {
-		return nil, fmt.Errorf("error parsing branches: %w", err)
+	branchesURL := fmt.Sprintf("%s/repos/%s/%s/branches?per_page=100", c.getBaseURL(), c.config.GitHubOwner, c.config.GitHubRepo)
+	for branchesURL != "" {
+		branchBody, next, err := c.makePagedRequest(ctx, branchesURL)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching branches: %w", err)
+		}
+
+		var page []githubBranchesResponse
+		if err := json.Unmarshal(branchBody, &page); err != nil {
+			return nil, fmt.Errorf("error parsing branches: %w", err)
+		}
+		branches = append(branches, page...)
+
+		branchesURL = next
 	}
-	
+
+branches:
 	for _, branch := range branches {
-		page := 1
-		for {
-			commitsURL := fmt.Sprintf("%s/repos/%s/%s/commits?sha=%s&since=%s&page=%d&per_page=100",
-				c.getBaseURL(), c.config.GitHubOwner, c.config.GitHubRepo, branch.Name,
-				since.Format(time.RFC3339), page)
-			
-			commitBody, err := c.makeRequest(commitsURL)
+		commitsURL := fmt.Sprintf("%s/repos/%s/%s/commits?sha=%s&since=%s&until=%s&per_page=100",
+			c.getBaseURL(), c.config.GitHubOwner, c.config.GitHubRepo, branch.Name,
+			since.Format(time.RFC3339), until.Format(time.RFC3339))
+		for page := 1; commitsURL != ""; page++ {
+			commitBody, next, err := c.makePagedRequest(ctx, commitsURL)
 			if err != nil {
-				fmt.Printf("Error fetching commits from branch %s: %v\n", branch.Name, err)
+				slog.Error("Error fetching commits from branch", "provider", "github", "repo", c.repoName(), "branch", branch.Name, "error", err)
 				break
 			}
 			
 			var commitList []githubCommitsResponse
 			if err := json.Unmarshal(commitBody, &commitList); err != nil {
+				slog.Warn("Stopping commits for branch, page could not be parsed", "provider", "github", "repo", c.repoName(), "branch", branch.Name, "page", page, "error", err)
 				break
 			}
 			
@@ -132,10 +389,11 @@ func (c Client) FetchCommits() ([]Commit, error) {
 				if commitDate.Before(since) {
 					break
 				}
-				
+
+				// Deleted accounts and unmatched emails have neither a login nor a name
 				author := commit.Author.Login
-				if author == "" && commit.Commit.Author.Name != "" {
-					author = commit.Commit.Author.Name
+				if author == "" {
+					author = c.config.AuthorOrFallback(commit.Commit.Author.Name, commit.Commit.Author.Email)
 				}
 				
 				commits = append(commits, Commit{
@@ -146,92 +404,260 @@ func (c Client) FetchCommits() ([]Commit, error) {
 					// Line counts require additional API calls
 					LinesAdded:   0,
 					LinesDeleted: 0,
+					Repo:         c.repoName(),
 				})
+
+				if max := c.config.CommitCap(); max > 0 && len(commits) >= max {
+					c.truncated("commits", max)
+					break branches
+				}
 			}
-			
-			if len(commitList) < 100 {
-				break
-			}
-			page++
+
+			c.reportProgress(len(commits))
+			commitsURL = next
 		}
 	}
 	
 	return commits, nil
 }
 
-// FetchPRs retrieves pull requests from GitHub
-func (c Client) FetchPRs() ([]PullRequest, error) {
+// FetchPRs retrieves pull requests from GitHub. When a PR search query is configured,
+// PRs are found across repositories with the search API instead of the repo PR list;
+// with GitHubUseGraphQL they are read through the GraphQL API.
+func (c Client) FetchPRs(ctx context.Context) ([]PullRequest, error) {
+	if c.config.GitHubPRSearch != "" {
+		return c.FetchPRsBySearch(ctx, c.config.GitHubPRSearch)
+	}
+	if c.config.GitHubUseGraphQL {
+		return c.FetchPRsGraphQL(ctx)
+	}
+
 	var prs []PullRequest
-	since := time.Now().AddDate(0, 0, -c.config.DaysToAnalyze)
-	
-	page := 1
-	for {
-		prsURL := fmt.Sprintf("%s/repos/%s/%s/pulls?state=all&sort=updated&direction=desc&page=%d&per_page=100",
-			c.getBaseURL(), c.config.GitHubOwner, c.config.GitHubRepo, page)
-		
-		prBody, err := c.makeRequest(prsURL)
+	since, until := c.config.Window()
+	ignore := pathfilter.New(c.config.IgnoreFiles)
+
+	prsURL := fmt.Sprintf("%s/repos/%s/%s/pulls?state=all&sort=updated&direction=desc&per_page=100",
+		c.getBaseURL(), c.config.GitHubOwner, c.config.GitHubRepo)
+	for page := 1; prsURL != ""; page++ {
+		prBody, next, err := c.makePagedRequest(ctx, prsURL)
 		if err != nil {
 			return nil, fmt.Errorf("error fetching PRs: %w", err)
 		}
 		
 		var prList []githubPRsResponse
 		if err := json.Unmarshal(prBody, &prList); err != nil {
-			return nil, fmt.Errorf("error parsing PRs: %w", err)
+			if page == 1 {
+				return nil, fmt.Errorf("error parsing PRs: %w", err)
+			}
+			// Keep the pages already fetched rather than discarding them for one truncated page
+			slog.Warn("Stopping pull requests, page could not be parsed", "provider", "github", "repo", c.repoName(), "page", page, "error", err)
+			break
 		}
 		
 		for _, pr := range prList {
 			if pr.CreatedAt.Before(since) {
 				break
 			}
-			
-			// Get reviews for this PR
-			reviewsURL := fmt.Sprintf("%s/repos/%s/%s/pulls/%d/reviews",
-				c.getBaseURL(), c.config.GitHubOwner, c.config.GitHubRepo, pr.Number)
-			
-			reviewBody, _ := c.makeRequest(reviewsURL)
-			var reviews []githubReviewsResponse
-			json.Unmarshal(reviewBody, &reviews)
-			
-			var firstReviewAt *time.Time
-			for _, review := range reviews {
-				if (review.State == "APPROVED" || review.State == "CHANGES_REQUESTED") && firstReviewAt == nil {
-					firstReviewAt = &review.SubmittedAt
-					break
-				}
-			}
-			
-			// Calculate status
-			status := "OPEN"
-			if pr.MergedAt != nil {
-				status = "MERGED"
-			} else if pr.State == "closed" {
-				status = "CLOSED"
+			if !pr.CreatedAt.Before(until) {
+				continue
 			}
 			
 			if pr.ChangedFiles > 0 {
-				prs = append(prs, PullRequest{
-					ID:           fmt.Sprintf("PR-%d", pr.Number),
-					Author:       pr.User.Login,
-					CreatedAt:    pr.CreatedAt,
-					MergedAt:     pr.MergedAt,
-					ClosedAt:     pr.ClosedAt,
-					FirstReviewAt: firstReviewAt,
-					LinesChanged:  pr.Additions + pr.Deletions,
-					Status:       status,
-					Reviewers:    c.extractReviewers(reviews),
-				})
+				prs = append(prs, c.toPullRequest(ctx, pr, ignore))
+			}
+			if max := c.config.PRCap(); max > 0 && len(prs) >= max {
+				c.truncated("PRs", max)
+				return prs, nil
 			}
 		}
-		
-		if len(prList) < 100 {
-			break
+
+		c.reportProgress(len(prs))
+		prsURL = next
+	}
+
+	return prs, nil
+}
+
+// FetchPRsByID retrieves specific pull requests by number regardless of the analysis window.
+// PRs that cannot be fetched are reported and skipped.
+func (c Client) FetchPRsByID(ctx context.Context, numbers []int) ([]PullRequest, error) {
+	prs := []PullRequest{}
+	ignore := pathfilter.New(c.config.IgnoreFiles)
+
+	for _, number := range numbers {
+		prURL := fmt.Sprintf("%s/repos/%s/%s/pulls/%d",
+			c.getBaseURL(), c.config.GitHubOwner, c.config.GitHubRepo, number)
+
+		body, err := c.makeRequest(ctx, prURL)
+		if err != nil {
+			slog.Warn("Skipping pull request", "provider", "github", "repo", c.repoName(), "pr", number, "error", err)
+			continue
+		}
+
+		var pr githubPRsResponse
+		if err := json.Unmarshal(body, &pr); err != nil {
+			slog.Warn("Skipping pull request, response could not be parsed", "provider", "github", "repo", c.repoName(), "pr", number, "error", err)
+			continue
 		}
-		page++
+
+		prs = append(prs, c.toPullRequest(ctx, pr, ignore))
 	}
-	
+
 	return prs, nil
 }
 
+// toPullRequest converts an API pull request into a PullRequest, fetching its reviews
+// and any optional details enabled in the configuration
+func (c Client) toPullRequest(ctx context.Context, pr githubPRsResponse, ignore pathfilter.Matcher) PullRequest {
+	// Get reviews for this PR
+	reviewsURL := fmt.Sprintf("%s/repos/%s/%s/pulls/%d/reviews",
+		c.getBaseURL(), c.config.GitHubOwner, c.config.GitHubRepo, pr.Number)
+
+	reviewBody, _ := c.makeRequest(ctx, reviewsURL)
+	var reviews []githubReviewsResponse
+	json.Unmarshal(reviewBody, &reviews)
+
+	return c.assemblePullRequest(ctx, pr, reviews, "", ignore)
+}
+
+// assemblePullRequest builds the shared pull request shape from a PR and its reviews. When
+// mergedBy is unknown it is looked up if FetchMergeActor is set; the other optional lookups
+// follow their config flags.
+func (c Client) assemblePullRequest(ctx context.Context, pr githubPRsResponse, reviews []githubReviewsResponse, mergedBy string, ignore pathfilter.Matcher) PullRequest {
+	firstReviewAt := c.firstReview(reviews, pr.User.Login)
+
+	// Calculate status
+	status := "OPEN"
+	if pr.MergedAt != nil {
+		status = "MERGED"
+	} else if pr.State == "closed" {
+		status = "CLOSED"
+	}
+
+	if mergedBy == "" && c.config.FetchMergeActor && pr.MergedAt != nil {
+		mergedBy = c.fetchMergedBy(ctx, pr.Number)
+	}
+
+	var firstCommitAt *time.Time
+	var commitHashes []string
+	if c.config.FetchPRCommits {
+		firstCommitAt, commitHashes = c.fetchPRCommits(ctx, pr.Number)
+	}
+	if pr.MergeCommitSHA != "" {
+		commitHashes = append(commitHashes, pr.MergeCommitSHA)
+	}
+
+	var labels []string
+	for _, label := range pr.Labels {
+		labels = append(labels, label.Name)
+	}
+
+	var draftHours float64
+	if c.config.FetchDraftTime {
+		draftHours = c.fetchDraftHours(ctx, pr)
+	}
+
+	var reviewComments *int
+	if c.config.FetchReviewComments {
+		reviewComments = c.fetchReviewCommentCount(ctx, pr.Number)
+	}
+
+	linesChanged, linesIgnored := pr.Additions+pr.Deletions, 0
+	if !ignore.Empty() {
+		if changed, ignored, err := c.fetchPRFileLines(ctx, pr.Number, ignore); err == nil {
+			linesChanged, linesIgnored = changed, ignored
+		}
+	}
+
+	return PullRequest{
+		ID:             fmt.Sprintf("PR-%d", pr.Number),
+		Author:         pr.User.Login,
+		CreatedAt:      pr.CreatedAt,
+		MergedAt:       pr.MergedAt,
+		ClosedAt:       pr.ClosedAt,
+		FirstReviewAt:  firstReviewAt,
+		LinesChanged:   linesChanged,
+		LinesIgnored:   linesIgnored,
+		Status:         status,
+		Reviewers:      c.extractReviewers(reviews),
+		CommentCount:   countWrittenReviews(reviews),
+		ReviewComments: reviewComments,
+		ReviewCycles:   countReviewCycles(reviews),
+		Approvers:      extractApprovers(reviews),
+		MergedBy:       mergedBy,
+		DraftHours:     draftHours,
+		BaseBranch:     pr.Base.Ref,
+		FirstCommitAt:  firstCommitAt,
+		Title:          pr.Title,
+		Labels:         labels,
+		CommitHashes:   commitHashes,
+		UpdatedAt:      &pr.UpdatedAt,
+		Repo:           c.repoName(),
+	}
+}
+
+// FetchDeployments retrieves deployments created within the analysis window
+func (c Client) FetchDeployments(ctx context.Context) ([]Deployment, error) {
+	deployments := []Deployment{}
+	since, until := c.config.Window()
+
+	deploymentsURL := fmt.Sprintf("%s/repos/%s/%s/deployments?per_page=100",
+		c.getBaseURL(), c.config.GitHubOwner, c.config.GitHubRepo)
+	for page := 1; deploymentsURL != ""; page++ {
+		body, next, err := c.makePagedRequest(ctx, deploymentsURL)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching deployments: %w", err)
+		}
+
+		var list []githubDeploymentsResponse
+		if err := json.Unmarshal(body, &list); err != nil {
+			if page == 1 {
+				return nil, fmt.Errorf("error parsing deployments: %w", err)
+			}
+			slog.Warn("Stopping deployments, page could not be parsed", "provider", "github", "repo", c.repoName(), "page", page, "error", err)
+			break
+		}
+
+		// Deployments are listed newest first
+		reachedWindowStart := false
+		for _, d := range list {
+			if d.CreatedAt.Before(since) {
+				reachedWindowStart = true
+				break
+			}
+			if !d.CreatedAt.Before(until) {
+				continue
+			}
+
+			var creator string
+			if d.Creator != nil {
+				creator = d.Creator.Login
+			}
+			deployments = append(deployments, Deployment{
+				ID:          d.ID,
+				Environment: d.Environment,
+				Ref:         d.Ref,
+				SHA:         d.SHA,
+				Creator:     creator,
+				CreatedAt:   d.CreatedAt,
+				Repo:        c.repoName(),
+			})
+			if max := c.config.MaxItems; max > 0 && len(deployments) >= max {
+				c.truncated("deployments", max)
+				return deployments, nil
+			}
+		}
+
+		c.reportProgress(len(deployments))
+		if reachedWindowStart {
+			break
+		}
+		deploymentsURL = next
+	}
+
+	return deployments, nil
+}
+
 // getBaseURL returns the GitHub API base URL
 func (c Client) getBaseURL() string {
 	if c.config.GitHubURL == "" || c.config.GitHubURL == "https://github.com" {
@@ -253,4 +679,294 @@ func (c Client) extractReviewers(reviews []githubReviewsResponse) []string {
 	}
 	
 	return reviewers
-}
\ No newline at end of file
+}
+
+// countWrittenReviews counts the reviews submitted with a written summary. It is the PR's
+// CommentCount on GitHub; inline comments on the diff are only counted, separately, by
+// fetchReviewCommentCount.
+func countWrittenReviews(reviews []githubReviewsResponse) int {
+	count := 0
+	for _, review := range reviews {
+		if strings.TrimSpace(review.Body) != "" {
+			count++
+		}
+	}
+	return count
+}
+
+// fetchLocalCommits reads commits with accurate line counts from the configured local clone
+func (c Client) fetchLocalCommits(ctx context.Context) ([]Commit, error) {
+	since, until := c.config.Window()
+	localCommits, err := gitlocal.NewClient(c.config.GitHubGitDir, pathfilter.New(c.config.IgnoreFiles)).FetchCommits(ctx, since, until)
+	if err != nil {
+		return nil, err
+	}
+
+	commits := make([]Commit, len(localCommits))
+	for i, lc := range localCommits {
+		commits[i] = Commit{
+			Hash:         lc.Hash,
+			Author:       lc.Author,
+			Date:         lc.Date,
+			Message:      lc.Message,
+			LinesAdded:   lc.LinesAdded,
+			LinesDeleted: lc.LinesDeleted,
+			LinesIgnored: lc.LinesIgnored,
+			Repo:         c.repoName(),
+		}
+	}
+	if max := c.config.CommitCap(); max > 0 && len(commits) > max {
+		c.truncated("commits", max)
+		commits = commits[:max]
+	}
+	return commits, nil
+}
+
+// truncated reports that fetching kind stopped at the configured cap, so results are incomplete
+func (c Client) truncated(kind string, max int) {
+	slog.Warn("Stopped fetching at the configured cap; results are truncated", "provider", "github", "repo", c.repoName(), "kind", kind, "count", max)
+	c.stats.RecordTruncated("github", kind)
+}
+
+// repoName returns the owner/repo identifier used to tag fetched data
+func (c Client) repoName() string {
+	return c.config.GitHubOwner + "/" + c.config.GitHubRepo
+}
+
+// firstReview returns the earliest submission among reviews by someone other than the author
+// whose state counts as a review, or nil when there is none. The API does not guarantee
+// chronological order, so every review is checked.
+func (c Client) firstReview(reviews []githubReviewsResponse, author string) *time.Time {
+	states := c.config.ReviewStatesCountingAsReview
+	if len(states) == 0 {
+		states = config.DefaultReviewStates
+	}
+
+	var first *time.Time
+	for _, review := range reviews {
+		if review.SubmittedAt.IsZero() || review.User.Login == author {
+			continue
+		}
+		counts := false
+		for _, state := range states {
+			if strings.EqualFold(review.State, state) {
+				counts = true
+				break
+			}
+		}
+		if counts && (first == nil || review.SubmittedAt.Before(*first)) {
+			submitted := review.SubmittedAt
+			first = &submitted
+		}
+	}
+	return first
+}
+
+// countReviewCycles counts review rounds: each CHANGES_REQUESTED closes a round and
+// forces a re-review, and any review after the last request for changes opens a final round
+func countReviewCycles(reviews []githubReviewsResponse) int {
+	ordered := make([]githubReviewsResponse, 0, len(reviews))
+	for _, review := range reviews {
+		if review.State != "PENDING" {
+			ordered = append(ordered, review)
+		}
+	}
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ordered[i].SubmittedAt.Before(ordered[j].SubmittedAt)
+	})
+
+	cycles := 0
+	openRound := false
+	for _, review := range ordered {
+		if review.State == "CHANGES_REQUESTED" {
+			cycles++
+			openRound = false
+		} else {
+			openRound = true
+		}
+	}
+	if openRound {
+		cycles++
+	}
+	return cycles
+}
+
+// extractApprovers returns the unique logins that approved the PR
+func extractApprovers(reviews []githubReviewsResponse) []string {
+	seen := make(map[string]bool)
+	var approvers []string
+
+	for _, review := range reviews {
+		if review.State == "APPROVED" && review.User.Login != "" && !seen[review.User.Login] {
+			seen[review.User.Login] = true
+			approvers = append(approvers, review.User.Login)
+		}
+	}
+
+	return approvers
+}
+
+// fetchPRFileLines sums the PR's per-file line changes, separating files matched by ignore
+func (c Client) fetchPRFileLines(ctx context.Context, number int, ignore pathfilter.Matcher) (int, int, error) {
+	changed, ignored := 0, 0
+	filesURL := fmt.Sprintf("%s/repos/%s/%s/pulls/%d/files?per_page=100",
+		c.getBaseURL(), c.config.GitHubOwner, c.config.GitHubRepo, number)
+	for filesURL != "" {
+		body, next, err := c.makePagedRequest(ctx, filesURL)
+		if err != nil {
+			return 0, 0, fmt.Errorf("error fetching PR files: %w", err)
+		}
+
+		var files []githubPRFilesResponse
+		if err := json.Unmarshal(body, &files); err != nil {
+			return 0, 0, fmt.Errorf("error parsing PR files: %w", err)
+		}
+
+		for _, file := range files {
+			if ignore.Match(file.Filename) {
+				ignored += file.Additions + file.Deletions
+			} else {
+				changed += file.Additions + file.Deletions
+			}
+		}
+
+		filesURL = next
+	}
+
+	return changed, ignored, nil
+}
+
+// fetchMergedBy returns the login of the user who merged the PR, or "" if unavailable
+func (c Client) fetchMergedBy(ctx context.Context, number int) string {
+	prURL := fmt.Sprintf("%s/repos/%s/%s/pulls/%d",
+		c.getBaseURL(), c.config.GitHubOwner, c.config.GitHubRepo, number)
+
+	body, err := c.makeRequest(ctx, prURL)
+	if err != nil {
+		return ""
+	}
+
+	var detail githubPRDetailResponse
+	if err := json.Unmarshal(body, &detail); err != nil || detail.MergedBy == nil {
+		return ""
+	}
+	return detail.MergedBy.Login
+}
+
+// fetchReviewCommentCount returns the number of inline review comments on the PR's diff.
+// Only the count is kept. It returns nil when a page fails, so a partial count does not
+// lower the per-PR average.
+func (c Client) fetchReviewCommentCount(ctx context.Context, number int) *int {
+	commentsURL := fmt.Sprintf("%s/repos/%s/%s/pulls/%d/comments?per_page=100",
+		c.getBaseURL(), c.config.GitHubOwner, c.config.GitHubRepo, number)
+
+	count := 0
+	for commentsURL != "" {
+		body, next, err := c.makePagedRequest(ctx, commentsURL)
+		if err != nil {
+			slog.Warn("Could not count review comments", "provider", "github", "repo", c.repoName(), "pr", number, "error", err)
+			return nil
+		}
+		var comments []json.RawMessage
+		if err := json.Unmarshal(body, &comments); err != nil {
+			slog.Warn("Could not count review comments", "provider", "github", "repo", c.repoName(), "pr", number, "error", err)
+			return nil
+		}
+		count += len(comments)
+		commentsURL = next
+	}
+	return &count
+}
+
+// fetchDraftHours reads the PR timeline and sums the time the PR spent as a draft.
+// A PR whose first draft transition is ready_for_review was opened as a draft. It returns
+// 0 when a page fails, since a missing transition would skew the total.
+func (c Client) fetchDraftHours(ctx context.Context, pr githubPRsResponse) float64 {
+	timelineURL := fmt.Sprintf("%s/repos/%s/%s/issues/%d/timeline?per_page=100",
+		c.getBaseURL(), c.config.GitHubOwner, c.config.GitHubRepo, pr.Number)
+
+	var transitions []githubTimelineEvent
+	for timelineURL != "" {
+		body, next, err := c.makePagedRequest(ctx, timelineURL)
+		if err != nil {
+			return 0
+		}
+
+		var events []githubTimelineEvent
+		if err := json.Unmarshal(body, &events); err != nil {
+			return 0
+		}
+		for _, event := range events {
+			if event.Event == "ready_for_review" || event.Event == "convert_to_draft" {
+				transitions = append(transitions, event)
+			}
+		}
+		timelineURL = next
+	}
+	sort.Slice(transitions, func(i, j int) bool {
+		return transitions[i].CreatedAt.Before(transitions[j].CreatedAt)
+	})
+
+	var draftSince *time.Time
+	if (len(transitions) > 0 && transitions[0].Event == "ready_for_review") || (len(transitions) == 0 && pr.Draft) {
+		createdAt := pr.CreatedAt
+		draftSince = &createdAt
+	}
+
+	var total time.Duration
+	for _, transition := range transitions {
+		switch transition.Event {
+		case "ready_for_review":
+			if draftSince != nil {
+				total += transition.CreatedAt.Sub(*draftSince)
+				draftSince = nil
+			}
+		case "convert_to_draft":
+			if draftSince == nil {
+				t := transition.CreatedAt
+				draftSince = &t
+			}
+		}
+	}
+
+	// Still a draft: count up to when it was closed, or now
+	if draftSince != nil {
+		end := time.Now()
+		if pr.ClosedAt != nil {
+			end = *pr.ClosedAt
+		}
+		total += end.Sub(*draftSince)
+	}
+
+	return total.Hours()
+}
+
+// fetchPRCommits returns the author date of the earliest commit on the PR's branch and the
+// hashes of the PR's commits, as far as they could be read
+func (c Client) fetchPRCommits(ctx context.Context, number int) (first *time.Time, hashes []string) {
+	commitsURL := fmt.Sprintf("%s/repos/%s/%s/pulls/%d/commits?per_page=100",
+		c.getBaseURL(), c.config.GitHubOwner, c.config.GitHubRepo, number)
+	for commitsURL != "" {
+		body, next, err := c.makePagedRequest(ctx, commitsURL)
+		if err != nil {
+			return first, hashes
+		}
+
+		var commits []githubCommitsResponse
+		if err := json.Unmarshal(body, &commits); err != nil {
+			return first, hashes
+		}
+
+		for _, commit := range commits {
+			hashes = append(hashes, commit.Hash)
+			t := commit.Commit.Author.Date
+			if first == nil || t.Before(*first) {
+				first = &t
+			}
+		}
+
+		// GitHub lists at most 250 commits per PR
+		commitsURL = next
+	}
+	return first, hashes
+}
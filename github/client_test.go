@@ -0,0 +1,568 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"devops-metrics/config"
+)
+
+// newTestClient returns a client for a GitHub Enterprise server at srv, whose API lives under
+// /api/v3, analyzing the last 30 days
+func newTestClient(srv *httptest.Server, cfg config.Config) Client {
+	cfg.GitHubURL = srv.URL
+	cfg.GitHubOwner, cfg.GitHubRepo = "acme", "api"
+	if cfg.DaysToAnalyze == 0 {
+		cfg.DaysToAnalyze = 30
+	}
+	return NewClient(cfg).WithHTTPClient(srv.Client())
+}
+
+// commitPages serves one branch whose commits span pages of 100, linked by rel="next"
+func commitPages(pages int) http.HandlerFunc {
+	now := time.Now().Add(-time.Hour)
+	var page strings.Builder
+	page.WriteString("[")
+	for i := 0; i < 100; i++ {
+		if i > 0 {
+			page.WriteString(",")
+		}
+		fmt.Fprintf(&page, `{"sha":"%040d","author":{"login":"dev-%d"},"commit":{"author":{"date":%q,"name":"Dev","email":"dev@example.com"},"message":"fix: change %d"}}`,
+			i, i%10, now.Add(-time.Duration(i)*time.Minute).Format(time.RFC3339), i)
+	}
+	page.WriteString("]")
+	body := page.String()
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/branches") {
+			fmt.Fprint(w, `[{"name":"main"}]`)
+			return
+		}
+		n, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		if n == 0 {
+			n = 1
+		}
+		if n < pages {
+			q := r.URL.Query()
+			q.Set("page", strconv.Itoa(n+1))
+			w.Header().Set("Link", fmt.Sprintf(`<http://%s%s?%s>; rel="next"`, r.Host, r.URL.Path, q.Encode()))
+		}
+		fmt.Fprint(w, body)
+	}
+}
+
+// BenchmarkFetchCommits measures paging through and parsing 1,000 commits
+func BenchmarkFetchCommits(b *testing.B) {
+	srv := httptest.NewServer(commitPages(10))
+	defer srv.Close()
+	client := newTestClient(srv, config.Config{})
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		commits, err := client.FetchCommits(ctx)
+		if err != nil || len(commits) != 1000 {
+			b.Fatalf("got %d commits, err %v", len(commits), err)
+		}
+	}
+}
+
+func TestFetchReviewCommentCount(t *testing.T) {
+	tests := []struct {
+		name  string
+		pages []string // Response bodies in page order; "" answers 404
+		want  *int
+	}{
+		{"two pages", []string{`[{"id":1},{"id":2},{"id":3}]`, `[{"id":4},{"id":5}]`}, intPtr(5)},
+		{"no comments", []string{`[]`}, intPtr(0)},
+		{"failed page", []string{`[{"id":1}]`, ""}, nil},
+		{"malformed page", []string{`{"message":"oops"}`}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/api/v3/repos/acme/api/pulls/7/comments" {
+					t.Errorf("unexpected request %s", r.URL.Path)
+				}
+				n, _ := strconv.Atoi(r.URL.Query().Get("page"))
+				if n == 0 {
+					n = 1
+				}
+				if tt.pages[n-1] == "" {
+					http.NotFound(w, r)
+					return
+				}
+				if n < len(tt.pages) {
+					w.Header().Set("Link", fmt.Sprintf(`<http://%s%s?page=%d>; rel="next"`, r.Host, r.URL.Path, n+1))
+				}
+				fmt.Fprint(w, tt.pages[n-1])
+			}))
+			defer srv.Close()
+
+			got := newTestClient(srv, config.Config{}).fetchReviewCommentCount(context.Background(), 7)
+			switch {
+			case got == nil && tt.want == nil:
+			case got == nil || tt.want == nil || *got != *tt.want:
+				t.Errorf("fetchReviewCommentCount() = %s, want %s", formatCount(got), formatCount(tt.want))
+			}
+		})
+	}
+}
+
+func intPtr(n int) *int {
+	return &n
+}
+
+func formatCount(n *int) string {
+	if n == nil {
+		return "nil"
+	}
+	return strconv.Itoa(*n)
+}
+
+func TestAuthorizationHeader(t *testing.T) {
+	tests := []struct {
+		name   string
+		scheme string
+		token  string
+		want   string
+	}{
+		{"classic token", "", "ghp_abc", "token ghp_abc"},
+		{"fine-grained token", "", "github_pat_abc", "Bearer github_pat_abc"},
+		{"installation token", "", "ghs_abc", "Bearer ghs_abc"},
+		{"oauth token", "", "gho_abc", "Bearer gho_abc"},
+		{"explicit token scheme", "token", "github_pat_abc", "token github_pat_abc"},
+		{"explicit bearer scheme", "Bearer", "ghp_abc", "Bearer ghp_abc"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = r.Header.Get("Authorization")
+				fmt.Fprint(w, `{"name":"api"}`)
+			}))
+			defer srv.Close()
+
+			client := newTestClient(srv, config.Config{GitHubToken: tt.token, GitHubAuthScheme: tt.scheme})
+			if _, err := client.FetchRepoInfo(context.Background()); err != nil {
+				t.Fatalf("FetchRepoInfo() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Authorization = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCountWrittenReviews(t *testing.T) {
+	review := func(state, body string) githubReviewsResponse {
+		return githubReviewsResponse{State: state, Body: body}
+	}
+	tests := []struct {
+		name    string
+		reviews []githubReviewsResponse
+		want    int
+	}{
+		{"no reviews", nil, 0},
+		{"approvals without text", []githubReviewsResponse{review("APPROVED", ""), review("APPROVED", "  \n")}, 0},
+		{"written reviews", []githubReviewsResponse{review("CHANGES_REQUESTED", "Please split this"), review("APPROVED", ""), review("COMMENTED", "nit")}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := countWrittenReviews(tt.reviews); got != tt.want {
+				t.Errorf("countWrittenReviews() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFirstReview(t *testing.T) {
+	base := time.Date(2025, 1, 6, 9, 0, 0, 0, time.UTC)
+	review := func(login, state string, hours int) githubReviewsResponse {
+		r := githubReviewsResponse{State: state, SubmittedAt: base.Add(time.Duration(hours) * time.Hour)}
+		r.User.Login = login
+		return r
+	}
+	// Out of chronological order, as the API may return them
+	reviews := []githubReviewsResponse{
+		review("bob", "APPROVED", 30),
+		review("carol", "CHANGES_REQUESTED", 12),
+		review("alice", "COMMENTED", 1),
+		review("dave", "COMMENTED", 6),
+		review("erin", "DISMISSED", 2),
+		review("frank", "PENDING", 0),
+	}
+
+	tests := []struct {
+		name    string
+		states  []string
+		reviews []githubReviewsResponse
+		want    int // Hours after base of the first review; -1 for none
+	}{
+		{"default states include comments", nil, reviews, 6},
+		{"approvals and change requests only", []string{"APPROVED", "CHANGES_REQUESTED"}, reviews, 12},
+		{"states match case-insensitively", []string{"approved"}, reviews, 30},
+		{"only the author commented", nil, reviews[2:3], -1},
+		{"no reviews", nil, nil, -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewClient(config.Config{ReviewStatesCountingAsReview: tt.states})
+			got := -1
+			if first := c.firstReview(tt.reviews, "alice"); first != nil {
+				got = int(first.Sub(base).Hours())
+			}
+			if got != tt.want {
+				t.Errorf("firstReview() = base+%dh, want base+%dh", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFetchCommitsBadPage(t *testing.T) {
+	tests := []struct {
+		name string
+		bad  int // Commit page answered with a truncated body; 0 for none
+		want int
+	}{
+		{"all pages parse", 0, 400},
+		{"later page is truncated", 3, 200},
+		{"first page is truncated", 1, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pages := commitPages(4)
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if n, _ := strconv.Atoi(r.URL.Query().Get("page")); strings.HasSuffix(r.URL.Path, "/commits") && max(n, 1) == tt.bad {
+					fmt.Fprint(w, `[{"sha":"trunc`)
+					return
+				}
+				pages(w, r)
+			}))
+			defer srv.Close()
+
+			commits, err := newTestClient(srv, config.Config{}).FetchCommits(context.Background())
+			if err != nil {
+				t.Fatalf("FetchCommits() error = %v", err)
+			}
+			if len(commits) != tt.want {
+				t.Errorf("got %d commits, want %d", len(commits), tt.want)
+			}
+		})
+	}
+}
+
+func TestFetchPRsCanned(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+	ago := func(hours int) string { return now.Add(-time.Duration(hours) * time.Hour).Format(time.RFC3339) }
+	responses := map[string]string{
+		"/api/v3/repos/acme/api/pulls": `[
+			{"number":12,"state":"closed","title":"Add endpoint","user":{"login":"alice"},"created_at":"` + ago(48) + `","updated_at":"` + ago(10) + `",
+			 "merged_at":"` + ago(10) + `","closed_at":"` + ago(10) + `","additions":30,"deletions":5,"changed_files":3,
+			 "base":{"ref":"main"},"labels":[{"name":"feature"}],"merge_commit_sha":"m12"},
+			{"number":11,"state":"closed","title":"Spike","user":{"login":"bob"},"created_at":"` + ago(60) + `","updated_at":"` + ago(50) + `",
+			 "closed_at":"` + ago(50) + `","additions":1,"deletions":1,"changed_files":1,"base":{"ref":"develop"}},
+			{"number":10,"state":"open","title":"Empty","user":{"login":"carol"},"created_at":"` + ago(70) + `","updated_at":"` + ago(70) + `","changed_files":0,"base":{"ref":"main"}},
+			{"number":9,"state":"open","title":"Ancient","user":{"login":"carol"},"created_at":"` + ago(24*90) + `","updated_at":"` + ago(1) + `","changed_files":2,"base":{"ref":"main"}}
+		]`,
+		"/api/v3/repos/acme/api/pulls/12/reviews": `[
+			{"user":{"login":"alice"},"state":"COMMENTED","submitted_at":"` + ago(47) + `"},
+			{"user":{"login":"carol"},"state":"APPROVED","body":"ship it","submitted_at":"` + ago(20) + `"},
+			{"user":{"login":"bob"},"state":"CHANGES_REQUESTED","body":"needs tests","submitted_at":"` + ago(30) + `"}
+		]`,
+		"/api/v3/repos/acme/api/pulls/11/reviews": `[]`,
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := responses[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, body)
+	}))
+	defer srv.Close()
+
+	got, err := newTestClient(srv, config.Config{}).FetchPRs(context.Background())
+	if err != nil {
+		t.Fatalf("FetchPRs() error = %v", err)
+	}
+
+	when := func(t *time.Time) string {
+		if t == nil {
+			return "-"
+		}
+		return t.UTC().Format(time.RFC3339)
+	}
+	tests := []struct {
+		id   string
+		want string
+	}{
+		{"PR-12", "alice MERGED main merged=" + ago(10) + " closed=" + ago(10) + " review=" + ago(30) +
+			" lines=35 reviewers=[alice carol bob] approvers=[carol] reviews=2 labels=[feature] hashes=[m12] repo=acme/api"},
+		{"PR-11", "bob CLOSED develop merged=- closed=" + ago(50) + " review=-" +
+			" lines=2 reviewers=[] approvers=[] reviews=0 labels=[] hashes=[] repo=acme/api"},
+	}
+	if len(got) != len(tests) {
+		t.Fatalf("got %d PRs, want %d (PR-10 has no changed files, PR-9 is outside the window)", len(got), len(tests))
+	}
+	for i, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			pr := got[i]
+			if pr.ID != tt.id {
+				t.Fatalf("ID = %s, want %s", pr.ID, tt.id)
+			}
+			summary := fmt.Sprintf("%s %s %s merged=%s closed=%s review=%s lines=%d reviewers=%v approvers=%v reviews=%d labels=%v hashes=%v repo=%s",
+				pr.Author, pr.Status, pr.BaseBranch, when(pr.MergedAt), when(pr.ClosedAt), when(pr.FirstReviewAt),
+				pr.LinesChanged, pr.Reviewers, pr.Approvers, pr.CommentCount, pr.Labels, pr.CommitHashes, pr.Repo)
+			if summary != tt.want {
+				t.Errorf("PR =\n  %s\nwant\n  %s", summary, tt.want)
+			}
+		})
+	}
+}
+
+// scriptedClient answers successive requests with its responses in order
+type scriptedClient struct {
+	responses []*http.Response
+	requests  int
+}
+
+func (s *scriptedClient) Do(req *http.Request) (*http.Response, error) {
+	resp := s.responses[s.requests]
+	s.requests++
+	resp.Request = req
+	return resp, nil
+}
+
+// response returns a canned response with the given status, headers (name, value pairs) and body
+func response(status int, body string, header ...string) *http.Response {
+	h := http.Header{}
+	for i := 0; i+1 < len(header); i += 2 {
+		h.Set(header[i], header[i+1])
+	}
+	return &http.Response{StatusCode: status, Header: h, Body: io.NopCloser(strings.NewReader(body))}
+}
+
+func TestMakeRequestRetriesRateLimits(t *testing.T) {
+	tests := []struct {
+		name         string
+		maxRetries   int
+		responses    []*http.Response
+		wantErr      bool
+		wantRequests int
+	}{
+		{
+			name:         "secondary limit then success",
+			responses:    []*http.Response{response(403, `{"message":"secondary rate limit"}`, "Retry-After", "1"), response(200, `{"ok":true}`)},
+			wantRequests: 2,
+		},
+		{
+			name:         "primary limit then success",
+			responses:    []*http.Response{response(403, `{}`, "X-RateLimit-Remaining", "0", "X-RateLimit-Reset", strconv.FormatInt(time.Now().Unix(), 10)), response(200, `{"ok":true}`)},
+			wantRequests: 2,
+		},
+		{
+			name:         "forbidden without rate-limit headers is not retried",
+			responses:    []*http.Response{response(403, `{"message":"Resource not accessible"}`)},
+			wantErr:      true,
+			wantRequests: 1,
+		},
+		{
+			name:         "gives up after the configured retries",
+			maxRetries:   1,
+			responses:    []*http.Response{response(429, `{}`, "Retry-After", "1"), response(429, `{}`, "Retry-After", "1"), response(200, `{}`)},
+			wantErr:      true,
+			wantRequests: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stub := &scriptedClient{responses: tt.responses}
+			client := NewClient(config.Config{GitHubURL: "https://github.example.com", GitHubMaxRetries: tt.maxRetries}).WithHTTPClient(stub)
+
+			_, err := client.makeRequest(context.Background(), "https://github.example.com/api/v3/repos/acme/api")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("makeRequest() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if stub.requests != tt.wantRequests {
+				t.Errorf("made %d requests, want %d", stub.requests, tt.wantRequests)
+			}
+		})
+	}
+}
+
+func TestRateLimitBackoff(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name     string
+		header   []string
+		attempt  int
+		min, max time.Duration
+	}{
+		{"retry-after seconds", []string{"Retry-After", "30"}, 0, 30 * time.Second, 30 * time.Second},
+		{"reset time", []string{"X-RateLimit-Reset", strconv.FormatInt(now.Add(30*time.Second).Unix(), 10)}, 0, 29 * time.Second, 32 * time.Second},
+		{"reset in the past waits a second", []string{"X-RateLimit-Reset", strconv.FormatInt(now.Add(-time.Minute).Unix(), 10)}, 0, time.Second, time.Second},
+		{"capped", []string{"Retry-After", "3600"}, 0, time.Minute, time.Minute},
+		{"exponential without hints", nil, 3, 8 * time.Second, 8 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := response(403, "", tt.header...).Header
+			got := rateLimitBackoff(header, tt.attempt, time.Second, time.Minute)
+			if got < tt.min || got > tt.max {
+				t.Errorf("rateLimitBackoff() = %v, want between %v and %v", got, tt.min, tt.max)
+			}
+		})
+	}
+}
+
+func TestNextPageURL(t *testing.T) {
+	tests := []struct {
+		name string
+		link string
+		want string
+	}{
+		{"no header", "", ""},
+		{"next and last", `<https://api.github.com/repos/acme/api/commits?page=2>; rel="next", <https://api.github.com/repos/acme/api/commits?page=5>; rel="last"`,
+			"https://api.github.com/repos/acme/api/commits?page=2"},
+		{"last page has only prev and first", `<https://api.github.com/x?page=4>; rel="prev", <https://api.github.com/x?page=1>; rel="first"`, ""},
+		{"next listed last", `<https://api.github.com/x?page=1>; rel="first", <https://api.github.com/x?page=3>; rel="next"`, "https://api.github.com/x?page=3"},
+		{"malformed entry is skipped", `https://api.github.com/x?page=2; rel="next"`, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := nextPageURL(tt.link); got != tt.want {
+				t.Errorf("nextPageURL() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFetchCommitsFollowsLinkHeader(t *testing.T) {
+	for _, pages := range []int{1, 2, 3} {
+		t.Run(fmt.Sprintf("%d full pages", pages), func(t *testing.T) {
+			var requests atomic.Int32
+			serve := commitPages(pages)
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if strings.HasSuffix(r.URL.Path, "/commits") {
+					requests.Add(1)
+				}
+				serve(w, r)
+			}))
+			defer srv.Close()
+
+			commits, err := newTestClient(srv, config.Config{}).FetchCommits(context.Background())
+			if err != nil {
+				t.Fatalf("FetchCommits() error = %v", err)
+			}
+			if len(commits) != 100*pages {
+				t.Errorf("got %d commits, want %d", len(commits), 100*pages)
+			}
+			// Every page is full, so only the missing rel="next" can end the listing
+			if n := int(requests.Load()); n != pages {
+				t.Errorf("made %d commit requests, want %d", n, pages)
+			}
+		})
+	}
+}
+
+func TestFetchCommitsCap(t *testing.T) {
+	tests := []struct {
+		name         string
+		cfg          config.Config
+		want         int
+		wantRequests int
+		wantProgress string
+	}{
+		{"unlimited", config.Config{}, 400, 4, "[100 200 300 400]"},
+		{"max items", config.Config{MaxItems: 150}, 150, 2, "[100]"},
+		{"cap on a page boundary", config.Config{MaxItems: 200}, 200, 2, "[100]"},
+		{"max commits overrides max items", config.Config{MaxItems: 300, MaxCommits: 50}, 50, 1, "[]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests atomic.Int32
+			serve := commitPages(4)
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if strings.HasSuffix(r.URL.Path, "/commits") {
+					requests.Add(1)
+				}
+				serve(w, r)
+			}))
+			defer srv.Close()
+
+			var progress []int
+			c := newTestClient(srv, tt.cfg).WithProgress(func(fetched int) { progress = append(progress, fetched) })
+			commits, err := c.FetchCommits(context.Background())
+			if err != nil {
+				t.Fatalf("FetchCommits() error = %v", err)
+			}
+			if len(commits) != tt.want {
+				t.Errorf("got %d commits, want %d", len(commits), tt.want)
+			}
+			// The cap stops the listing rather than trimming a full fetch
+			if n := int(requests.Load()); n != tt.wantRequests {
+				t.Errorf("made %d commit requests, want %d", n, tt.wantRequests)
+			}
+			if fmt.Sprint(progress) != tt.wantProgress {
+				t.Errorf("progress = %v, want %s", progress, tt.wantProgress)
+			}
+		})
+	}
+}
+
+func TestFetchDraftHoursPages(t *testing.T) {
+	created := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	closed := created.Add(100 * time.Hour)
+	at := func(hours int) string { return created.Add(time.Duration(hours) * time.Hour).Format(time.RFC3339) }
+	// Opened as a draft, ready after 10h, back to draft at 20h and ready again at 25h; the
+	// last transitions are on the second page
+	pages := []string{
+		`[{"event":"labeled","created_at":"` + at(1) + `"},{"event":"ready_for_review","created_at":"` + at(10) + `"}]`,
+		`[{"event":"convert_to_draft","created_at":"` + at(20) + `"},{"event":"ready_for_review","created_at":"` + at(25) + `"}]`,
+	}
+
+	tests := []struct {
+		name string
+		bad  int // Timeline page answered with a server error; 0 for none
+		want float64
+	}{
+		{"every page is read", 0, 15},
+		{"failed page discards the total", 2, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n, _ := strconv.Atoi(r.URL.Query().Get("page"))
+				if n == 0 {
+					n = 1
+				}
+				if n == tt.bad {
+					http.Error(w, "boom", http.StatusBadRequest)
+					return
+				}
+				if n < len(pages) {
+					q := r.URL.Query()
+					q.Set("page", strconv.Itoa(n+1))
+					w.Header().Set("Link", fmt.Sprintf(`<http://%s%s?%s>; rel="next"`, r.Host, r.URL.Path, q.Encode()))
+				}
+				fmt.Fprint(w, pages[n-1])
+			}))
+			defer srv.Close()
+
+			pr := githubPRsResponse{Number: 7, CreatedAt: created, ClosedAt: &closed}
+			if got := newTestClient(srv, config.Config{}).fetchDraftHours(context.Background(), pr); got != tt.want {
+				t.Errorf("fetchDraftHours() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
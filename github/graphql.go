@@ -0,0 +1,253 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"devops-metrics/pathfilter"
+)
+
+// graphqlPageSize is the number of PRs per query page. Each PR carries up to 100 reviews,
+// which keeps a page well inside GitHub's node limit.
+const graphqlPageSize = 50
+
+// prsQuery lists a repository's PRs newest first with the fields the REST path needs one
+// extra request per PR for
+const prsQuery = `query($owner: String!, $name: String!, $first: Int!, $after: String) {
+  repository(owner: $owner, name: $name) {
+    pullRequests(first: $first, after: $after, orderBy: {field: CREATED_AT, direction: DESC}) {
+      pageInfo { hasNextPage endCursor }
+      nodes {
+        number
+        title
+        state
+        isDraft
+        createdAt
+        updatedAt
+        mergedAt
+        closedAt
+        additions
+        deletions
+        changedFiles
+        baseRefName
+        author { login }
+        mergedBy { login }
+        mergeCommit { oid }
+        labels(first: 50) { nodes { name } }
+        reviews(first: 100) {
+          nodes { author { login } state body submittedAt }
+        }
+      }
+    }
+  }
+}`
+
+type graphqlActor struct {
+	Login string `json:"login"`
+}
+
+type graphqlPR struct {
+	Number       int           `json:"number"`
+	Title        string        `json:"title"`
+	State        string        `json:"state"` // OPEN, CLOSED or MERGED
+	IsDraft      bool          `json:"isDraft"`
+	CreatedAt    time.Time     `json:"createdAt"`
+	UpdatedAt    time.Time     `json:"updatedAt"`
+	MergedAt     *time.Time    `json:"mergedAt"`
+	ClosedAt     *time.Time    `json:"closedAt"`
+	Additions    int           `json:"additions"`
+	Deletions    int           `json:"deletions"`
+	ChangedFiles int           `json:"changedFiles"`
+	BaseRefName  string        `json:"baseRefName"`
+	Author       *graphqlActor `json:"author"` // nil for deleted accounts
+	MergedBy     *graphqlActor `json:"mergedBy"`
+	MergeCommit  *struct {
+		OID string `json:"oid"`
+	} `json:"mergeCommit"`
+	Labels struct {
+		Nodes []struct {
+			Name string `json:"name"`
+		} `json:"nodes"`
+	} `json:"labels"`
+	Reviews struct {
+		Nodes []struct {
+			Author      *graphqlActor `json:"author"`
+			State       string        `json:"state"`
+			Body        string        `json:"body"`
+			SubmittedAt *time.Time    `json:"submittedAt"`
+		} `json:"nodes"`
+	} `json:"reviews"`
+}
+
+type graphqlPRsResponse struct {
+	Data struct {
+		Repository *struct {
+			PullRequests struct {
+				PageInfo struct {
+					HasNextPage bool   `json:"hasNextPage"`
+					EndCursor   string `json:"endCursor"`
+				} `json:"pageInfo"`
+				Nodes []graphqlPR `json:"nodes"`
+			} `json:"pullRequests"`
+		} `json:"repository"`
+	} `json:"data"`
+	Errors []struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// FetchPRsGraphQL retrieves pull requests created within the analysis window through the
+// GraphQL API. Reviews, line counts and the merging user come back with each page, so
+// only the lookups enabled by FetchPRCommits, FetchDraftTime and IgnoreFiles cost extra
+// requests.
+func (c Client) FetchPRsGraphQL(ctx context.Context) ([]PullRequest, error) {
+	prs := []PullRequest{}
+	since, until := c.config.Window()
+	ignore := pathfilter.New(c.config.IgnoreFiles)
+
+	var cursor *string
+	for page := 1; ; page++ {
+		response, err := c.queryPRs(ctx, cursor)
+		if err != nil {
+			if page == 1 {
+				return nil, fmt.Errorf("error fetching PRs: %w", err)
+			}
+			slog.Warn("Stopping pull requests", "provider", "github", "repo", c.repoName(), "page", page, "error", err)
+			break
+		}
+		if response.Data.Repository == nil {
+			return nil, fmt.Errorf("repository %s not found", c.repoName())
+		}
+		list := response.Data.Repository.PullRequests
+
+		reachedWindowStart := false
+		for _, node := range list.Nodes {
+			if node.CreatedAt.Before(since) {
+				reachedWindowStart = true
+				break
+			}
+			if !node.CreatedAt.Before(until) || node.ChangedFiles == 0 {
+				continue
+			}
+
+			pr, reviews, mergedBy := fromGraphQL(node)
+			prs = append(prs, c.assemblePullRequest(ctx, pr, reviews, mergedBy, ignore))
+			if max := c.config.PRCap(); max > 0 && len(prs) >= max {
+				c.truncated("PRs", max)
+				return prs, nil
+			}
+		}
+
+		c.reportProgress(len(prs))
+		if reachedWindowStart || !list.PageInfo.HasNextPage {
+			break
+		}
+		next := list.PageInfo.EndCursor
+		cursor = &next
+	}
+
+	return prs, nil
+}
+
+// queryPRs runs one page of prsQuery starting after cursor (nil for the first page)
+func (c Client) queryPRs(ctx context.Context, cursor *string) (graphqlPRsResponse, error) {
+	var response graphqlPRsResponse
+	payload, err := json.Marshal(map[string]any{
+		"query": prsQuery,
+		"variables": map[string]any{
+			"owner": c.config.GitHubOwner,
+			"name":  c.config.GitHubRepo,
+			"first": graphqlPageSize,
+			"after": cursor,
+		},
+	})
+	if err != nil {
+		return response, err
+	}
+
+	body, _, err := c.send(ctx, "POST", c.graphqlURL(), payload)
+	if err != nil {
+		return response, err
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return response, fmt.Errorf("error parsing GraphQL response: %w", err)
+	}
+
+	// GraphQL reports query errors with a 200 status; partial data is still usable
+	if len(response.Errors) > 0 {
+		messages := make([]string, len(response.Errors))
+		for i, e := range response.Errors {
+			messages[i] = e.Message
+		}
+		if response.Data.Repository == nil {
+			return response, fmt.Errorf("GraphQL query failed: %s", strings.Join(messages, "; "))
+		}
+		slog.Warn("GraphQL query returned partial data", "provider", "github", "repo", c.repoName(), "error", strings.Join(messages, "; "))
+	}
+	return response, nil
+}
+
+// fromGraphQL converts a GraphQL PR node to the REST shapes toPullRequest works from, and
+// returns the login of the user who merged it
+func fromGraphQL(node graphqlPR) (githubPRsResponse, []githubReviewsResponse, string) {
+	var pr githubPRsResponse
+	pr.Number = node.Number
+	pr.Title = node.Title
+	pr.State = strings.ToLower(node.State)
+	if pr.State == "merged" {
+		pr.State = "closed"
+	}
+	pr.Draft = node.IsDraft
+	pr.CreatedAt = node.CreatedAt
+	pr.UpdatedAt = node.UpdatedAt
+	pr.MergedAt = node.MergedAt
+	pr.ClosedAt = node.ClosedAt
+	pr.Additions = node.Additions
+	pr.Deletions = node.Deletions
+	pr.ChangedFiles = node.ChangedFiles
+	pr.Base.Ref = node.BaseRefName
+	if node.Author != nil {
+		pr.User.Login = node.Author.Login
+	}
+	if node.MergeCommit != nil {
+		pr.MergeCommitSHA = node.MergeCommit.OID
+	}
+	for _, label := range node.Labels.Nodes {
+		pr.Labels = append(pr.Labels, struct {
+			Name string `json:"name"`
+		}{label.Name})
+	}
+
+	reviews := make([]githubReviewsResponse, 0, len(node.Reviews.Nodes))
+	for _, r := range node.Reviews.Nodes {
+		var review githubReviewsResponse
+		if r.Author != nil {
+			review.User.Login = r.Author.Login
+		}
+		review.State = r.State
+		review.Body = r.Body
+		if r.SubmittedAt != nil {
+			review.SubmittedAt = *r.SubmittedAt
+		}
+		reviews = append(reviews, review)
+	}
+
+	var mergedBy string
+	if node.MergedBy != nil {
+		mergedBy = node.MergedBy.Login
+	}
+	return pr, reviews, mergedBy
+}
+
+// graphqlURL returns the GraphQL endpoint for GitHub.com or GitHub Enterprise Server
+func (c Client) graphqlURL() string {
+	if c.config.GitHubURL == "" || c.config.GitHubURL == "https://github.com" {
+		return "https://api.github.com/graphql"
+	}
+	return c.config.GitHubURL + "/api/graphql"
+}
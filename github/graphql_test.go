@@ -0,0 +1,146 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"devops-metrics/config"
+)
+
+func TestFetchPRsGraphQLCanned(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+	ago := func(hours int) string { return now.Add(-time.Duration(hours) * time.Hour).Format(time.RFC3339) }
+	// Pages by the cursor they follow; the same PRs as TestFetchPRsCanned
+	pages := map[string]string{
+		"": `{"data":{"repository":{"pullRequests":{"pageInfo":{"hasNextPage":true,"endCursor":"c1"},"nodes":[
+			{"number":12,"title":"Add endpoint","state":"MERGED","createdAt":"` + ago(48) + `","updatedAt":"` + ago(10) + `",
+			 "mergedAt":"` + ago(10) + `","closedAt":"` + ago(10) + `","additions":30,"deletions":5,"changedFiles":3,"baseRefName":"main",
+			 "author":{"login":"alice"},"mergedBy":{"login":"carol"},"mergeCommit":{"oid":"m12"},"labels":{"nodes":[{"name":"feature"}]},
+			 "reviews":{"nodes":[
+				{"author":{"login":"alice"},"state":"COMMENTED","body":"","submittedAt":"` + ago(47) + `"},
+				{"author":{"login":"carol"},"state":"APPROVED","body":"ship it","submittedAt":"` + ago(20) + `"},
+				{"author":{"login":"bob"},"state":"CHANGES_REQUESTED","body":"needs tests","submittedAt":"` + ago(30) + `"}]}},
+			{"number":11,"title":"Spike","state":"CLOSED","createdAt":"` + ago(60) + `","updatedAt":"` + ago(50) + `",
+			 "closedAt":"` + ago(50) + `","additions":1,"deletions":1,"changedFiles":1,"baseRefName":"develop",
+			 "author":{"login":"bob"},"labels":{"nodes":[]},"reviews":{"nodes":[]}}
+		]}}}}`,
+		"c1": `{"data":{"repository":{"pullRequests":{"pageInfo":{"hasNextPage":true,"endCursor":"c2"},"nodes":[
+			{"number":10,"title":"Empty","state":"OPEN","createdAt":"` + ago(70) + `","updatedAt":"` + ago(70) + `","changedFiles":0,"baseRefName":"main",
+			 "author":{"login":"carol"},"labels":{"nodes":[]},"reviews":{"nodes":[]}},
+			{"number":9,"title":"Ancient","state":"OPEN","createdAt":"` + ago(24*90) + `","updatedAt":"` + ago(1) + `","changedFiles":2,"baseRefName":"main",
+			 "author":null,"labels":{"nodes":[]},"reviews":{"nodes":[]}}
+		]}}}}`,
+	}
+	var cursors []string
+	var rest int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/graphql" || r.Method != http.MethodPost {
+			rest++
+			http.NotFound(w, r)
+			return
+		}
+		var request struct {
+			Query     string `json:"query"`
+			Variables struct {
+				Owner string  `json:"owner"`
+				Name  string  `json:"name"`
+				After *string `json:"after"`
+			} `json:"variables"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Errorf("decoding GraphQL request: %v", err)
+		}
+		if request.Variables.Owner != "acme" || request.Variables.Name != "api" {
+			t.Errorf("queried %s/%s, want acme/api", request.Variables.Owner, request.Variables.Name)
+		}
+		cursor := ""
+		if request.Variables.After != nil {
+			cursor = *request.Variables.After
+		}
+		cursors = append(cursors, cursor)
+		fmt.Fprint(w, pages[cursor])
+	}))
+	defer srv.Close()
+
+	got, err := newTestClient(srv, config.Config{GitHubUseGraphQL: true}).FetchPRs(context.Background())
+	if err != nil {
+		t.Fatalf("FetchPRs() error = %v", err)
+	}
+	// PR-9 predates the window, so the listing stops without asking for the page after c2
+	if fmt.Sprint(cursors) != "[ c1]" {
+		t.Errorf("requested cursors %q, want [\"\" \"c1\"]", cursors)
+	}
+	if rest != 0 {
+		t.Errorf("made %d REST requests, want none", rest)
+	}
+
+	when := func(t *time.Time) string {
+		if t == nil {
+			return "-"
+		}
+		return t.UTC().Format(time.RFC3339)
+	}
+	tests := []struct {
+		id   string
+		want string
+	}{
+		{"PR-12", "alice MERGED main merged=" + ago(10) + " closed=" + ago(10) + " review=" + ago(30) +
+			" lines=35 reviewers=[alice carol bob] approvers=[carol] by=carol reviews=2 labels=[feature] hashes=[m12] repo=acme/api"},
+		{"PR-11", "bob CLOSED develop merged=- closed=" + ago(50) + " review=-" +
+			" lines=2 reviewers=[] approvers=[] by= reviews=0 labels=[] hashes=[] repo=acme/api"},
+	}
+	if len(got) != len(tests) {
+		t.Fatalf("got %d PRs, want %d (PR-10 has no changed files, PR-9 is outside the window)", len(got), len(tests))
+	}
+	for i, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			pr := got[i]
+			if pr.ID != tt.id {
+				t.Fatalf("ID = %s, want %s", pr.ID, tt.id)
+			}
+			summary := fmt.Sprintf("%s %s %s merged=%s closed=%s review=%s lines=%d reviewers=%v approvers=%v by=%s reviews=%d labels=%v hashes=%v repo=%s",
+				pr.Author, pr.Status, pr.BaseBranch, when(pr.MergedAt), when(pr.ClosedAt), when(pr.FirstReviewAt),
+				pr.LinesChanged, pr.Reviewers, pr.Approvers, pr.MergedBy, pr.CommentCount, pr.Labels, pr.CommitHashes, pr.Repo)
+			if summary != tt.want {
+				t.Errorf("PR =\n  %s\nwant\n  %s", summary, tt.want)
+			}
+		})
+	}
+}
+
+func TestFetchPRsGraphQLErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		wantErr string // Substring of the error; "" for success
+	}{
+		{"repository not found", `{"data":{"repository":null},"errors":[{"type":"NOT_FOUND","message":"Could not resolve to a Repository"}]}`, "Could not resolve to a Repository"},
+		{"partial data", `{"data":{"repository":{"pullRequests":{"pageInfo":{"hasNextPage":false},"nodes":[]}}},"errors":[{"message":"label lookup timed out"}]}`, ""},
+		{"malformed body", `{"data":`, "error parsing GraphQL response"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, tt.body)
+			}))
+			defer srv.Close()
+
+			_, err := newTestClient(srv, config.Config{}).FetchPRsGraphQL(context.Background())
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("FetchPRsGraphQL() error = %v, want none", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("FetchPRsGraphQL() error = %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,179 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Search pacing and limits. The search API allows only 30 authenticated requests per
+// minute, far fewer than the core API, so pages are spaced out and rate limits retried.
+const (
+	searchPageSize    = 100
+	searchMaxResults  = 1000 // GitHub never returns more than this for one query
+	searchPageDelay   = 2 * time.Second
+	searchMaxRetries  = 3
+	searchMaxBackoff  = 60 * time.Second
+	searchBaseBackoff = 5 * time.Second
+)
+
+type githubSearchResponse struct {
+	TotalCount        int  `json:"total_count"`
+	IncompleteResults bool `json:"incomplete_results"`
+	Items             []struct {
+		Number        int    `json:"number"`
+		State         string `json:"state"`
+		RepositoryURL string `json:"repository_url"`
+		User          struct {
+			Login string `json:"login"`
+		} `json:"user"`
+		CreatedAt   time.Time  `json:"created_at"`
+		UpdatedAt   time.Time  `json:"updated_at"`
+		ClosedAt    *time.Time `json:"closed_at"`
+		Comments    int        `json:"comments"`
+		PullRequest *struct {
+			MergedAt *time.Time `json:"merged_at"`
+		} `json:"pull_request"`
+	} `json:"items"`
+}
+
+// FetchPRsBySearch retrieves pull requests across repositories with the issue search API,
+// e.g. "is:pr author:alice org:acme". The analysis window is added to the query. Search
+// results carry no review data, so review timing and reviewers are left empty.
+func (c Client) FetchPRsBySearch(ctx context.Context, query string) ([]PullRequest, error) {
+	prs := []PullRequest{}
+	since, until := c.config.Window()
+	// The search range is inclusive of whole days, so end on the window's last day
+	q := fmt.Sprintf("%s is:pr created:%s..%s", query,
+		since.Format("2006-01-02"), until.Add(-time.Nanosecond).Format("2006-01-02"))
+
+	searchURL := fmt.Sprintf("%s/search/issues?q=%s&sort=created&order=desc&per_page=%d",
+		c.getBaseURL(), url.QueryEscape(q), searchPageSize)
+	for page := 1; searchURL != ""; page++ {
+		if page > 1 {
+			if err := sleep(ctx, searchPageDelay); err != nil {
+				return nil, err
+			}
+		}
+
+		body, next, err := c.searchRequest(ctx, searchURL)
+		if err != nil {
+			if page == 1 {
+				return nil, fmt.Errorf("error searching PRs: %w", err)
+			}
+			slog.Warn("Stopping pull request search", "provider", "github", "page", page, "error", err)
+			break
+		}
+
+		var response githubSearchResponse
+		if err := json.Unmarshal(body, &response); err != nil {
+			if page == 1 {
+				return nil, fmt.Errorf("error parsing PR search results: %w", err)
+			}
+			slog.Warn("Stopping pull request search, page could not be parsed", "provider", "github", "page", page, "error", err)
+			break
+		}
+		if response.IncompleteResults {
+			slog.Warn("Search results are incomplete; some pull requests may be missing", "provider", "github")
+		}
+		if page == 1 && response.TotalCount > searchMaxResults {
+			slog.Warn("Pull request search matched more results than GitHub returns", "provider", "github", "count", response.TotalCount, "max", searchMaxResults)
+		}
+
+		for _, item := range response.Items {
+			if item.PullRequest == nil {
+				continue
+			}
+
+			status := "OPEN"
+			if item.PullRequest.MergedAt != nil {
+				status = "MERGED"
+			} else if item.State == "closed" {
+				status = "CLOSED"
+			}
+
+			repo := repoFromURL(item.RepositoryURL)
+			prs = append(prs, PullRequest{
+				ID:           fmt.Sprintf("%s#%d", repo, item.Number),
+				Author:       item.User.Login,
+				CreatedAt:    item.CreatedAt,
+				MergedAt:     item.PullRequest.MergedAt,
+				ClosedAt:     item.ClosedAt,
+				CommentCount: item.Comments,
+				UpdatedAt:    &item.UpdatedAt,
+				Repo:         repo,
+				Status:       status,
+			})
+			if max := c.config.PRCap(); max > 0 && len(prs) >= max {
+				c.truncated("PRs", max)
+				return prs, nil
+			}
+		}
+
+		c.reportProgress(len(prs))
+		searchURL = next
+	}
+
+	return prs, nil
+}
+
+// searchRequest performs a search API call and returns the next page URL from the Link
+// header, backing off when the secondary or search rate limit is hit. GitHub signals these
+// with 403 or 429 plus Retry-After or X-RateLimit-Reset.
+func (c Client) searchRequest(ctx context.Context, searchURL string) ([]byte, string, error) {
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, "GET", searchURL, nil)
+		if err != nil {
+			return nil, "", err
+		}
+		req.Header.Set("Authorization", c.authorization())
+		req.Header.Set("Accept", "application/vnd.github.v3+json")
+		req.Header.Set("User-Agent", "devops-metrics")
+
+		start := time.Now()
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			c.stats.RecordRequest("github", 0, time.Since(start), err)
+			return nil, "", err
+		}
+
+		body, readErr := io.ReadAll(resp.Body)
+		resp.Body.Close()
+
+		if c.config.IsSuccessStatus(resp.StatusCode) {
+			c.stats.RecordRequest("github", len(body), time.Since(start), readErr)
+			return body, nextPageURL(resp.Header.Get("Link")), readErr
+		}
+
+		if isRateLimited(resp) && attempt < searchMaxRetries {
+			c.stats.RecordRequest("github", len(body), time.Since(start), nil)
+			c.stats.RecordRetry("github")
+			if err := sleep(ctx, rateLimitBackoff(resp.Header, attempt, searchBaseBackoff, searchMaxBackoff)); err != nil {
+				return nil, "", err
+			}
+			continue
+		}
+
+		err = fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
+		c.stats.RecordRequest("github", len(body), time.Since(start), err)
+		return nil, "", err
+	}
+}
+
+// repoFromURL turns an API repository URL into owner/repo
+func repoFromURL(repositoryURL string) string {
+	u, err := url.Parse(repositoryURL)
+	if err != nil {
+		return repositoryURL
+	}
+	if _, repo, ok := strings.Cut(u.Path, "/repos/"); ok {
+		return repo
+	}
+	return u.Path
+}
@@ -0,0 +1,57 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/url"
+)
+
+type githubTeamMember struct {
+	Login string `json:"login"`
+}
+
+// FetchTeamMembers retrieves the logins of a team in the configured owner organization
+func (c Client) FetchTeamMembers(ctx context.Context, team string) ([]string, error) {
+	var members []string
+	membersURL := fmt.Sprintf("%s/orgs/%s/teams/%s/members?per_page=100",
+		c.getBaseURL(), c.config.GitHubOwner, url.PathEscape(team))
+	for membersURL != "" {
+		body, next, err := c.makePagedRequest(ctx, membersURL)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching members of team %s: %w", team, err)
+		}
+
+		var list []githubTeamMember
+		if err := json.Unmarshal(body, &list); err != nil {
+			return nil, fmt.Errorf("error parsing members of team %s: %w", team, err)
+		}
+		for _, m := range list {
+			members = append(members, m.Login)
+		}
+
+		membersURL = next
+	}
+	return members, nil
+}
+
+// FetchTeamMemberships maps each member of the given teams to their team, fetching every
+// team once. Members of several teams are assigned to the first team listed. Teams that
+// cannot be fetched are reported and skipped.
+func (c Client) FetchTeamMemberships(ctx context.Context, teams []string) map[string]string {
+	memberships := make(map[string]string)
+	for _, team := range teams {
+		members, err := c.FetchTeamMembers(ctx, team)
+		if err != nil {
+			slog.Warn("Skipping team", "provider", "github", "team", team, "error", err)
+			continue
+		}
+		for _, member := range members {
+			if _, ok := memberships[member]; !ok {
+				memberships[member] = team
+			}
+		}
+	}
+	return memberships
+}
@@ -12,17 +12,51 @@ type Commit struct {
 	Message      string    `json:"message"`
 	LinesAdded   int       `json:"lines_added"`
 	LinesDeleted int       `json:"lines_deleted"`
+	LinesIgnored int       `json:"lines_ignored,omitempty"`
+	Repo         string    `json:"repo,omitempty"`
 }
 
 // PullRequest represents a pull request
 type PullRequest struct {
-	ID            string     `json:"id"`
-	Author        string     `json:"author"`
-	CreatedAt     time.Time  `json:"created_at"`
-	MergedAt      *time.Time `json:"merged_at,omitempty"`
-	ClosedAt      *time.Time `json:"closed_at,omitempty"`
-	FirstReviewAt *time.Time `json:"first_review_at,omitempty"`
-	LinesChanged  int        `json:"lines_changed"`
-	Reviewers     []string   `json:"reviewers"`
-	Status        string     `json:"status"`
-}
\ No newline at end of file
+	ID             string     `json:"id"`
+	Author         string     `json:"author"`
+	CreatedAt      time.Time  `json:"created_at"`
+	MergedAt       *time.Time `json:"merged_at,omitempty"`
+	ClosedAt       *time.Time `json:"closed_at,omitempty"`
+	FirstReviewAt  *time.Time `json:"first_review_at,omitempty"`
+	LinesChanged   int        `json:"lines_changed"`
+	LinesIgnored   int        `json:"lines_ignored,omitempty"`
+	Reviewers      []string   `json:"reviewers"`
+	CommentCount   int        `json:"comment_count"`             // Reviews submitted with a written summary, not inline comments (see ReviewComments)
+	ReviewComments *int       `json:"review_comments,omitempty"` // Inline review comments on the diff; nil unless counted (with FETCH_REVIEW_COMMENTS)
+	ReviewCycles   int        `json:"review_cycles"`
+	Approvers      []string   `json:"approvers,omitempty"`
+	MergedBy       string     `json:"merged_by,omitempty"`
+	DraftHours     float64    `json:"draft_hours,omitempty"` // Time spent as a draft before/while review was possible
+	FirstCommitAt  *time.Time `json:"first_commit_at,omitempty"`
+	BaseBranch     string     `json:"base_branch,omitempty"`
+	Title          string     `json:"title,omitempty"`
+	Labels         []string   `json:"labels,omitempty"`
+	CommitHashes   []string   `json:"commit_hashes,omitempty"` // Commits on the PR branch (with FETCH_PR_COMMITS) and its merge commit
+	UpdatedAt      *time.Time `json:"updated_at,omitempty"`
+	Repo           string     `json:"repo,omitempty"`
+	Status         string     `json:"status"`
+}
+
+// RepoInfo holds repository metadata used to decide whether a repo is analyzed
+type RepoInfo struct {
+	Name     string `json:"name"`
+	Archived bool   `json:"archived"`
+	Fork     bool   `json:"fork"`
+}
+
+// Deployment represents a deployment recorded through the GitHub deployments API
+type Deployment struct {
+	ID          int       `json:"id"`
+	Environment string    `json:"environment"`
+	Ref         string    `json:"ref"`
+	SHA         string    `json:"sha"`
+	Creator     string    `json:"creator"`
+	CreatedAt   time.Time `json:"created_at"`
+	Repo        string    `json:"repo,omitempty"`
+}
@@ -0,0 +1,360 @@
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"devops-metrics/config"
+	"devops-metrics/fetchstats"
+	"devops-metrics/replay"
+)
+
+// Client handles GitLab API operations using direct HTTP calls
+type Client struct {
+	config     config.Config
+	stats      *fetchstats.Recorder
+	httpClient HTTPClient
+	progress   func(fetched int)
+}
+
+// HTTPClient sends API requests; *http.Client satisfies it, and tests can substitute a stub
+type HTTPClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// NewClient creates a new GitLab client
+func NewClient(config config.Config) Client {
+	return Client{
+		config:     config,
+		httpClient: replay.NewHTTPClient("gitlab", config),
+	}
+}
+
+// WithStats returns a copy of the client that records request statistics into r
+func (c Client) WithStats(r *fetchstats.Recorder) Client {
+	c.stats = r
+	return c
+}
+
+// WithProgress returns a copy of the client that calls fn with the running count of items
+// after each page of a list fetch
+func (c Client) WithProgress(fn func(fetched int)) Client {
+	c.progress = fn
+	return c
+}
+
+// reportProgress passes the running count of fetched items to the progress callback, if any
+func (c Client) reportProgress(fetched int) {
+	if c.progress != nil {
+		c.progress(fetched)
+	}
+}
+
+// WithHTTPClient returns a copy of the client that sends its requests through h
+func (c Client) WithHTTPClient(h HTTPClient) Client {
+	c.httpClient = h
+	return c
+}
+
+// GitLab API response structures
+type gitlabUser struct {
+	Username string `json:"username"`
+}
+
+type gitlabCommitsResponse struct {
+	ID         string    `json:"id"`
+	AuthorName string    `json:"author_name"`
+	AuthorMail string    `json:"author_email"`
+	AuthoredAt time.Time `json:"authored_date"`
+	Message    string    `json:"message"`
+	Stats      *struct {
+		Additions int `json:"additions"`
+		Deletions int `json:"deletions"`
+	} `json:"stats"`
+}
+
+type gitlabMergeRequestsResponse struct {
+	IID            int          `json:"iid"`
+	State          string       `json:"state"` // opened, closed, locked, merged
+	Author         gitlabUser   `json:"author"`
+	CreatedAt      time.Time    `json:"created_at"`
+	UpdatedAt      time.Time    `json:"updated_at"`
+	MergedAt       *time.Time   `json:"merged_at"`
+	ClosedAt       *time.Time   `json:"closed_at"`
+	MergedBy       *gitlabUser  `json:"merged_by"`
+	Reviewers      []gitlabUser `json:"reviewers"`
+	UserNotesCount int          `json:"user_notes_count"`
+	TargetBranch   string       `json:"target_branch"`
+	Title          string       `json:"title"`
+	Labels         []string     `json:"labels"`
+	SHA            string       `json:"sha"`
+	MergeCommitSHA string       `json:"merge_commit_sha"`
+	SquashSHA      string       `json:"squash_commit_sha"`
+}
+
+type gitlabApprovalsResponse struct {
+	ApprovedBy []struct {
+		User gitlabUser `json:"user"`
+	} `json:"approved_by"`
+}
+
+// makeRequest makes an authenticated GET request, backing off on 429 responses
+func (c Client) makeRequest(ctx context.Context, url string) ([]byte, error) {
+	const maxRetries = 5
+	const baseDelay = 1 * time.Second
+
+	for attempt := 0; attempt <= maxRetries; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("PRIVATE-TOKEN", c.config.GitLabToken)
+
+		start := time.Now()
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			c.stats.RecordRequest("gitlab", 0, time.Since(start), err)
+			return nil, err
+		}
+
+		if resp.StatusCode == http.StatusTooManyRequests && attempt < maxRetries {
+			resp.Body.Close()
+			c.stats.RecordRequest("gitlab", 0, time.Since(start), nil)
+			c.stats.RecordRetry("gitlab")
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(time.Duration(baseDelay.Nanoseconds() * (1 << attempt))):
+			}
+			continue
+		}
+
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err == nil && !c.config.IsSuccessStatus(resp.StatusCode) {
+			err = fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
+		}
+		c.stats.RecordRequest("gitlab", len(body), time.Since(start), err)
+		if err != nil {
+			return nil, err
+		}
+		return body, nil
+	}
+
+	return nil, fmt.Errorf("API request failed after %d attempts", maxRetries+1)
+}
+
+// FetchCommits retrieves commits on all branches since the analysis window start,
+// with line counts from the commit stats
+func (c Client) FetchCommits(ctx context.Context) ([]Commit, error) {
+	commits := []Commit{}
+	since, until := c.config.Window()
+
+	for page := 1; ; page++ {
+		commitsURL := fmt.Sprintf("%s/repository/commits?all=true&with_stats=true&since=%s&until=%s&per_page=100&page=%d",
+			c.projectURL(), url.QueryEscape(since.Format(time.RFC3339)), url.QueryEscape(until.Format(time.RFC3339)), page)
+
+		body, err := c.makeRequest(ctx, commitsURL)
+		if err != nil {
+			if page == 1 {
+				return nil, fmt.Errorf("error fetching commits: %w", err)
+			}
+			slog.Warn("Stopping commits", "provider", "gitlab", "repo", c.repoName(), "page", page, "error", err)
+			break
+		}
+
+		var commitList []gitlabCommitsResponse
+		if err := json.Unmarshal(body, &commitList); err != nil {
+			if page == 1 {
+				return nil, fmt.Errorf("error parsing commits: %w", err)
+			}
+			slog.Warn("Stopping commits, page could not be parsed", "provider", "gitlab", "repo", c.repoName(), "page", page, "error", err)
+			break
+		}
+
+		for _, commit := range commitList {
+			commits = append(commits, c.toCommit(commit))
+			if max := c.config.CommitCap(); max > 0 && len(commits) >= max {
+				c.truncated("commits", max)
+				return commits, nil
+			}
+		}
+
+		c.reportProgress(len(commits))
+		if len(commitList) < 100 {
+			break
+		}
+	}
+
+	return commits, nil
+}
+
+// toCommit converts an API commit, falling back to the email or unknown-author bucket
+// when the author name is empty
+func (c Client) toCommit(commit gitlabCommitsResponse) Commit {
+	author := commit.AuthorName
+	if author == "" {
+		author = c.config.AuthorOrFallback("", commit.AuthorMail)
+	}
+
+	result := Commit{
+		Hash:    commit.ID,
+		Author:  author,
+		Date:    commit.AuthoredAt,
+		Message: commit.Message,
+		Repo:    c.repoName(),
+	}
+	if commit.Stats != nil {
+		result.LinesAdded = commit.Stats.Additions
+		result.LinesDeleted = commit.Stats.Deletions
+	}
+	return result
+}
+
+// FetchPRs retrieves merge requests created within the analysis window
+func (c Client) FetchPRs(ctx context.Context) ([]PullRequest, error) {
+	prs := []PullRequest{}
+	since, until := c.config.Window()
+
+	for page := 1; ; page++ {
+		mrsURL := fmt.Sprintf("%s/merge_requests?state=all&scope=all&created_after=%s&created_before=%s&order_by=created_at&sort=desc&per_page=100&page=%d",
+			c.projectURL(), url.QueryEscape(since.Format(time.RFC3339)), url.QueryEscape(until.Format(time.RFC3339)), page)
+
+		body, err := c.makeRequest(ctx, mrsURL)
+		if err != nil {
+			if page == 1 {
+				return nil, fmt.Errorf("error fetching merge requests: %w", err)
+			}
+			slog.Warn("Stopping merge requests", "provider", "gitlab", "repo", c.repoName(), "page", page, "error", err)
+			break
+		}
+
+		var mrList []gitlabMergeRequestsResponse
+		if err := json.Unmarshal(body, &mrList); err != nil {
+			if page == 1 {
+				return nil, fmt.Errorf("error parsing merge requests: %w", err)
+			}
+			slog.Warn("Stopping merge requests, page could not be parsed", "provider", "gitlab", "repo", c.repoName(), "page", page, "error", err)
+			break
+		}
+
+		for _, mr := range mrList {
+			prs = append(prs, c.toPullRequest(ctx, mr))
+			if max := c.config.PRCap(); max > 0 && len(prs) >= max {
+				c.truncated("PRs", max)
+				return prs, nil
+			}
+		}
+
+		c.reportProgress(len(prs))
+		if len(mrList) < 100 {
+			break
+		}
+	}
+
+	return prs, nil
+}
+
+// toPullRequest converts a merge request to the shared pull request shape. Approvers come
+// from the approvals endpoint (an extra API call per merge request).
+func (c Client) toPullRequest(ctx context.Context, mr gitlabMergeRequestsResponse) PullRequest {
+	status := "OPEN"
+	switch mr.State {
+	case "merged":
+		status = "MERGED"
+	case "closed":
+		status = "CLOSED"
+	}
+
+	var reviewers []string
+	for _, reviewer := range mr.Reviewers {
+		reviewers = append(reviewers, reviewer.Username)
+	}
+
+	var mergedBy string
+	if mr.MergedBy != nil {
+		mergedBy = mr.MergedBy.Username
+	}
+
+	var commitHashes []string
+	for _, sha := range []string{mr.SHA, mr.MergeCommitSHA, mr.SquashSHA} {
+		if sha != "" {
+			commitHashes = append(commitHashes, sha)
+		}
+	}
+
+	closedAt := mr.ClosedAt
+	if status != "CLOSED" {
+		closedAt = nil
+	}
+
+	return PullRequest{
+		ID:           fmt.Sprintf("!%d", mr.IID),
+		Author:       mr.Author.Username,
+		CreatedAt:    mr.CreatedAt,
+		MergedAt:     mr.MergedAt,
+		ClosedAt:     closedAt,
+		Reviewers:    reviewers,
+		CommentCount: mr.UserNotesCount,
+		Approvers:    c.fetchApprovers(ctx, mr.IID),
+		MergedBy:     mergedBy,
+		BaseBranch:   mr.TargetBranch,
+		Title:        mr.Title,
+		Labels:       mr.Labels,
+		CommitHashes: commitHashes,
+		UpdatedAt:    &mr.UpdatedAt,
+		Repo:         c.repoName(),
+		Status:       status,
+	}
+}
+
+// fetchApprovers returns who approved a merge request, or nil if it cannot be determined
+func (c Client) fetchApprovers(ctx context.Context, iid int) []string {
+	body, err := c.makeRequest(ctx, fmt.Sprintf("%s/merge_requests/%d/approvals", c.projectURL(), iid))
+	if err != nil {
+		return nil
+	}
+
+	var approvals gitlabApprovalsResponse
+	if err := json.Unmarshal(body, &approvals); err != nil {
+		return nil
+	}
+
+	var approvers []string
+	for _, a := range approvals.ApprovedBy {
+		approvers = append(approvers, a.User.Username)
+	}
+	return approvers
+}
+
+// projectURL returns the API base URL of the configured project. The project may be
+// configured by numeric ID or by its full path (group/project).
+func (c Client) projectURL() string {
+	return fmt.Sprintf("%s/api/v4/projects/%s", c.getBaseURL(), url.PathEscape(c.config.GitLabProjectID))
+}
+
+// getBaseURL returns the GitLab instance URL, defaulting to gitlab.com
+func (c Client) getBaseURL() string {
+	if c.config.GitLabURL == "" {
+		return "https://gitlab.com"
+	}
+	return strings.TrimSuffix(c.config.GitLabURL, "/")
+}
+
+// truncated reports that fetching kind stopped at the configured cap, so results are incomplete
+func (c Client) truncated(kind string, max int) {
+	slog.Warn("Stopped fetching at the configured cap; results are truncated", "provider", "gitlab", "repo", c.repoName(), "kind", kind, "count", max)
+	c.stats.RecordTruncated("gitlab", kind)
+}
+
+// repoName returns the project identifier used to tag fetched data
+func (c Client) repoName() string {
+	return c.config.GitLabProjectID
+}
@@ -0,0 +1,126 @@
+package gitlocal
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"devops-metrics/pathfilter"
+)
+
+// Client reads commit history directly from a local git clone using `git log --numstat`
+type Client struct {
+	dir    string
+	ignore pathfilter.Matcher
+}
+
+const (
+	recordSep = "\x1e"
+	fieldSep  = "\x1f"
+)
+
+// NewClient creates a new local git client for the clone at dir. Line changes in files
+// matched by ignore are reported as ignored rather than added/deleted.
+func NewClient(dir string, ignore pathfilter.Matcher) Client {
+	return Client{
+		dir:    dir,
+		ignore: ignore,
+	}
+}
+
+// FetchCommits retrieves commits from all refs of the local clone made between since and until.
+// Cancelling ctx kills the git process.
+func (c Client) FetchCommits(ctx context.Context, since, until time.Time) ([]Commit, error) {
+	cmd := exec.CommandContext(ctx, "git", "-C", c.dir, "log", "--all",
+		"--since="+since.Format(time.RFC3339),
+		"--until="+until.Format(time.RFC3339),
+		"--numstat",
+		"--format="+recordSep+"%H"+fieldSep+"%an"+fieldSep+"%aI"+fieldSep+"%s",
+	)
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("error running git log in %s: %w: %s", c.dir, err, strings.TrimSpace(stderr.String()))
+	}
+
+	return parseLog(out, c.ignore)
+}
+
+// parseLog parses `git log --numstat` output produced with the record/field separator format
+func parseLog(out []byte, ignore pathfilter.Matcher) ([]Commit, error) {
+	var commits []Commit
+
+	for _, record := range strings.Split(string(out), recordSep) {
+		if strings.TrimSpace(record) == "" {
+			continue
+		}
+
+		scanner := bufio.NewScanner(strings.NewReader(record))
+		scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+		if !scanner.Scan() {
+			continue
+		}
+
+		fields := strings.Split(scanner.Text(), fieldSep)
+		if len(fields) < 4 {
+			return nil, fmt.Errorf("unexpected git log header: %q", scanner.Text())
+		}
+
+		date, err := time.Parse(time.RFC3339, fields[2])
+		if err != nil {
+			return nil, fmt.Errorf("error parsing commit date %q: %w", fields[2], err)
+		}
+
+		commit := Commit{
+			Hash:    fields[0],
+			Author:  fields[1],
+			Date:    date,
+			Message: fields[3],
+		}
+
+		// Remaining lines are "<added>\t<deleted>\t<path>"; binary files report "-"
+		for scanner.Scan() {
+			parts := strings.SplitN(scanner.Text(), "\t", 3)
+			if len(parts) < 3 {
+				continue
+			}
+			added, _ := strconv.Atoi(parts[0])
+			deleted, _ := strconv.Atoi(parts[1])
+			if ignore.Match(numstatPath(parts[2])) {
+				commit.LinesIgnored += added + deleted
+				continue
+			}
+			commit.LinesAdded += added
+			commit.LinesDeleted += deleted
+		}
+
+		commits = append(commits, commit)
+	}
+
+	return commits, nil
+}
+
+// numstatPath returns the path a numstat line refers to after the commit. Renames are shown
+// as "old => new", or with the changed part in braces, as in "src/{a => b}/main.go".
+func numstatPath(path string) string {
+	start := strings.Index(path, "{")
+	end := strings.LastIndex(path, "}")
+	if start >= 0 && end > start {
+		if _, renamed, ok := strings.Cut(path[start+1:end], " => "); ok {
+			// An empty side drops a directory level, leaving a doubled separator behind
+			resolved := path[:start] + renamed + path[end+1:]
+			return strings.ReplaceAll(resolved, "//", "/")
+		}
+	}
+	if _, renamed, ok := strings.Cut(path, " => "); ok {
+		return renamed
+	}
+	return path
+}
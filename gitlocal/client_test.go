@@ -0,0 +1,73 @@
+package gitlocal
+
+import (
+	"context"
+	"os/exec"
+	"testing"
+	"time"
+
+	"devops-metrics/pathfilter"
+)
+
+func TestNumstatPath(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"api/handler.go", "api/handler.go"},
+		{"old.go => new.go", "new.go"},
+		{"docs/README.md => README.md", "README.md"},
+		{"src/{a => b}/main.go", "src/b/main.go"},
+		{"{vendor => third_party}/lib.go", "third_party/lib.go"},
+		{"api/{handler.go => handlers.go}", "api/handlers.go"},
+		{"src/{ => generated}/types.go", "src/generated/types.go"},
+		{"src/{generated => }/types.go", "src/types.go"},
+		{"weird{name}.go", "weird{name}.go"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := numstatPath(tt.path); got != tt.want {
+				t.Errorf("numstatPath(%q) = %q, want %q", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseLogRenames(t *testing.T) {
+	out := recordSep + "abc" + fieldSep + "alice" + fieldSep + "2026-03-02T09:00:00Z" + fieldSep + "Move code\n\n" +
+		"10\t2\t{app => vendor}/lib.go\n" +
+		"3\t1\tvendor/x.go => src/x.go\n" +
+		"4\t0\tREADME.md\n"
+
+	commits, err := parseLog([]byte(out), pathfilter.New([]string{"vendor/**"}))
+	if err != nil {
+		t.Fatalf("parseLog() error = %v", err)
+	}
+	if len(commits) != 1 {
+		t.Fatalf("got %d commits, want 1", len(commits))
+	}
+	// Renames are matched by their new path: into vendor is ignored, out of it counts
+	c := commits[0]
+	if c.LinesAdded != 7 || c.LinesDeleted != 1 || c.LinesIgnored != 12 {
+		t.Errorf("added, deleted, ignored = %d, %d, %d; want 7, 1, 12", c.LinesAdded, c.LinesDeleted, c.LinesIgnored)
+	}
+}
+
+func TestFetchCommitsCancelled(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	// The repository this package lives in
+	client := NewClient("..", pathfilter.New(nil))
+	until := time.Now()
+	since := until.AddDate(0, 0, -1)
+
+	if _, err := client.FetchCommits(context.Background(), since, until); err != nil {
+		t.Skipf("not in a git clone: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := client.FetchCommits(ctx, since, until); err == nil {
+		t.Error("FetchCommits with a cancelled context succeeded, want an error")
+	}
+}
@@ -0,0 +1,16 @@
+package gitlocal
+
+import "time"
+
+// types.go - Data structures for local git clone integration
+
+// Commit represents a git commit read from a local clone
+type Commit struct {
+	Hash         string    `json:"hash"`
+	Author       string    `json:"author"`
+	Date         time.Time `json:"date"`
+	Message      string    `json:"message"`
+	LinesAdded   int       `json:"lines_added"`
+	LinesDeleted int       `json:"lines_deleted"`
+	LinesIgnored int       `json:"lines_ignored,omitempty"`
+}
@@ -1,41 +1,43 @@
 package jira
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
 	"strings"
 	"time"
+
 	"devops-metrics/config"
+	"devops-metrics/fetchstats"
+	"devops-metrics/replay"
 )
 
 // Client handles Jira API operations
 type Client struct {
-	config config.Config
+	config     config.Config
+	stats      *fetchstats.Recorder
+	httpClient HTTPClient
+	progress   func(fetched int)
+}
+
+// HTTPClient sends API requests; *http.Client satisfies it, and tests can substitute a stub
+type HTTPClient interface {
+	Do(req *http.Request) (*http.Response, error)
 }
 
 // Jira API response structures
 type jiraIssuesResponse struct {
 	Issues []struct {
-		Key       string `json:"key"`
-		Expand    string `json:"expand"`
-		Fields    struct {
-			Summary        string `json:"summary"`
-			Status         struct {
-				Name string `json:"name"`
-			} `json:"status"`
-			Assignee *struct {
-				DisplayName string `json:"displayName"`
-				Name        string `json:"name"`
-			} `json:"assignee"`
-			Created        string  `json:"created"`
-			Updated        string  `json:"updated"`
-			Resolutiondate *string `json:"resolutiondate"`
-			StoryPoints    float64 `json:"customfield_10016"` // Common story points field
-			TimeEstimate   int     `json:"timeestimate"`
-			TimeSpent      int     `json:"timespent"`
-		} `json:"fields"`
+		Key       string          `json:"key"`
+		Expand    string          `json:"expand"`
+		Fields    jiraIssueFields `json:"fields"`
 		Changelog *struct {
 			Histories []struct {
 				Created string `json:"created"`
@@ -50,88 +52,309 @@ type jiraIssuesResponse struct {
 	Total int `json:"total"`
 }
 
+// jiraIssueFields holds the issue fields read by name. Custom fields such as story points
+// have instance-specific keys, so the raw fields are kept for lookup by configured key.
+type jiraIssueFields struct {
+	Summary string `json:"summary"`
+	Status  struct {
+		Name string `json:"name"`
+	} `json:"status"`
+	Assignee *struct {
+		DisplayName string `json:"displayName"`
+		Name        string `json:"name"`
+	} `json:"assignee"`
+	Created        string  `json:"created"`
+	Updated        string  `json:"updated"`
+	Resolutiondate *string `json:"resolutiondate"`
+	TimeEstimate   int     `json:"timeestimate"`
+	TimeSpent      int     `json:"timespent"`
+	Components     []struct {
+		Name string `json:"name"`
+	} `json:"components"`
+	Labels    []string `json:"labels"`
+	IssueType struct {
+		Name    string `json:"name"`
+		Subtask bool   `json:"subtask"`
+	} `json:"issuetype"`
+	Parent *struct {
+		Key string `json:"key"`
+	} `json:"parent"`
+
+	raw map[string]json.RawMessage
+}
+
+// UnmarshalJSON decodes the named fields and keeps every field's raw value
+func (f *jiraIssueFields) UnmarshalJSON(data []byte) error {
+	type named jiraIssueFields
+	if err := json.Unmarshal(data, (*named)(f)); err != nil {
+		return err
+	}
+	return json.Unmarshal(data, &f.raw)
+}
+
+// number returns the numeric value of the field with the given key, or 0 when the field is
+// missing, null or not a number
+func (f jiraIssueFields) number(key string) float64 {
+	var value float64
+	if raw, ok := f.raw[key]; ok {
+		if err := json.Unmarshal(raw, &value); err != nil {
+			return 0
+		}
+	}
+	return value
+}
+
 // NewClient creates a new Jira client
 func NewClient(config config.Config) Client {
 	return Client{
-		config: config,
+		config:     config,
+		httpClient: replay.NewHTTPClient("jira", config),
 	}
 }
 
-// makeRequest makes an HTTP request with proper authentication
-func (c Client) makeRequest(url, method, username, token string) ([]byte, error) {
-	req, err := http.NewRequest(method, url, nil)
-	if err != nil {
-		return nil, err
-	}
+// WithStats returns a copy of the client that records request statistics into r
+func (c Client) WithStats(r *fetchstats.Recorder) Client {
+	c.stats = r
+	return c
+}
 
-	if username != "" {
-		req.SetBasicAuth(username, token)
-	} else {
-		req.Header.Set("Authorization", "Bearer "+token)
+// WithProgress returns a copy of the client that calls fn with the running count of items
+// after each page of a list fetch
+func (c Client) WithProgress(fn func(fetched int)) Client {
+	c.progress = fn
+	return c
+}
+
+// reportProgress passes the running count of fetched items to the progress callback, if any
+func (c Client) reportProgress(fetched int) {
+	if c.progress != nil {
+		c.progress(fetched)
 	}
+}
+
+// WithHTTPClient returns a copy of the client that sends its requests through h
+func (c Client) WithHTTPClient(h HTTPClient) Client {
+	c.httpClient = h
+	return c
+}
+
+// makeRequest makes an HTTP request with proper authentication, retrying 429 and 5xx
+// responses with exponential backoff and jitter (or the server's Retry-After, when given)
+func (c Client) makeRequest(ctx context.Context, url, method, username, token string) ([]byte, error) {
+	const maxRetries = 5
+	const baseDelay = 1 * time.Second
+
+	for attempt := 0; attempt <= maxRetries; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, method, url, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		if username != "" {
+			req.SetBasicAuth(username, token)
+		} else {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+
+		start := time.Now()
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			c.stats.RecordRequest("jira", 0, time.Since(start), err)
+			return nil, err
+		}
+		body, readErr := io.ReadAll(resp.Body)
+		resp.Body.Close()
+
+		if c.config.IsSuccessStatus(resp.StatusCode) {
+			c.stats.RecordRequest("jira", len(body), time.Since(start), readErr)
+			return body, readErr
+		}
 
-	client := &http.Client{Timeout: 30 * time.Second}
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, err
+		apiErr := &APIError{StatusCode: resp.StatusCode, Body: string(body)}
+		if apiErr.Temporary() && attempt < maxRetries {
+			c.stats.RecordRequest("jira", len(body), time.Since(start), nil)
+			c.stats.RecordRetry("jira")
+			delay := time.Duration(baseDelay.Nanoseconds() * (1 << attempt))
+			if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+				delay = time.Duration(seconds) * time.Second
+			} else {
+				// Add jitter (up to 50%)
+				delay += time.Duration(time.Now().UnixNano()%int64(time.Second/2)) % (delay / 2)
+			}
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(delay):
+			}
+			continue
+		}
+
+		c.stats.RecordRequest("jira", len(body), time.Since(start), apiErr)
+		return nil, apiErr
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
+	return nil, fmt.Errorf("API request failed after %d attempts", maxRetries+1)
+}
+
+// CheckProject verifies that the configured project exists and is visible, returning a
+// NotFoundError otherwise
+func (c Client) CheckProject(ctx context.Context) error {
+	url := fmt.Sprintf("%s/rest/api/%s/project/%s", c.config.JiraURL, c.apiVersion(), c.config.JiraProject)
+	if _, err := c.makeRequest(ctx, url, "GET", c.config.JiraUsername, c.config.JiraToken); err != nil {
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+			return &NotFoundError{Project: c.config.JiraProject}
+		}
+		return fmt.Errorf("error checking Jira project: %w", err)
 	}
+	return nil
+}
 
-	return io.ReadAll(resp.Body)
+// apiVersion returns the REST API version for the configured deployment type
+func (c Client) apiVersion() string {
+	if c.config.IsJiraCloud {
+		return "3"
+	}
+	return "2"
 }
 
 // FetchIssues retrieves issues from Jira
-func (c Client) FetchIssues() ([]JiraStory, error) {
-	var stories []JiraStory
+func (c Client) FetchIssues(ctx context.Context) ([]JiraStory, error) {
+	// Make sure the project exists so an empty result means "no issues", not "misconfigured".
+	// A custom JQL may span several projects or none, so it is left to the search to reject.
+	if strings.TrimSpace(c.config.JiraJQL) == "" {
+		if err := c.CheckProject(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	return c.searchIssues(ctx, c.issuesJQL())
+}
+
+// jqlOrderBy matches a trailing ORDER BY clause; jqlCreatedClause matches a condition on the
+// created date
+var (
+	jqlOrderBy       = regexp.MustCompile(`(?i)\s*\border\s+by\b.*$`)
+	jqlCreatedClause = regexp.MustCompile(`(?i)\bcreated\s*(>=|<=|>|<|=|!=|\bin\b)`)
+)
+
+// issuesJQL returns the query for issues in the analysis window: the configured JiraJQL, or
+// the project when none is set, restricted to issues created in the window unless the
+// configured query already constrains the created date. With JiraSprint set, the query is
+// restricted to that sprint instead of the window.
+func (c Client) issuesJQL() string {
+	if sprint := strings.TrimSpace(c.config.JiraSprint); sprint != "" {
+		return c.sprintJQL(sprint)
+	}
+
+	since, until := c.config.Window()
+	// JQL dates mean midnight, so bound by the day after the window's last day
+	before := until.Add(-time.Nanosecond).AddDate(0, 0, 1)
+	window := fmt.Sprintf("created >= %s AND created < %s", since.Format("2006-01-02"), before.Format("2006-01-02"))
+
+	base := strings.TrimSpace(c.config.JiraJQL)
+	if base == "" {
+		return fmt.Sprintf("project = %s AND %s ORDER BY created DESC", c.config.JiraProject, window)
+	}
+
+	orderBy := strings.TrimSpace(jqlOrderBy.FindString(base))
+	if orderBy == "" {
+		orderBy = "ORDER BY created DESC"
+	}
+	base = strings.TrimSpace(jqlOrderBy.ReplaceAllString(base, ""))
+	if !jqlCreatedClause.MatchString(base) {
+		base = fmt.Sprintf("(%s) AND %s", base, window)
+	}
+	return base + " " + orderBy
+}
+
+// sprintJQL returns the query for the issues of a sprint, given by ID or name, within the
+// configured JiraJQL or project
+func (c Client) sprintJQL(sprint string) string {
+	clause := "sprint = " + sprint
+	if _, err := strconv.Atoi(sprint); err != nil {
+		clause = fmt.Sprintf("sprint = %q", sprint)
+	}
+
+	base := strings.TrimSpace(c.config.JiraJQL)
+	if base == "" {
+		return fmt.Sprintf("project = %s AND %s ORDER BY created DESC", c.config.JiraProject, clause)
+	}
+	orderBy := strings.TrimSpace(jqlOrderBy.FindString(base))
+	if orderBy == "" {
+		orderBy = "ORDER BY created DESC"
+	}
+	base = strings.TrimSpace(jqlOrderBy.ReplaceAllString(base, ""))
+	return fmt.Sprintf("(%s) AND %s %s", base, clause, orderBy)
+}
+
+// FetchIssuesByKey retrieves specific issues regardless of project or analysis window
+func (c Client) FetchIssuesByKey(ctx context.Context, keys []string) ([]JiraStory, error) {
+	if len(keys) == 0 {
+		return []JiraStory{}, nil
+	}
+
+	jql := fmt.Sprintf("key in (%s) ORDER BY created DESC", strings.Join(keys, ","))
+	return c.searchIssues(ctx, jql)
+}
+
+// searchIssues runs a JQL search, following pagination, and converts the results to stories
+func (c Client) searchIssues(ctx context.Context, jql string) ([]JiraStory, error) {
+	stories := []JiraStory{}
 	startAt := 0
 	maxResults := 100
-	since := time.Now().AddDate(0, 0, -c.config.DaysToAnalyze).Format("2006-01-02")
 
 	for {
-		jql := fmt.Sprintf("project = %s AND created >= %s ORDER BY created DESC",
-			c.config.JiraProject, since)
-
-		var url string
-		if c.config.IsJiraCloud {
-			url = fmt.Sprintf("%s/rest/api/3/search?jql=%s&maxResults=%d&startAt=%d&expand=changelog",
-				c.config.JiraURL, jql, maxResults, startAt)
-		} else {
-			url = fmt.Sprintf("%s/rest/api/2/search?jql=%s&maxResults=%d&startAt=%d&expand=changelog",
-				c.config.JiraURL, jql, maxResults, startAt)
-		}
+		query := url.Values{}
+		query.Set("jql", jql)
+		query.Set("maxResults", strconv.Itoa(maxResults))
+		query.Set("startAt", strconv.Itoa(startAt))
+		query.Set("expand", "changelog")
+		searchURL := fmt.Sprintf("%s/rest/api/%s/search?%s", c.config.JiraURL, c.apiVersion(), query.Encode())
 
-		body, err := c.makeRequest(url, "GET", c.config.JiraUsername, c.config.JiraToken)
+		body, err := c.makeRequest(ctx, searchURL, "GET", c.config.JiraUsername, c.config.JiraToken)
 		if err != nil {
 			return nil, fmt.Errorf("error fetching Jira issues: %w", err)
 		}
 
 		var response jiraIssuesResponse
 		if err := json.Unmarshal(body, &response); err != nil {
-			return nil, fmt.Errorf("error parsing Jira response: %w", err)
+			if startAt == 0 {
+				return nil, fmt.Errorf("error parsing Jira response: %w", err)
+			}
+			// Keep the pages already fetched rather than discarding them for one truncated page
+			slog.Warn("Stopping issue search, page could not be parsed", "provider", "jira", "start", startAt, "error", err)
+			break
 		}
 
 		for _, issue := range response.Issues {
-			createdAt, _ := time.Parse(time.RFC3339, issue.Fields.Created)
+			createdAt, _ := parseJiraTime(issue.Fields.Created)
 
 			var completedAt, startedAt *time.Time
 			if issue.Fields.Resolutiondate != nil && *issue.Fields.Resolutiondate != "" {
-				t, _ := time.Parse(time.RFC3339, *issue.Fields.Resolutiondate)
+				t, _ := parseJiraTime(*issue.Fields.Resolutiondate)
 				completedAt = &t
 			}
 
-			// Find when issue moved to "In Progress"
+			// Find when issue moved to "In Progress" and which keys it had before moving projects
+			var previousKeys []string
+			var lastStatusChangeAt *time.Time
 			if issue.Changelog != nil {
 				for _, history := range issue.Changelog.Histories {
 					for _, item := range history.Items {
+						if item.Field == "status" {
+							t, ok := parseJiraTime(history.Created)
+							if ok && (lastStatusChangeAt == nil || t.After(*lastStatusChangeAt)) {
+								lastStatusChangeAt = &t
+							}
+						}
+						if item.Field == "Key" && item.FromString != "" && item.FromString != issue.Key {
+							previousKeys = append(previousKeys, item.FromString)
+						}
 						if item.Field == "status" &&
 							(strings.Contains(strings.ToLower(item.ToString), "progress") ||
 								strings.Contains(strings.ToLower(item.ToString), "development")) {
-							t, _ := time.Parse(time.RFC3339, history.Created)
+							t, _ := parseJiraTime(history.Created)
 							if startedAt == nil || t.Before(*startedAt) {
 								startedAt = &t
 							}
@@ -149,7 +372,7 @@ func (c Client) FetchIssues() ([]JiraStory, error) {
 				}
 			}
 
-			estimate := issue.Fields.StoryPoints
+			estimate := issue.Fields.number(c.storyPointField())
 			if estimate == 0 && issue.Fields.TimeEstimate > 0 {
 				estimate = float64(issue.Fields.TimeEstimate) / 3600 // Convert seconds to hours
 			}
@@ -159,6 +382,16 @@ func (c Client) FetchIssues() ([]JiraStory, error) {
 				actualEffort = float64(issue.Fields.TimeSpent) / 3600
 			}
 
+			var components []string
+			for _, component := range issue.Fields.Components {
+				components = append(components, component.Name)
+			}
+
+			parentKey := ""
+			if issue.Fields.Parent != nil {
+				parentKey = issue.Fields.Parent.Key
+			}
+
 			stories = append(stories, JiraStory{
 				Key:          issue.Key,
 				Assignee:     assignee,
@@ -168,9 +401,24 @@ func (c Client) FetchIssues() ([]JiraStory, error) {
 				Estimate:     estimate,
 				ActualEffort: actualEffort,
 				Status:       issue.Fields.Status.Name,
+				Components:   components,
+				Labels:       issue.Fields.Labels,
+				PreviousKeys: previousKeys,
+				IssueType:    issue.Fields.IssueType.Name,
+				IsSubtask:    issue.Fields.IssueType.Subtask,
+				ParentKey:    parentKey,
+				Sprints:      issue.Fields.sprints(c.sprintField()),
+
+				LastStatusChangeAt: lastStatusChangeAt,
 			})
+			if max := c.config.IssueCap(); max > 0 && len(stories) >= max {
+				slog.Warn("Stopped fetching at the configured cap; results are truncated", "provider", "jira", "kind", "issues", "count", max)
+				c.stats.RecordTruncated("jira", "issues")
+				return stories, nil
+			}
 		}
 
+		c.reportProgress(len(stories))
 		if len(response.Issues) < maxResults {
 			break
 		}
@@ -178,4 +426,31 @@ func (c Client) FetchIssues() ([]JiraStory, error) {
 	}
 
 	return stories, nil
-}
\ No newline at end of file
+}
+
+// storyPointField returns the key of the custom field holding story points
+func (c Client) storyPointField() string {
+	if c.config.JiraStoryPointField == "" {
+		return config.DefaultJiraStoryPointField
+	}
+	return c.config.JiraStoryPointField
+}
+
+// sprintField returns the key of the custom field holding sprints
+func (c Client) sprintField() string {
+	if c.config.JiraSprintField == "" {
+		return config.DefaultJiraSprintField
+	}
+	return c.config.JiraSprintField
+}
+
+// parseJiraTime parses a Jira timestamp, which uses a numeric zone without a colon
+// (2006-01-02T15:04:05.000-0700) on most instances, falling back to RFC 3339
+func parseJiraTime(value string) (time.Time, bool) {
+	for _, layout := range []string{"2006-01-02T15:04:05.000-0700", time.RFC3339} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
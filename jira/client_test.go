@@ -0,0 +1,519 @@
+package jira
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"devops-metrics/config"
+)
+
+// searchServer answers project checks and issue searches, recording the JQL of each search.
+// Projects other than known return 404.
+type searchServer struct {
+	known    string
+	issues   string // JSON array of issues returned by every search
+	searches []string
+	checked  []string
+}
+
+func (s *searchServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case strings.Contains(r.URL.Path, "/project/"):
+		project := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+		s.checked = append(s.checked, project)
+		if project != s.known {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, `{"key":"`+project+`"}`)
+	case strings.HasSuffix(r.URL.Path, "/search"):
+		s.searches = append(s.searches, r.URL.Query().Get("jql"))
+		issues := s.issues
+		if issues == "" {
+			issues = "[]"
+		}
+		fmt.Fprintf(w, `{"startAt":0,"maxResults":100,"total":0,"issues":%s}`, issues)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func newTestClient(srv *httptest.Server, cfg config.Config) Client {
+	cfg.JiraURL = srv.URL
+	cfg.IsJiraCloud = true
+	if cfg.DaysToAnalyze == 0 {
+		cfg.DaysToAnalyze = 30
+	}
+	return NewClient(cfg).WithHTTPClient(srv.Client())
+}
+
+func TestIssuesJQL(t *testing.T) {
+	until := time.Date(2026, 3, 31, 15, 0, 0, 0, time.UTC)
+	window := "created >= 2026-03-01 AND created < 2026-04-01"
+	tests := []struct {
+		name string
+		cfg  config.Config
+		want string
+	}{
+		{"default project query", config.Config{JiraProject: "PROJ"},
+			"project = PROJ AND " + window + " ORDER BY created DESC"},
+		{"custom query gets the window", config.Config{JiraJQL: `project in (A, B) AND labels = "tech-debt"`},
+			`(project in (A, B) AND labels = "tech-debt") AND ` + window + " ORDER BY created DESC"},
+		{"custom order is kept last", config.Config{JiraJQL: "issuetype = Bug order by priority DESC"},
+			"(issuetype = Bug) AND " + window + " order by priority DESC"},
+		{"custom created constraint wins", config.Config{JiraJQL: "project = A AND created >= -90d"},
+			"project = A AND created >= -90d ORDER BY created DESC"},
+		{"sprint by id", config.Config{JiraProject: "PROJ", JiraSprint: "42"},
+			"project = PROJ AND sprint = 42 ORDER BY created DESC"},
+		{"sprint by name within custom query", config.Config{JiraJQL: "project = A", JiraSprint: "Sprint 7"},
+			`(project = A) AND sprint = "Sprint 7" ORDER BY created DESC`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.cfg.WindowStart = time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+			tt.cfg.WindowEnd = until
+			if got := NewClient(tt.cfg).issuesJQL(); got != tt.want {
+				t.Errorf("issuesJQL() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFetchIssuesEncodesJQL(t *testing.T) {
+	jql := `project = "My Project" AND labels in ("tech-debt", "a&b") AND summary ~ "50% off?" AND created >= -7d`
+	var rawQuery, received string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rawQuery, received = r.URL.RawQuery, r.URL.Query().Get("jql")
+		fmt.Fprint(w, `{"startAt":0,"maxResults":100,"total":0,"issues":[]}`)
+	}))
+	defer srv.Close()
+
+	if _, err := newTestClient(srv, config.Config{JiraJQL: jql}).FetchIssues(context.Background()); err != nil {
+		t.Fatalf("FetchIssues() error = %v", err)
+	}
+	if want := jql + " ORDER BY created DESC"; received != want {
+		t.Errorf("server received jql %q, want %q", received, want)
+	}
+	if strings.ContainsAny(rawQuery, ` "?`) || strings.Contains(rawQuery, "a&b") {
+		t.Errorf("query string %q is not encoded", rawQuery)
+	}
+}
+
+func TestFetchIssuesProjectCheck(t *testing.T) {
+	tests := []struct {
+		name        string
+		cfg         config.Config
+		wantErr     bool
+		wantChecked bool
+	}{
+		{"default query checks the project", config.Config{JiraProject: "PROJ"}, false, true},
+		{"missing project is reported", config.Config{JiraProject: "NOPE"}, true, true},
+		{"custom query without project", config.Config{JiraJQL: "assignee = currentUser()"}, false, false},
+		{"custom query across projects", config.Config{JiraProject: "NOPE", JiraJQL: "project in (A, B)"}, false, false},
+		{"sprint with custom query", config.Config{JiraJQL: "project in (A, B)", JiraSprint: "3"}, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := &searchServer{known: "PROJ"}
+			srv := httptest.NewServer(server)
+			defer srv.Close()
+
+			_, err := newTestClient(srv, tt.cfg).FetchIssues(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("FetchIssues() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := len(server.checked) > 0; got != tt.wantChecked {
+				t.Errorf("project checked = %v, want %v", got, tt.wantChecked)
+			}
+			if !tt.wantErr && len(server.searches) != 1 {
+				t.Errorf("searches = %d, want 1", len(server.searches))
+			}
+		})
+	}
+}
+
+func TestSearchIssuesBadPage(t *testing.T) {
+	created := time.Now().Add(-time.Hour).Format("2006-01-02T15:04:05.000-0700")
+	var page strings.Builder
+	page.WriteString(`{"startAt":0,"maxResults":100,"issues":[`)
+	for i := 0; i < 100; i++ {
+		if i > 0 {
+			page.WriteString(",")
+		}
+		fmt.Fprintf(&page, `{"key":"PROJ-%d","fields":{"created":%q,"status":{"name":"Open"}}}`, i, created)
+	}
+	page.WriteString("]}")
+
+	tests := []struct {
+		name    string
+		bad     int // startAt answered with a truncated body; -1 for none
+		want    int
+		wantErr bool
+	}{
+		{"all pages parse", -1, 250, false},
+		{"later page is truncated", 200, 200, false},
+		{"first page is truncated", 0, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				startAt, _ := strconv.Atoi(r.URL.Query().Get("startAt"))
+				switch startAt {
+				case tt.bad:
+					fmt.Fprint(w, `{"startAt":0,"issues":[{"key":"PRO`)
+				case 200:
+					fmt.Fprintf(w, `{"startAt":200,"maxResults":100,"issues":[%s]}`, strings.Repeat(`{"key":"PROJ-X","fields":{}},`, 49)+`{"key":"PROJ-Y","fields":{}}`)
+				default:
+					fmt.Fprint(w, page.String())
+				}
+			}))
+			defer srv.Close()
+
+			stories, err := newTestClient(srv, config.Config{JiraJQL: "project = PROJ"}).FetchIssues(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("FetchIssues() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(stories) != tt.want {
+				t.Errorf("got %d stories, want %d", len(stories), tt.want)
+			}
+		})
+	}
+}
+
+func TestFetchIssuesCanned(t *testing.T) {
+	issues := `[
+		{"key":"NEW-7","fields":{"status":{"name":"Done"},"assignee":{"displayName":"Alice Doe","name":"alice"},
+		 "created":"2026-03-02T09:00:00.000+0100","resolutiondate":"2026-03-06T17:30:00.000+0100",
+		 "customfield_10016":5,"timespent":14400,"components":[{"name":"api"},{"name":"web"}],"labels":["tech-debt"],
+		 "issuetype":{"name":"Story"}},
+		 "changelog":{"histories":[
+			{"created":"2026-03-03T10:00:00.000+0100","items":[{"field":"status","fromString":"To Do","toString":"In Progress"}]},
+			{"created":"2026-03-04T08:00:00.000+0100","items":[{"field":"Key","fromString":"OLD-3","toString":"NEW-7"}]},
+			{"created":"2026-03-06T17:30:00.000+0100","items":[{"field":"status","fromString":"In Progress","toString":"Done"}]}
+		 ]}},
+		{"key":"NEW-8","fields":{"status":{"name":"To Do"},"created":"2026-03-05T12:00:00.000+0000","timeestimate":7200,
+		 "issuetype":{"name":"Sub-task","subtask":true},"parent":{"key":"NEW-7"}}},
+		{"key":"NEW-9","fields":{"status":{"name":"In Progress"},"assignee":{"displayName":"Bob Roe","name":"bob"},
+		 "created":"2026-03-05T12:00:00Z","issuetype":{"name":"Bug"},"customfield_10020":[{"id":4,"name":"Sprint 4","state":"active","startDate":"2026-03-02T09:00:00.000Z"}]}}
+	]`
+
+	tests := []struct {
+		name  string
+		cloud bool
+		want  []string
+	}{
+		{
+			name:  "cloud",
+			cloud: true,
+			want: []string{
+				"NEW-7 Story Done Alice Doe created=2026-03-02T08:00:00Z started=2026-03-03T09:00:00Z completed=2026-03-06T16:30:00Z changed=2026-03-06T16:30:00Z estimate=5 actual=4 components=[api web] labels=[tech-debt] previous=[OLD-3] parent= subtask=false sprints=0",
+				"NEW-8 Sub-task To Do Unassigned created=2026-03-05T12:00:00Z started=- completed=- changed=- estimate=2 actual=0 components=[] labels=[] previous=[] parent=NEW-7 subtask=true sprints=0",
+				"NEW-9 Bug In Progress Bob Roe created=2026-03-05T12:00:00Z started=- completed=- changed=- estimate=0 actual=0 components=[] labels=[] previous=[] parent= subtask=false sprints=1",
+			},
+		},
+		{
+			name: "data center uses the user name",
+			want: []string{
+				"NEW-7 Story Done alice created=2026-03-02T08:00:00Z started=2026-03-03T09:00:00Z completed=2026-03-06T16:30:00Z changed=2026-03-06T16:30:00Z estimate=5 actual=4 components=[api web] labels=[tech-debt] previous=[OLD-3] parent= subtask=false sprints=0",
+				"NEW-8 Sub-task To Do Unassigned created=2026-03-05T12:00:00Z started=- completed=- changed=- estimate=2 actual=0 components=[] labels=[] previous=[] parent=NEW-7 subtask=true sprints=0",
+				"NEW-9 Bug In Progress bob created=2026-03-05T12:00:00Z started=- completed=- changed=- estimate=0 actual=0 components=[] labels=[] previous=[] parent= subtask=false sprints=1",
+			},
+		},
+	}
+	when := func(t *time.Time) string {
+		if t == nil {
+			return "-"
+		}
+		return t.UTC().Format(time.RFC3339)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(&searchServer{issues: issues})
+			defer srv.Close()
+
+			client := newTestClient(srv, config.Config{JiraJQL: "project = NEW"})
+			client.config.IsJiraCloud = tt.cloud
+			stories, err := client.FetchIssues(context.Background())
+			if err != nil {
+				t.Fatalf("FetchIssues() error = %v", err)
+			}
+			var got []string
+			for _, s := range stories {
+				got = append(got, fmt.Sprintf("%s %s %s %s created=%s started=%s completed=%s changed=%s estimate=%g actual=%g components=%v labels=%v previous=%v parent=%s subtask=%t sprints=%d",
+					s.Key, s.IssueType, s.Status, s.Assignee, s.CreatedAt.UTC().Format(time.RFC3339), when(s.StartedAt), when(s.CompletedAt),
+					when(s.LastStatusChangeAt), s.Estimate, s.ActualEffort, s.Components, s.Labels, s.PreviousKeys, s.ParentKey, s.IsSubtask, len(s.Sprints)))
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d stories, want %d:\n%s", len(got), len(tt.want), strings.Join(got, "\n"))
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("story %d =\n  %s\nwant\n  %s", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestFetchIssuesStoryPointField(t *testing.T) {
+	issues := `[
+		{"key":"P-1","fields":{"created":"2026-03-02T09:00:00.000+0000","customfield_10016":3,"customfield_10026":8}},
+		{"key":"P-2","fields":{"created":"2026-03-02T09:00:00.000+0000","customfield_10016":null,"customfield_10026":2.5,"timeestimate":3600}},
+		{"key":"P-3","fields":{"created":"2026-03-02T09:00:00.000+0000","customfield_10026":"large","timeestimate":5400}},
+		{"key":"P-4","fields":{"created":"2026-03-02T09:00:00.000+0000"}}
+	]`
+	tests := []struct {
+		name  string
+		field string
+		want  []float64 // Estimate of each issue
+	}{
+		{"default field", "", []float64{3, 1, 1.5, 0}},
+		{"custom field", "customfield_10026", []float64{8, 2.5, 1.5, 0}},
+		{"field no issue has", "customfield_99999", []float64{0, 1, 1.5, 0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(&searchServer{issues: issues})
+			defer srv.Close()
+
+			stories, err := newTestClient(srv, config.Config{JiraJQL: "project = P", JiraStoryPointField: tt.field}).FetchIssues(context.Background())
+			if err != nil {
+				t.Fatalf("FetchIssues() error = %v", err)
+			}
+			var got []float64
+			for _, s := range stories {
+				got = append(got, s.Estimate)
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("estimates = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSearchRequestEscaping(t *testing.T) {
+	tests := []struct {
+		name    string
+		jql     string
+		wantRaw string // Encoded jql parameter
+	}{
+		{"window comparison", "created >= 2024-01-01", "created+%3E%3D+2024-01-01"},
+		{"quoted name with ampersand", `project = "R&D"`, "project+%3D+%22R%26D%22"},
+		{"hash and plus", `summary ~ "C# + Go"`, "summary+~+%22C%23+%2B+Go%22"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requestURI string
+			var query url.Values
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requestURI, query = r.RequestURI, r.URL.Query()
+				fmt.Fprint(w, `{"startAt":0,"maxResults":100,"total":0,"issues":[]}`)
+			}))
+			defer srv.Close()
+
+			if _, err := newTestClient(srv, config.Config{}).searchIssues(context.Background(), tt.jql); err != nil {
+				t.Fatalf("searchIssues() error = %v", err)
+			}
+			if !strings.Contains(requestURI, "jql="+tt.wantRaw+"&") && !strings.HasSuffix(requestURI, "jql="+tt.wantRaw) {
+				t.Errorf("request %s does not carry jql=%s", requestURI, tt.wantRaw)
+			}
+			if got := query.Get("jql"); got != tt.jql {
+				t.Errorf("jql round-trips to %q, want %q", got, tt.jql)
+			}
+			for param, want := range map[string]string{"maxResults": "100", "startAt": "0", "expand": "changelog"} {
+				if got := query.Get(param); got != want {
+					t.Errorf("%s = %q, want %q", param, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestMakeRequestRetries(t *testing.T) {
+	tests := []struct {
+		name         string
+		statuses     []int // Status of each response in turn; the last repeats
+		wantRequests int
+		wantStatus   int // Status of the returned APIError; 0 for success
+	}{
+		{"success", []int{http.StatusOK}, 1, 0},
+		{"server error then success", []int{http.StatusServiceUnavailable, http.StatusOK}, 2, 0},
+		{"rate limit then success", []int{http.StatusTooManyRequests, http.StatusOK}, 2, 0},
+		{"unauthorized is not retried", []int{http.StatusUnauthorized}, 1, http.StatusUnauthorized},
+		{"not found is not retried", []int{http.StatusNotFound}, 1, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests int
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				status := tt.statuses[min(requests, len(tt.statuses)-1)]
+				requests++
+				if status != http.StatusOK {
+					w.Header().Set("Retry-After", "1")
+					http.Error(w, http.StatusText(status), status)
+					return
+				}
+				fmt.Fprint(w, `{"ok":true}`)
+			}))
+			defer srv.Close()
+
+			c := newTestClient(srv, config.Config{})
+			body, err := c.makeRequest(context.Background(), srv.URL+"/rest/api/3/myself", "GET", "", "token")
+
+			if requests != tt.wantRequests {
+				t.Errorf("made %d requests, want %d", requests, tt.wantRequests)
+			}
+			if tt.wantStatus == 0 {
+				if err != nil || string(body) != `{"ok":true}` {
+					t.Fatalf("makeRequest() = %q, %v; want the final body", body, err)
+				}
+				return
+			}
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("makeRequest() error = %v, want an *APIError", err)
+			}
+			if apiErr.StatusCode != tt.wantStatus {
+				t.Errorf("StatusCode = %d, want %d", apiErr.StatusCode, tt.wantStatus)
+			}
+			if apiErr.Unauthorized() != (tt.wantStatus == http.StatusUnauthorized) {
+				t.Errorf("Unauthorized() = %v for status %d", apiErr.Unauthorized(), apiErr.StatusCode)
+			}
+		})
+	}
+}
+
+func TestAPIErrorClassification(t *testing.T) {
+	tests := []struct {
+		status                               int
+		unauthorized, rateLimited, temporary bool
+	}{
+		{http.StatusUnauthorized, true, false, false},
+		{http.StatusForbidden, true, false, false},
+		{http.StatusTooManyRequests, false, true, true},
+		{http.StatusInternalServerError, false, false, true},
+		{http.StatusServiceUnavailable, false, false, true},
+		{http.StatusBadRequest, false, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(strconv.Itoa(tt.status), func(t *testing.T) {
+			e := &APIError{StatusCode: tt.status}
+			if e.Unauthorized() != tt.unauthorized || e.RateLimited() != tt.rateLimited || e.Temporary() != tt.temporary {
+				t.Errorf("Unauthorized, RateLimited, Temporary = %v, %v, %v; want %v, %v, %v",
+					e.Unauthorized(), e.RateLimited(), e.Temporary(), tt.unauthorized, tt.rateLimited, tt.temporary)
+			}
+		})
+	}
+}
+
+func TestFetchIssuesCap(t *testing.T) {
+	issues := `[
+		{"key":"P-1","fields":{"created":"2026-03-02T09:00:00.000+0000"}},
+		{"key":"P-2","fields":{"created":"2026-03-03T09:00:00.000+0000"}},
+		{"key":"P-3","fields":{"created":"2026-03-04T09:00:00.000+0000"}}
+	]`
+	tests := []struct {
+		name string
+		cfg  config.Config
+		want string
+	}{
+		{"unlimited", config.Config{}, "[P-1 P-2 P-3]"},
+		{"max items", config.Config{MaxItems: 2}, "[P-1 P-2]"},
+		{"max issues overrides max items", config.Config{MaxItems: 2, MaxIssues: 1}, "[P-1]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(&searchServer{issues: issues})
+			defer srv.Close()
+
+			tt.cfg.JiraJQL = "project = P"
+			stories, err := newTestClient(srv, tt.cfg).FetchIssues(context.Background())
+			if err != nil {
+				t.Fatalf("FetchIssues() error = %v", err)
+			}
+			var keys []string
+			for _, s := range stories {
+				keys = append(keys, s.Key)
+			}
+			if fmt.Sprint(keys) != tt.want {
+				t.Errorf("issues = %v, want %s", keys, tt.want)
+			}
+		})
+	}
+}
+
+func TestFetchIssuesLabels(t *testing.T) {
+	issues := `[
+		{"key":"P-1","fields":{"created":"2026-03-02T09:00:00.000+0000","labels":["tech-debt","bug"]}},
+		{"key":"P-2","fields":{"created":"2026-03-02T09:00:00.000+0000","labels":[]}},
+		{"key":"P-3","fields":{"created":"2026-03-02T09:00:00.000+0000"}}
+	]`
+	srv := httptest.NewServer(&searchServer{issues: issues})
+	defer srv.Close()
+
+	stories, err := newTestClient(srv, config.Config{JiraJQL: "project = P"}).FetchIssues(context.Background())
+	if err != nil {
+		t.Fatalf("FetchIssues() error = %v", err)
+	}
+	want := []string{"[tech-debt bug]", "[]", "[]"}
+	if len(stories) != len(want) {
+		t.Fatalf("got %d stories, want %d", len(stories), len(want))
+	}
+	for i, s := range stories {
+		if fmt.Sprint(s.Labels) != want[i] {
+			t.Errorf("%s labels = %v, want %s", s.Key, s.Labels, want[i])
+		}
+	}
+}
+
+// BenchmarkSearchIssues measures paging through and parsing 1,000 issues with changelogs
+func BenchmarkSearchIssues(b *testing.B) {
+	const layout = "2006-01-02T15:04:05.000-0700"
+	created := time.Now().Add(-72 * time.Hour)
+	pages := make([]string, 10)
+	for p := range pages {
+		var page strings.Builder
+		fmt.Fprintf(&page, `{"startAt":%d,"maxResults":100,"total":1000,"issues":[`, p*100)
+		for i := p * 100; i < (p+1)*100; i++ {
+			if i > p*100 {
+				page.WriteString(",")
+			}
+			fmt.Fprintf(&page, `{"key":"PROJ-%d","fields":{"created":%q,"resolutiondate":%q,"status":{"name":"Done"},`+
+				`"assignee":{"displayName":"Dev %d","name":"dev-%d"},"issuetype":{"name":"Story"},"labels":["feature"],`+
+				`"components":[{"name":"api"}],"customfield_10016":3,"timespent":7200},`+
+				`"changelog":{"histories":[{"created":%q,"items":[{"field":"status","toString":"In Progress"}]}]}}`,
+				i, created.Format(layout), created.Add(48*time.Hour).Format(layout), i%10, i%10,
+				created.Add(time.Hour).Format(layout))
+		}
+		page.WriteString("]}")
+		pages[p] = page.String()
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		startAt, _ := strconv.Atoi(r.URL.Query().Get("startAt"))
+		if startAt/100 >= len(pages) {
+			fmt.Fprint(w, `{"startAt":1000,"maxResults":100,"total":1000,"issues":[]}`)
+			return
+		}
+		fmt.Fprint(w, pages[startAt/100])
+	}))
+	defer srv.Close()
+	client := newTestClient(srv, config.Config{})
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		stories, err := client.searchIssues(ctx, "project = PROJ")
+		if err != nil || len(stories) != 1000 {
+			b.Fatalf("got %d stories, err %v", len(stories), err)
+		}
+	}
+}
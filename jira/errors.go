@@ -0,0 +1,41 @@
+package jira
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// APIError is returned when the Jira API responds with an unexpected status
+type APIError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("API request failed with status %d: %s", e.StatusCode, e.Body)
+}
+
+// Unauthorized reports whether the credentials were rejected (401) or lack permission (403)
+func (e *APIError) Unauthorized() bool {
+	return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden
+}
+
+// RateLimited reports whether the request was throttled
+func (e *APIError) RateLimited() bool {
+	return e.StatusCode == http.StatusTooManyRequests
+}
+
+// Temporary reports whether the request may succeed when retried: rate limits and server errors
+func (e *APIError) Temporary() bool {
+	return e.RateLimited() || e.StatusCode >= 500
+}
+
+// NotFoundError indicates the configured project does not exist or is not visible to the
+// configured user, as opposed to a valid project with no issues in the analysis window
+type NotFoundError struct {
+	Project string
+}
+
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("jira project %s not found or not accessible (check jira_project and user permissions)", e.Project)
+}
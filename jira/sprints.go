@@ -0,0 +1,157 @@
+package jira
+
+import (
+	"encoding/json"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Sprint is a Jira agile sprint an issue belongs to
+type Sprint struct {
+	ID           int        `json:"id"`
+	Name         string     `json:"name"`
+	State        string     `json:"state"` // future, active or closed
+	StartDate    *time.Time `json:"start_date,omitempty"`
+	EndDate      *time.Time `json:"end_date,omitempty"`
+	CompleteDate *time.Time `json:"complete_date,omitempty"` // When the sprint was closed
+}
+
+// Matches reports whether sprint, a sprint ID or name, identifies s. Names are compared
+// case-insensitively.
+func (s Sprint) Matches(sprint string) bool {
+	if id, err := strconv.Atoi(sprint); err == nil {
+		return id == s.ID
+	}
+	return strings.EqualFold(strings.TrimSpace(sprint), s.Name)
+}
+
+// legacySprint matches the string form Jira Server returns for sprints, e.g.
+// com.atlassian.greenhopper.service.sprint.Sprint@1a2b[id=12,rapidViewId=3,state=CLOSED,name=Sprint 5,...]
+var legacySprint = regexp.MustCompile(`\[(.*)\]$`)
+
+// sprints returns the sprints in the field with the given key, oldest first. Jira Cloud
+// returns sprint objects; Jira Server returns their toString form, which is parsed too.
+func (f jiraIssueFields) sprints(key string) []Sprint {
+	raw, ok := f.raw[key]
+	if !ok {
+		return nil
+	}
+
+	var sprints []Sprint
+	var objects []struct {
+		ID           int    `json:"id"`
+		Name         string `json:"name"`
+		State        string `json:"state"`
+		StartDate    string `json:"startDate"`
+		EndDate      string `json:"endDate"`
+		CompleteDate string `json:"completeDate"`
+	}
+	if err := json.Unmarshal(raw, &objects); err == nil {
+		for _, o := range objects {
+			sprints = append(sprints, Sprint{
+				ID:           o.ID,
+				Name:         o.Name,
+				State:        strings.ToLower(o.State),
+				StartDate:    sprintTime(o.StartDate),
+				EndDate:      sprintTime(o.EndDate),
+				CompleteDate: sprintTime(o.CompleteDate),
+			})
+		}
+	} else {
+		var legacy []string
+		if err := json.Unmarshal(raw, &legacy); err != nil {
+			return nil
+		}
+		for _, value := range legacy {
+			if sprint, ok := parseLegacySprint(value); ok {
+				sprints = append(sprints, sprint)
+			}
+		}
+	}
+
+	sort.SliceStable(sprints, func(i, j int) bool {
+		return sprintOrder(sprints[i]).Before(sprintOrder(sprints[j]))
+	})
+	return sprints
+}
+
+// parseLegacySprint parses one Jira Server sprint string. Sprint names may contain commas,
+// so each value runs up to the next ",key=".
+func parseLegacySprint(value string) (Sprint, bool) {
+	m := legacySprint.FindStringSubmatch(value)
+	if m == nil {
+		return Sprint{}, false
+	}
+
+	fields := make(map[string]string)
+	rest := m[1]
+	for rest != "" {
+		key, after, ok := strings.Cut(rest, "=")
+		if !ok {
+			break
+		}
+		end := len(after)
+		for i := 0; i < len(after); i++ {
+			if after[i] == ',' && legacyKeyFollows(after[i+1:]) {
+				end = i
+				break
+			}
+		}
+		fields[key] = after[:end]
+		if end == len(after) {
+			break
+		}
+		rest = after[end+1:]
+	}
+
+	id, err := strconv.Atoi(fields["id"])
+	if err != nil {
+		return Sprint{}, false
+	}
+	return Sprint{
+		ID:           id,
+		Name:         fields["name"],
+		State:        strings.ToLower(fields["state"]),
+		StartDate:    sprintTime(fields["startDate"]),
+		EndDate:      sprintTime(fields["endDate"]),
+		CompleteDate: sprintTime(fields["completeDate"]),
+	}, true
+}
+
+// legacyKeyFollows reports whether s starts with a key such as "state=", so a comma before it
+// separates fields rather than being part of a name
+func legacyKeyFollows(s string) bool {
+	key, _, ok := strings.Cut(s, "=")
+	if !ok || key == "" {
+		return false
+	}
+	for _, r := range key {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z') {
+			return false
+		}
+	}
+	return true
+}
+
+// sprintTime parses a sprint date; Jira Server writes "<null>" for unset dates
+func sprintTime(value string) *time.Time {
+	if value == "" || value == "<null>" {
+		return nil
+	}
+	if t, ok := parseJiraTime(value); ok {
+		return &t
+	}
+	return nil
+}
+
+// sprintOrder is the time a sprint sorts by: its start, or far in the future for sprints
+// that have not started
+func sprintOrder(s Sprint) time.Time {
+	if s.StartDate != nil {
+		return *s.StartDate
+	}
+	return time.Date(9999, 1, 1, 0, 0, 0, 0, time.UTC)
+}
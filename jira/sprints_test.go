@@ -0,0 +1,91 @@
+package jira
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+)
+
+func TestSprints(t *testing.T) {
+	tests := []struct {
+		name   string
+		fields string
+		want   []string // id/name/state/start/complete of each sprint, oldest first
+	}{
+		{
+			name:   "missing field",
+			fields: `{}`,
+		},
+		{
+			name:   "null field",
+			fields: `{"customfield_10020": null}`,
+		},
+		{
+			name: "cloud objects sorted by start",
+			fields: `{"customfield_10020": [
+				{"id": 8, "name": "Sprint 8", "state": "active", "startDate": "2025-02-03T09:00:00.000Z", "endDate": "2025-02-17T09:00:00.000Z"},
+				{"id": 7, "name": "Sprint 7", "state": "closed", "startDate": "2025-01-20T09:00:00.000Z", "endDate": "2025-02-03T09:00:00.000Z", "completeDate": "2025-02-03T10:00:00.000Z"}
+			]}`,
+			want: []string{
+				"7/Sprint 7/closed/2025-01-20T09:00:00Z/2025-02-03T10:00:00Z",
+				"8/Sprint 8/active/2025-02-03T09:00:00Z/-",
+			},
+		},
+		{
+			name: "server strings with commas in the name",
+			fields: `{"customfield_10020": [
+				"com.atlassian.greenhopper.service.sprint.Sprint@1a2b[id=12,rapidViewId=3,state=CLOSED,name=Sprint 5, hardening,startDate=2025-01-06T09:00:00.000+0100,endDate=2025-01-20T09:00:00.000+0100,completeDate=2025-01-20T11:00:00.000+0100,sequence=12]",
+				"com.atlassian.greenhopper.service.sprint.Sprint@3c4d[id=13,rapidViewId=3,state=FUTURE,name=Sprint 6,startDate=<null>,endDate=<null>,completeDate=<null>,sequence=13]"
+			]}`,
+			want: []string{
+				"12/Sprint 5, hardening/closed/2025-01-06T08:00:00Z/2025-01-20T10:00:00Z",
+				"13/Sprint 6/future/-/-",
+			},
+		},
+		{
+			name:   "unparseable server string",
+			fields: `{"customfield_10020": ["Sprint 5"]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var fields jiraIssueFields
+			if err := json.Unmarshal([]byte(tt.fields), &fields); err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, s := range fields.sprints("customfield_10020") {
+				start, complete := "-", "-"
+				if s.StartDate != nil {
+					start = s.StartDate.UTC().Format("2006-01-02T15:04:05Z07:00")
+				}
+				if s.CompleteDate != nil {
+					complete = s.CompleteDate.UTC().Format("2006-01-02T15:04:05Z07:00")
+				}
+				got = append(got, fmt.Sprintf("%d/%s/%s/%s/%s", s.ID, s.Name, s.State, start, complete))
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("sprints = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSprintMatches(t *testing.T) {
+	sprint := Sprint{ID: 12, Name: "Sprint 5"}
+	tests := []struct {
+		sprint string
+		want   bool
+	}{
+		{"12", true},
+		{"13", false},
+		{"sprint 5", true},
+		{" Sprint 5 ", true},
+		{"Sprint 6", false},
+	}
+	for _, tt := range tests {
+		if got := sprint.Matches(tt.sprint); got != tt.want {
+			t.Errorf("Matches(%q) = %v, want %v", tt.sprint, got, tt.want)
+		}
+	}
+}
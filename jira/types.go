@@ -14,4 +14,13 @@ type JiraStory struct {
 	Estimate     float64    `json:"estimate"`
 	ActualEffort float64    `json:"actual_effort"`
 	Status       string     `json:"status"`
-}
\ No newline at end of file
+	Components   []string   `json:"components,omitempty"`
+	Labels       []string   `json:"labels,omitempty"`
+	PreviousKeys []string   `json:"previous_keys,omitempty"` // Keys the issue had before moving projects
+	IssueType    string     `json:"issue_type,omitempty"`
+	IsSubtask    bool       `json:"is_subtask,omitempty"`
+	ParentKey    string     `json:"parent_key,omitempty"` // Parent issue of a sub-task
+	Sprints      []Sprint   `json:"sprints,omitempty"`    // Sprints the issue was planned into, oldest first
+
+	LastStatusChangeAt *time.Time `json:"last_status_change_at,omitempty"`
+}
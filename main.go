@@ -1,37 +1,93 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"log"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"devops-metrics/azuredevops"
 	"devops-metrics/bitbucket"
+	"devops-metrics/cache"
 	"devops-metrics/config"
+	"devops-metrics/fetchstats"
 	"devops-metrics/github"
+	"devops-metrics/gitlab"
 	"devops-metrics/jira"
+	"devops-metrics/logging"
 	"devops-metrics/metrics"
+	"devops-metrics/replay"
 	"devops-metrics/report"
+	"devops-metrics/storage"
 	"devops-metrics/web"
 )
 
 func main() {
 	fmt.Println("DevOps & Productivity Metrics Generator with API Integration")
-	fmt.Println("============================================================\n")
+	fmt.Println("============================================================")
+	fmt.Println()
 
 	// Parse command line flags
 	var sampleConfig bool
+	var sampleFormat string
 	var runServer bool
 	var port string
+	var cacheDir string
+	var cacheTTL time.Duration
+	var commitTo string
+	var prList, prFile, issueList, issueFile string
+	var artifactPath string
+	var providerList string
+	var exportFormat string
+	var save bool
+	var inputDir, dumpDir string
+	var webhookURL string
 	flag.BoolVar(&sampleConfig, "sample-config", false, "Generate sample configuration file")
+	flag.StringVar(&sampleFormat, "sample-format", "json", "Format of the sample configuration file: json or yaml")
 	flag.BoolVar(&runServer, "server", false, "Run as web server")
 	flag.StringVar(&port, "port", "8080", "Port to run the server on (when using -server)")
+	flag.StringVar(&cacheDir, "cache-dir", "", "Directory for caching raw fetch results between runs (disabled when empty)")
+	flag.DurationVar(&cacheTTL, "cache-ttl", time.Hour, "How long cached fetch results stay valid (when using -cache-dir)")
+	flag.StringVar(&commitTo, "commit-to", "", "Git working tree to commit the JSON report into after each run")
+	flag.StringVar(&prList, "prs", "", "Comma-separated PR numbers to analyze instead of the date window")
+	flag.StringVar(&prFile, "prs-file", "", "File with PR numbers to analyze, one per line")
+	flag.StringVar(&issueList, "issues", "", "Comma-separated Jira keys to analyze instead of the date window")
+	flag.StringVar(&issueFile, "issues-file", "", "File with Jira keys to analyze, one per line")
+	flag.StringVar(&providerList, "providers", "", "Comma-separated providers to run (bitbucket, github, gitlab, azuredevops, jira); defaults to all configured")
+	flag.StringVar(&artifactPath, "artifact", "", "JSON or CSV files (comma-separated) of commits and PRs to compute metrics from instead of calling the APIs")
+	flag.StringVar(&exportFormat, "format", "", "Also export metrics.<format> in this format: json, csv, html, md or xlsx")
+	flag.BoolVar(&save, "save", false, "Save this run's metrics to the snapshot_db SQLite database (default metrics.db)")
+	flag.StringVar(&inputDir, "input-dir", "", "Replay raw API responses saved with -dump-dir instead of calling the APIs")
+	flag.StringVar(&dumpDir, "dump-dir", "", "Save every raw API response under this directory for later replay with -input-dir")
+	flag.StringVar(&webhookURL, "webhook", "", "POST the metrics JSON to this URL after the run (overrides webhook_url)")
 	flag.Parse()
 
+	switch exportFormat {
+	case "", "json", "csv", "html", "md", "xlsx":
+	default:
+		log.Fatalf("Unknown export format %q: use json, csv, html, md or xlsx", exportFormat)
+	}
+
 	if sampleConfig {
-		if err := config.CreateSampleConfig(); err != nil {
+		create, name := config.CreateSampleConfig, "config.json"
+		switch sampleFormat {
+		case "json":
+		case "yaml", "yml":
+			create, name = config.CreateSampleConfigYAML, "config.yaml"
+		default:
+			log.Fatalf("Unknown sample config format %q: use json or yaml", sampleFormat)
+		}
+		if err := create(); err != nil {
 			log.Fatalf("Error creating sample config: %v", err)
 		}
-		fmt.Println("✅ Sample configuration file created: config.sample.json")
-		fmt.Println("\nEdit this file with your credentials and rename to config.json")
+		fmt.Printf("✅ Sample configuration file created: config.sample.%s\n", strings.TrimPrefix(name, "config."))
+		fmt.Printf("\nEdit this file with your credentials and rename to %s\n", name)
 		return
 	}
 
@@ -44,23 +100,61 @@ func main() {
 
 	// Original CLI mode
 	// Load configuration
-	cfg, err := config.LoadConfig("config.json")
+	configFile := config.FindConfigFile()
+	cfg, err := config.LoadConfig(configFile)
+	if logErr := logging.Setup(cfg.LogFormat, cfg.LogLevel); logErr != nil {
+		logging.Setup(logging.FormatText, "")
+		slog.Warn("Invalid logging configuration, using text output", "error", logErr)
+	}
 	if err != nil {
-		log.Printf("Warning: Could not load config.json, trying environment variables: %v\n", err)
+		slog.Warn("Could not load config file, trying environment variables", "file", configFile, "error", err)
+	}
+
+	if inputDir != "" {
+		cfg.InputDir = inputDir
+	}
+	if dumpDir != "" {
+		cfg.DumpDir = dumpDir
+	}
+	if webhookURL != "" {
+		cfg.WebhookURL = webhookURL
 	}
 
 	// Validate configuration
 	hasBitbucket := cfg.BitbucketURL != ""
 	hasGitHub := cfg.GitHubURL != ""
+	hasGitLab := cfg.GitLabProjectID != ""
+	hasAzure := cfg.AzureOrg != ""
 	hasJira := cfg.JiraURL != ""
-	
-	if !hasBitbucket && !hasGitHub && !hasJira {
+
+	providerFlags := []struct {
+		name string
+		has  *bool
+	}{{"bitbucket", &hasBitbucket}, {"github", &hasGitHub}, {"gitlab", &hasGitLab}, {"azuredevops", &hasAzure}, {"jira", &hasJira}}
+	if providerList != "" {
+		selected, err := parseProviders(providerList)
+		if err != nil {
+			fatal("Invalid -providers", "error", err)
+		}
+		for _, p := range providerFlags {
+			if selected[p.name] && !*p.has {
+				slog.Warn("Provider was selected but is not configured", "provider", p.name)
+			}
+			*p.has = *p.has && selected[p.name]
+		}
+	}
+
+	if !hasBitbucket && !hasGitHub && !hasGitLab && !hasAzure && !hasJira && artifactPath == "" {
 		fmt.Println("❌ Configuration Error!")
 		fmt.Println("\nYou need to provide configuration either by:")
-		fmt.Println("1. Creating a config.json file (run with --sample-config to generate template)")
+		fmt.Println("1. Creating a config.json or config.yaml file (run with --sample-config to generate template)")
 		fmt.Println("2. Setting environment variables:")
 		fmt.Println("   GitHub:")
 		fmt.Println("   - GITHUB_URL, GITHUB_TOKEN, GITHUB_OWNER, GITHUB_REPO")
+		fmt.Println("   GitLab:")
+		fmt.Println("   - GITLAB_URL (optional), GITLAB_TOKEN, GITLAB_PROJECT_ID")
+		fmt.Println("   Azure DevOps:")
+		fmt.Println("   - AZURE_URL (optional), AZURE_ORG, AZURE_PROJECT, AZURE_REPO, AZURE_PAT")
 		fmt.Println("   Bitbucket:")
 		fmt.Println("   - BITBUCKET_URL, BITBUCKET_TOKEN, BITBUCKET_PROJECT, BITBUCKET_REPO")
 		fmt.Println("   Jira:")
@@ -70,41 +164,163 @@ func main() {
 		return
 	}
 
-	fmt.Printf("Analyzing data from the last %d days...\n\n", cfg.DaysToAnalyze)
+	warnings, err := cfg.Validate()
+	if err != nil {
+		slog.Error("Configuration error", "error", err)
+		return
+	}
+	for _, warning := range warnings {
+		slog.Warn(warning)
+	}
+
+	slog.Info("Analyzing data", "days", cfg.DaysToAnalyze)
+
+	// Optional on-disk cache of raw fetch results
+	var diskCache *cache.DiskCache
+	if cacheDir != "" {
+		diskCache, err = cache.NewDiskCache(cacheDir, cacheTTL)
+		if err != nil {
+			fatal("Error setting up cache", "error", err)
+		}
+		slog.Info("Using fetch cache", "dir", cacheDir, "ttl", cacheTTL)
+	}
+
+	// One numbering of recorded and replayed responses across the repository checks and fetches
+	ctx := replay.WithSequence(context.Background())
+	recorder := fetchstats.NewRecorder()
 
-	var commits []bitbucket.Commit
-	var prs []bitbucket.PullRequest
-	var stories []jira.JiraStory
+	// Artifact mode computes metrics from data exported by another job instead of the APIs
+	var artifact report.Artifact
+	if artifactPath != "" {
+		artifact, err = report.LoadArtifacts(strings.Split(artifactPath, ",")...)
+		if err != nil {
+			fatal("Error reading artifact", "file", artifactPath, "error", err)
+		}
+		slog.Info("Loaded artifact", "file", artifactPath, "commits", len(artifact.Commits),
+			"prs", len(artifact.PullRequests), "deployments", len(artifact.Deployments), "stories", len(artifact.Stories))
+		hasBitbucket, hasGitHub, hasGitLab, hasAzure, hasJira = false, false, false, false, false
+	}
+
+	// Skip repositories excluded by name
+	if hasBitbucket {
+		bbRepo := cfg.BitbucketProject + "/" + cfg.BitbucketRepo
+		if reason := cfg.RepoNameExclusionReason(bbRepo); reason != "" {
+			slog.Info("Skipping repository", "provider", "bitbucket", "repo", bbRepo, "reason", reason)
+			hasBitbucket = false
+		}
+	}
+	if hasGitHub {
+		ghRepo := cfg.GitHubOwner + "/" + cfg.GitHubRepo
+		if reason := cfg.RepoNameExclusionReason(ghRepo); reason != "" {
+			slog.Info("Skipping repository", "provider", "github", "repo", ghRepo, "reason", reason)
+			hasGitHub = false
+		}
+	}
+	if hasGitLab {
+		if reason := cfg.RepoNameExclusionReason(cfg.GitLabProjectID); reason != "" {
+			slog.Info("Skipping repository", "provider", "gitlab", "repo", cfg.GitLabProjectID, "reason", reason)
+			hasGitLab = false
+		}
+	}
+	azureRepo := cfg.AzureProject + "/" + cfg.AzureRepo
+	if hasAzure {
+		if reason := cfg.RepoNameExclusionReason(azureRepo); reason != "" {
+			slog.Info("Skipping repository", "provider", "azuredevops", "repo", azureRepo, "reason", reason)
+			hasAzure = false
+		}
+	}
+
+	// Skip archived/forked repositories when configured
+	if cfg.ExcludeArchived || cfg.ExcludeForks {
+		if hasBitbucket {
+			info, err := bitbucket.NewClient(cfg).WithStats(recorder).FetchRepoInfo(ctx)
+			if err != nil {
+				slog.Warn("Could not check repository flags", "provider", "bitbucket", "error", err)
+			} else if reason := cfg.RepoExclusionReason(info.Archived, info.Fork); reason != "" {
+				slog.Info("Skipping repository", "provider", "bitbucket", "repo", info.Name, "reason", reason)
+				hasBitbucket = false
+			}
+		}
+		if hasGitHub {
+			info, err := github.NewClient(cfg).WithStats(recorder).FetchRepoInfo(ctx)
+			if err != nil {
+				slog.Warn("Could not check repository flags", "provider", "github", "error", err)
+			} else if reason := cfg.RepoExclusionReason(info.Archived, info.Fork); reason != "" {
+				slog.Info("Skipping repository", "provider", "github", "repo", info.Name, "reason", reason)
+				hasGitHub = false
+			}
+		}
+	}
+
+	commits := artifact.Commits
+	prs := artifact.PullRequests
+	stories := artifact.Stories
+	deployments := artifact.Deployments
+
+	// Remember which providers run, so the summary can leave out the others
+	var providers []string
+	for _, p := range providerFlags {
+		if *p.has {
+			providers = append(providers, p.name)
+		}
+	}
+
+	// Targeted mode analyzes an explicit set of PRs/issues instead of the date window
+	prIDs, err := readPRNumbers(prList, prFile)
+	if err != nil {
+		fatal("Error reading PR list", "error", err)
+	}
+	issueKeys, err := readList(issueList, issueFile)
+	if err != nil {
+		fatal("Error reading issue list", "error", err)
+	}
+	if len(prIDs) > 0 || len(issueKeys) > 0 {
+		slog.Info("Analyzing selected PRs and issues", "prs", len(prIDs), "issues", len(issueKeys))
+		prs, stories = fetchSelected(ctx, cfg, recorder, hasBitbucket, hasGitHub, hasJira, prIDs, issueKeys)
+		hasBitbucket, hasGitHub, hasGitLab, hasAzure, hasJira = false, false, false, false, false
+	}
 
 	// Fetch Bitbucket data
 	if hasBitbucket {
-		bbClient := bitbucket.NewClient(cfg)
-		fmt.Println("🔄 Fetching Bitbucket commits...")
-		commits, err = bbClient.FetchCommits()
+		bbClient := bitbucket.NewClient(cfg).WithStats(recorder)
+		bbRepo := cfg.BitbucketProject + "/" + cfg.BitbucketRepo
+		slog.Info("Fetching commits", "provider", "bitbucket", "repo", bbRepo)
+		cached, err := diskCache.Fetch(cache.Key("bitbucket", bbRepo, "commits", cfg), &commits, func() (err error) {
+			commits, err = bbClient.WithProgress(progress("commits", "provider", "bitbucket", "repo", bbRepo)).FetchCommits(ctx)
+			return err
+		})
 		if err != nil {
-			log.Printf("❌ Error fetching commits: %v", err)
+			slog.Error("Error fetching commits", "provider", "bitbucket", "repo", bbRepo, "error", err)
 			commits = []bitbucket.Commit{}
 		} else {
-			fmt.Printf("✅ Fetched %d commits\n", len(commits))
+			slog.Info("Fetched commits", "provider", "bitbucket", "repo", bbRepo, "count", len(commits), "cached", cached)
 		}
 
-		fmt.Println("🔄 Fetching Bitbucket pull requests...")
-		prs, err = bbClient.FetchPRs()
+		slog.Info("Fetching pull requests", "provider", "bitbucket", "repo", bbRepo)
+		cached, err = diskCache.Fetch(cache.Key("bitbucket", bbRepo, "prs", cfg), &prs, func() (err error) {
+			prs, err = bbClient.WithProgress(progress("pull requests", "provider", "bitbucket", "repo", bbRepo)).FetchPRs(ctx)
+			return err
+		})
 		if err != nil {
-			log.Printf("❌ Error fetching PRs: %v", err)
+			slog.Error("Error fetching pull requests", "provider", "bitbucket", "repo", bbRepo, "error", err)
 			prs = []bitbucket.PullRequest{}
 		} else {
-			fmt.Printf("✅ Fetched %d pull requests\n", len(prs))
+			slog.Info("Fetched pull requests", "provider", "bitbucket", "repo", bbRepo, "count", len(prs), "cached", cached)
 		}
 	}
 
-	// Fetch GitHub data  
+	// Fetch GitHub data
 	if hasGitHub {
-		ghClient := github.NewClient(cfg)
-		fmt.Println("🔄 Fetching GitHub commits...")
-		ghCommits, err := ghClient.FetchCommits()
+		ghClient := github.NewClient(cfg).WithStats(recorder)
+		ghRepo := cfg.GitHubOwner + "/" + cfg.GitHubRepo
+		slog.Info("Fetching commits", "provider", "github", "repo", ghRepo)
+		var ghCommits []github.Commit
+		cached, err := diskCache.Fetch(cache.Key("github", ghRepo, "commits", cfg), &ghCommits, func() (err error) {
+			ghCommits, err = ghClient.WithProgress(progress("commits", "provider", "github", "repo", ghRepo)).FetchCommits(ctx)
+			return err
+		})
 		if err != nil {
-			log.Printf("❌ Error fetching GitHub commits: %v", err)
+			slog.Error("Error fetching commits", "provider", "github", "repo", ghRepo, "error", err)
 		} else {
 			// Convert GitHub commits to Bitbucket format for metrics calculation
 			for _, c := range ghCommits {
@@ -115,65 +331,227 @@ func main() {
 					Message:      c.Message,
 					LinesAdded:   c.LinesAdded,
 					LinesDeleted: c.LinesDeleted,
+					LinesIgnored: c.LinesIgnored,
+					Repo:         c.Repo,
 				})
 			}
-			fmt.Printf("✅ Fetched %d GitHub commits\n", len(ghCommits))
+			slog.Info("Fetched commits", "provider", "github", "repo", ghRepo, "count", len(ghCommits), "cached", cached)
 		}
 
-		fmt.Println("🔄 Fetching GitHub pull requests...")
-		ghPRs, err := ghClient.FetchPRs()
+		slog.Info("Fetching pull requests", "provider", "github", "repo", ghRepo)
+		var ghPRs []github.PullRequest
+		cached, err = diskCache.Fetch(cache.Key("github", ghRepo, "prs", cfg), &ghPRs, func() (err error) {
+			ghPRs, err = ghClient.WithProgress(progress("pull requests", "provider", "github", "repo", ghRepo)).FetchPRs(ctx)
+			return err
+		})
 		if err != nil {
-			log.Printf("❌ Error fetching GitHub PRs: %v", err)
+			slog.Error("Error fetching pull requests", "provider", "github", "repo", ghRepo, "error", err)
 		} else {
 			// Convert GitHub PRs to Bitbucket format for metrics calculation
-			for _, p := range ghPRs {
-				prs = append(prs, bitbucket.PullRequest{
-					ID:            p.ID,
-					Author:        p.Author,
-					CreatedAt:     p.CreatedAt,
-					MergedAt:      p.MergedAt,
-					ClosedAt:      p.ClosedAt,
-					FirstReviewAt: p.FirstReviewAt,
-					LinesChanged:  p.LinesChanged,
-					Reviewers:     p.Reviewers,
-					Status:        p.Status,
-				})
-			}
-			fmt.Printf("✅ Fetched %d GitHub PRs\n", len(ghPRs))
+			prs = append(prs, convertGitHubPRs(ghPRs)...)
+			slog.Info("Fetched pull requests", "provider", "github", "repo", ghRepo, "count", len(ghPRs), "cached", cached)
+		}
+
+		slog.Info("Fetching deployments", "provider", "github", "repo", ghRepo)
+		cached, err = diskCache.Fetch(cache.Key("github", ghRepo, "deployments", cfg), &deployments, func() (err error) {
+			deployments, err = ghClient.WithProgress(progress("deployments", "provider", "github", "repo", ghRepo)).FetchDeployments(ctx)
+			return err
+		})
+		if err != nil {
+			slog.Error("Error fetching deployments", "provider", "github", "repo", ghRepo, "error", err)
+			deployments = []github.Deployment{}
+		} else {
+			slog.Info("Fetched deployments", "provider", "github", "repo", ghRepo, "count", len(deployments), "cached", cached)
+		}
+	}
+
+	// Fetch GitLab data
+	if hasGitLab {
+		glClient := gitlab.NewClient(cfg).WithStats(recorder)
+		slog.Info("Fetching commits", "provider", "gitlab", "repo", cfg.GitLabProjectID)
+		var glCommits []gitlab.Commit
+		cached, err := diskCache.Fetch(cache.Key("gitlab", cfg.GitLabProjectID, "commits", cfg), &glCommits, func() (err error) {
+			glCommits, err = glClient.WithProgress(progress("commits", "provider", "gitlab", "repo", cfg.GitLabProjectID)).FetchCommits(ctx)
+			return err
+		})
+		if err != nil {
+			slog.Error("Error fetching commits", "provider", "gitlab", "repo", cfg.GitLabProjectID, "error", err)
+		} else {
+			commits = append(commits, convertGitLabCommits(glCommits)...)
+			slog.Info("Fetched commits", "provider", "gitlab", "repo", cfg.GitLabProjectID, "count", len(glCommits), "cached", cached)
+		}
+
+		slog.Info("Fetching merge requests", "provider", "gitlab", "repo", cfg.GitLabProjectID)
+		var glPRs []gitlab.PullRequest
+		cached, err = diskCache.Fetch(cache.Key("gitlab", cfg.GitLabProjectID, "prs", cfg), &glPRs, func() (err error) {
+			glPRs, err = glClient.WithProgress(progress("merge requests", "provider", "gitlab", "repo", cfg.GitLabProjectID)).FetchPRs(ctx)
+			return err
+		})
+		if err != nil {
+			slog.Error("Error fetching merge requests", "provider", "gitlab", "repo", cfg.GitLabProjectID, "error", err)
+		} else {
+			prs = append(prs, convertGitLabPRs(glPRs)...)
+			slog.Info("Fetched merge requests", "provider", "gitlab", "repo", cfg.GitLabProjectID, "count", len(glPRs), "cached", cached)
+		}
+	}
+
+	// Fetch Azure DevOps data
+	if hasAzure {
+		azClient := azuredevops.NewClient(cfg).WithStats(recorder)
+		slog.Info("Fetching commits", "provider", "azuredevops", "repo", azureRepo)
+		var azCommits []azuredevops.Commit
+		cached, err := diskCache.Fetch(cache.Key("azuredevops", azureRepo, "commits", cfg), &azCommits, func() (err error) {
+			azCommits, err = azClient.WithProgress(progress("commits", "provider", "azuredevops", "repo", azureRepo)).FetchCommits(ctx)
+			return err
+		})
+		if err != nil {
+			slog.Error("Error fetching commits", "provider", "azuredevops", "repo", azureRepo, "error", err)
+		} else {
+			commits = append(commits, convertAzureCommits(azCommits)...)
+			slog.Info("Fetched commits", "provider", "azuredevops", "repo", azureRepo, "count", len(azCommits), "cached", cached)
+		}
+
+		slog.Info("Fetching pull requests", "provider", "azuredevops", "repo", azureRepo)
+		var azPRs []azuredevops.PullRequest
+		cached, err = diskCache.Fetch(cache.Key("azuredevops", azureRepo, "prs", cfg), &azPRs, func() (err error) {
+			azPRs, err = azClient.WithProgress(progress("pull requests", "provider", "azuredevops", "repo", azureRepo)).FetchPRs(ctx)
+			return err
+		})
+		if err != nil {
+			slog.Error("Error fetching pull requests", "provider", "azuredevops", "repo", azureRepo, "error", err)
+		} else {
+			prs = append(prs, convertAzurePRs(azPRs)...)
+			slog.Info("Fetched pull requests", "provider", "azuredevops", "repo", azureRepo, "count", len(azPRs), "cached", cached)
 		}
 	}
 
 	// Fetch Jira data
 	if hasJira {
-		jClient := jira.NewClient(cfg)
-		fmt.Println("🔄 Fetching Jira issues...")
-		stories, err = jClient.FetchIssues()
-		if err != nil {
-			log.Printf("❌ Error fetching Jira issues: %v", err)
+		jClient := jira.NewClient(cfg).WithStats(recorder)
+		slog.Info("Fetching issues", "provider", "jira", "project", cfg.JiraProject)
+		cached, err := diskCache.Fetch(cache.Key("jira", cfg.JiraProject, "issues", cfg), &stories, func() (err error) {
+			stories, err = jClient.WithProgress(progress("issues", "provider", "jira", "project", cfg.JiraProject)).FetchIssues(ctx)
+			return err
+		})
+		var apiErr *jira.APIError
+		if errors.As(err, &apiErr) && apiErr.Unauthorized() {
+			slog.Error("Jira rejected the credentials; check JIRA_USERNAME, JIRA_TOKEN and JIRA_IS_CLOUD", "provider", "jira", "status", apiErr.StatusCode)
+			stories = []jira.JiraStory{}
+		} else if err != nil {
+			slog.Error("Error fetching issues", "provider", "jira", "project", cfg.JiraProject, "error", err)
 			stories = []jira.JiraStory{}
 		} else {
-			fmt.Printf("✅ Fetched %d Jira stories\n", len(stories))
+			slog.Info("Fetched issues", "provider", "jira", "project", cfg.JiraProject, "count", len(stories), "cached", cached)
 		}
 	}
 
+	// Assign authors to teams from GitHub org membership, falling back to author_teams
+	if cfg.GitHubURL != "" && len(cfg.GitHubTeams) > 0 && artifactPath == "" {
+		slog.Info("Fetching team memberships", "provider", "github", "teams", len(cfg.GitHubTeams))
+		memberships := github.NewClient(cfg).WithStats(recorder).FetchTeamMemberships(ctx, cfg.GitHubTeams)
+		cfg = cfg.WithTeamMemberships(memberships)
+		slog.Info("Assigned users to teams", "provider", "github", "count", len(memberships))
+	}
+
 	// Calculate metrics
-	fmt.Println("\n📊 Calculating metrics...")
-	teamMetrics := metrics.CalculateTeamMetrics(commits, prs, stories)
+	slog.Info("Calculating metrics", "commits", len(commits), "prs", len(prs), "stories", len(stories), "deployments", len(deployments))
+	teamMetrics := metrics.CalculateTeamMetrics(commits, prs, stories, deployments, cfg)
+	teamMetrics.Truncated = recorder.TruncatedFetches()
+	teamMetrics.Providers = providers
 
 	// Print summary
-	report.PrintMetricsSummary(teamMetrics)
+	numberFormat := report.NumberFormatFor(cfg.NumberLocale)
+	report.PrintMetricsSummary(teamMetrics, numberFormat)
+	report.PrintFetchStats(recorder.Snapshot())
+
+	// Export to every configured sink
+	sinkSpecs := cfg.Sinks
+	if len(sinkSpecs) == 0 {
+		sinkSpecs = report.DefaultSinks
+	}
+	if exportFormat != "" {
+		sinkSpecs = append(sinkSpecs, "file:metrics."+exportFormat)
+	}
+	httpOptions := report.HTTPOptions{
+		Headers: cfg.HTTPSinkHeaders,
+		Retries: cfg.HTTPSinkRetries,
+		Timeout: time.Duration(cfg.HTTPSinkTimeoutSeconds) * time.Second,
+	}
+	var sinks []report.Sink
+	for _, spec := range sinkSpecs {
+		sink, err := report.NewSink(spec, numberFormat, httpOptions)
+		if err != nil {
+			slog.Error("Error configuring sink", "sink", spec, "error", err)
+			continue
+		}
+		sinks = append(sinks, sink)
+	}
+
+	sinkErrors := report.WriteAll(sinks, teamMetrics)
+	for _, sink := range sinks {
+		if err, failed := sinkErrors[sink.Name()]; failed {
+			slog.Error("Error exporting metrics", "sink", sink.Name(), "error", err)
+		} else {
+			slog.Info("Metrics exported", "sink", sink.Name())
+		}
+	}
+
+	if cfg.WebhookURL != "" {
+		webhookOptions := httpOptions
+		webhookOptions.Headers = cfg.WebhookHeaders
+		if err := report.PostToWebhook(teamMetrics, cfg.WebhookURL, webhookOptions); err != nil {
+			slog.Error("Error posting metrics to webhook", "error", err)
+		} else {
+			slog.Info("Metrics posted to webhook")
+		}
+	}
+	if cfg.SlackWebhookURL != "" {
+		if err := report.PostToSlack(teamMetrics, cfg.SlackWebhookURL); err != nil {
+			slog.Error("Error posting metrics digest to Slack", "error", err)
+		} else {
+			slog.Info("Metrics digest posted to Slack")
+		}
+	}
+
+	if cfg.RawCommitsFile != "" {
+		// Without enrichment no PRs are linked, so every commit is exported as-is
+		linkedPRs := prs
+		if !cfg.EnrichCommits {
+			linkedPRs = nil
+		}
+		if err := report.ExportCommits(metrics.EnrichCommits(commits, linkedPRs), cfg.RawCommitsFile); err != nil {
+			slog.Error("Error exporting raw commits", "file", cfg.RawCommitsFile, "error", err)
+		} else {
+			slog.Info("Raw commits exported", "file", cfg.RawCommitsFile, "count", len(commits))
+		}
+	}
+
+	if cfg.TrendsFile != "" {
+		trends := metrics.CalculateWeeklyTrends(commits, prs, stories, 0, cfg)
+		if err := report.ExportTrends(trends, cfg.TrendsFile); err != nil {
+			slog.Error("Error exporting weekly trends", "file", cfg.TrendsFile, "error", err)
+		} else {
+			slog.Info("Weekly trends exported", "file", cfg.TrendsFile, "weeks", len(trends))
+		}
+	}
 
-	// Export to files
-	if err := report.ExportToJSON(teamMetrics, "metrics.json"); err != nil {
-		log.Printf("Error exporting to JSON: %v", err)
-	} else {
-		fmt.Println("\n✅ Metrics exported to: metrics.json")
+	if save {
+		if err := saveSnapshot(teamMetrics, cfg.SnapshotPath()); err != nil {
+			slog.Error("Error saving snapshot", "file", cfg.SnapshotPath(), "error", err)
+		} else {
+			slog.Info("Snapshot saved", "file", cfg.SnapshotPath())
+		}
 	}
 
-	if err := report.ExportToCSV(teamMetrics, "metrics.csv"); err != nil {
-		log.Printf("Error exporting to CSV: %v", err)
-	} else {
-		fmt.Println("✅ Metrics exported to: metrics.csv")
+	if commitTo != "" {
+		committed, err := report.CommitSnapshot(teamMetrics, commitTo)
+		if err != nil {
+			slog.Error("Error committing report snapshot", "repo", commitTo, "error", err)
+		} else if committed {
+			slog.Info("Report snapshot committed", "repo", commitTo)
+		} else {
+			slog.Info("Report unchanged, nothing committed", "repo", commitTo)
+		}
 	}
 
 	fmt.Println("\n🎉 Analysis complete!")
@@ -182,4 +560,264 @@ func main() {
 	fmt.Println("- Import metrics.csv into spreadsheet for visualization")
 	fmt.Println("- Schedule this script to run periodically for tracking trends")
 	fmt.Println("- Run with --server to start the web API")
-}
\ No newline at end of file
+}
+
+// fatal logs msg with its attributes at error level and exits
+func fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
+}
+
+// progress returns a fetch progress callback that logs the running count of kind, tagged with args
+func progress(kind string, args ...any) func(fetched int) {
+	return func(fetched int) {
+		slog.Info("Fetching "+kind, append(args, "count", fetched)...)
+	}
+}
+
+// convertGitLabCommits converts GitLab commits to the Bitbucket shape used for metrics calculation
+func convertGitLabCommits(glCommits []gitlab.Commit) []bitbucket.Commit {
+	commits := make([]bitbucket.Commit, 0, len(glCommits))
+	for _, c := range glCommits {
+		commits = append(commits, bitbucket.Commit{
+			Hash:         c.Hash,
+			Author:       c.Author,
+			Date:         c.Date,
+			Message:      c.Message,
+			LinesAdded:   c.LinesAdded,
+			LinesDeleted: c.LinesDeleted,
+			Repo:         c.Repo,
+		})
+	}
+	return commits
+}
+
+// convertGitLabPRs converts GitLab merge requests to the Bitbucket shape used for metrics calculation
+func convertGitLabPRs(glPRs []gitlab.PullRequest) []bitbucket.PullRequest {
+	prs := make([]bitbucket.PullRequest, 0, len(glPRs))
+	for _, p := range glPRs {
+		prs = append(prs, bitbucket.PullRequest{
+			ID:            p.ID,
+			Author:        p.Author,
+			CreatedAt:     p.CreatedAt,
+			MergedAt:      p.MergedAt,
+			ClosedAt:      p.ClosedAt,
+			FirstReviewAt: p.FirstReviewAt,
+			LinesChanged:  p.LinesChanged,
+			Reviewers:     p.Reviewers,
+			CommentCount:  p.CommentCount,
+			Approvers:     p.Approvers,
+			MergedBy:      p.MergedBy,
+			BaseBranch:    p.BaseBranch,
+			Title:         p.Title,
+			Labels:        p.Labels,
+			CommitHashes:  p.CommitHashes,
+			Repo:          p.Repo,
+			UpdatedAt:     p.UpdatedAt,
+			Status:        p.Status,
+		})
+	}
+	return prs
+}
+
+// convertAzureCommits converts Azure DevOps commits to the Bitbucket shape used for metrics calculation
+func convertAzureCommits(azCommits []azuredevops.Commit) []bitbucket.Commit {
+	commits := make([]bitbucket.Commit, 0, len(azCommits))
+	for _, c := range azCommits {
+		commits = append(commits, bitbucket.Commit{
+			Hash:         c.Hash,
+			Author:       c.Author,
+			Date:         c.Date,
+			Message:      c.Message,
+			LinesAdded:   c.LinesAdded,
+			LinesDeleted: c.LinesDeleted,
+			Repo:         c.Repo,
+		})
+	}
+	return commits
+}
+
+// convertAzurePRs converts Azure DevOps pull requests to the Bitbucket shape used for metrics calculation
+func convertAzurePRs(azPRs []azuredevops.PullRequest) []bitbucket.PullRequest {
+	prs := make([]bitbucket.PullRequest, 0, len(azPRs))
+	for _, p := range azPRs {
+		prs = append(prs, bitbucket.PullRequest{
+			ID:            p.ID,
+			Author:        p.Author,
+			CreatedAt:     p.CreatedAt,
+			MergedAt:      p.MergedAt,
+			ClosedAt:      p.ClosedAt,
+			FirstReviewAt: p.FirstReviewAt,
+			LinesChanged:  p.LinesChanged,
+			Reviewers:     p.Reviewers,
+			CommentCount:  p.CommentCount,
+			Approvers:     p.Approvers,
+			MergedBy:      p.MergedBy,
+			BaseBranch:    p.BaseBranch,
+			Title:         p.Title,
+			Labels:        p.Labels,
+			CommitHashes:  p.CommitHashes,
+			Repo:          p.Repo,
+			UpdatedAt:     p.UpdatedAt,
+			Status:        p.Status,
+		})
+	}
+	return prs
+}
+
+// convertGitHubPRs converts GitHub PRs to the Bitbucket format used for metrics calculation
+func convertGitHubPRs(ghPRs []github.PullRequest) []bitbucket.PullRequest {
+	prs := make([]bitbucket.PullRequest, 0, len(ghPRs))
+	for _, p := range ghPRs {
+		prs = append(prs, bitbucket.PullRequest{
+			ID:             p.ID,
+			Author:         p.Author,
+			CreatedAt:      p.CreatedAt,
+			MergedAt:       p.MergedAt,
+			ClosedAt:       p.ClosedAt,
+			FirstReviewAt:  p.FirstReviewAt,
+			LinesChanged:   p.LinesChanged,
+			LinesIgnored:   p.LinesIgnored,
+			Reviewers:      p.Reviewers,
+			CommentCount:   p.CommentCount,
+			ReviewCycles:   p.ReviewCycles,
+			Approvers:      p.Approvers,
+			MergedBy:       p.MergedBy,
+			DraftHours:     p.DraftHours,
+			ReviewComments: p.ReviewComments,
+			BaseBranch:     p.BaseBranch,
+			Title:          p.Title,
+			Labels:         p.Labels,
+			CommitHashes:   p.CommitHashes,
+			Repo:           p.Repo,
+			UpdatedAt:      p.UpdatedAt,
+			FirstCommitAt:  p.FirstCommitAt,
+			Status:         p.Status,
+		})
+	}
+	return prs
+}
+
+// fetchSelected fetches an explicit set of PRs from every configured PR provider and an
+// explicit set of Jira issues, ignoring the date window
+func fetchSelected(ctx context.Context, cfg config.Config, recorder *fetchstats.Recorder, hasBitbucket, hasGitHub, hasJira bool, prIDs []int, issueKeys []string) ([]bitbucket.PullRequest, []jira.JiraStory) {
+	prs := []bitbucket.PullRequest{}
+	stories := []jira.JiraStory{}
+
+	if len(prIDs) > 0 && hasBitbucket {
+		slog.Info("Fetching selected pull requests", "provider", "bitbucket", "count", len(prIDs))
+		bbPRs, err := bitbucket.NewClient(cfg).WithStats(recorder).FetchPRsByID(ctx, prIDs)
+		if err != nil {
+			slog.Error("Error fetching pull requests", "provider", "bitbucket", "error", err)
+		} else {
+			prs = append(prs, bbPRs...)
+			slog.Info("Fetched pull requests", "provider", "bitbucket", "count", len(bbPRs))
+		}
+	}
+
+	if len(prIDs) > 0 && hasGitHub {
+		slog.Info("Fetching selected pull requests", "provider", "github", "count", len(prIDs))
+		ghPRs, err := github.NewClient(cfg).WithStats(recorder).FetchPRsByID(ctx, prIDs)
+		if err != nil {
+			slog.Error("Error fetching pull requests", "provider", "github", "error", err)
+		} else {
+			prs = append(prs, convertGitHubPRs(ghPRs)...)
+			slog.Info("Fetched pull requests", "provider", "github", "count", len(ghPRs))
+		}
+	}
+
+	if len(issueKeys) > 0 && hasJira {
+		slog.Info("Fetching selected issues", "provider", "jira", "count", len(issueKeys))
+		selected, err := jira.NewClient(cfg).WithStats(recorder).FetchIssuesByKey(ctx, issueKeys)
+		if err != nil {
+			slog.Error("Error fetching issues", "provider", "jira", "error", err)
+		} else {
+			stories = selected
+			slog.Info("Fetched issues", "provider", "jira", "count", len(stories))
+		}
+	}
+
+	return prs, stories
+}
+
+// saveSnapshot appends the run's metrics to the SQLite snapshot database at path
+func saveSnapshot(m metrics.TeamMetrics, path string) error {
+	store, err := storage.Open(path)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+	return store.SaveSnapshot(m)
+}
+
+// knownProviders are the names accepted by -providers
+var knownProviders = []string{"bitbucket", "github", "gitlab", "azuredevops", "jira"}
+
+// parseProviders parses a comma-separated -providers value into a set, rejecting unknown names
+func parseProviders(list string) (map[string]bool, error) {
+	selected := make(map[string]bool)
+	for _, name := range strings.Split(list, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		known := false
+		for _, k := range knownProviders {
+			if name == k {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return nil, fmt.Errorf("unknown provider %q: use %s", name, strings.Join(knownProviders, ", "))
+		}
+		selected[name] = true
+	}
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("no providers given")
+	}
+	return selected, nil
+}
+
+// readList combines a comma-separated flag value with a file of one entry per line.
+// Blank lines and lines starting with # are ignored.
+func readList(inline, file string) ([]string, error) {
+	var items []string
+	for _, item := range strings.Split(inline, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	if file != "" {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		for _, line := range strings.Split(string(data), "\n") {
+			if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "#") {
+				items = append(items, line)
+			}
+		}
+	}
+
+	return items, nil
+}
+
+// readPRNumbers reads PR numbers, accepting both "42" and "PR-42"
+func readPRNumbers(inline, file string) ([]int, error) {
+	items, err := readList(inline, file)
+	if err != nil {
+		return nil, err
+	}
+
+	numbers := make([]int, 0, len(items))
+	for _, item := range items {
+		n, err := strconv.Atoi(strings.TrimPrefix(strings.TrimPrefix(item, "PR-"), "#"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid PR number %q", item)
+		}
+		numbers = append(numbers, n)
+	}
+	return numbers, nil
+}
@@ -0,0 +1,315 @@
+package metrics
+
+import (
+	"math"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"devops-metrics/bitbucket"
+	"devops-metrics/config"
+	"devops-metrics/jira"
+)
+
+// globEscaper keeps brackets literal so "*[bot]" matches the suffix rather than one of b, o, t
+var globEscaper = strings.NewReplacer(`[`, `\[`, `]`, `\]`)
+
+// authorExcluder reports whether an author is left out of the metrics by ExcludeAuthors or
+// ExcludeAuthorPatterns. Filtering happens here rather than in the clients, so fetched and
+// cached data stays complete and changing the lists needs no refetch.
+type authorExcluder struct {
+	names    []string
+	suffixes []string
+	globs    []string
+	patterns []*regexp.Regexp
+}
+
+// newAuthorExcluder prepares the configured exclusions. Entries of ExcludeAuthors with * or ?
+// are globs, and bracketed entries such as "[bot]" match as a suffix, so "[bot]" drops every bot
+// account. The others match an author exactly, so "dan" keeps "jordan". Invalid patterns are
+// skipped; Validate reports them.
+func newAuthorExcluder(cfg config.Config) authorExcluder {
+	var e authorExcluder
+	for _, entry := range cfg.ExcludeAuthors {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		switch {
+		case entry == "":
+		case strings.ContainsAny(entry, "*?"):
+			e.globs = append(e.globs, globEscaper.Replace(entry))
+		case strings.HasPrefix(entry, "[") && strings.HasSuffix(entry, "]"):
+			e.suffixes = append(e.suffixes, entry)
+		default:
+			e.names = append(e.names, entry)
+		}
+	}
+	for _, pattern := range cfg.ExcludeAuthorPatterns {
+		if re, err := regexp.Compile(pattern); err == nil {
+			e.patterns = append(e.patterns, re)
+		}
+	}
+	return e
+}
+
+func (e authorExcluder) empty() bool {
+	return len(e.names) == 0 && len(e.suffixes) == 0 && len(e.globs) == 0 && len(e.patterns) == 0
+}
+
+// excluded reports whether author matches any exclusion
+func (e authorExcluder) excluded(author string) bool {
+	lower := strings.ToLower(author)
+	for _, name := range e.names {
+		if lower == name {
+			return true
+		}
+	}
+	for _, suffix := range e.suffixes {
+		if strings.HasSuffix(lower, suffix) {
+			return true
+		}
+	}
+	for _, glob := range e.globs {
+		if ok, _ := path.Match(glob, lower); ok {
+			return true
+		}
+	}
+	for _, re := range e.patterns {
+		if re.MatchString(author) {
+			return true
+		}
+	}
+	return false
+}
+
+// excludeCommitAuthors returns commits without those by excluded authors
+func excludeCommitAuthors(commits []bitbucket.Commit, cfg config.Config) []bitbucket.Commit {
+	e := newAuthorExcluder(cfg)
+	if e.empty() {
+		return commits
+	}
+	kept := make([]bitbucket.Commit, 0, len(commits))
+	for _, c := range commits {
+		if !e.excluded(c.Author) {
+			kept = append(kept, c)
+		}
+	}
+	return kept
+}
+
+// excludePRAuthors returns PRs without those opened by excluded authors
+func excludePRAuthors(prs []bitbucket.PullRequest, cfg config.Config) []bitbucket.PullRequest {
+	e := newAuthorExcluder(cfg)
+	if e.empty() {
+		return prs
+	}
+	kept := make([]bitbucket.PullRequest, 0, len(prs))
+	for _, pr := range prs {
+		if !e.excluded(pr.Author) {
+			kept = append(kept, pr)
+		}
+	}
+	return kept
+}
+
+// AuthorMetrics holds one person's activity and individual metrics across providers
+type AuthorMetrics struct {
+	Author              string                  `json:"author"`
+	Names               []string                `json:"names"` // Names the person was matched under, from AuthorAliases
+	CommitCount         int                     `json:"commit_count"`
+	LinesAdded          int                     `json:"lines_added"`
+	LinesDeleted        int                     `json:"lines_deleted"`
+	PRCount             int                     `json:"pr_count"`
+	MergedPRs           int                     `json:"merged_prs"`
+	AvgPRCycleTimeHours float64                 `json:"avg_pr_cycle_time_hours"`
+	PRsReviewed         int                     `json:"prs_reviewed"`
+	StoriesAssigned     int                     `json:"stories_assigned"`
+	StoriesCompleted    int                     `json:"stories_completed"`
+	Commits             []bitbucket.Commit      `json:"commits"`
+	PullRequests        []bitbucket.PullRequest `json:"pull_requests"`
+	Stories             []jira.JiraStory        `json:"stories"`
+}
+
+// HasActivity reports whether the author made any commit, PR or review or was assigned a story
+func (m AuthorMetrics) HasActivity() bool {
+	return m.CommitCount > 0 || m.PRCount > 0 || m.PRsReviewed > 0 || m.StoriesAssigned > 0
+}
+
+// authorIndex maps lower-cased names to their canonical name in AuthorAliases
+type authorIndex map[string]string
+
+// newAuthorIndex inverts aliases (canonical name: aliases). Canonical names map to themselves
+// ahead of any alias, and canonicals are indexed in sorted order so a name listed under two of
+// them, which Validate rejects, still resolves the same way on every run.
+func newAuthorIndex(aliases map[string][]string) authorIndex {
+	canonicals := make([]string, 0, len(aliases))
+	for canonical := range aliases {
+		canonicals = append(canonicals, canonical)
+	}
+	sort.Strings(canonicals)
+
+	index := make(authorIndex, len(aliases))
+	for _, canonical := range canonicals {
+		if _, ok := index[strings.ToLower(canonical)]; !ok {
+			index[strings.ToLower(canonical)] = canonical
+		}
+	}
+	for _, canonical := range canonicals {
+		for _, alias := range aliases[canonical] {
+			if _, ok := index[strings.ToLower(alias)]; !ok {
+				index[strings.ToLower(alias)] = canonical
+			}
+		}
+	}
+	return index
+}
+
+// canonical returns the canonical name for name, or name when it is not mapped
+func (x authorIndex) canonical(name string) string {
+	if canonical, ok := x[strings.ToLower(name)]; ok {
+		return canonical
+	}
+	return name
+}
+
+// CanonicalAuthor returns the canonical name in aliases (canonical name: aliases) that name is
+// or is an alias of, compared case-insensitively. Unmapped names are returned unchanged.
+func CanonicalAuthor(name string, aliases map[string][]string) string {
+	return newAuthorIndex(aliases).canonical(name)
+}
+
+// canonicalCommitAuthors returns commits with their authors replaced by canonical names
+func canonicalCommitAuthors(commits []bitbucket.Commit, aliases map[string][]string) []bitbucket.Commit {
+	if len(aliases) == 0 {
+		return commits
+	}
+	index := newAuthorIndex(aliases)
+	result := make([]bitbucket.Commit, len(commits))
+	for i, c := range commits {
+		c.Author = index.canonical(c.Author)
+		result[i] = c
+	}
+	return result
+}
+
+// canonicalPRAuthors returns PRs with their author, merger, reviewers and approvers replaced by
+// canonical names, so self-merges and review pairs are recognised across identities
+func canonicalPRAuthors(prs []bitbucket.PullRequest, aliases map[string][]string) []bitbucket.PullRequest {
+	if len(aliases) == 0 {
+		return prs
+	}
+	index := newAuthorIndex(aliases)
+	canonical := func(names []string) []string {
+		if names == nil {
+			return nil
+		}
+		result := make([]string, len(names))
+		for i, name := range names {
+			result[i] = index.canonical(name)
+		}
+		return result
+	}
+	result := make([]bitbucket.PullRequest, len(prs))
+	for i, pr := range prs {
+		pr.Author = index.canonical(pr.Author)
+		pr.MergedBy = index.canonical(pr.MergedBy)
+		pr.Reviewers = canonical(pr.Reviewers)
+		pr.Approvers = canonical(pr.Approvers)
+		result[i] = pr
+	}
+	return result
+}
+
+// canonicalAssignees returns stories with their assignees replaced by canonical names
+func canonicalAssignees(stories []jira.JiraStory, aliases map[string][]string) []jira.JiraStory {
+	if len(aliases) == 0 {
+		return stories
+	}
+	index := newAuthorIndex(aliases)
+	result := make([]jira.JiraStory, len(stories))
+	for i, s := range stories {
+		s.Assignee = index.canonical(s.Assignee)
+		result[i] = s
+	}
+	return result
+}
+
+// authorNames returns the names name is known by: name itself plus, when it is a canonical
+// name or an alias in aliases, the canonical name and all its aliases
+func authorNames(name string, aliases map[string][]string) []string {
+	canonical, ok := newAuthorIndex(aliases)[strings.ToLower(name)]
+	if !ok {
+		return []string{name}
+	}
+	return append([]string{canonical}, aliases[canonical]...)
+}
+
+// CalculateAuthorMetrics collects the commits, PRs and assigned stories of one person, matched
+// case-insensitively under any of their AuthorAliases, and computes their individual metrics.
+// PR cycle time follows CalculatePRMetrics, including ExcludeDraftTime.
+func CalculateAuthorMetrics(name string, commits []bitbucket.Commit, prs []bitbucket.PullRequest, stories []jira.JiraStory, cfg config.Config) AuthorMetrics {
+	names := authorNames(name, cfg.AuthorAliases)
+	matches := func(author string) bool {
+		for _, n := range names {
+			if strings.EqualFold(author, n) {
+				return true
+			}
+		}
+		return false
+	}
+
+	metrics := AuthorMetrics{
+		Author:       names[0],
+		Names:        names,
+		Commits:      []bitbucket.Commit{},
+		PullRequests: []bitbucket.PullRequest{},
+		Stories:      []jira.JiraStory{},
+	}
+
+	for _, c := range commits {
+		if matches(c.Author) {
+			metrics.Commits = append(metrics.Commits, c)
+			metrics.LinesAdded += c.LinesAdded
+			metrics.LinesDeleted += c.LinesDeleted
+		}
+	}
+	metrics.CommitCount = len(metrics.Commits)
+
+	var totalCycleTime float64
+	for _, pr := range prs {
+		for _, reviewer := range pr.Reviewers {
+			if matches(reviewer) && !matches(pr.Author) {
+				metrics.PRsReviewed++
+				break
+			}
+		}
+		if !matches(pr.Author) {
+			continue
+		}
+		metrics.PullRequests = append(metrics.PullRequests, pr)
+		if pr.MergedAt != nil {
+			cycleTime := pr.MergedAt.Sub(pr.CreatedAt).Hours()
+			if cfg.ExcludeDraftTime {
+				cycleTime = math.Max(cycleTime-pr.DraftHours, 0)
+			}
+			totalCycleTime += cycleTime
+			metrics.MergedPRs++
+		}
+	}
+	metrics.PRCount = len(metrics.PullRequests)
+	if metrics.MergedPRs > 0 {
+		metrics.AvgPRCycleTimeHours = totalCycleTime / float64(metrics.MergedPRs)
+	}
+
+	for _, s := range stories {
+		if !matches(s.Assignee) {
+			continue
+		}
+		metrics.Stories = append(metrics.Stories, s)
+		if isCompletedStatus(s.Status) {
+			metrics.StoriesCompleted++
+		}
+	}
+	metrics.StoriesAssigned = len(metrics.Stories)
+
+	return metrics
+}
@@ -0,0 +1,119 @@
+package metrics
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"devops-metrics/bitbucket"
+	"devops-metrics/config"
+	"devops-metrics/jira"
+)
+
+func TestAuthorExcluder(t *testing.T) {
+	tests := []struct {
+		name     string
+		authors  []string
+		patterns []string
+		author   string
+		want     bool
+	}{
+		{"bot suffix", []string{"[bot]"}, nil, "dependabot[bot]", true},
+		{"bot suffix case-insensitive", []string{"[BOT]"}, nil, "Renovate[bot]", true},
+		{"bot suffix keeps people", []string{"[bot]"}, nil, "dan", false},
+		{"exact name", []string{"dan"}, nil, "Dan", true},
+		{"exact name is not a suffix", []string{"dan"}, nil, "jordan", false},
+		{"glob", []string{"renovate*"}, nil, "renovate-bot", true},
+		{"glob brackets are literal", []string{"*[bot]"}, nil, "github-actions[bot]", true},
+		{"custom pattern", nil, []string{"^svc-"}, "svc-deploy", true},
+		{"custom pattern no match", nil, []string{"^svc-"}, "alice-svc-", false},
+		{"invalid pattern skipped", nil, []string{"("}, "alice", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newAuthorExcluder(config.Config{ExcludeAuthors: tt.authors, ExcludeAuthorPatterns: tt.patterns})
+			if got := e.excluded(tt.author); got != tt.want {
+				t.Errorf("excluded(%q) = %v, want %v", tt.author, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCanonicalAuthor(t *testing.T) {
+	aliases := map[string][]string{
+		"John Doe":  {"jdoe", "john-doe"},
+		"Ann Smith": {"asmith"},
+	}
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"jdoe", "John Doe"},
+		{"john-doe", "John Doe"},
+		{"JDoe", "John Doe"},
+		{"John Doe", "John Doe"},
+		{"asmith", "Ann Smith"},
+		{"bob", "bob"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CanonicalAuthor(tt.name, aliases); got != tt.want {
+				t.Errorf("CanonicalAuthor(%q) = %q, want %q", tt.name, got, tt.want)
+			}
+		})
+	}
+	// A differently-cased canonical name merges too, and an alias listed under two people
+	// (which Validate rejects) always resolves to the first canonical in sorted order
+	ambiguous := map[string][]string{"Zoe": {"zed", "shared"}, "Alice": {"shared"}, "Mike": {"alice"}}
+	for i := 0; i < 20; i++ {
+		if got := CanonicalAuthor("ALICE", ambiguous); got != "Alice" {
+			t.Fatalf("CanonicalAuthor(ALICE) = %q, want Alice", got)
+		}
+		if got := CanonicalAuthor("shared", ambiguous); got != "Alice" {
+			t.Fatalf("CanonicalAuthor(shared) = %q, want Alice", got)
+		}
+	}
+	if got := CanonicalAuthor("jdoe", nil); got != "jdoe" {
+		t.Errorf("CanonicalAuthor without aliases = %q, want jdoe", got)
+	}
+}
+
+func TestAuthorAliasesCollapse(t *testing.T) {
+	cfg := config.Config{AuthorAliases: map[string][]string{"John Doe": {"jdoe", "john-doe"}}}
+	day := func(d int) *time.Time {
+		t := benchmarkStart.AddDate(0, 0, d)
+		return &t
+	}
+
+	// One identity per provider: jdoe in Bitbucket, john-doe in GitHub, John Doe in Jira
+	commits := []bitbucket.Commit{
+		{Author: "jdoe", Date: *day(0)},
+		{Author: "john-doe", Date: *day(1)},
+		{Author: "John Doe", Date: *day(2)},
+		{Author: "bob", Date: *day(3)},
+	}
+	prs := []bitbucket.PullRequest{
+		{ID: "PR-1", Author: "jdoe", Status: "MERGED", CreatedAt: *day(0), MergedAt: day(1)},
+		{ID: "PR-2", Author: "john-doe", Status: "MERGED", CreatedAt: *day(1), MergedAt: day(2)},
+		{ID: "PR-3", Author: "John Doe", Status: "OPEN", CreatedAt: *day(2)},
+		{ID: "PR-4", Author: "bob", Status: "OPEN", CreatedAt: *day(3)},
+	}
+	stories := []jira.JiraStory{
+		{Key: "P-1", Assignee: "jdoe", Status: "Done", CreatedAt: *day(0), CompletedAt: day(2)},
+		{Key: "P-2", Assignee: "john-doe", Status: "Done", CreatedAt: *day(1), CompletedAt: day(3)},
+		{Key: "P-3", Assignee: "John Doe", Status: "To Do", CreatedAt: *day(2)},
+		{Key: "P-4", Assignee: "bob", Status: "To Do", CreatedAt: *day(3)},
+	}
+	want := "map[John Doe:3 bob:1]"
+
+	if got := CalculateCommitMetrics(commits, cfg).CommitsByAuthor; fmt.Sprint(got) != want {
+		t.Errorf("CommitsByAuthor = %v, want %s", got, want)
+	}
+	if got := CalculatePRMetrics(prs, cfg).PRsByAuthor; fmt.Sprint(got) != want {
+		t.Errorf("PRsByAuthor = %v, want %s", got, want)
+	}
+	if got := CalculateJiraMetrics(stories, cfg).StoriesByAssignee; fmt.Sprint(got) != want {
+		t.Errorf("StoriesByAssignee = %v, want %s", got, want)
+	}
+}
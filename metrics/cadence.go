@@ -0,0 +1,97 @@
+package metrics
+
+import (
+	"sort"
+	"time"
+
+	"devops-metrics/bitbucket"
+	"devops-metrics/config"
+)
+
+// CommitGap describes the spacing between an author's consecutive commits
+type CommitGap struct {
+	Gaps        int     `json:"gaps"`
+	AvgHours    float64 `json:"avg_hours"`
+	MedianHours float64 `json:"median_hours"`
+}
+
+// calculateCommitGaps sorts each author's commit times and measures the gaps between
+// consecutive commits. Commits seen on several branches are counted once.
+// It returns the per-author gaps and the average over all gaps.
+func calculateCommitGaps(commits []bitbucket.Commit, cfg config.Config) (map[string]CommitGap, float64) {
+	seen := make(map[string]bool)
+	timesByAuthor := make(map[string][]time.Time)
+	for _, c := range commits {
+		if c.Hash != "" {
+			if seen[c.Hash] {
+				continue
+			}
+			seen[c.Hash] = true
+		}
+		author := cfg.AuthorOrFallback(c.Author, "")
+		timesByAuthor[author] = append(timesByAuthor[author], c.Date)
+	}
+
+	byAuthor := make(map[string]CommitGap)
+	var total float64
+	var count int
+	for author, times := range timesByAuthor {
+		if len(times) < 2 {
+			continue
+		}
+		sort.Slice(times, func(i, j int) bool { return times[i].Before(times[j]) })
+
+		gaps := make([]float64, 0, len(times)-1)
+		var sum float64
+		for i := 1; i < len(times); i++ {
+			gap := times[i].Sub(times[i-1]).Hours()
+			gaps = append(gaps, gap)
+			sum += gap
+		}
+
+		byAuthor[author] = CommitGap{
+			Gaps:        len(gaps),
+			AvgHours:    sum / float64(len(gaps)),
+			MedianHours: median(gaps),
+		}
+		total += sum
+		count += len(gaps)
+	}
+
+	if count == 0 {
+		return byAuthor, 0
+	}
+	return byAuthor, total / float64(count)
+}
+
+// median returns the middle value of values (the mean of the two middle values for an even count)
+func median(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
+
+// percentile returns the p-th percentile (0-100) of values, interpolating linearly between
+// the two closest ranks; it returns 0 for no values
+func percentile(values []float64, p float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	rank := p / 100 * float64(len(sorted)-1)
+	lower := int(rank)
+	if lower >= len(sorted)-1 {
+		return sorted[len(sorted)-1]
+	}
+	return sorted[lower] + (rank-float64(lower))*(sorted[lower+1]-sorted[lower])
+}
@@ -0,0 +1,36 @@
+package metrics
+
+import "testing"
+
+func TestPercentile(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		p      float64
+		want   float64
+	}{
+		{"empty", nil, 50, 0},
+		{"single value", []float64{7}, 90, 7},
+		{"odd count median", []float64{5, 1, 3}, 50, 3},
+		{"even count median interpolates", []float64{4, 1, 3, 2}, 50, 2.5},
+		{"p90 interpolates between the top two", []float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, 90, 9.1},
+		{"p90 on an exact rank", []float64{10, 20, 30, 40, 50, 60, 70, 80, 90, 100, 110}, 90, 100},
+		{"p100 is the maximum", []float64{3, 9, 1}, 100, 9},
+		{"p0 is the minimum", []float64{3, 9, 1}, 0, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := percentile(tt.values, tt.p); abs(got-tt.want) > 1e-9 {
+				t.Errorf("percentile(%v, %v) = %v, want %v", tt.values, tt.p, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPercentileLeavesInputUnsorted(t *testing.T) {
+	values := []float64{3, 1, 2}
+	percentile(values, 50)
+	if values[0] != 3 || values[1] != 1 || values[2] != 2 {
+		t.Errorf("percentile reordered its input to %v", values)
+	}
+}
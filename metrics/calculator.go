@@ -2,89 +2,226 @@ package metrics
 
 import (
 	"fmt"
+	"math"
+	"sort"
 	"strings"
 	"time"
+
 	"devops-metrics/bitbucket"
+	"devops-metrics/config"
+	"devops-metrics/github"
 	"devops-metrics/jira"
 )
 
+// excessiveReviewCycles is the number of review rounds at which a PR is flagged
+const excessiveReviewCycles = 3
+
 // Metric structures
 type CommitMetrics struct {
-	TotalCommits      int            `json:"total_commits"`
-	CommitsPerDay     float64        `json:"commits_per_day"`
-	CommitsByAuthor   map[string]int `json:"commits_by_author"`
-	CommitsByWeekday  map[string]int `json:"commits_by_weekday"`
-	TotalLinesAdded   int            `json:"total_lines_added"`
-	TotalLinesDeleted int            `json:"total_lines_deleted"`
-	ActiveDays        int            `json:"active_days"`
-	DateRange         string         `json:"date_range"`
+	TotalCommits           int            `json:"total_commits"`
+	CommitsPerDay          float64        `json:"commits_per_day"`
+	CommitsByAuthor        map[string]int `json:"commits_by_author"`
+	CommitsByWeekday       map[string]int `json:"commits_by_weekday"`
+	CommitsByHour          map[int]int    `json:"commits_by_hour"` // Hour of day (0-23) in the report zone
+	CommitsByType          map[string]int `json:"commits_by_type"`
+	ConventionalCommitRate float64        `json:"conventional_commit_rate"` // Percent of commit subjects matching the commit message pattern
+	CommitsByDay           []SeriesPoint  `json:"commits_by_day"`
+	TotalLinesAdded        int            `json:"total_lines_added"`
+	TotalLinesDeleted      int            `json:"total_lines_deleted"`
+	TotalLinesIgnored      int            `json:"total_lines_ignored"`
+	ActiveDays             int            `json:"active_days"`
+	DateRange              string         `json:"date_range"`
+
+	AvgCommitGapHours float64              `json:"avg_commit_gap_hours"`
+	CommitGapByAuthor map[string]CommitGap `json:"commit_gap_by_author"`
 }
 
 type PRMetrics struct {
-	TotalPRs           int            `json:"total_prs"`
-	MergedPRs          int            `json:"merged_prs"`
-	ClosedPRs          int            `json:"closed_prs"`
-	OpenPRs            int            `json:"open_prs"`
-	AvgCycleTimeHours  float64        `json:"avg_cycle_time_hours"`
-	AvgReviewTimeHours float64        `json:"avg_review_time_hours"`
-	AvgPRSize          float64        `json:"avg_pr_size"`
-	PRsByAuthor        map[string]int `json:"prs_by_author"`
-	MergeSuccessRate   float64        `json:"merge_success_rate"`
+	TotalPRs               int            `json:"total_prs"`
+	MergedPRs              int            `json:"merged_prs"`
+	ClosedPRs              int            `json:"closed_prs"`
+	OpenPRs                int            `json:"open_prs"`
+	AvgCycleTimeHours      float64        `json:"avg_cycle_time_hours"`
+	AvgReviewTimeHours     float64        `json:"avg_review_time_hours"`
+	MedianCycleTimeHours   float64        `json:"median_cycle_time_hours"`
+	P90CycleTimeHours      float64        `json:"p90_cycle_time_hours"`
+	MedianReviewTimeHours  float64        `json:"median_review_time_hours"`
+	P90ReviewTimeHours     float64        `json:"p90_review_time_hours"`
+	AvgPRSize              float64        `json:"avg_pr_size"`
+	PRsByAuthor            map[string]int `json:"prs_by_author"`
+	MergeSuccessRate       float64        `json:"merge_success_rate"`
+	SizeVsReview           SizeVsReview   `json:"size_vs_review"`
+	AvgReviewCycles        float64        `json:"avg_review_cycles"`
+	TotalReviewComments    int            `json:"total_review_comments"`      // Inline review comments, counted with FetchReviewComments
+	AvgReviewCommentsPerPR float64        `json:"avg_review_comments_per_pr"` // Over the PRs whose review comments were counted
+	HighReviewCyclePRs     []string       `json:"high_review_cycle_prs"`
+	SelfMergedPRs          int            `json:"self_merged_prs"`
+	SelfMergedPRIDs        []string       `json:"self_merged_pr_ids"`
+	TotalLinesIgnored      int            `json:"total_lines_ignored"`
+	AvgTimeInDraftHours    float64        `json:"avg_time_in_draft_hours"`
+	DraftTimeExcluded      bool           `json:"draft_time_excluded"`
+	AvgBranchLifetimeHours float64        `json:"avg_branch_lifetime_hours"`
+	UnderReviewedPRs       int            `json:"under_reviewed_prs"`
+	UnderReviewedPRIDs     []string       `json:"under_reviewed_pr_ids"`
+	StalePRs               StalePRs       `json:"stale_prs"`
+
+	CycleTimeByAuthor  map[string]float64 `json:"cycle_time_by_author"`  // Avg hours to merge; authors without merged PRs are omitted
+	ReviewTimeByAuthor map[string]float64 `json:"review_time_by_author"` // Avg hours to first review; authors without reviewed PRs are omitted
+}
+
+// PRSizeBucket summarizes review effort for PRs within a size range
+type PRSizeBucket struct {
+	Label              string  `json:"label"`
+	MinLines           int     `json:"min_lines"`
+	MaxLines           int     `json:"max_lines"` // 0 means unbounded
+	PRCount            int     `json:"pr_count"`
+	AvgReviewTimeHours float64 `json:"avg_review_time_hours"`
+	AvgComments        float64 `json:"avg_comments"`
+}
+
+// SizeVsReview relates PR size to review time and comment count
+type SizeVsReview struct {
+	Buckets               []PRSizeBucket `json:"buckets"`
+	ReviewTimeCorrelation float64        `json:"review_time_correlation"`
+	CommentsCorrelation   float64        `json:"comments_correlation"`
 }
 
 type JiraMetrics struct {
-	TotalStories      int            `json:"total_stories"`
-	CompletedStories  int            `json:"completed_stories"`
-	AvgLeadTimeDays   float64        `json:"avg_lead_time_days"`
-	AvgCycleTimeDays  float64        `json:"avg_cycle_time_days"`
-	Throughput        float64        `json:"throughput_per_week"`
-	AvgEstimate       float64        `json:"avg_estimate"`
-	AvgActualEffort   float64        `json:"avg_actual_effort"`
-	EstimateAccuracy  float64        `json:"estimate_accuracy_percent"`
-	StoriesByAssignee map[string]int `json:"stories_by_assignee"`
+	TotalStories            int            `json:"total_stories"`
+	CompletedStories        int            `json:"completed_stories"`
+	AvgLeadTimeDays         float64        `json:"avg_lead_time_days"`
+	AvgLeadTimeBusinessDays float64        `json:"avg_lead_time_business_days"`
+	AvgCycleTimeDays        float64        `json:"avg_cycle_time_days"`
+	Throughput              float64        `json:"throughput_per_week"`
+	AvgEstimate             float64        `json:"avg_estimate"`
+	AvgActualEffort         float64        `json:"avg_actual_effort"`
+	EstimateAccuracy        float64        `json:"estimate_accuracy_percent"`
+	AccuracySample          int            `json:"estimate_accuracy_sample_size"`
+	StoriesByAssignee       map[string]int `json:"stories_by_assignee"`
+
+	ThroughputByAssignee   map[string]float64 `json:"throughput_by_assignee"`
+	AvgLeadTimeByAssignee  map[string]float64 `json:"avg_lead_time_by_assignee"`
+	LowConfidenceAssignees []string           `json:"low_confidence_assignees"`
+
+	StoriesByComponent     map[string]int     `json:"stories_by_component"`
+	AvgLeadTimeByComponent map[string]float64 `json:"avg_lead_time_by_component"`
+
+	StoriesByLabel   map[string]int `json:"stories_by_label"`
+	CompletedByLabel map[string]int `json:"completed_by_label"`
+
+	StoriesByType     map[string]int     `json:"stories_by_type"` // Keyed by Jira issue type: Story, Bug, Task, ...
+	ThroughputByType  map[string]float64 `json:"throughput_by_type"`
+	AvgLeadTimeByType map[string]float64 `json:"avg_lead_time_by_type"`
+
+	OpenStories    int     `json:"open_stories"`
+	AvgAgeOpenDays float64 `json:"avg_age_open_days"` // Days since creation, averaged over open stories
+	OldestOpenDays float64 `json:"oldest_open_days"`
+
+	StaleStories StaleStories    `json:"stale_stories"`
+	Subtasks     *SubtaskMetrics `json:"subtasks,omitempty"`
+	Sprint       *SprintMetrics  `json:"sprint,omitempty"` // Set when a sprint is analyzed with JiraSprint
 }
 
+// noComponent is the bucket used for stories without any Jira component
+const noComponent = "(none)"
+
+// noLabel is the bucket used for stories without any Jira label
+const noLabel = "(none)"
+
+// dateWindow is the span between the earliest and latest of a set of timestamps
+type dateWindow struct {
+	start, end time.Time
+	set        bool
+}
+
+// add widens the window to include t
+func (w *dateWindow) add(t time.Time) {
+	if !w.set || t.Before(w.start) {
+		w.start = t
+	}
+	if !w.set || t.After(w.end) {
+		w.end = t
+	}
+	w.set = true
+}
+
+// weeks is the length of the window in weeks, the divisor for per-week rates
+func (w dateWindow) weeks() float64 {
+	return w.end.Sub(w.start).Hours() / 24 / 7
+}
+
+// noIssueType is the bucket used for stories whose issue type was not fetched
+const noIssueType = "(none)"
+
 type TeamMetrics struct {
-	CommitMetrics CommitMetrics `json:"commit_metrics"`
-	PRMetrics     PRMetrics     `json:"pr_metrics"`
-	JiraMetrics   JiraMetrics   `json:"jira_metrics"`
-	GeneratedAt   time.Time     `json:"generated_at"`
+	CommitMetrics     CommitMetrics        `json:"commit_metrics"`
+	PRMetrics         PRMetrics            `json:"pr_metrics"`
+	JiraMetrics       JiraMetrics          `json:"jira_metrics"`
+	DeploymentMetrics DeploymentMetrics    `json:"deployment_metrics"`
+	ChangeFailure     ChangeFailureMetrics `json:"change_failure"`
+	OrgRollup         OrgRollup            `json:"org_rollup"`
+	PerCapita         PerCapita            `json:"per_capita"`
+	ProductivityScore float64              `json:"productivity_score"` // 0-100, see CalculateProductivityScore
+	ReviewTeam        *ReviewTeamMetrics   `json:"review_team,omitempty"`
+	Teams             []TeamGroupMetrics   `json:"teams,omitempty"` // Per-team rollup when author teams are configured or fetched
+	ReviewGraph       ReviewGraph          `json:"review_graph"`
+	IssueLinkage      *IssueLinkage        `json:"issue_linkage,omitempty"`
+	CommitPRLinkage   *CommitPRLinkage     `json:"commit_pr_linkage,omitempty"` // Set when PRs were fetched
+	Truncated         []string             `json:"truncated,omitempty"`         // Fetches stopped at a configured cap, as provider:kind
+	Providers         []string             `json:"providers,omitempty"`         // Providers fetched from in this run; empty when unknown
+	GeneratedAt       time.Time            `json:"generated_at"`
 }
 
-// CalculateCommitMetrics computes metrics from commits
-func CalculateCommitMetrics(commits []bitbucket.Commit) CommitMetrics {
+// CalculateCommitMetrics computes metrics from commits, leaving out excluded authors
+func CalculateCommitMetrics(commits []bitbucket.Commit, cfg config.Config) CommitMetrics {
 	metrics := CommitMetrics{
-		CommitsByAuthor:  make(map[string]int),
-		CommitsByWeekday: make(map[string]int),
+		CommitsByAuthor:   make(map[string]int),
+		CommitsByWeekday:  make(map[string]int),
+		CommitsByHour:     make(map[int]int),
+		CommitsByType:     make(map[string]int),
+		CommitGapByAuthor: make(map[string]CommitGap),
 	}
 
+	commits = canonicalCommitAuthors(excludeCommitAuthors(commits, cfg), cfg.AuthorAliases)
 	if len(commits) == 0 {
 		return metrics
 	}
 
 	metrics.TotalCommits = len(commits)
-	activeDaysMap := make(map[string]bool)
-
-	var minDate, maxDate time.Time
-	for i, c := range commits {
-		if i == 0 || c.Date.Before(minDate) {
-			minDate = c.Date
-		}
-		if i == 0 || c.Date.After(maxDate) {
-			maxDate = c.Date
-		}
-
-		metrics.CommitsByAuthor[c.Author]++
-		weekday := c.Date.Weekday().String()
+	commitsPerDay := make(map[string]int)
+	ticket := ticketPattern(cfg)
+	convention := commitMessagePattern(cfg)
+	var conventional int
+	loc := cfg.ReportLocation()
+
+	var window dateWindow
+	for _, c := range commits {
+		// Bucket every commit in the report zone so days don't depend on each author's offset
+		date := c.Date.In(loc)
+		window.add(date)
+
+		metrics.CommitsByAuthor[cfg.AuthorOrFallback(c.Author, "")]++
+		weekday := date.Weekday().String()
 		metrics.CommitsByWeekday[weekday]++
+		metrics.CommitsByHour[date.Hour()]++
+		metrics.CommitsByType[CommitType(c.Message, ticket)]++
+		subject, _, _ := strings.Cut(c.Message, "\n")
+		if convention.MatchString(subject) {
+			conventional++
+		}
 		metrics.TotalLinesAdded += c.LinesAdded
 		metrics.TotalLinesDeleted += c.LinesDeleted
+		metrics.TotalLinesIgnored += c.LinesIgnored
 
-		dateKey := c.Date.Format("2006-01-02")
-		activeDaysMap[dateKey] = true
+		dateKey := date.Format("2006-01-02")
+		commitsPerDay[dateKey]++
 	}
 
-	metrics.ActiveDays = len(activeDaysMap)
+	metrics.ActiveDays = len(commitsPerDay)
+	metrics.ConventionalCommitRate = float64(conventional) / float64(metrics.TotalCommits) * 100
+	metrics.CommitGapByAuthor, metrics.AvgCommitGapHours = calculateCommitGaps(commits, cfg)
+	minDate, maxDate := window.start, window.end
+	metrics.CommitsByDay = dailySeries(commitsPerDay, minDate, maxDate, cfg.SmoothingWindow)
 	daysDiff := maxDate.Sub(minDate).Hours() / 24
 	if daysDiff > 0 {
 		metrics.CommitsPerDay = float64(metrics.TotalCommits) / daysDiff
@@ -94,12 +231,16 @@ func CalculateCommitMetrics(commits []bitbucket.Commit) CommitMetrics {
 	return metrics
 }
 
-// CalculatePRMetrics computes metrics from pull requests
-func CalculatePRMetrics(prs []bitbucket.PullRequest) PRMetrics {
+// CalculatePRMetrics computes metrics from pull requests, leaving out those by excluded authors
+func CalculatePRMetrics(prs []bitbucket.PullRequest, cfg config.Config) PRMetrics {
 	metrics := PRMetrics{
-		PRsByAuthor: make(map[string]int),
+		PRsByAuthor:        make(map[string]int),
+		DraftTimeExcluded:  cfg.ExcludeDraftTime,
+		CycleTimeByAuthor:  make(map[string]float64),
+		ReviewTimeByAuthor: make(map[string]float64),
 	}
 
+	prs = canonicalPRAuthors(excludePRAuthors(prs, cfg), cfg.AuthorAliases)
 	if len(prs) == 0 {
 		return metrics
 	}
@@ -107,6 +248,13 @@ func CalculatePRMetrics(prs []bitbucket.PullRequest) PRMetrics {
 	metrics.TotalPRs = len(prs)
 	var totalCycleTime, totalReviewTime, totalSize float64
 	var cycleTimeCount, reviewTimeCount int
+	var cycleTimes, reviewTimes []float64
+	var totalReviewCycles, reviewCycleCount int
+	var reviewCommentPRs int // PRs whose review comments were counted
+	var totalDraftHours, totalBranchLifetime float64
+	var draftCount, branchLifetimeCount int
+	cycleCountByAuthor := make(map[string]int)
+	reviewCountByAuthor := make(map[string]int)
 
 	for _, pr := range prs {
 		metrics.PRsByAuthor[pr.Author]++
@@ -122,17 +270,60 @@ func CalculatePRMetrics(prs []bitbucket.PullRequest) PRMetrics {
 
 		if pr.MergedAt != nil {
 			cycleTime := pr.MergedAt.Sub(pr.CreatedAt).Hours()
+			if cfg.ExcludeDraftTime {
+				cycleTime = math.Max(cycleTime-pr.DraftHours, 0)
+			}
 			totalCycleTime += cycleTime
 			cycleTimeCount++
+			cycleTimes = append(cycleTimes, cycleTime)
+			metrics.CycleTimeByAuthor[pr.Author] += cycleTime
+			cycleCountByAuthor[pr.Author]++
 		}
 
 		if pr.FirstReviewAt != nil {
 			reviewTime := pr.FirstReviewAt.Sub(pr.CreatedAt).Hours()
 			totalReviewTime += reviewTime
 			reviewTimeCount++
+			reviewTimes = append(reviewTimes, reviewTime)
+			metrics.ReviewTimeByAuthor[pr.Author] += reviewTime
+			reviewCountByAuthor[pr.Author]++
+		}
+
+		if pr.ReviewCycles > 0 {
+			totalReviewCycles += pr.ReviewCycles
+			reviewCycleCount++
+			if pr.ReviewCycles >= excessiveReviewCycles {
+				metrics.HighReviewCyclePRs = append(metrics.HighReviewCyclePRs, pr.ID)
+			}
+		}
+
+		if isSelfMerged(pr) {
+			metrics.SelfMergedPRs++
+			metrics.SelfMergedPRIDs = append(metrics.SelfMergedPRIDs, pr.ID)
 		}
 
+		if isUnderReviewed(pr, cfg) {
+			metrics.UnderReviewedPRs++
+			metrics.UnderReviewedPRIDs = append(metrics.UnderReviewedPRIDs, pr.ID)
+		}
+
+		if pr.DraftHours > 0 {
+			totalDraftHours += pr.DraftHours
+			draftCount++
+		}
+
+		// Branch lifetime runs from the first commit on the branch, which may predate the PR
+		if pr.MergedAt != nil && pr.FirstCommitAt != nil {
+			totalBranchLifetime += pr.MergedAt.Sub(*pr.FirstCommitAt).Hours()
+			branchLifetimeCount++
+		}
+
+		if pr.ReviewComments != nil {
+			metrics.TotalReviewComments += *pr.ReviewComments
+			reviewCommentPRs++
+		}
 		totalSize += float64(pr.LinesChanged)
+		metrics.TotalLinesIgnored += pr.LinesIgnored
 	}
 
 	if cycleTimeCount > 0 {
@@ -141,26 +332,197 @@ func CalculatePRMetrics(prs []bitbucket.PullRequest) PRMetrics {
 	if reviewTimeCount > 0 {
 		metrics.AvgReviewTimeHours = totalReviewTime / float64(reviewTimeCount)
 	}
+	metrics.MedianCycleTimeHours = percentile(cycleTimes, 50)
+	metrics.P90CycleTimeHours = percentile(cycleTimes, 90)
+	metrics.MedianReviewTimeHours = percentile(reviewTimes, 50)
+	metrics.P90ReviewTimeHours = percentile(reviewTimes, 90)
+	if branchLifetimeCount > 0 {
+		metrics.AvgBranchLifetimeHours = totalBranchLifetime / float64(branchLifetimeCount)
+	}
+	if draftCount > 0 {
+		metrics.AvgTimeInDraftHours = totalDraftHours / float64(draftCount)
+	}
+	if reviewCycleCount > 0 {
+		metrics.AvgReviewCycles = float64(totalReviewCycles) / float64(reviewCycleCount)
+	}
 	if metrics.TotalPRs > 0 {
 		metrics.AvgPRSize = totalSize / float64(metrics.TotalPRs)
 		metrics.MergeSuccessRate = float64(metrics.MergedPRs) / float64(metrics.TotalPRs) * 100
 	}
+	if reviewCommentPRs > 0 {
+		metrics.AvgReviewCommentsPerPR = float64(metrics.TotalReviewComments) / float64(reviewCommentPRs)
+	}
+
+	for author, n := range cycleCountByAuthor {
+		metrics.CycleTimeByAuthor[author] /= float64(n)
+	}
+	for author, n := range reviewCountByAuthor {
+		metrics.ReviewTimeByAuthor[author] /= float64(n)
+	}
+
+	metrics.SizeVsReview = calculateSizeVsReview(prs)
+	metrics.StalePRs = calculateStalePRs(prs, cfg.StalePRDays, cfg.MaxPRAgeDays, time.Now())
 
 	return metrics
 }
 
+// isSelfMerged reports whether the author merged their own PR without approval from anyone else.
+// PRs without merge actor data are never counted.
+func isSelfMerged(pr bitbucket.PullRequest) bool {
+	if pr.MergedBy == "" || pr.MergedBy != pr.Author {
+		return false
+	}
+	for _, approver := range pr.Approvers {
+		if approver != pr.Author {
+			return false
+		}
+	}
+	return true
+}
+
+// isUnderReviewed reports whether a merged PR has fewer approvals from people other than the
+// author than its base branch requires. Branches without a configured requirement, and PRs
+// without base-branch data, are never flagged.
+func isUnderReviewed(pr bitbucket.PullRequest, cfg config.Config) bool {
+	if pr.MergedAt == nil || pr.BaseBranch == "" {
+		return false
+	}
+	required, ok := cfg.RequiredApprovalsFor(pr.BaseBranch)
+	if !ok || required <= 0 {
+		return false
+	}
+
+	approvals := 0
+	for _, approver := range pr.Approvers {
+		if approver != pr.Author {
+			approvals++
+		}
+	}
+	return approvals < required
+}
+
+// prSizeBuckets defines the size ranges used for the size vs review breakdown
+var prSizeBuckets = []PRSizeBucket{
+	{Label: "XS", MinLines: 0, MaxLines: 9},
+	{Label: "S", MinLines: 10, MaxLines: 99},
+	{Label: "M", MinLines: 100, MaxLines: 499},
+	{Label: "L", MinLines: 500, MaxLines: 999},
+	{Label: "XL", MinLines: 1000, MaxLines: 0},
+}
+
+// calculateSizeVsReview buckets PRs by size and correlates size with review time and comments
+func calculateSizeVsReview(prs []bitbucket.PullRequest) SizeVsReview {
+	buckets := make([]PRSizeBucket, len(prSizeBuckets))
+	copy(buckets, prSizeBuckets)
+	reviewTotals := make([]float64, len(buckets))
+	reviewCounts := make([]int, len(buckets))
+	commentTotals := make([]int, len(buckets))
+
+	var sizes, reviewTimes, allSizes, comments []float64
+	for _, pr := range prs {
+		for i, b := range buckets {
+			if pr.LinesChanged < b.MinLines || (b.MaxLines > 0 && pr.LinesChanged > b.MaxLines) {
+				continue
+			}
+			buckets[i].PRCount++
+			commentTotals[i] += pr.CommentCount
+			if pr.FirstReviewAt != nil {
+				reviewTotals[i] += pr.FirstReviewAt.Sub(pr.CreatedAt).Hours()
+				reviewCounts[i]++
+			}
+			break
+		}
+
+		allSizes = append(allSizes, float64(pr.LinesChanged))
+		comments = append(comments, float64(pr.CommentCount))
+		if pr.FirstReviewAt != nil {
+			sizes = append(sizes, float64(pr.LinesChanged))
+			reviewTimes = append(reviewTimes, pr.FirstReviewAt.Sub(pr.CreatedAt).Hours())
+		}
+	}
+
+	for i := range buckets {
+		if reviewCounts[i] > 0 {
+			buckets[i].AvgReviewTimeHours = reviewTotals[i] / float64(reviewCounts[i])
+		}
+		if buckets[i].PRCount > 0 {
+			buckets[i].AvgComments = float64(commentTotals[i]) / float64(buckets[i].PRCount)
+		}
+	}
+
+	return SizeVsReview{
+		Buckets:               buckets,
+		ReviewTimeCorrelation: pearson(sizes, reviewTimes),
+		CommentsCorrelation:   pearson(allSizes, comments),
+	}
+}
+
+// pearson returns the Pearson correlation coefficient of xs and ys, or 0 when undefined
+func pearson(xs, ys []float64) float64 {
+	n := float64(len(xs))
+	if len(xs) < 2 || len(xs) != len(ys) {
+		return 0
+	}
+
+	var sumX, sumY float64
+	for i := range xs {
+		sumX += xs[i]
+		sumY += ys[i]
+	}
+	meanX, meanY := sumX/n, sumY/n
+
+	var cov, varX, varY float64
+	for i := range xs {
+		dx, dy := xs[i]-meanX, ys[i]-meanY
+		cov += dx * dy
+		varX += dx * dx
+		varY += dy * dy
+	}
+	if varX == 0 || varY == 0 {
+		return 0
+	}
+	return cov / math.Sqrt(varX*varY)
+}
+
 // CalculateJiraMetrics computes metrics from Jira stories
-func CalculateJiraMetrics(stories []jira.JiraStory) JiraMetrics {
+func CalculateJiraMetrics(stories []jira.JiraStory, cfg config.Config) JiraMetrics {
 	metrics := JiraMetrics{
-		StoriesByAssignee: make(map[string]int),
+		StoriesByAssignee:      make(map[string]int),
+		ThroughputByAssignee:   make(map[string]float64),
+		AvgLeadTimeByAssignee:  make(map[string]float64),
+		StoriesByComponent:     make(map[string]int),
+		AvgLeadTimeByComponent: make(map[string]float64),
+		StoriesByLabel:         make(map[string]int),
+		CompletedByLabel:       make(map[string]int),
+		StoriesByType:          make(map[string]int),
+		ThroughputByType:       make(map[string]float64),
+		AvgLeadTimeByType:      make(map[string]float64),
 	}
 
+	stories, metrics.Subtasks = applySubtaskMode(canonicalAssignees(stories, cfg.AuthorAliases), cfg.SubtaskMode)
+	if cfg.JiraSprint != "" {
+		if sprint, ok := CalculateSprintMetrics(cfg.JiraSprint, stories); ok {
+			metrics.Sprint = &sprint
+		}
+	}
 	if len(stories) == 0 {
 		return metrics
 	}
 
 	metrics.TotalStories = len(stories)
-	var totalLeadTime, totalCycleTime, totalEstimate, totalActual float64
+	metrics.StaleStories = calculateStaleStories(stories, cfg.StaleStoryDays, time.Now())
+	metrics.OpenStories, metrics.AvgAgeOpenDays, metrics.OldestOpenDays = calculateOpenAge(stories, time.Now())
+	var totalLeadTime, totalBusinessLeadTime, totalCycleTime, totalEstimate, totalActual float64
+	calendar := NewWorkCalendar(cfg)
+	var accuracyEstimate, accuracyActual float64
+	completedByAssignee := make(map[string]int)
+	leadTimeByAssignee := make(map[string]float64)
+	leadTimeCountByAssignee := make(map[string]int)
+	leadTimeByComponent := make(map[string]float64)
+	leadTimeCountByComponent := make(map[string]int)
+	completedByType := make(map[string]int)
+	leadTimeByType := make(map[string]float64)
+	leadTimeCountByType := make(map[string]int)
 	var leadTimeCount, cycleTimeCount int
 
 	var minDate, maxDate time.Time
@@ -174,16 +536,59 @@ func CalculateJiraMetrics(stories []jira.JiraStory) JiraMetrics {
 
 		metrics.StoriesByAssignee[s.Assignee]++
 
-		if strings.Contains(strings.ToLower(s.Status), "done") ||
-			strings.Contains(strings.ToLower(s.Status), "completed") ||
-			strings.Contains(strings.ToLower(s.Status), "resolved") {
+		// A story counts once towards each of its components
+		components := s.Components
+		if len(components) == 0 {
+			components = []string{noComponent}
+		}
+		for _, component := range components {
+			metrics.StoriesByComponent[component]++
+		}
+
+		issueType := s.IssueType
+		if issueType == "" {
+			issueType = noIssueType
+		}
+		metrics.StoriesByType[issueType]++
+
+		// Likewise for labels, so a "bug" and "tech-debt" story shows up under both
+		labels := s.Labels
+		if len(labels) == 0 {
+			labels = []string{noLabel}
+		}
+		for _, label := range labels {
+			metrics.StoriesByLabel[label]++
+		}
+
+		if isCompletedStatus(s.Status) {
 			metrics.CompletedStories++
+			completedByAssignee[s.Assignee]++
+			for _, label := range labels {
+				metrics.CompletedByLabel[label]++
+			}
+			completedByType[issueType]++
+
+			// Only finished work with both an estimate and a recorded actual says anything about accuracy
+			if s.Estimate > 0 && s.ActualEffort > 0 {
+				accuracyEstimate += s.Estimate
+				accuracyActual += s.ActualEffort
+				metrics.AccuracySample++
+			}
 		}
 
 		if s.CompletedAt != nil {
 			leadTime := s.CompletedAt.Sub(s.CreatedAt).Hours() / 24
 			totalLeadTime += leadTime
+			totalBusinessLeadTime += calendar.WorkingDays(s.CreatedAt, *s.CompletedAt)
 			leadTimeCount++
+			leadTimeByAssignee[s.Assignee] += leadTime
+			leadTimeCountByAssignee[s.Assignee]++
+			for _, component := range components {
+				leadTimeByComponent[component] += leadTime
+				leadTimeCountByComponent[component]++
+			}
+			leadTimeByType[issueType] += leadTime
+			leadTimeCountByType[issueType]++
 
 			if s.StartedAt != nil {
 				cycleTime := s.CompletedAt.Sub(*s.StartedAt).Hours() / 24
@@ -198,6 +603,7 @@ func CalculateJiraMetrics(stories []jira.JiraStory) JiraMetrics {
 
 	if leadTimeCount > 0 {
 		metrics.AvgLeadTimeDays = totalLeadTime / float64(leadTimeCount)
+		metrics.AvgLeadTimeBusinessDays = totalBusinessLeadTime / float64(leadTimeCount)
 	}
 	if cycleTimeCount > 0 {
 		metrics.AvgCycleTimeDays = totalCycleTime / float64(cycleTimeCount)
@@ -206,26 +612,96 @@ func CalculateJiraMetrics(stories []jira.JiraStory) JiraMetrics {
 		metrics.AvgEstimate = totalEstimate / float64(metrics.TotalStories)
 		metrics.AvgActualEffort = totalActual / float64(metrics.TotalStories)
 	}
-	if totalEstimate > 0 {
-		metrics.EstimateAccuracy = (1 - abs(totalActual-totalEstimate)/totalEstimate) * 100
+	if accuracyEstimate > 0 {
+		metrics.EstimateAccuracy = (1 - abs(accuracyActual-accuracyEstimate)/accuracyEstimate) * 100
 	}
 
-	weeksDiff := maxDate.Sub(minDate).Hours() / 24 / 7
-	if weeksDiff > 0 {
-		metrics.Throughput = float64(metrics.CompletedStories) / weeksDiff
+	weeksDiff := dateWindow{start: minDate, end: maxDate}.weeks()
+	if weeksDiff <= 0 && metrics.CompletedStories > 0 {
+		// Every story was created and completed at the same instant, so there is no span to
+		// divide by; use the length of the analysis window instead
+		since, until := cfg.Window()
+		weeksDiff = dateWindow{start: since, end: until}.weeks()
+	}
+	// A span of a few days would inflate per-week rates (and an empty one leave them unset),
+	// so anything shorter counts as one week
+	weeksDiff = math.Max(weeksDiff, 1)
+	metrics.Throughput = float64(metrics.CompletedStories) / weeksDiff
+
+	minSample := cfg.MinSampleSize
+	if minSample <= 0 {
+		minSample = config.DefaultMinSampleSize
+	}
+	for assignee, completed := range completedByAssignee {
+		metrics.ThroughputByAssignee[assignee] = float64(completed) / weeksDiff
+		if completed < minSample {
+			metrics.LowConfidenceAssignees = append(metrics.LowConfidenceAssignees, assignee)
+		}
+	}
+	sort.Strings(metrics.LowConfidenceAssignees)
+	for assignee, total := range leadTimeByAssignee {
+		metrics.AvgLeadTimeByAssignee[assignee] = total / float64(leadTimeCountByAssignee[assignee])
+	}
+	for component, total := range leadTimeByComponent {
+		metrics.AvgLeadTimeByComponent[component] = total / float64(leadTimeCountByComponent[component])
+	}
+	for issueType, total := range leadTimeByType {
+		metrics.AvgLeadTimeByType[issueType] = total / float64(leadTimeCountByType[issueType])
+	}
+	for issueType, completed := range completedByType {
+		metrics.ThroughputByType[issueType] = float64(completed) / weeksDiff
 	}
 
 	return metrics
 }
 
+// isCompletedStatus reports whether a Jira status name represents finished work
+func isCompletedStatus(status string) bool {
+	status = strings.ToLower(status)
+	return strings.Contains(status, "done") ||
+		strings.Contains(status, "completed") ||
+		strings.Contains(status, "resolved")
+}
+
 // CalculateTeamMetrics combines all metrics
-func CalculateTeamMetrics(commits []bitbucket.Commit, prs []bitbucket.PullRequest, stories []jira.JiraStory) TeamMetrics {
-	return TeamMetrics{
-		CommitMetrics: CalculateCommitMetrics(commits),
-		PRMetrics:     CalculatePRMetrics(prs),
-		JiraMetrics:   CalculateJiraMetrics(stories),
-		GeneratedAt:   time.Now(),
+func CalculateTeamMetrics(commits []bitbucket.Commit, prs []bitbucket.PullRequest, stories []jira.JiraStory, deployments []github.Deployment, cfg config.Config) TeamMetrics {
+	// Drop excluded authors and merge aliases once so rollups, review and team metrics agree
+	commits = canonicalCommitAuthors(excludeCommitAuthors(commits, cfg), cfg.AuthorAliases)
+	prs = canonicalPRAuthors(excludePRAuthors(prs, cfg), cfg.AuthorAliases)
+	stories = canonicalAssignees(stories, cfg.AuthorAliases)
+	teamMetrics := TeamMetrics{
+		CommitMetrics:     CalculateCommitMetrics(commits, cfg),
+		PRMetrics:         CalculatePRMetrics(prs, cfg),
+		JiraMetrics:       CalculateJiraMetrics(stories, cfg),
+		DeploymentMetrics: CalculateDeploymentMetrics(deployments),
+		ChangeFailure:     CalculateChangeFailureMetrics(commits, prs, deployments, cfg),
+		ReviewGraph:       CalculateReviewGraph(prs),
+		OrgRollup:         CalculateOrgRollup(commits, cfg),
+		GeneratedAt:       time.Now(),
+	}
+	teamMetrics.PerCapita = calculatePerCapita(teamMetrics, cfg.TeamSize)
+	teamMetrics.ProductivityScore = CalculateProductivityScore(teamMetrics, cfg)
+
+	if len(cfg.ReviewTeam) > 0 {
+		reviewTeam := CalculateReviewTeamMetrics(prs, cfg.ReviewTeam)
+		teamMetrics.ReviewTeam = &reviewTeam
+	}
+
+	if len(cfg.AuthorTeams) > 0 {
+		teamMetrics.Teams = CalculateTeamGroups(commits, prs, cfg)
+	}
+
+	if len(stories) > 0 {
+		linkage := CalculateIssueLinkage(commits, stories, ticketPattern(cfg))
+		teamMetrics.IssueLinkage = &linkage
 	}
+
+	if len(prs) > 0 {
+		linkage := CalculateCommitPRLinkage(commits, prs)
+		teamMetrics.CommitPRLinkage = &linkage
+	}
+
+	return teamMetrics
 }
 
 func abs(x float64) float64 {
@@ -233,4 +709,4 @@ func abs(x float64) float64 {
 		return -x
 	}
 	return x
-}
\ No newline at end of file
+}
@@ -0,0 +1,433 @@
+package metrics
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"devops-metrics/bitbucket"
+	"devops-metrics/config"
+	"devops-metrics/jira"
+)
+
+// benchmarkSize is the number of synthetic commits, PRs or stories per benchmark
+const benchmarkSize = 10000
+
+// benchmarkStart anchors synthetic data so runs are comparable
+var benchmarkStart = time.Date(2025, 1, 6, 9, 0, 0, 0, time.UTC)
+
+func syntheticCommits(n int) []bitbucket.Commit {
+	commits := make([]bitbucket.Commit, n)
+	for i := range commits {
+		commits[i] = bitbucket.Commit{
+			Hash:         fmt.Sprintf("%040x", i),
+			Author:       fmt.Sprintf("author-%d", i%50),
+			Date:         benchmarkStart.Add(time.Duration(i) * 17 * time.Minute),
+			Message:      fmt.Sprintf("feat(api): PROJ-%d change %d", i%900, i),
+			LinesAdded:   i % 300,
+			LinesDeleted: i % 120,
+			Repo:         fmt.Sprintf("repo-%d", i%5),
+		}
+	}
+	return commits
+}
+
+func syntheticPRs(n int) []bitbucket.PullRequest {
+	prs := make([]bitbucket.PullRequest, n)
+	for i := range prs {
+		created := benchmarkStart.Add(time.Duration(i) * 29 * time.Minute)
+		review := created.Add(time.Duration(i%48) * time.Hour)
+		pr := bitbucket.PullRequest{
+			ID:            fmt.Sprintf("PR-%d", i),
+			Author:        fmt.Sprintf("author-%d", i%50),
+			CreatedAt:     created,
+			FirstReviewAt: &review,
+			LinesChanged:  i % 2000,
+			Reviewers:     []string{fmt.Sprintf("author-%d", (i+1)%50)},
+			Approvers:     []string{fmt.Sprintf("author-%d", (i+1)%50)},
+			CommentCount:  i % 7,
+			ReviewCycles:  i % 4,
+			Status:        "OPEN",
+			BaseBranch:    "main",
+		}
+		if i%5 != 0 {
+			merged := review.Add(time.Duration(i%24) * time.Hour)
+			pr.MergedAt = &merged
+			pr.Status = "MERGED"
+			pr.MergedBy = pr.Author
+		}
+		prs[i] = pr
+	}
+	return prs
+}
+
+func syntheticStories(n int) []jira.JiraStory {
+	statuses := []string{"Done", "In Progress", "To Do", "Closed"}
+	stories := make([]jira.JiraStory, n)
+	for i := range stories {
+		created := benchmarkStart.Add(time.Duration(i) * 41 * time.Minute)
+		story := jira.JiraStory{
+			Key:          fmt.Sprintf("PROJ-%d", i),
+			Assignee:     fmt.Sprintf("author-%d", i%50),
+			CreatedAt:    created,
+			Estimate:     float64(i%8 + 1),
+			ActualEffort: float64(i%10 + 1),
+			Status:       statuses[i%len(statuses)],
+			Components:   []string{fmt.Sprintf("component-%d", i%6)},
+			Labels:       []string{"feature", fmt.Sprintf("label-%d", i%4)},
+			IssueType:    []string{"Story", "Bug", "Task"}[i%3],
+		}
+		if story.Status == "Done" || story.Status == "Closed" {
+			completed := created.Add(time.Duration(i%240) * time.Hour)
+			story.CompletedAt = &completed
+		}
+		stories[i] = story
+	}
+	return stories
+}
+
+func BenchmarkCalculateCommitMetrics(b *testing.B) {
+	commits := syntheticCommits(benchmarkSize)
+	cfg := config.Config{DaysToAnalyze: 30}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		CalculateCommitMetrics(commits, cfg)
+	}
+}
+
+func BenchmarkCalculatePRMetrics(b *testing.B) {
+	prs := syntheticPRs(benchmarkSize)
+	cfg := config.Config{DaysToAnalyze: 30}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		CalculatePRMetrics(prs, cfg)
+	}
+}
+
+func BenchmarkCalculateJiraMetrics(b *testing.B) {
+	stories := syntheticStories(benchmarkSize)
+	cfg := config.Config{DaysToAnalyze: 30}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		CalculateJiraMetrics(stories, cfg)
+	}
+}
+
+func TestCalculatePRMetricsReviewComments(t *testing.T) {
+	count := func(n int) *int { return &n }
+	tests := []struct {
+		name      string
+		comments  []*int // Review comments per PR; nil when they were not counted
+		wantTotal int
+		wantAvg   float64
+	}{
+		{"all counted", []*int{count(4), count(0), count(2)}, 6, 2},
+		{"some not counted", []*int{count(4), nil, count(2), nil}, 6, 3},
+		{"none counted", []*int{nil, nil}, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prs := make([]bitbucket.PullRequest, len(tt.comments))
+			for i, c := range tt.comments {
+				prs[i] = bitbucket.PullRequest{ID: fmt.Sprintf("PR-%d", i), Author: "dev", CreatedAt: benchmarkStart, Status: "OPEN", ReviewComments: c}
+			}
+			m := CalculatePRMetrics(prs, config.Config{DaysToAnalyze: 30})
+			if m.TotalReviewComments != tt.wantTotal || m.AvgReviewCommentsPerPR != tt.wantAvg {
+				t.Errorf("TotalReviewComments, AvgReviewCommentsPerPR = %d, %v; want %d, %v",
+					m.TotalReviewComments, m.AvgReviewCommentsPerPR, tt.wantTotal, tt.wantAvg)
+			}
+		})
+	}
+}
+
+func TestCommitsByHourAcrossDST(t *testing.T) {
+	// The commits carry an author offset of +05:30, which must not leak into the buckets
+	india := time.FixedZone("IST", 5*3600+1800)
+	at := func(utc string) bitbucket.Commit {
+		ts, err := time.Parse(time.RFC3339, utc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return bitbucket.Commit{Author: "dev", Date: ts.In(india), Message: "fix: change"}
+	}
+	tests := []struct {
+		name    string
+		cfg     config.Config
+		commits []bitbucket.Commit
+		want    map[int]int
+	}{
+		{
+			name:    "UTC by default",
+			commits: []bitbucket.Commit{at("2026-03-28T08:30:00Z"), at("2026-03-29T08:30:00Z")},
+			want:    map[int]int{8: 2},
+		},
+		{
+			name:    "Berlin before and after the spring change",
+			cfg:     config.Config{Timezone: "Europe/Berlin"},
+			commits: []bitbucket.Commit{at("2026-03-28T08:30:00Z"), at("2026-03-29T08:30:00Z")},
+			want:    map[int]int{9: 1, 10: 1},
+		},
+		{
+			name:    "Berlin before and after the autumn change",
+			cfg:     config.Config{Timezone: "Europe/Berlin"},
+			commits: []bitbucket.Commit{at("2026-10-24T08:30:00Z"), at("2026-10-25T08:30:00Z")},
+			want:    map[int]int{10: 1, 9: 1},
+		},
+		{
+			name:    "New York across the spring change",
+			cfg:     config.Config{Timezone: "America/New_York"},
+			commits: []bitbucket.Commit{at("2026-03-07T14:30:00Z"), at("2026-03-08T14:30:00Z")},
+			want:    map[int]int{9: 1, 10: 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := CalculateCommitMetrics(tt.commits, tt.cfg).CommitsByHour
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("CommitsByHour = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCommitType(t *testing.T) {
+	ticket := ticketPattern(config.Config{})
+	tests := []struct {
+		message string
+		want    string
+	}{
+		{"PROJ-1 feat: x", "feat"},
+		{"[PROJ-1] feat: x", "feat"},
+		{"(PROJ-1) fix(api): x", "fix"},
+		{"PROJ-1: PROJ-2 feat: x", "feat"},
+		{"feat(PROJ-1): x", "feat"},
+		{"PROJ-1 - refactor!: drop v1", "refactor"},
+		{"Fix: capitalised type\n\nbody", "fix"},
+		{"Update README", "other"},
+		{"PROJ-1 Update README", "other"},
+		{"wip: not a conventional type", "other"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.message, func(t *testing.T) {
+			if got := CommitType(tt.message, ticket); got != tt.want {
+				t.Errorf("CommitType(%q) = %q, want %q", tt.message, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConventionalCommits(t *testing.T) {
+	commit := func(msg string) bitbucket.Commit {
+		return bitbucket.Commit{Author: "dev", Date: benchmarkStart, Message: msg}
+	}
+	tests := []struct {
+		name     string
+		cfg      config.Config
+		commits  []bitbucket.Commit
+		wantRate float64
+		wantType map[string]int
+	}{
+		{
+			name: "default pattern",
+			commits: []bitbucket.Commit{
+				commit("feat(api): add endpoint"),
+				commit("fix!: drop legacy flag\n\nBREAKING CHANGE: removed"),
+				commit("Update readme"),
+				commit("wip"),
+			},
+			wantRate: 50,
+			wantType: map[string]int{"feat": 1, "fix": 1, "other": 2},
+		},
+		{
+			name:     "subject only",
+			commits:  []bitbucket.Commit{commit("Merge branch 'main'\n\nfix: not the subject")},
+			wantRate: 0,
+			wantType: map[string]int{"other": 1},
+		},
+		{
+			name:     "type outside the default pattern",
+			commits:  []bitbucket.Commit{commit("ci: cache modules"), commit("docs: typo")},
+			wantRate: 50,
+			wantType: map[string]int{"ci": 1, "docs": 1},
+		},
+		{
+			name:     "custom pattern",
+			cfg:      config.Config{CommitMessagePattern: `^[A-Z]+-\d+ `},
+			commits:  []bitbucket.Commit{commit("PROJ-1 feat: add endpoint"), commit("feat: add endpoint")},
+			wantRate: 50,
+			wantType: map[string]int{"feat": 2},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := CalculateCommitMetrics(tt.commits, tt.cfg)
+			if m.ConventionalCommitRate != tt.wantRate {
+				t.Errorf("ConventionalCommitRate = %v, want %v", m.ConventionalCommitRate, tt.wantRate)
+			}
+			if fmt.Sprint(m.CommitsByType) != fmt.Sprint(tt.wantType) {
+				t.Errorf("CommitsByType = %v, want %v", m.CommitsByType, tt.wantType)
+			}
+		})
+	}
+}
+
+func TestCalculateJiraMetricsByType(t *testing.T) {
+	day := func(d int) *time.Time {
+		t := benchmarkStart.AddDate(0, 0, d)
+		return &t
+	}
+	story := func(issueType, status string, created int, completed *time.Time) jira.JiraStory {
+		return jira.JiraStory{IssueType: issueType, Status: status, CreatedAt: *day(created), CompletedAt: completed}
+	}
+	tests := []struct {
+		name           string
+		cfg            config.Config
+		stories        []jira.JiraStory
+		wantByType     map[string]int
+		wantThroughput map[string]float64
+		wantLeadTime   map[string]float64
+	}{
+		{
+			name: "mixed types over two weeks",
+			stories: []jira.JiraStory{
+				story("Story", "Done", 0, day(4)),
+				story("Story", "Done", 2, day(14)),
+				story("Bug", "Done", 7, day(8)),
+				story("Task", "In Progress", 3, nil),
+				story("", "Done", 10, day(12)),
+			},
+			wantByType:     map[string]int{"Story": 2, "Bug": 1, "Task": 1, noIssueType: 1},
+			wantThroughput: map[string]float64{"Story": 1, "Bug": 0.5, noIssueType: 0.5},
+			wantLeadTime:   map[string]float64{"Story": 8, "Bug": 1, noIssueType: 2},
+		},
+		{
+			name: "no span falls back to the analysis window",
+			cfg:  config.Config{DaysToAnalyze: 14, WindowEnd: benchmarkStart.AddDate(0, 0, 14)},
+			stories: []jira.JiraStory{
+				story("Story", "Done", 0, day(0)),
+				story("Bug", "Done", 0, day(0)),
+				story("Bug", "Done", 0, day(0)),
+			},
+			wantByType:     map[string]int{"Story": 1, "Bug": 2},
+			wantThroughput: map[string]float64{"Story": 0.5, "Bug": 1},
+			wantLeadTime:   map[string]float64{"Story": 0, "Bug": 0},
+		},
+		{
+			name: "span under a week counts as one week",
+			stories: []jira.JiraStory{
+				story("Story", "Done", 0, day(2)),
+				story("Bug", "Done", 1, day(3)),
+				story("Bug", "To Do", 2, nil),
+			},
+			wantByType:     map[string]int{"Story": 1, "Bug": 2},
+			wantThroughput: map[string]float64{"Story": 1, "Bug": 1},
+			wantLeadTime:   map[string]float64{"Story": 2, "Bug": 2},
+		},
+		{
+			name:           "analysis window under a week counts as one week",
+			cfg:            config.Config{DaysToAnalyze: 2, WindowEnd: benchmarkStart.AddDate(0, 0, 2)},
+			stories:        []jira.JiraStory{story("Story", "Done", 0, day(0))},
+			wantByType:     map[string]int{"Story": 1},
+			wantThroughput: map[string]float64{"Story": 1},
+			wantLeadTime:   map[string]float64{"Story": 0},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := CalculateJiraMetrics(tt.stories, tt.cfg)
+			if fmt.Sprint(m.StoriesByType) != fmt.Sprint(tt.wantByType) {
+				t.Errorf("StoriesByType = %v, want %v", m.StoriesByType, tt.wantByType)
+			}
+			if fmt.Sprint(m.ThroughputByType) != fmt.Sprint(tt.wantThroughput) {
+				t.Errorf("ThroughputByType = %v, want %v", m.ThroughputByType, tt.wantThroughput)
+			}
+			if fmt.Sprint(m.AvgLeadTimeByType) != fmt.Sprint(tt.wantLeadTime) {
+				t.Errorf("AvgLeadTimeByType = %v, want %v", m.AvgLeadTimeByType, tt.wantLeadTime)
+			}
+		})
+	}
+}
+
+func TestCalculateJiraMetricsByLabel(t *testing.T) {
+	story := func(status string, labels ...string) jira.JiraStory {
+		return jira.JiraStory{Status: status, CreatedAt: benchmarkStart, Labels: labels}
+	}
+	tests := []struct {
+		name          string
+		stories       []jira.JiraStory
+		wantStories   map[string]int
+		wantCompleted map[string]int
+	}{
+		{
+			name: "multi-label stories count under each label",
+			stories: []jira.JiraStory{
+				story("Done", "bug", "tech-debt"),
+				story("In Progress", "tech-debt"),
+				story("Resolved", "bug"),
+				story("To Do", "bug", "tech-debt", "ui"),
+			},
+			wantStories:   map[string]int{"bug": 3, "tech-debt": 3, "ui": 1},
+			wantCompleted: map[string]int{"bug": 2, "tech-debt": 1},
+		},
+		{
+			name: "unlabelled stories",
+			stories: []jira.JiraStory{
+				story("Done"),
+				story("To Do"),
+				story("Done", "bug"),
+			},
+			wantStories:   map[string]int{noLabel: 2, "bug": 1},
+			wantCompleted: map[string]int{noLabel: 1, "bug": 1},
+		},
+		{
+			name:          "no stories",
+			wantStories:   map[string]int{},
+			wantCompleted: map[string]int{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := CalculateJiraMetrics(tt.stories, config.Config{})
+			if fmt.Sprint(m.StoriesByLabel) != fmt.Sprint(tt.wantStories) {
+				t.Errorf("StoriesByLabel = %v, want %v", m.StoriesByLabel, tt.wantStories)
+			}
+			if fmt.Sprint(m.CompletedByLabel) != fmt.Sprint(tt.wantCompleted) {
+				t.Errorf("CompletedByLabel = %v, want %v", m.CompletedByLabel, tt.wantCompleted)
+			}
+		})
+	}
+}
+
+func TestCalculatePRMetricsPercentiles(t *testing.T) {
+	// pr returns a PR merged cycle hours after opening and first reviewed review hours after
+	pr := func(cycle, review int) bitbucket.PullRequest {
+		merged := benchmarkStart.Add(time.Duration(cycle) * time.Hour)
+		reviewed := benchmarkStart.Add(time.Duration(review) * time.Hour)
+		return bitbucket.PullRequest{Author: "dev", CreatedAt: benchmarkStart, MergedAt: &merged, FirstReviewAt: &reviewed, Status: "MERGED"}
+	}
+	tests := []struct {
+		name string
+		prs  []bitbucket.PullRequest
+		want [4]float64 // median cycle, p90 cycle, median review, p90 review
+	}{
+		{"no PRs", nil, [4]float64{}},
+		{"open PR only", []bitbucket.PullRequest{{Author: "dev", CreatedAt: benchmarkStart, Status: "OPEN"}}, [4]float64{}},
+		{"odd count", []bitbucket.PullRequest{pr(10, 1), pr(2, 3), pr(100, 2)}, [4]float64{10, 82, 2, 2.8}},
+		{"even count", []bitbucket.PullRequest{pr(4, 1), pr(2, 2), pr(8, 4), pr(6, 3)}, [4]float64{5, 7.4, 2.5, 3.7}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := CalculatePRMetrics(tt.prs, config.Config{DaysToAnalyze: 30})
+			got := [4]float64{m.MedianCycleTimeHours, m.P90CycleTimeHours, m.MedianReviewTimeHours, m.P90ReviewTimeHours}
+			for i := range got {
+				if abs(got[i]-tt.want[i]) > 1e-9 {
+					t.Errorf("median/p90 cycle, median/p90 review = %v, want %v", got, tt.want)
+					break
+				}
+			}
+		})
+	}
+}
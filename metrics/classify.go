@@ -0,0 +1,90 @@
+package metrics
+
+import (
+	"regexp"
+	"strings"
+
+	"devops-metrics/config"
+)
+
+// conventionalTypes are the commit types recognised from the Conventional Commits spec
+var conventionalTypes = map[string]bool{
+	"feat": true, "fix": true, "docs": true, "style": true, "refactor": true, "perf": true,
+	"test": true, "build": true, "ci": true, "chore": true, "revert": true,
+}
+
+var closingBracket = map[byte]byte{'[': ']', '(': ')'}
+
+var conventionalHeader = regexp.MustCompile(`^([a-zA-Z]+)(\([^)]*\))?!?:\s*`)
+
+// ticketPattern compiles the configured ticket key pattern, falling back to the default.
+// Validate rejects an invalid pattern, so the fallback only applies to unvalidated configs.
+func ticketPattern(cfg config.Config) *regexp.Regexp {
+	pattern := cfg.TicketPattern
+	if pattern == "" {
+		pattern = config.DefaultTicketPattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return regexp.MustCompile(config.DefaultTicketPattern)
+	}
+	return re
+}
+
+// commitMessagePattern compiles the configured commit convention, falling back to the default
+func commitMessagePattern(cfg config.Config) *regexp.Regexp {
+	pattern := cfg.CommitMessagePattern
+	if pattern == "" {
+		pattern = config.DefaultCommitMessagePattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return regexp.MustCompile(config.DefaultCommitMessagePattern)
+	}
+	return re
+}
+
+// stripTicketPrefix removes leading ticket keys such as "PROJ-123:", "[PROJ-123]" or "(PROJ-123)"
+// from a commit subject so the conventional-commit header underneath can be parsed
+func stripTicketPrefix(subject string, ticket *regexp.Regexp) string {
+	for {
+		trimmed := strings.TrimSpace(subject)
+		loc := ticket.FindStringIndex(trimmed)
+		if loc == nil {
+			return trimmed
+		}
+
+		// Accept the ticket bare at the start, or wrapped in brackets/parentheses
+		start, end := loc[0], loc[1]
+		switch {
+		case start == 0:
+		case start == 1 && end < len(trimmed) && closingBracket[trimmed[0]] == trimmed[end]:
+			end++
+		default:
+			return trimmed
+		}
+
+		subject = strings.TrimLeft(trimmed[end:], " :-\t")
+	}
+}
+
+// CommitType extracts the conventional-commit type from a commit message, ignoring ticket
+// keys placed before the header or inside its scope. Messages without a recognised type
+// are classified as "other".
+func CommitType(message string, ticket *regexp.Regexp) string {
+	subject := message
+	if i := strings.IndexByte(subject, '\n'); i >= 0 {
+		subject = subject[:i]
+	}
+	subject = stripTicketPrefix(subject, ticket)
+
+	m := conventionalHeader.FindStringSubmatch(subject)
+	if m == nil {
+		return "other"
+	}
+	commitType := strings.ToLower(m[1])
+	if !conventionalTypes[commitType] {
+		return "other"
+	}
+	return commitType
+}
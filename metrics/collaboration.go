@@ -0,0 +1,69 @@
+package metrics
+
+import (
+	"sort"
+
+	"devops-metrics/bitbucket"
+)
+
+// ReviewEdge is a directed reviewer → author interaction: Reviewer reviewed Reviews of Author's PRs
+type ReviewEdge struct {
+	Author   string `json:"author"`
+	Reviewer string `json:"reviewer"`
+	Reviews  int    `json:"reviews"`
+}
+
+// ReviewGraph is the author↔reviewer interaction network, for spotting review silos and
+// knowledge concentrated in a few reviewers
+type ReviewGraph struct {
+	Nodes     []string                  `json:"nodes"`
+	Edges     []ReviewEdge              `json:"edges"`     // Most frequent pairs first
+	Adjacency map[string]map[string]int `json:"adjacency"` // author → reviewer → PRs reviewed
+}
+
+// CalculateReviewGraph aggregates PR reviewers by author. Self-reviews are ignored.
+func CalculateReviewGraph(prs []bitbucket.PullRequest) ReviewGraph {
+	graph := ReviewGraph{
+		Nodes:     []string{},
+		Edges:     []ReviewEdge{},
+		Adjacency: make(map[string]map[string]int),
+	}
+
+	nodes := make(map[string]bool)
+	for _, pr := range prs {
+		for _, reviewer := range pr.Reviewers {
+			if reviewer == "" || reviewer == pr.Author {
+				continue
+			}
+			if graph.Adjacency[pr.Author] == nil {
+				graph.Adjacency[pr.Author] = make(map[string]int)
+			}
+			graph.Adjacency[pr.Author][reviewer]++
+			nodes[pr.Author] = true
+			nodes[reviewer] = true
+		}
+	}
+
+	for node := range nodes {
+		graph.Nodes = append(graph.Nodes, node)
+	}
+	sort.Strings(graph.Nodes)
+
+	for author, reviewers := range graph.Adjacency {
+		for reviewer, count := range reviewers {
+			graph.Edges = append(graph.Edges, ReviewEdge{Author: author, Reviewer: reviewer, Reviews: count})
+		}
+	}
+	sort.Slice(graph.Edges, func(i, j int) bool {
+		a, b := graph.Edges[i], graph.Edges[j]
+		if a.Reviews != b.Reviews {
+			return a.Reviews > b.Reviews
+		}
+		if a.Author != b.Author {
+			return a.Author < b.Author
+		}
+		return a.Reviewer < b.Reviewer
+	})
+
+	return graph
+}
@@ -0,0 +1,46 @@
+package metrics
+
+import (
+	"devops-metrics/github"
+)
+
+// DeploymentMetrics covers DORA deployment frequency
+type DeploymentMetrics struct {
+	TotalDeployments         int            `json:"total_deployments"`
+	DeploymentsPerWeek       float64        `json:"deployments_per_week"`
+	DeploymentsByEnvironment map[string]int `json:"deployments_by_environment"`
+}
+
+// noEnvironment is the bucket used for deployments without an environment
+const noEnvironment = "(none)"
+
+// CalculateDeploymentMetrics computes deployment frequency over the span between the
+// first and last deployment, the same windowing used for Jira throughput
+func CalculateDeploymentMetrics(deployments []github.Deployment) DeploymentMetrics {
+	metrics := DeploymentMetrics{
+		DeploymentsByEnvironment: make(map[string]int),
+	}
+
+	if len(deployments) == 0 {
+		return metrics
+	}
+
+	metrics.TotalDeployments = len(deployments)
+	var window dateWindow
+	for _, d := range deployments {
+		window.add(d.CreatedAt)
+
+		environment := d.Environment
+		if environment == "" {
+			environment = noEnvironment
+		}
+		metrics.DeploymentsByEnvironment[environment]++
+	}
+
+	weeksDiff := window.weeks()
+	if weeksDiff > 0 {
+		metrics.DeploymentsPerWeek = float64(metrics.TotalDeployments) / weeksDiff
+	}
+
+	return metrics
+}
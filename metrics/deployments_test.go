@@ -0,0 +1,50 @@
+package metrics
+
+import (
+	"fmt"
+	"testing"
+
+	"devops-metrics/github"
+)
+
+func TestCalculateDeploymentMetrics(t *testing.T) {
+	deploy := func(day int, environment string) github.Deployment {
+		return github.Deployment{Environment: environment, CreatedAt: benchmarkStart.AddDate(0, 0, day)}
+	}
+	tests := []struct {
+		name        string
+		deployments []github.Deployment
+		wantTotal   int
+		wantPerWeek float64
+		wantByEnv   map[string]int
+	}{
+		{
+			name:      "none",
+			wantByEnv: map[string]int{},
+		},
+		{
+			name:        "two weeks out of order",
+			deployments: []github.Deployment{deploy(14, "production"), deploy(0, "production"), deploy(7, "staging"), deploy(3, "")},
+			wantTotal:   4,
+			wantPerWeek: 2,
+			wantByEnv:   map[string]int{"production": 2, "staging": 1, noEnvironment: 1},
+		},
+		{
+			name:        "single deployment has no rate",
+			deployments: []github.Deployment{deploy(0, "production")},
+			wantTotal:   1,
+			wantByEnv:   map[string]int{"production": 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := CalculateDeploymentMetrics(tt.deployments)
+			if m.TotalDeployments != tt.wantTotal || m.DeploymentsPerWeek != tt.wantPerWeek {
+				t.Errorf("total, per week = %d, %v, want %d, %v", m.TotalDeployments, m.DeploymentsPerWeek, tt.wantTotal, tt.wantPerWeek)
+			}
+			if fmt.Sprint(m.DeploymentsByEnvironment) != fmt.Sprint(tt.wantByEnv) {
+				t.Errorf("DeploymentsByEnvironment = %v, want %v", m.DeploymentsByEnvironment, tt.wantByEnv)
+			}
+		})
+	}
+}
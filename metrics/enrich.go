@@ -0,0 +1,45 @@
+package metrics
+
+import (
+	"devops-metrics/bitbucket"
+)
+
+// CommitPRContext is the pull request a commit landed through
+type CommitPRContext struct {
+	ID        string   `json:"id"`
+	Title     string   `json:"title,omitempty"`
+	Labels    []string `json:"labels,omitempty"`
+	Reviewers []string `json:"reviewers,omitempty"`
+}
+
+// EnrichedCommit is a raw commit with the context of its pull request, if any
+type EnrichedCommit struct {
+	bitbucket.Commit
+	PR *CommitPRContext `json:"pr,omitempty"` // nil for direct commits and commits whose PR was not fetched
+}
+
+// EnrichCommits attaches PR title, labels and reviewers to each commit whose hash is one of a
+// PR's commit hashes. When a commit belongs to several PRs (e.g. a merge commit that was
+// also on a branch), the first PR in prs wins.
+func EnrichCommits(commits []bitbucket.Commit, prs []bitbucket.PullRequest) []EnrichedCommit {
+	byHash := make(map[string]*CommitPRContext)
+	for _, pr := range prs {
+		context := &CommitPRContext{
+			ID:        pr.ID,
+			Title:     pr.Title,
+			Labels:    pr.Labels,
+			Reviewers: pr.Reviewers,
+		}
+		for _, hash := range pr.CommitHashes {
+			if _, ok := byHash[hash]; !ok {
+				byHash[hash] = context
+			}
+		}
+	}
+
+	enriched := make([]EnrichedCommit, len(commits))
+	for i, commit := range commits {
+		enriched[i] = EnrichedCommit{Commit: commit, PR: byHash[commit.Hash]}
+	}
+	return enriched
+}
@@ -0,0 +1,94 @@
+package metrics
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"devops-metrics/bitbucket"
+	"devops-metrics/config"
+	"devops-metrics/github"
+)
+
+// ChangeFailureMetrics covers DORA change failure rate
+type ChangeFailureMetrics struct {
+	Failures          int     `json:"failures"`            // Commits that look like reverts, hotfixes or rollbacks
+	Changes           int     `json:"changes"`             // Deployments, or merged PRs when no deployments were fetched
+	ChangeFailureRate float64 `json:"change_failure_rate"` // Failures as a percentage of changes
+	FailedPRs         int     `json:"failed_prs"`          // Merged PRs followed by a failure commit within the attribution window
+}
+
+// failureKeywords returns the configured failure keywords, falling back to the defaults
+func failureKeywords(cfg config.Config) []string {
+	keywords := cfg.FailureKeywords
+	if len(keywords) == 0 {
+		keywords = config.DefaultFailureKeywords
+	}
+	lowered := make([]string, 0, len(keywords))
+	for _, k := range keywords {
+		if k = strings.ToLower(strings.TrimSpace(k)); k != "" {
+			lowered = append(lowered, k)
+		}
+	}
+	return lowered
+}
+
+// isFailureCommit reports whether a commit message contains any failure keyword
+func isFailureCommit(message string, keywords []string) bool {
+	message = strings.ToLower(message)
+	for _, k := range keywords {
+		if strings.Contains(message, k) {
+			return true
+		}
+	}
+	return false
+}
+
+// CalculateChangeFailureMetrics counts revert/hotfix/rollback commits against the number of
+// changes shipped. Each failure is attributed to the most recent PR merged before it, if that
+// merge happened within cfg.FailureWindow.
+func CalculateChangeFailureMetrics(commits []bitbucket.Commit, prs []bitbucket.PullRequest, deployments []github.Deployment, cfg config.Config) ChangeFailureMetrics {
+	metrics := ChangeFailureMetrics{}
+	keywords := failureKeywords(cfg)
+	window := cfg.FailureWindow()
+
+	var merges []time.Time
+	for _, pr := range prs {
+		if pr.MergedAt != nil {
+			merges = append(merges, *pr.MergedAt)
+		}
+	}
+	sort.Slice(merges, func(i, j int) bool { return merges[i].Before(merges[j]) })
+
+	metrics.Changes = len(deployments)
+	if metrics.Changes == 0 {
+		metrics.Changes = len(merges)
+	}
+
+	seen := make(map[string]bool)
+	failed := make(map[int]bool)
+	for _, commit := range commits {
+		// The same commit is reported once per branch it appears on
+		if seen[commit.Hash] || !isFailureCommit(commit.Message, keywords) {
+			continue
+		}
+		seen[commit.Hash] = true
+		metrics.Failures++
+
+		// Index of the first merge after the commit; the one before it is the latest prior merge
+		i := sort.Search(len(merges), func(i int) bool { return merges[i].After(commit.Date) }) - 1
+		if i >= 0 && commit.Date.Sub(merges[i]) <= window {
+			failed[i] = true
+		}
+	}
+	metrics.FailedPRs = len(failed)
+
+	if metrics.Changes > 0 {
+		metrics.ChangeFailureRate = float64(metrics.Failures) / float64(metrics.Changes) * 100
+		if metrics.ChangeFailureRate > 100 {
+			metrics.ChangeFailureRate = 100
+		}
+	}
+
+	return metrics
+}
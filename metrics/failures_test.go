@@ -0,0 +1,95 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"devops-metrics/bitbucket"
+	"devops-metrics/config"
+	"devops-metrics/github"
+)
+
+func TestCalculateChangeFailureMetrics(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2026, 3, d, 12, 0, 0, 0, time.UTC) }
+	merged := func(id string, d int) bitbucket.PullRequest {
+		at := day(d)
+		return bitbucket.PullRequest{ID: id, Author: "dev", CreatedAt: day(d - 1), MergedAt: &at, Status: "MERGED"}
+	}
+	commit := func(hash, message string, d int) bitbucket.Commit {
+		return bitbucket.Commit{Hash: hash, Author: "dev", Date: day(d), Message: message}
+	}
+	prs := []bitbucket.PullRequest{merged("PR-1", 2), merged("PR-2", 5), merged("PR-3", 9), merged("PR-4", 20)}
+
+	tests := []struct {
+		name         string
+		commits      []bitbucket.Commit
+		deployments  []github.Deployment
+		cfg          config.Config
+		wantFailures int
+		wantFailed   int
+		wantRate     float64
+	}{
+		{
+			name:    "no failure markers",
+			commits: []bitbucket.Commit{commit("a", "feat: add search", 3), commit("b", "fix: typo", 6)},
+		},
+		{
+			name: "revert, hotfix and rollback",
+			commits: []bitbucket.Commit{
+				commit("a", `Revert "feat: add search"`, 3),
+				commit("b", "Hotfix for login", 6),
+				commit("c", "rollback config change", 10),
+				commit("d", "feat: unrelated", 11),
+			},
+			wantFailures: 3,
+			wantFailed:   3,
+			wantRate:     75,
+		},
+		{
+			name:         "same commit on two branches counts once",
+			commits:      []bitbucket.Commit{commit("a", "revert bad merge", 3), commit("a", "revert bad merge", 3)},
+			wantFailures: 1,
+			wantFailed:   1,
+			wantRate:     25,
+		},
+		{
+			name:         "failure after the default window is not attributed",
+			commits:      []bitbucket.Commit{commit("a", "hotfix", 28)},
+			wantFailures: 1,
+			wantRate:     25,
+		},
+		{
+			name:         "failure inside a longer configured window",
+			commits:      []bitbucket.Commit{commit("a", "hotfix", 28)},
+			cfg:          config.Config{FailureWindowDays: 10},
+			wantFailures: 1,
+			wantFailed:   1,
+			wantRate:     25,
+		},
+		{
+			name:         "custom keywords",
+			commits:      []bitbucket.Commit{commit("a", "hotfix", 3), commit("b", "incident follow-up", 6)},
+			cfg:          config.Config{FailureKeywords: []string{"Incident"}},
+			wantFailures: 1,
+			wantFailed:   1,
+			wantRate:     25,
+		},
+		{
+			name:         "deployments are the changes when present",
+			commits:      []bitbucket.Commit{commit("a", "revert", 3)},
+			deployments:  make([]github.Deployment, 10),
+			wantFailures: 1,
+			wantFailed:   1,
+			wantRate:     10,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := CalculateChangeFailureMetrics(tt.commits, prs, tt.deployments, tt.cfg)
+			if m.Failures != tt.wantFailures || m.FailedPRs != tt.wantFailed || m.ChangeFailureRate != tt.wantRate {
+				t.Errorf("Failures, FailedPRs, ChangeFailureRate = %d, %d, %v; want %d, %d, %v",
+					m.Failures, m.FailedPRs, m.ChangeFailureRate, tt.wantFailures, tt.wantFailed, tt.wantRate)
+			}
+		})
+	}
+}
@@ -0,0 +1,179 @@
+package metrics
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"devops-metrics/bitbucket"
+	"devops-metrics/jira"
+)
+
+// IssueLinkage summarizes how commits reference the fetched Jira stories
+type IssueLinkage struct {
+	CommitsWithTicket int      `json:"commits_with_ticket"`
+	LinkedCommits     int      `json:"linked_commits"`
+	LinkedViaAlias    int      `json:"linked_via_alias"`
+	UnmatchedKeys     []string `json:"unmatched_keys,omitempty"`
+}
+
+// storyKeyIndex maps every current and previous story key to the story's current key
+func storyKeyIndex(stories []jira.JiraStory) map[string]string {
+	index := make(map[string]string)
+	for _, story := range stories {
+		for _, alias := range story.PreviousKeys {
+			index[alias] = story.Key
+		}
+	}
+	// Current keys win over aliases if a key was ever reused
+	for _, story := range stories {
+		index[story.Key] = story.Key
+	}
+	return index
+}
+
+// CalculateIssueLinkage links commits to stories by the ticket keys in their messages,
+// following keys that changed when an issue moved between projects
+func CalculateIssueLinkage(commits []bitbucket.Commit, stories []jira.JiraStory, ticket *regexp.Regexp) IssueLinkage {
+	var linkage IssueLinkage
+	index := storyKeyIndex(stories)
+	unmatched := make(map[string]bool)
+
+	for _, c := range commits {
+		keys := ticket.FindAllString(c.Message, -1)
+		if len(keys) == 0 {
+			continue
+		}
+		linkage.CommitsWithTicket++
+
+		linked, viaAlias := false, false
+		for _, key := range keys {
+			current, ok := index[key]
+			if !ok {
+				unmatched[key] = true
+				continue
+			}
+			linked = true
+			if current != key {
+				viaAlias = true
+			}
+		}
+		if linked {
+			linkage.LinkedCommits++
+		}
+		if viaAlias {
+			linkage.LinkedViaAlias++
+		}
+	}
+
+	for key := range unmatched {
+		linkage.UnmatchedKeys = append(linkage.UnmatchedKeys, key)
+	}
+	sort.Strings(linkage.UnmatchedKeys)
+
+	return linkage
+}
+
+// prReference matches PR references in commit messages: "#123", "PR-123" and GitLab's "!123"
+var prReference = regexp.MustCompile(`(?i)(?:\bPR-|#|!)(\d+)\b`)
+
+// prCommitLookback is how long before a PR was opened its author's commits are still
+// attributed to it when the PR's own first commit is unknown
+const prCommitLookback = 7 * 24 * time.Hour
+
+// CommitPRLinkage summarizes how many commits landed through a merged PR
+type CommitPRLinkage struct {
+	CommitsInPRs    int     `json:"commits_in_prs"`
+	OrphanCommits   int     `json:"orphan_commits"`   // Commits not linked to any merged PR
+	PRCoverageRate  float64 `json:"pr_coverage_rate"` // Percentage of commits linked to a merged PR
+	LinkedByHash    int     `json:"linked_by_hash"`
+	LinkedByMessage int     `json:"linked_by_message"`
+	LinkedByAuthor  int     `json:"linked_by_author"`
+}
+
+// prNumber returns the number in a PR ID such as "PR-12", "!12", "12" or "owner/repo#12"
+func prNumber(id string) (int, bool) {
+	if i := strings.LastIndexByte(id, '#'); i >= 0 {
+		id = id[i+1:]
+	}
+	n, err := strconv.Atoi(strings.TrimLeft(id, "PR-!"))
+	return n, err == nil
+}
+
+// repoPR identifies a PR by its repository and number, since numbers repeat across
+// repositories and providers
+type repoPR struct {
+	repo   string
+	number int
+}
+
+// CalculateCommitPRLinkage links commits to merged PRs, trying in turn: the PR's branch and
+// merge commit hashes, a "#123", "PR-123" or "!123" reference in the message, and finally a
+// commit by the PR's author between the PR's first commit (or a week before it was opened)
+// and its merge. References and authorship only link a commit to PRs of its own repository.
+// Commits linked by none of these are orphans that bypassed review.
+func CalculateCommitPRLinkage(commits []bitbucket.Commit, prs []bitbucket.PullRequest) CommitPRLinkage {
+	var linkage CommitPRLinkage
+
+	var merged []bitbucket.PullRequest
+	hashes := make(map[string]bool)
+	numbers := make(map[repoPR]bool)
+	for _, pr := range prs {
+		if pr.MergedAt == nil {
+			continue
+		}
+		merged = append(merged, pr)
+		for _, hash := range pr.CommitHashes {
+			hashes[hash] = true
+		}
+		if n, ok := prNumber(pr.ID); ok {
+			numbers[repoPR{pr.Repo, n}] = true
+		}
+	}
+
+	byAuthor := func(c bitbucket.Commit) bool {
+		for _, pr := range merged {
+			if pr.Repo != c.Repo || !strings.EqualFold(pr.Author, c.Author) {
+				continue
+			}
+			start := pr.CreatedAt.Add(-prCommitLookback)
+			if pr.FirstCommitAt != nil {
+				start = *pr.FirstCommitAt
+			}
+			if !c.Date.Before(start) && !c.Date.After(*pr.MergedAt) {
+				return true
+			}
+		}
+		return false
+	}
+	byMessage := func(c bitbucket.Commit) bool {
+		for _, match := range prReference.FindAllStringSubmatch(c.Message, -1) {
+			if n, err := strconv.Atoi(match[1]); err == nil && numbers[repoPR{c.Repo, n}] {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, c := range commits {
+		switch {
+		case hashes[c.Hash]:
+			linkage.LinkedByHash++
+		case byMessage(c):
+			linkage.LinkedByMessage++
+		case byAuthor(c):
+			linkage.LinkedByAuthor++
+		default:
+			linkage.OrphanCommits++
+			continue
+		}
+		linkage.CommitsInPRs++
+	}
+
+	if len(commits) > 0 {
+		linkage.PRCoverageRate = float64(linkage.CommitsInPRs) / float64(len(commits)) * 100
+	}
+	return linkage
+}
@@ -0,0 +1,78 @@
+package metrics
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"devops-metrics/bitbucket"
+)
+
+func TestCalculateCommitPRLinkage(t *testing.T) {
+	at := func(hours int) time.Time { return benchmarkStart.Add(time.Duration(hours) * time.Hour) }
+	merged := func(id, repo, author string, opened, merged int, hashes ...string) bitbucket.PullRequest {
+		mergedAt := at(merged)
+		return bitbucket.PullRequest{ID: id, Repo: repo, Author: author, CreatedAt: at(opened), MergedAt: &mergedAt, CommitHashes: hashes}
+	}
+	commit := func(hash, repo, author string, hours int, message string) bitbucket.Commit {
+		return bitbucket.Commit{Hash: hash, Repo: repo, Author: author, Date: at(hours), Message: message}
+	}
+	prs := []bitbucket.PullRequest{
+		merged("PR-12", "acme/api", "alice", 10, 20, "a1"),
+		merged("!7", "group/web", "bob", 100, 120),
+		merged("acme/tools#3", "acme/tools", "carol", 200, 210),
+		{ID: "PR-13", Repo: "acme/api", Author: "dave", CreatedAt: at(30)}, // Still open
+	}
+
+	tests := []struct {
+		name    string
+		commits []bitbucket.Commit
+		want    string // by hash/message/author, orphans, coverage
+	}{
+		{
+			name: "linked and orphan commits",
+			commits: []bitbucket.Commit{
+				commit("a1", "acme/api", "alice", 12, "feat: add endpoint"),
+				commit("b1", "group/web", "erin", 300, "fix: typo (!7)"),
+				commit("c1", "acme/tools", "erin", 300, "Merge pull request #3"),
+				commit("d1", "acme/api", "alice", 15, "chore: tidy"),
+				commit("e1", "acme/api", "erin", 15, "hotfix straight to main"),
+			},
+			want: "1/2/1 1 80.00",
+		},
+		{
+			name: "references only match within the commit's repository",
+			commits: []bitbucket.Commit{
+				commit("f1", "acme/web", "erin", 300, "fix: follow-up to #12"),
+				commit("g1", "acme/api", "erin", 300, "fix: follow-up to PR-7"),
+			},
+			want: "0/0/0 2 0.00",
+		},
+		{
+			name: "open PRs link nothing",
+			commits: []bitbucket.Commit{
+				commit("h1", "acme/api", "dave", 31, "feat: draft (PR-13)"),
+			},
+			want: "0/0/0 1 0.00",
+		},
+		{
+			name: "authorship only counts within the PR's repository",
+			commits: []bitbucket.Commit{
+				commit("i1", "acme/web", "alice", 15, "chore: tidy"),
+			},
+			want: "0/0/0 1 0.00",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := CalculateCommitPRLinkage(tt.commits, prs)
+			got := fmt.Sprintf("%d/%d/%d %d %.2f", l.LinkedByHash, l.LinkedByMessage, l.LinkedByAuthor, l.OrphanCommits, l.PRCoverageRate)
+			if got != tt.want {
+				t.Errorf("linkage = %s, want %s", got, tt.want)
+			}
+			if l.CommitsInPRs+l.OrphanCommits != len(tt.commits) {
+				t.Errorf("CommitsInPRs + OrphanCommits = %d, want %d", l.CommitsInPRs+l.OrphanCommits, len(tt.commits))
+			}
+		})
+	}
+}
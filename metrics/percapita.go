@@ -0,0 +1,34 @@
+package metrics
+
+// PerCapita normalizes team output by team size so teams of different sizes can be compared
+type PerCapita struct {
+	TeamSize                       int     `json:"team_size"`
+	TeamSizeSource                 string  `json:"team_size_source"` // "configured" or "active_contributors"
+	CommitsPerContributor          float64 `json:"commits_per_contributor"`
+	LinesChangedPerContributor     float64 `json:"lines_changed_per_contributor"`
+	PRsPerContributor              float64 `json:"prs_per_contributor"`
+	MergedPRsPerContributor        float64 `json:"merged_prs_per_contributor"`
+	CompletedStoriesPerContributor float64 `json:"completed_stories_per_contributor"`
+}
+
+// calculatePerCapita divides the team totals by the configured headcount, or by the number
+// of distinct commit authors when no headcount is configured
+func calculatePerCapita(m TeamMetrics, headcount int) PerCapita {
+	perCapita := PerCapita{TeamSize: headcount, TeamSizeSource: "configured"}
+	if headcount <= 0 {
+		perCapita.TeamSize = len(m.OrgRollup.Contributors)
+		perCapita.TeamSizeSource = "active_contributors"
+	}
+	if perCapita.TeamSize == 0 {
+		return perCapita
+	}
+
+	size := float64(perCapita.TeamSize)
+	perCapita.CommitsPerContributor = float64(m.OrgRollup.TotalCommits) / size
+	perCapita.LinesChangedPerContributor = float64(m.CommitMetrics.TotalLinesAdded+m.CommitMetrics.TotalLinesDeleted) / size
+	perCapita.PRsPerContributor = float64(m.PRMetrics.TotalPRs) / size
+	perCapita.MergedPRsPerContributor = float64(m.PRMetrics.MergedPRs) / size
+	perCapita.CompletedStoriesPerContributor = float64(m.JiraMetrics.CompletedStories) / size
+
+	return perCapita
+}
@@ -0,0 +1,117 @@
+package metrics
+
+import (
+	"fmt"
+	"time"
+
+	"devops-metrics/bitbucket"
+	"devops-metrics/config"
+	"devops-metrics/github"
+	"devops-metrics/jira"
+)
+
+// Supported sub-period granularities for CalculatePeriodMetrics
+const (
+	PeriodMonth   = "month"
+	PeriodQuarter = "quarter"
+)
+
+// PeriodMetrics holds the team metrics for one sub-period of the analysis window
+type PeriodMetrics struct {
+	Period  string      `json:"period"` // e.g. 2024-03 or 2024-Q1
+	Start   time.Time   `json:"start"`
+	End     time.Time   `json:"end"`
+	Metrics TeamMetrics `json:"metrics"`
+}
+
+// ValidPeriod reports whether period is a supported granularity
+func ValidPeriod(period string) bool {
+	return period == PeriodMonth || period == PeriodQuarter
+}
+
+// periodStart returns the start of the month or quarter containing t
+func periodStart(t time.Time, period string) time.Time {
+	month := t.Month()
+	if period == PeriodQuarter {
+		month = time.Month((int(month)-1)/3*3 + 1)
+	}
+	return time.Date(t.Year(), month, 1, 0, 0, 0, 0, t.Location())
+}
+
+// periodLabel names the sub-period starting at start
+func periodLabel(start time.Time, period string) string {
+	if period == PeriodQuarter {
+		return fmt.Sprintf("%d-Q%d", start.Year(), (int(start.Month())-1)/3+1)
+	}
+	return start.Format("2006-01")
+}
+
+// CalculatePeriodMetrics partitions the analysis window into calendar months or quarters
+// and computes TeamMetrics for each. Commits and deployments are assigned by their own date,
+// PRs and stories by creation date. Periods with no activity are included so the series has no gaps.
+func CalculatePeriodMetrics(commits []bitbucket.Commit, prs []bitbucket.PullRequest, stories []jira.JiraStory, deployments []github.Deployment, cfg config.Config, period string) ([]PeriodMetrics, error) {
+	if !ValidPeriod(period) {
+		return nil, fmt.Errorf("unsupported period %q: use %q or %q", period, PeriodMonth, PeriodQuarter)
+	}
+
+	windowStart, windowEnd := cfg.Window()
+
+	type bucket struct {
+		commits     []bitbucket.Commit
+		prs         []bitbucket.PullRequest
+		stories     []jira.JiraStory
+		deployments []github.Deployment
+	}
+	buckets := make(map[time.Time]*bucket)
+	var starts []time.Time
+	for start := periodStart(windowStart, period); start.Before(windowEnd); {
+		buckets[start] = &bucket{}
+		starts = append(starts, start)
+		if period == PeriodQuarter {
+			start = start.AddDate(0, 3, 0)
+		} else {
+			start = start.AddDate(0, 1, 0)
+		}
+	}
+
+	bucketFor := func(t time.Time) *bucket {
+		return buckets[periodStart(t.In(windowEnd.Location()), period)]
+	}
+	for _, c := range commits {
+		if b := bucketFor(c.Date); b != nil {
+			b.commits = append(b.commits, c)
+		}
+	}
+	for _, pr := range prs {
+		if b := bucketFor(pr.CreatedAt); b != nil {
+			b.prs = append(b.prs, pr)
+		}
+	}
+	for _, s := range stories {
+		if b := bucketFor(s.CreatedAt); b != nil {
+			b.stories = append(b.stories, s)
+		}
+	}
+	for _, d := range deployments {
+		if b := bucketFor(d.CreatedAt); b != nil {
+			b.deployments = append(b.deployments, d)
+		}
+	}
+
+	series := make([]PeriodMetrics, 0, len(starts))
+	for i, start := range starts {
+		end := windowEnd
+		if i+1 < len(starts) {
+			end = starts[i+1]
+		}
+		b := buckets[start]
+		series = append(series, PeriodMetrics{
+			Period:  periodLabel(start, period),
+			Start:   start,
+			End:     end,
+			Metrics: CalculateTeamMetrics(b.commits, b.prs, b.stories, b.deployments, cfg),
+		})
+	}
+
+	return series, nil
+}
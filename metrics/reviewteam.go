@@ -0,0 +1,76 @@
+package metrics
+
+import (
+	"sort"
+
+	"devops-metrics/bitbucket"
+)
+
+// ReviewTeamMetrics describes the review load and turnaround of a configured reviewer group,
+// scoped to the PRs that at least one group member reviewed
+type ReviewTeamMetrics struct {
+	Members            []string       `json:"members"`
+	PRsReviewed        int            `json:"prs_reviewed"`
+	ReviewsByMember    map[string]int `json:"reviews_by_member"`
+	AvgTurnaroundHours float64        `json:"avg_turnaround_hours"`
+	AvgCycleTimeHours  float64        `json:"avg_cycle_time_hours"`
+	ShareOfAllPRsPct   float64        `json:"share_of_all_prs_percent"`
+	IdleMembers        []string       `json:"idle_members"`
+}
+
+// CalculateReviewTeamMetrics filters PRs by reviewer membership in team and summarizes the group's load
+func CalculateReviewTeamMetrics(prs []bitbucket.PullRequest, team []string) ReviewTeamMetrics {
+	metrics := ReviewTeamMetrics{
+		Members:         append([]string(nil), team...),
+		ReviewsByMember: make(map[string]int),
+	}
+	sort.Strings(metrics.Members)
+
+	isMember := make(map[string]bool)
+	for _, member := range team {
+		isMember[member] = true
+		metrics.ReviewsByMember[member] = 0
+	}
+
+	var totalTurnaround, totalCycleTime float64
+	var turnaroundCount, cycleTimeCount int
+	for _, pr := range prs {
+		reviewedByTeam := false
+		for _, reviewer := range pr.Reviewers {
+			if isMember[reviewer] {
+				metrics.ReviewsByMember[reviewer]++
+				reviewedByTeam = true
+			}
+		}
+		if !reviewedByTeam {
+			continue
+		}
+
+		metrics.PRsReviewed++
+		if pr.FirstReviewAt != nil {
+			totalTurnaround += pr.FirstReviewAt.Sub(pr.CreatedAt).Hours()
+			turnaroundCount++
+		}
+		if pr.MergedAt != nil {
+			totalCycleTime += pr.MergedAt.Sub(pr.CreatedAt).Hours()
+			cycleTimeCount++
+		}
+	}
+
+	if turnaroundCount > 0 {
+		metrics.AvgTurnaroundHours = totalTurnaround / float64(turnaroundCount)
+	}
+	if cycleTimeCount > 0 {
+		metrics.AvgCycleTimeHours = totalCycleTime / float64(cycleTimeCount)
+	}
+	if len(prs) > 0 {
+		metrics.ShareOfAllPRsPct = float64(metrics.PRsReviewed) / float64(len(prs)) * 100
+	}
+	for _, member := range metrics.Members {
+		if metrics.ReviewsByMember[member] == 0 {
+			metrics.IdleMembers = append(metrics.IdleMembers, member)
+		}
+	}
+
+	return metrics
+}
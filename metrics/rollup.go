@@ -0,0 +1,103 @@
+package metrics
+
+import (
+	"sort"
+
+	"devops-metrics/bitbucket"
+	"devops-metrics/config"
+)
+
+// ContributorRollup summarizes one person's activity across every analyzed repository
+type ContributorRollup struct {
+	Author              string   `json:"author"`
+	Commits             int      `json:"commits"`
+	ActiveDays          int      `json:"active_days"`
+	CommitsPerActiveDay float64  `json:"commits_per_active_day"`
+	Repos               []string `json:"repos"`
+}
+
+// OrgRollup aggregates commit activity across repositories, counting each
+// contributor's commits and active days once even when they work in several repos
+type OrgRollup struct {
+	TotalCommits        int                 `json:"total_commits"`
+	Contributors        []ContributorRollup `json:"contributors"`
+	SharedContributors  int                 `json:"shared_contributors"`
+	PersonActiveDays    int                 `json:"person_active_days"`
+	CommitsPerActiveDay float64             `json:"commits_per_active_day"`
+}
+
+// CalculateOrgRollup combines repo-tagged commits into a per-person, cross-repo view.
+// Commits seen more than once (e.g. on several branches or mirrored repos) are counted once,
+// and an active day is counted once per person regardless of how many repos they touched.
+func CalculateOrgRollup(commits []bitbucket.Commit, cfg config.Config) OrgRollup {
+	rollup := OrgRollup{}
+	if len(commits) == 0 {
+		return rollup
+	}
+
+	type contributor struct {
+		commits int
+		days    map[string]bool
+		repos   map[string]bool
+	}
+
+	seen := make(map[string]bool)
+	byAuthor := make(map[string]*contributor)
+	loc := cfg.ReportLocation()
+	for _, c := range commits {
+		if c.Hash != "" {
+			if seen[c.Hash] {
+				continue
+			}
+			seen[c.Hash] = true
+		}
+
+		author := cfg.AuthorOrFallback(c.Author, "")
+		person, ok := byAuthor[author]
+		if !ok {
+			person = &contributor{days: make(map[string]bool), repos: make(map[string]bool)}
+			byAuthor[author] = person
+		}
+		person.commits++
+		// Same day boundaries as CalculateCommitMetrics, whatever zone the provider parsed into
+		person.days[c.Date.In(loc).Format("2006-01-02")] = true
+		if c.Repo != "" {
+			person.repos[c.Repo] = true
+		}
+		rollup.TotalCommits++
+	}
+
+	for author, person := range byAuthor {
+		repos := make([]string, 0, len(person.repos))
+		for repo := range person.repos {
+			repos = append(repos, repo)
+		}
+		sort.Strings(repos)
+
+		cr := ContributorRollup{
+			Author:     author,
+			Commits:    person.commits,
+			ActiveDays: len(person.days),
+			Repos:      repos,
+		}
+		if cr.ActiveDays > 0 {
+			cr.CommitsPerActiveDay = float64(cr.Commits) / float64(cr.ActiveDays)
+		}
+
+		rollup.Contributors = append(rollup.Contributors, cr)
+		rollup.PersonActiveDays += cr.ActiveDays
+		if len(repos) > 1 {
+			rollup.SharedContributors++
+		}
+	}
+
+	sort.Slice(rollup.Contributors, func(i, j int) bool {
+		return rollup.Contributors[i].Author < rollup.Contributors[j].Author
+	})
+
+	if rollup.PersonActiveDays > 0 {
+		rollup.CommitsPerActiveDay = float64(rollup.TotalCommits) / float64(rollup.PersonActiveDays)
+	}
+
+	return rollup
+}
@@ -0,0 +1,118 @@
+package metrics
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"devops-metrics/bitbucket"
+	"devops-metrics/config"
+)
+
+// zonedCommit returns a commit at the given UTC instant, carried in zone the way a provider
+// might parse it
+func zonedCommit(t *testing.T, hash, utc string, zone *time.Location) bitbucket.Commit {
+	t.Helper()
+	ts, err := time.Parse(time.RFC3339, utc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return bitbucket.Commit{Hash: hash, Author: "dev", Date: ts.In(zone), Message: "fix: change", Repo: "acme/api"}
+}
+
+func TestCommitDaysAroundMidnight(t *testing.T) {
+	pacific := time.FixedZone("PDT", -7*3600)
+	tests := []struct {
+		name         string
+		timezone     string
+		commits      []string // UTC instants
+		wantDays     int
+		wantWeekdays map[string]int
+	}{
+		{
+			name:         "UTC splits late evening and early morning",
+			commits:      []string{"2025-01-05T23:30:00Z", "2025-01-06T00:30:00Z"},
+			wantDays:     2,
+			wantWeekdays: map[string]int{"Sunday": 1, "Monday": 1},
+		},
+		{
+			name:         "Tokyo puts Sunday night UTC on Monday",
+			timezone:     "Asia/Tokyo",
+			commits:      []string{"2025-01-05T23:30:00Z", "2025-01-06T00:30:00Z"},
+			wantDays:     1,
+			wantWeekdays: map[string]int{"Monday": 2},
+		},
+		{
+			name:         "New York puts Monday early UTC on Sunday",
+			timezone:     "America/New_York",
+			commits:      []string{"2025-01-05T23:30:00Z", "2025-01-06T00:30:00Z"},
+			wantDays:     1,
+			wantWeekdays: map[string]int{"Sunday": 2},
+		},
+		{
+			name:         "just before and after local midnight",
+			timezone:     "Europe/Berlin",
+			commits:      []string{"2025-01-06T22:59:59Z", "2025-01-06T23:00:00Z"},
+			wantDays:     2,
+			wantWeekdays: map[string]int{"Monday": 1, "Tuesday": 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.Config{Timezone: tt.timezone}
+			// The provider's zone must not matter, so each commit arrives in a different one
+			var commits []bitbucket.Commit
+			for i, utc := range tt.commits {
+				zone := time.UTC
+				if i%2 == 1 {
+					zone = pacific
+				}
+				commits = append(commits, zonedCommit(t, fmt.Sprint(i), utc, zone))
+			}
+
+			m := CalculateCommitMetrics(commits, cfg)
+			if m.ActiveDays != tt.wantDays {
+				t.Errorf("ActiveDays = %d, want %d", m.ActiveDays, tt.wantDays)
+			}
+			if fmt.Sprint(m.CommitsByWeekday) != fmt.Sprint(tt.wantWeekdays) {
+				t.Errorf("CommitsByWeekday = %v, want %v", m.CommitsByWeekday, tt.wantWeekdays)
+			}
+			if rollup := CalculateOrgRollup(commits, cfg); rollup.PersonActiveDays != tt.wantDays {
+				t.Errorf("rollup PersonActiveDays = %d, want %d", rollup.PersonActiveDays, tt.wantDays)
+			}
+		})
+	}
+}
+
+func TestWeeklyTrendsWeekBoundary(t *testing.T) {
+	tests := []struct {
+		name     string
+		timezone string
+		want     string // commits per ISO week
+	}{
+		{"UTC keeps Sunday night in the first week", "", "[2025-W01:1 2025-W02:1]"},
+		{"Tokyo moves Sunday night into the second week", "Asia/Tokyo", "[2025-W01:0 2025-W02:2]"},
+		{"Los Angeles moves Monday morning into the first week", "America/Los_Angeles", "[2025-W01:2 2025-W02:0]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.Config{
+				Timezone:    tt.timezone,
+				WindowStart: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+				WindowEnd:   time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC),
+			}
+			commits := []bitbucket.Commit{
+				zonedCommit(t, "a", "2025-01-05T22:00:00Z", time.UTC), // Sunday night UTC
+				zonedCommit(t, "b", "2025-01-06T02:00:00Z", time.UTC), // Monday morning UTC
+			}
+
+			var got []string
+			for _, week := range CalculateWeeklyTrends(commits, nil, nil, 2, cfg) {
+				got = append(got, fmt.Sprintf("%s:%d", week.Week, week.CommitMetrics.TotalCommits))
+			}
+			if fmt.Sprint(got) != tt.want {
+				t.Errorf("weekly commits = %v, want %s", got, tt.want)
+			}
+		})
+	}
+}
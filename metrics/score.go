@@ -0,0 +1,67 @@
+package metrics
+
+import "devops-metrics/config"
+
+// Reference points at which a productivity sub-metric reaches its full score
+const (
+	scoreThroughputTarget = 1.0  // Completed stories per contributor per week
+	scoreCycleTimeTarget  = 24.0 // Average PR cycle time in hours
+)
+
+// CalculateProductivityScore combines four sub-metrics into a single 0-100 headline number:
+//
+//	score = 100 * Σ(weight_i * s_i) / Σ(weight_i)
+//
+// where each s_i is normalized to [0, 1]:
+//
+//   - throughput: completed stories per contributor per week divided by scoreThroughputTarget
+//   - merge success: MergeSuccessRate / 100
+//   - estimate accuracy: EstimateAccuracy / 100, 0 when no story had both estimate and actual
+//   - cycle time: scoreCycleTimeTarget / average cycle time in hours, 1 at or below the target
+//     and 0 when no PR was merged
+//
+// Sub-metrics without data count as 0, so a team with no activity scores 0. Weights come from
+// cfg.ScoreWeights (equal by default) and the result is clamped to [0, 100].
+func CalculateProductivityScore(m TeamMetrics, cfg config.Config) float64 {
+	w := cfg.Weights()
+	totalWeight := w.Throughput + w.MergeSuccess + w.EstimateAccuracy + w.CycleTime
+	if totalWeight <= 0 {
+		return 0
+	}
+
+	throughput := m.JiraMetrics.Throughput
+	if m.PerCapita.TeamSize > 0 {
+		throughput /= float64(m.PerCapita.TeamSize)
+	}
+
+	var accuracy float64
+	if m.JiraMetrics.AccuracySample > 0 {
+		accuracy = m.JiraMetrics.EstimateAccuracy / 100
+	}
+
+	var cycleTime float64
+	if m.PRMetrics.MergedPRs > 0 {
+		cycleTime = 1
+		if m.PRMetrics.AvgCycleTimeHours > scoreCycleTimeTarget {
+			cycleTime = scoreCycleTimeTarget / m.PRMetrics.AvgCycleTimeHours
+		}
+	}
+
+	weighted := w.Throughput*clamp(throughput/scoreThroughputTarget, 0, 1) +
+		w.MergeSuccess*clamp(m.PRMetrics.MergeSuccessRate/100, 0, 1) +
+		w.EstimateAccuracy*clamp(accuracy, 0, 1) +
+		w.CycleTime*clamp(cycleTime, 0, 1)
+
+	return clamp(100*weighted/totalWeight, 0, 100)
+}
+
+// clamp limits v to [min, max]
+func clamp(v, min, max float64) float64 {
+	if v < min {
+		return min
+	}
+	if v > max {
+		return max
+	}
+	return v
+}
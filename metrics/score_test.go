@@ -0,0 +1,49 @@
+package metrics
+
+import (
+	"math"
+	"testing"
+
+	"devops-metrics/config"
+)
+
+func TestCalculateProductivityScore(t *testing.T) {
+	healthy := TeamMetrics{
+		PRMetrics:   PRMetrics{MergedPRs: 20, MergeSuccessRate: 95, AvgCycleTimeHours: 18},
+		JiraMetrics: JiraMetrics{Throughput: 5, EstimateAccuracy: 85, AccuracySample: 12},
+		PerCapita:   PerCapita{TeamSize: 4},
+	}
+	slow := healthy
+	slow.PRMetrics.AvgCycleTimeHours = 96
+
+	tests := []struct {
+		name    string
+		metrics TeamMetrics
+		weights config.ScoreWeights
+		want    float64
+	}{
+		{"all zero", TeamMetrics{}, config.ScoreWeights{}, 0},
+		{"all zero with custom weights", TeamMetrics{}, config.ScoreWeights{Throughput: 3, CycleTime: 1}, 0},
+		// (1 + 0.95 + 0.85 + 1) / 4
+		{"healthy team", healthy, config.ScoreWeights{}, 95},
+		// (1 + 0.95 + 0.85 + 0.25) / 4
+		{"slow reviews", slow, config.ScoreWeights{}, 76.25},
+		// Only cycle time counts
+		{"weights select sub-metrics", slow, config.ScoreWeights{CycleTime: 1}, 25},
+		// Accuracy without a sample counts as 0
+		{"no accuracy sample", TeamMetrics{JiraMetrics: JiraMetrics{EstimateAccuracy: 100}}, config.ScoreWeights{}, 0},
+		// Throughput and merge rate above target are capped
+		{"clamped", TeamMetrics{
+			PRMetrics:   PRMetrics{MergedPRs: 1, MergeSuccessRate: 150, AvgCycleTimeHours: 1},
+			JiraMetrics: JiraMetrics{Throughput: 100, EstimateAccuracy: 100, AccuracySample: 1},
+		}, config.ScoreWeights{}, 100},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := CalculateProductivityScore(tt.metrics, config.Config{ScoreWeights: tt.weights})
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("CalculateProductivityScore() = %.2f, want %.2f", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package metrics
+
+import "time"
+
+// SeriesPoint is one value of a time series, with an optional rolling-average smoothed value
+type SeriesPoint struct {
+	Date     string  `json:"date"`
+	Value    float64 `json:"value"`
+	Smoothed float64 `json:"smoothed"`
+}
+
+// RollingAverage returns the trailing N-point moving average of values. Points before a full
+// window is available average over what is there so far. A window of 1 or less returns a copy.
+func RollingAverage(values []float64, window int) []float64 {
+	smoothed := make([]float64, len(values))
+	if window <= 1 {
+		copy(smoothed, values)
+		return smoothed
+	}
+
+	var sum float64
+	for i, v := range values {
+		sum += v
+		if i >= window {
+			sum -= values[i-window]
+		}
+		n := i + 1
+		if n > window {
+			n = window
+		}
+		smoothed[i] = sum / float64(n)
+	}
+	return smoothed
+}
+
+// dailySeries builds a gap-free daily series between the first and last day in counts
+// and fills in the smoothed values using the given window
+func dailySeries(counts map[string]int, minDate, maxDate time.Time, window int) []SeriesPoint {
+	if len(counts) == 0 {
+		return nil
+	}
+
+	start := time.Date(minDate.Year(), minDate.Month(), minDate.Day(), 0, 0, 0, 0, minDate.Location())
+	end := time.Date(maxDate.Year(), maxDate.Month(), maxDate.Day(), 0, 0, 0, 0, maxDate.Location())
+
+	var series []SeriesPoint
+	var values []float64
+	for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+		key := day.Format("2006-01-02")
+		series = append(series, SeriesPoint{Date: key, Value: float64(counts[key])})
+		values = append(values, float64(counts[key]))
+	}
+
+	for i, v := range RollingAverage(values, window) {
+		series[i].Smoothed = v
+	}
+	return series
+}
@@ -0,0 +1,91 @@
+package metrics
+
+import (
+	"sort"
+	"time"
+
+	"devops-metrics/jira"
+)
+
+// SprintMetrics summarizes what a sprint committed to and delivered, in stories and story
+// points (the stories' estimates)
+type SprintMetrics struct {
+	SprintID         int        `json:"sprint_id"`
+	Name             string     `json:"name"`
+	State            string     `json:"state"`
+	StartDate        *time.Time `json:"start_date,omitempty"`
+	EndDate          *time.Time `json:"end_date,omitempty"`
+	CommittedStories int        `json:"committed_stories"`
+	CommittedPoints  float64    `json:"committed_points"`
+	CompletedStories int        `json:"completed_stories"`
+	CompletedPoints  float64    `json:"completed_points"`
+	CompletionRate   float64    `json:"completion_rate"` // Percentage of committed points completed
+	Velocity         float64    `json:"velocity"`        // Story points completed in the sprint
+	CarryoverCount   int        `json:"carryover_count"` // Stories moved on to a later sprint unfinished
+	CarryoverKeys    []string   `json:"carryover_keys,omitempty"`
+}
+
+// CalculateSprintMetrics computes the metrics of the sprint with the given ID or name from
+// the stories planned into it. Jira keeps no history of when a story joined the sprint, so
+// every story in the sprint counts as committed. A story counts as completed in the sprint
+// when it is done, the sprint is its last one and it was completed before the sprint closed
+// (or ended, while the sprint is still open); a story that also belongs to a later sprint
+// was carried over. The boolean is false when no story belongs to the sprint.
+func CalculateSprintMetrics(sprint string, stories []jira.JiraStory) (SprintMetrics, bool) {
+	var metrics SprintMetrics
+	found := false
+
+	for _, s := range stories {
+		index := -1
+		for i, sp := range s.Sprints {
+			if sp.Matches(sprint) {
+				index = i
+				break
+			}
+		}
+		if index < 0 {
+			continue
+		}
+		if !found {
+			sp := s.Sprints[index]
+			metrics.SprintID, metrics.Name, metrics.State = sp.ID, sp.Name, sp.State
+			metrics.StartDate, metrics.EndDate = sp.StartDate, sp.EndDate
+			found = true
+		}
+
+		metrics.CommittedStories++
+		metrics.CommittedPoints += s.Estimate
+
+		// Sprints are ordered oldest first, so any later entry means the story moved on
+		if index < len(s.Sprints)-1 {
+			metrics.CarryoverCount++
+			metrics.CarryoverKeys = append(metrics.CarryoverKeys, s.Key)
+			continue
+		}
+		if isCompletedStatus(s.Status) && completedInSprint(s, s.Sprints[index]) {
+			metrics.CompletedStories++
+			metrics.CompletedPoints += s.Estimate
+		}
+	}
+	if !found {
+		return metrics, false
+	}
+
+	sort.Strings(metrics.CarryoverKeys)
+	metrics.Velocity = metrics.CompletedPoints
+	if metrics.CommittedPoints > 0 {
+		metrics.CompletionRate = metrics.CompletedPoints / metrics.CommittedPoints * 100
+	}
+	return metrics, true
+}
+
+// completedInSprint reports whether the story was completed before the sprint closed, or
+// before its planned end when it has not closed. Stories without a completion time and
+// sprints without an end date are given the benefit of the doubt.
+func completedInSprint(s jira.JiraStory, sprint jira.Sprint) bool {
+	end := sprint.CompleteDate
+	if end == nil {
+		end = sprint.EndDate
+	}
+	return s.CompletedAt == nil || end == nil || !s.CompletedAt.After(*end)
+}
@@ -0,0 +1,58 @@
+package metrics
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"devops-metrics/jira"
+)
+
+func TestCalculateSprintMetrics(t *testing.T) {
+	day := func(d int) *time.Time {
+		t := benchmarkStart.AddDate(0, 0, d)
+		return &t
+	}
+	sprint5 := jira.Sprint{ID: 5, Name: "Sprint 5", State: "closed", StartDate: day(0), EndDate: day(14), CompleteDate: day(15)}
+	sprint6 := jira.Sprint{ID: 6, Name: "Sprint 6", State: "active", StartDate: day(15), EndDate: day(29)}
+	story := func(key, status string, points float64, completed *time.Time, sprints ...jira.Sprint) jira.JiraStory {
+		return jira.JiraStory{Key: key, Status: status, Estimate: points, CompletedAt: completed, Sprints: sprints}
+	}
+	stories := []jira.JiraStory{
+		story("A-1", "Done", 3, day(5), sprint5),
+		story("A-2", "Done", 5, day(20), sprint5, sprint6), // Carried over, then done in sprint 6
+		story("A-3", "In Progress", 2, nil, sprint5, sprint6),
+		story("A-4", "Done", 8, day(18), sprint5), // Done after sprint 5 closed
+		story("A-5", "Done", 1, day(15), sprint5), // Done on the day it closed
+		story("A-6", "Done", 2, day(22), sprint6),
+		story("A-7", "To Do", 3, nil, sprint6),
+		story("A-8", "Done", 13, day(3)),
+	}
+
+	tests := []struct {
+		name      string
+		sprint    string
+		wantFound bool
+		want      string // committed stories/points, completed stories/points, rate, carryovers
+	}{
+		{"closed sprint by id", "5", true, "5/19 2/4 21.05 [A-2 A-3]"},
+		{"active sprint by name", "sprint 6", true, "4/12 2/7 58.33 []"},
+		{"unknown sprint", "7", false, "0/0 0/0 0.00 []"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, found := CalculateSprintMetrics(tt.sprint, stories)
+			if found != tt.wantFound {
+				t.Fatalf("found = %v, want %v", found, tt.wantFound)
+			}
+			got := fmt.Sprintf("%d/%g %d/%g %.2f %v", m.CommittedStories, m.CommittedPoints,
+				m.CompletedStories, m.CompletedPoints, m.CompletionRate, m.CarryoverKeys)
+			if got != tt.want {
+				t.Errorf("sprint metrics = %s, want %s", got, tt.want)
+			}
+			if m.Velocity != m.CompletedPoints {
+				t.Errorf("Velocity = %v, want completed points %v", m.Velocity, m.CompletedPoints)
+			}
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package metrics
+
+import (
+	"sort"
+	"time"
+
+	"devops-metrics/bitbucket"
+	"devops-metrics/jira"
+)
+
+// StaleStories reports open stories whose status hasn't changed for longer than the threshold
+type StaleStories struct {
+	ThresholdDays  int      `json:"threshold_days"`
+	Count          int      `json:"count"`
+	Keys           []string `json:"keys"`
+	OldestKey      string   `json:"oldest_key,omitempty"`
+	OldestIdleDays float64  `json:"oldest_idle_days"`
+}
+
+// calculateStaleStories finds stories not in a done status whose last status change
+// (or creation, if the status never changed) is older than thresholdDays
+func calculateStaleStories(stories []jira.JiraStory, thresholdDays int, now time.Time) StaleStories {
+	stale := StaleStories{ThresholdDays: thresholdDays, Keys: []string{}}
+	if thresholdDays <= 0 {
+		return stale
+	}
+
+	idleByKey := make(map[string]float64)
+	for _, s := range stories {
+		if isCompletedStatus(s.Status) || s.CompletedAt != nil {
+			continue
+		}
+
+		lastActivity := s.CreatedAt
+		if s.LastStatusChangeAt != nil {
+			lastActivity = *s.LastStatusChangeAt
+		}
+
+		idleDays := now.Sub(lastActivity).Hours() / 24
+		if idleDays <= float64(thresholdDays) {
+			continue
+		}
+
+		stale.Keys = append(stale.Keys, s.Key)
+		idleByKey[s.Key] = idleDays
+		if idleDays > stale.OldestIdleDays {
+			stale.OldestIdleDays = idleDays
+			stale.OldestKey = s.Key
+		}
+	}
+
+	// Longest idle first
+	sort.SliceStable(stale.Keys, func(i, j int) bool {
+		return idleByKey[stale.Keys[i]] > idleByKey[stale.Keys[j]]
+	})
+	stale.Count = len(stale.Keys)
+
+	return stale
+}
+
+// calculateOpenAge counts the stories that are not done and returns their average and
+// maximum age in days, measured from creation to now
+func calculateOpenAge(stories []jira.JiraStory, now time.Time) (open int, avgDays, oldestDays float64) {
+	var total float64
+	for _, s := range stories {
+		if isCompletedStatus(s.Status) || s.CompletedAt != nil {
+			continue
+		}
+		age := now.Sub(s.CreatedAt).Hours() / 24
+		total += age
+		if age > oldestDays {
+			oldestDays = age
+		}
+		open++
+	}
+	if open > 0 {
+		avgDays = total / float64(open)
+	}
+	return open, avgDays, oldestDays
+}
+
+// StalePRs reports open PRs that look abandoned along two separate dimensions: idle PRs have
+// not been updated recently, aged PRs were opened long ago. A PR that is rebased or commented
+// on to look active is still aged.
+type StalePRs struct {
+	IdleThresholdDays int      `json:"idle_threshold_days"`
+	IdleCount         int      `json:"idle_count"`
+	IdleIDs           []string `json:"idle_ids"`
+	AgeThresholdDays  int      `json:"age_threshold_days"`
+	AgedCount         int      `json:"aged_count"`
+	AgedIDs           []string `json:"aged_ids"`
+	OldestID          string   `json:"oldest_id,omitempty"`
+	OldestAgeDays     float64  `json:"oldest_age_days"`
+}
+
+// calculateStalePRs finds open PRs last updated more than idleDays ago (or created, when the
+// update time is unknown) and, independently, open PRs created more than maxAgeDays ago.
+// A threshold of 0 disables that dimension. Both lists are sorted oldest first.
+func calculateStalePRs(prs []bitbucket.PullRequest, idleDays, maxAgeDays int, now time.Time) StalePRs {
+	stale := StalePRs{
+		IdleThresholdDays: idleDays,
+		IdleIDs:           []string{},
+		AgeThresholdDays:  maxAgeDays,
+		AgedIDs:           []string{},
+	}
+
+	idleByID := make(map[string]float64)
+	ageByID := make(map[string]float64)
+	for _, pr := range prs {
+		if pr.Status != "OPEN" {
+			continue
+		}
+
+		ageDays := now.Sub(pr.CreatedAt).Hours() / 24
+		lastActivity := pr.CreatedAt
+		if pr.UpdatedAt != nil {
+			lastActivity = *pr.UpdatedAt
+		}
+		idle := now.Sub(lastActivity).Hours() / 24
+
+		if idleDays > 0 && idle > float64(idleDays) {
+			stale.IdleIDs = append(stale.IdleIDs, pr.ID)
+			idleByID[pr.ID] = idle
+		}
+		if maxAgeDays > 0 && ageDays > float64(maxAgeDays) {
+			stale.AgedIDs = append(stale.AgedIDs, pr.ID)
+			ageByID[pr.ID] = ageDays
+			if ageDays > stale.OldestAgeDays {
+				stale.OldestAgeDays = ageDays
+				stale.OldestID = pr.ID
+			}
+		}
+	}
+
+	sort.SliceStable(stale.IdleIDs, func(i, j int) bool {
+		return idleByID[stale.IdleIDs[i]] > idleByID[stale.IdleIDs[j]]
+	})
+	sort.SliceStable(stale.AgedIDs, func(i, j int) bool {
+		return ageByID[stale.AgedIDs[i]] > ageByID[stale.AgedIDs[j]]
+	})
+	stale.IdleCount = len(stale.IdleIDs)
+	stale.AgedCount = len(stale.AgedIDs)
+
+	return stale
+}
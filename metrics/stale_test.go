@@ -0,0 +1,64 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"devops-metrics/jira"
+)
+
+func TestCalculateOpenAge(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	story := func(status string, ageDays int, completed bool) jira.JiraStory {
+		s := jira.JiraStory{Status: status, CreatedAt: now.AddDate(0, 0, -ageDays)}
+		if completed {
+			done := now.AddDate(0, 0, -1)
+			s.CompletedAt = &done
+		}
+		return s
+	}
+
+	tests := []struct {
+		name       string
+		stories    []jira.JiraStory
+		wantOpen   int
+		wantAvg    float64
+		wantOldest float64
+	}{
+		{"no stories", nil, 0, 0, 0},
+		{
+			name: "mix of open and completed",
+			stories: []jira.JiraStory{
+				story("In Progress", 10, false),
+				story("To Do", 30, false),
+				story("Done", 90, true),
+				story("In Review", 2, false),
+			},
+			wantOpen: 3, wantAvg: 14, wantOldest: 30,
+		},
+		{
+			name: "done status without a completion date is not open",
+			stories: []jira.JiraStory{
+				story("Resolved", 60, false),
+				story("Backlog", 4, false),
+			},
+			wantOpen: 1, wantAvg: 4, wantOldest: 4,
+		},
+		{
+			name: "completion date without a done status is not open",
+			stories: []jira.JiraStory{
+				story("Closed", 45, true),
+			},
+			wantOpen: 0, wantAvg: 0, wantOldest: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			open, avg, oldest := calculateOpenAge(tt.stories, now)
+			if open != tt.wantOpen || avg != tt.wantAvg || oldest != tt.wantOldest {
+				t.Errorf("calculateOpenAge() = %d, %.2f, %.2f; want %d, %.2f, %.2f",
+					open, avg, oldest, tt.wantOpen, tt.wantAvg, tt.wantOldest)
+			}
+		})
+	}
+}
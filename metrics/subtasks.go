@@ -0,0 +1,64 @@
+package metrics
+
+import (
+	"devops-metrics/config"
+	"devops-metrics/jira"
+)
+
+// SubtaskMetrics reports how Jira sub-tasks were treated in the story metrics
+type SubtaskMetrics struct {
+	Mode            string  `json:"mode"`
+	Total           int     `json:"total"`
+	Completed       int     `json:"completed"`
+	RolledUp        int     `json:"rolled_up,omitempty"` // Sub-tasks whose effort was added to a parent in the result set
+	AvgActualEffort float64 `json:"avg_actual_effort,omitempty"`
+}
+
+// applySubtaskMode separates sub-tasks from stories according to mode. Sub-tasks stay in the
+// story set only in the include mode; otherwise they are dropped, after adding their estimate
+// and effort to their parent story in the rollup mode.
+func applySubtaskMode(stories []jira.JiraStory, mode string) ([]jira.JiraStory, *SubtaskMetrics) {
+	if mode == "" {
+		mode = config.SubtaskInclude
+	}
+
+	var subtasks []jira.JiraStory
+	kept := make([]jira.JiraStory, 0, len(stories))
+	for _, s := range stories {
+		if s.IsSubtask && mode != config.SubtaskInclude {
+			subtasks = append(subtasks, s)
+			continue
+		}
+		kept = append(kept, s)
+	}
+	if len(subtasks) == 0 {
+		return stories, nil
+	}
+
+	summary := &SubtaskMetrics{Mode: mode, Total: len(subtasks)}
+	parentIndex := make(map[string]int)
+	for i, s := range kept {
+		parentIndex[s.Key] = i
+	}
+
+	var totalActual float64
+	for _, s := range subtasks {
+		if isCompletedStatus(s.Status) {
+			summary.Completed++
+		}
+		totalActual += s.ActualEffort
+
+		if mode == config.SubtaskRollup {
+			if i, ok := parentIndex[s.ParentKey]; ok {
+				kept[i].Estimate += s.Estimate
+				kept[i].ActualEffort += s.ActualEffort
+				summary.RolledUp++
+			}
+		}
+	}
+	if mode == config.SubtaskSeparate {
+		summary.AvgActualEffort = totalActual / float64(len(subtasks))
+	}
+
+	return kept, summary
+}
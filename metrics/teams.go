@@ -0,0 +1,80 @@
+package metrics
+
+import (
+	"sort"
+
+	"devops-metrics/bitbucket"
+	"devops-metrics/config"
+)
+
+// unassignedTeam is the bucket for authors without a team in AuthorTeams
+const unassignedTeam = "(unassigned)"
+
+// TeamGroupMetrics summarizes the commits and PRs authored by the members of one team
+type TeamGroupMetrics struct {
+	Team              string   `json:"team"`
+	Members           []string `json:"members"` // Authors with activity in the analysis window
+	Commits           int      `json:"commits"`
+	PRs               int      `json:"prs"`
+	MergedPRs         int      `json:"merged_prs"`
+	AvgCycleTimeHours float64  `json:"avg_cycle_time_hours"`
+}
+
+// CalculateTeamGroups groups commits and PRs by the team of their author, using
+// cfg.AuthorTeams. Authors without a team are grouped under "(unassigned)". Teams are
+// sorted by name.
+func CalculateTeamGroups(commits []bitbucket.Commit, prs []bitbucket.PullRequest, cfg config.Config) []TeamGroupMetrics {
+	type group struct {
+		metrics        TeamGroupMetrics
+		members        map[string]bool
+		totalCycleTime float64
+	}
+
+	groups := make(map[string]*group)
+	groupFor := func(author string) *group {
+		team, ok := cfg.AuthorTeams[author]
+		// Authors arrive under their canonical name; teams may be keyed by an alias such as a GitHub login
+		for _, alias := range cfg.AuthorAliases[author] {
+			if ok {
+				break
+			}
+			team, ok = cfg.AuthorTeams[alias]
+		}
+		if !ok || team == "" {
+			team = unassignedTeam
+		}
+		g, ok := groups[team]
+		if !ok {
+			g = &group{metrics: TeamGroupMetrics{Team: team}, members: make(map[string]bool)}
+			groups[team] = g
+		}
+		g.members[author] = true
+		return g
+	}
+
+	for _, c := range commits {
+		groupFor(cfg.AuthorOrFallback(c.Author, "")).metrics.Commits++
+	}
+	for _, pr := range prs {
+		g := groupFor(pr.Author)
+		g.metrics.PRs++
+		if pr.MergedAt != nil {
+			g.metrics.MergedPRs++
+			g.totalCycleTime += pr.MergedAt.Sub(pr.CreatedAt).Hours()
+		}
+	}
+
+	result := make([]TeamGroupMetrics, 0, len(groups))
+	for _, g := range groups {
+		for member := range g.members {
+			g.metrics.Members = append(g.metrics.Members, member)
+		}
+		sort.Strings(g.metrics.Members)
+		if g.metrics.MergedPRs > 0 {
+			g.metrics.AvgCycleTimeHours = g.totalCycleTime / float64(g.metrics.MergedPRs)
+		}
+		result = append(result, g.metrics)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Team < result[j].Team })
+	return result
+}
@@ -0,0 +1,88 @@
+package metrics
+
+import (
+	"fmt"
+	"time"
+
+	"devops-metrics/bitbucket"
+	"devops-metrics/config"
+	"devops-metrics/jira"
+)
+
+// WeeklyMetrics holds the commit, PR and story metrics of one ISO week
+type WeeklyMetrics struct {
+	Week          string        `json:"week"`  // ISO week, e.g. 2024-W09
+	Start         time.Time     `json:"start"` // Monday 00:00 in the report zone
+	End           time.Time     `json:"end"`   // Start of the following week
+	CommitMetrics CommitMetrics `json:"commit_metrics"`
+	PRMetrics     PRMetrics     `json:"pr_metrics"`
+	JiraMetrics   JiraMetrics   `json:"jira_metrics"`
+}
+
+// weekStart returns midnight of the Monday of the ISO week containing t, in t's zone
+func weekStart(t time.Time) time.Time {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+}
+
+// CalculateWeeklyTrends buckets commits, PRs and stories by ISO week and computes the metrics
+// of each week, oldest first. The series covers the last weeks weeks up to the end of the
+// analysis window, or every week overlapping the window when weeks is not positive. Commits
+// are assigned by commit date, PRs and stories by creation date; weeks with no activity are
+// included with zero metrics so charts have no gaps.
+func CalculateWeeklyTrends(commits []bitbucket.Commit, prs []bitbucket.PullRequest, stories []jira.JiraStory, weeks int, cfg config.Config) []WeeklyMetrics {
+	loc := cfg.ReportLocation()
+	windowStart, windowEnd := cfg.Window()
+
+	last := weekStart(windowEnd.Add(-time.Nanosecond).In(loc))
+	first := weekStart(windowStart.In(loc))
+	if weeks > 0 {
+		first = last.AddDate(0, 0, -7*(weeks-1))
+	}
+
+	type bucket struct {
+		commits []bitbucket.Commit
+		prs     []bitbucket.PullRequest
+		stories []jira.JiraStory
+	}
+	buckets := make(map[time.Time]*bucket)
+	var starts []time.Time
+	for start := first; !start.After(last); start = start.AddDate(0, 0, 7) {
+		buckets[start] = &bucket{}
+		starts = append(starts, start)
+	}
+
+	bucketFor := func(t time.Time) *bucket {
+		return buckets[weekStart(t.In(loc))]
+	}
+	for _, c := range commits {
+		if b := bucketFor(c.Date); b != nil {
+			b.commits = append(b.commits, c)
+		}
+	}
+	for _, pr := range prs {
+		if b := bucketFor(pr.CreatedAt); b != nil {
+			b.prs = append(b.prs, pr)
+		}
+	}
+	for _, s := range stories {
+		if b := bucketFor(s.CreatedAt); b != nil {
+			b.stories = append(b.stories, s)
+		}
+	}
+
+	series := make([]WeeklyMetrics, 0, len(starts))
+	for _, start := range starts {
+		b := buckets[start]
+		year, week := start.ISOWeek()
+		series = append(series, WeeklyMetrics{
+			Week:          fmt.Sprintf("%d-W%02d", year, week),
+			Start:         start,
+			End:           start.AddDate(0, 0, 7),
+			CommitMetrics: CalculateCommitMetrics(b.commits, cfg),
+			PRMetrics:     CalculatePRMetrics(b.prs, cfg),
+			JiraMetrics:   CalculateJiraMetrics(b.stories, cfg),
+		})
+	}
+	return series
+}
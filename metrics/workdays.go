@@ -0,0 +1,74 @@
+package metrics
+
+import (
+	"strings"
+	"time"
+
+	"devops-metrics/config"
+)
+
+// defaultWeekend is used when no weekend days are configured
+var defaultWeekend = []time.Weekday{time.Saturday, time.Sunday}
+
+// WorkCalendar knows which calendar days count as working days
+type WorkCalendar struct {
+	weekend  map[time.Weekday]bool
+	holidays map[string]bool
+}
+
+// NewWorkCalendar builds a calendar from the configured weekend days and holidays (YYYY-MM-DD)
+func NewWorkCalendar(cfg config.Config) WorkCalendar {
+	calendar := WorkCalendar{
+		weekend:  make(map[time.Weekday]bool),
+		holidays: make(map[string]bool),
+	}
+
+	for _, name := range cfg.WeekendDays {
+		for day := time.Sunday; day <= time.Saturday; day++ {
+			if strings.EqualFold(name, day.String()) || strings.EqualFold(name, day.String()[:3]) {
+				calendar.weekend[day] = true
+			}
+		}
+	}
+	if len(calendar.weekend) == 0 {
+		for _, day := range defaultWeekend {
+			calendar.weekend[day] = true
+		}
+	}
+
+	for _, holiday := range cfg.Holidays {
+		calendar.holidays[holiday] = true
+	}
+
+	return calendar
+}
+
+// IsWorkingDay reports whether t falls on a day that is neither a weekend day nor a holiday
+func (w WorkCalendar) IsWorkingDay(t time.Time) bool {
+	return !w.weekend[t.Weekday()] && !w.holidays[t.Format("2006-01-02")]
+}
+
+// WorkingDays returns the duration between start and end in days, counting only the
+// portions that fall on working days
+func (w WorkCalendar) WorkingDays(start, end time.Time) float64 {
+	if !end.After(start) {
+		return 0
+	}
+
+	var total time.Duration
+	for day := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, start.Location()); day.Before(end); day = day.AddDate(0, 0, 1) {
+		if !w.IsWorkingDay(day) {
+			continue
+		}
+		from, to := day, day.AddDate(0, 0, 1)
+		if from.Before(start) {
+			from = start
+		}
+		if to.After(end) {
+			to = end
+		}
+		total += to.Sub(from)
+	}
+
+	return total.Hours() / 24
+}
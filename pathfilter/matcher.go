@@ -0,0 +1,83 @@
+package pathfilter
+
+import (
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Matcher matches file paths against gitattributes-style glob patterns such as
+// "*.lock", "dist/**" or "vendor/**/*.go". Patterns without a slash match the
+// file name in any directory.
+type Matcher struct {
+	patterns []*regexp.Regexp
+}
+
+// New compiles the given glob patterns into a Matcher
+func New(patterns []string) Matcher {
+	var m Matcher
+	for _, p := range patterns {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		m.patterns = append(m.patterns, compile(p))
+	}
+	return m
+}
+
+// Empty reports whether the matcher has no patterns
+func (m Matcher) Empty() bool {
+	return len(m.patterns) == 0
+}
+
+// Match reports whether the file path matches any pattern
+func (m Matcher) Match(file string) bool {
+	file = strings.TrimPrefix(path.Clean(file), "/")
+	for _, re := range m.patterns {
+		if re.MatchString(file) {
+			return true
+		}
+	}
+	return false
+}
+
+// compile converts a glob pattern into an anchored regular expression
+func compile(pattern string) *regexp.Regexp {
+	anchored := strings.Contains(strings.TrimSuffix(pattern, "/"), "/")
+	pattern = strings.TrimPrefix(pattern, "/")
+	if strings.HasSuffix(pattern, "/") {
+		pattern += "**"
+	}
+
+	var b strings.Builder
+	if !anchored {
+		b.WriteString("(^|.*/)")
+	} else {
+		b.WriteString("^")
+	}
+	// Runes, not bytes, so multi-byte characters in names are quoted whole
+	runes := []rune(pattern)
+	for i := 0; i < len(runes); i++ {
+		ch := runes[i]
+		switch {
+		case ch == '*' && i+1 < len(runes) && runes[i+1] == '*':
+			// "**/" matches zero or more directories, a trailing "**" matches everything below
+			if i+2 < len(runes) && runes[i+2] == '/' {
+				b.WriteString("(.*/)?")
+				i += 2
+			} else {
+				b.WriteString(".*")
+				i++
+			}
+		case ch == '*':
+			b.WriteString("[^/]*")
+		case ch == '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(ch)))
+		}
+	}
+	b.WriteString("$")
+	return regexp.MustCompile(b.String())
+}
@@ -0,0 +1,35 @@
+package pathfilter
+
+import "testing"
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		pattern string
+		file    string
+		want    bool
+	}{
+		{"*.lock", "yarn.lock", true},
+		{"*.lock", "web/yarn.lock", true},
+		{"*.lock", "yarn.locked", false},
+		{"dist/**", "dist/app.js", true},
+		{"dist/**", "web/dist/app.js", false}, // A slash anchors the pattern at the root
+		{"dist/", "dist/css/app.css", true},
+		{"vendor/**/*.go", "vendor/lib.go", true},
+		{"vendor/**/*.go", "vendor/a/b/lib.go", true},
+		{"vendor/**/*.go", "vendor/a/lib.c", false},
+		{"/gen.go", "gen.go", true},
+		{"?.txt", "a.txt", true},
+		{"?.txt", "ab.txt", false},
+		{"docs/café/**", "docs/café/menu.md", true},
+		{"docs/café/**", "docs/cafe/menu.md", false},
+		{"naïve?.md", "naïveé.md", true}, // "?" matches one character, not one byte
+		{"*.日本", "notes/memo.日本", true},
+		{"a+b.txt", "a+b.txt", true},
+		{"a+b.txt", "aab.txt", false},
+	}
+	for _, tt := range tests {
+		if got := New([]string{tt.pattern}).Match(tt.file); got != tt.want {
+			t.Errorf("New(%q).Match(%q) = %v, want %v", tt.pattern, tt.file, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,188 @@
+package replay
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"devops-metrics/config"
+)
+
+// HTTPClient sends API requests; it matches each provider package's HTTPClient interface
+type HTTPClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// meta is the on-disk envelope for a response's status and headers
+type meta struct {
+	Status int         `json:"status"`
+	Header http.Header `json:"header,omitempty"`
+}
+
+// sequence numbers the requests per file name
+type sequence struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+func newSequence() *sequence {
+	return &sequence{counts: make(map[string]int)}
+}
+
+// next returns the next number for base, starting at 1
+func (s *sequence) next(base string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.counts[base]++
+	return s.counts[base]
+}
+
+type sequenceKey struct{}
+
+// WithSequence returns a context that numbers recorded and replayed requests from the first
+// one. Every client fetching with it continues the same sequence, so the CLI's separate
+// repository-check and fetch clients share one run, and each web request replays from the
+// start without affecting requests served concurrently.
+func WithSequence(ctx context.Context) context.Context {
+	return context.WithValue(ctx, sequenceKey{}, newSequence())
+}
+
+// sequenceFor returns the sequence of req's context, or fallback when it carries none
+func sequenceFor(req *http.Request, fallback *sequence) *sequence {
+	if s, ok := req.Context().Value(sequenceKey{}).(*sequence); ok {
+		return s
+	}
+	return fallback
+}
+
+// NewHTTPClient returns the HTTP client a provider's API client should send its requests
+// through: a replayer reading cfg.InputDir, a recorder writing cfg.DumpDir, or a plain client.
+//
+// Responses are stored one file per request under <dir>/<provider>/, named after the URL path
+// and numbered in request order, e.g. github/repos_acme_api_pulls.3.json for the third request
+// to /repos/acme/api/pulls. The query string is left out because it holds the analysis
+// window, which moves with every run. Status and headers, which carry paging links and
+// continuation tokens, are kept in a .meta.json file next to the body; a body without one
+// replays as 200 OK, so fixtures can be written by hand.
+func NewHTTPClient(provider string, cfg config.Config) HTTPClient {
+	live := &http.Client{Timeout: 30 * time.Second}
+	switch {
+	case cfg.InputDir != "":
+		return NewReplayer(filepath.Join(cfg.InputDir, provider))
+	case cfg.DumpDir != "":
+		return NewRecorder(filepath.Join(cfg.DumpDir, provider), live)
+	}
+	return live
+}
+
+// Recorder sends requests through Next and saves each response under Dir
+type Recorder struct {
+	Dir  string
+	Next HTTPClient
+	seq  *sequence // numbering for requests whose context has no WithSequence
+}
+
+// NewRecorder creates a recorder saving the responses of next under dir
+func NewRecorder(dir string, next HTTPClient) *Recorder {
+	return &Recorder{Dir: dir, Next: next, seq: newSequence()}
+}
+
+// Do sends the request and records the response. Rate-limited responses are not recorded;
+// the client retries them, and a replay should not have to wait them out again.
+func (r *Recorder) Do(req *http.Request) (*http.Response, error) {
+	resp, err := r.Next.Do(req)
+	if err != nil || resp.StatusCode == http.StatusTooManyRequests {
+		return resp, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	name := nextName(r.Dir, req, sequenceFor(req, r.seq))
+	if err := os.MkdirAll(r.Dir, 0755); err != nil {
+		return nil, fmt.Errorf("error creating dump directory: %w", err)
+	}
+	if err := os.WriteFile(name+".json", body, 0644); err != nil {
+		return nil, fmt.Errorf("error writing response dump: %w", err)
+	}
+	header := resp.Header.Clone()
+	header.Del("Set-Cookie")
+	data, _ := json.MarshalIndent(meta{Status: resp.StatusCode, Header: header}, "", "  ")
+	if err := os.WriteFile(name+".meta.json", data, 0644); err != nil {
+		return nil, fmt.Errorf("error writing response dump: %w", err)
+	}
+	return resp, nil
+}
+
+// Replayer answers requests with the responses saved under Dir
+type Replayer struct {
+	Dir string
+	seq *sequence // numbering for requests whose context has no WithSequence
+}
+
+// NewReplayer creates a replayer answering from the responses saved under dir
+func NewReplayer(dir string) *Replayer {
+	return &Replayer{Dir: dir, seq: newSequence()}
+}
+
+// Do returns the next saved response for the request's path. A request with no saved
+// response fails, as a network error would.
+func (r *Replayer) Do(req *http.Request) (*http.Response, error) {
+	name := nextName(r.Dir, req, sequenceFor(req, r.seq))
+	body, err := os.ReadFile(name + ".json")
+	if err != nil {
+		return nil, fmt.Errorf("no recorded response for %s %s: %w", req.Method, req.URL.Path, err)
+	}
+
+	m := meta{Status: http.StatusOK, Header: http.Header{}}
+	if data, err := os.ReadFile(name + ".meta.json"); err == nil {
+		if err := json.Unmarshal(data, &m); err != nil {
+			return nil, fmt.Errorf("error parsing %s.meta.json: %w", name, err)
+		}
+		if m.Header == nil {
+			m.Header = http.Header{}
+		}
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", m.Status, http.StatusText(m.Status)),
+		StatusCode:    m.Status,
+		Header:        m.Header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+// nextName returns the file name, without extension, of the next request to req's path
+func nextName(dir string, req *http.Request, seq *sequence) string {
+	base := filepath.Join(dir, slug(req.URL.Path))
+	return fmt.Sprintf("%s.%d", base, seq.next(base))
+}
+
+// slug turns a URL path into a file name: separators and unsafe characters become "_"
+func slug(path string) string {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return "root"
+	}
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '.':
+			return r
+		}
+		return '_'
+	}, path)
+}
@@ -0,0 +1,97 @@
+package replay
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// get sends a GET for url through client and returns the status, Link header and body
+func get(t *testing.T, ctx context.Context, client HTTPClient, url string) (int, string, string) {
+	t.Helper()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("Do(%s) error = %v", url, err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, resp.Header.Get("Link"), string(body)
+}
+
+func TestRecordReplayRoundTrip(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		switch r.URL.Path {
+		case "/repos/acme/api/pulls":
+			page := r.URL.Query().Get("page")
+			if page == "1" {
+				w.Header().Set("Link", `<next>; rel="next"`)
+			}
+			fmt.Fprintf(w, `[{"page":%q}]`, page)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"message":"Not Found"}`)
+		}
+	}))
+	defer srv.Close()
+
+	type response struct {
+		status int
+		link   string
+		body   string
+	}
+	requests := []struct {
+		path string
+		want response
+	}{
+		{"/repos/acme/api/pulls?page=1", response{200, `<next>; rel="next"`, `[{"page":"1"}]`}},
+		{"/repos/acme/api/pulls?page=2", response{200, "", `[{"page":"2"}]`}},
+		{"/repos/acme/missing", response{404, "", `{"message":"Not Found"}`}},
+	}
+
+	dir := t.TempDir()
+	recorder := NewRecorder(dir, srv.Client())
+	ctx := WithSequence(context.Background())
+	for _, r := range requests {
+		status, link, body := get(t, ctx, recorder, srv.URL+r.path)
+		if got := (response{status, link, body}); got != r.want {
+			t.Errorf("recording %s = %+v, want %+v", r.path, got, r.want)
+		}
+	}
+
+	// Every replay with a fresh sequence answers from the first saved response again
+	replayer := NewReplayer(dir)
+	for run := 1; run <= 2; run++ {
+		ctx := WithSequence(context.Background())
+		for _, r := range requests {
+			status, link, body := get(t, ctx, replayer, "http://replay.invalid"+r.path)
+			if got := (response{status, link, body}); got != r.want {
+				t.Errorf("replay %d of %s = %+v, want %+v", run, r.path, got, r.want)
+			}
+		}
+	}
+	if calls != len(requests) {
+		t.Errorf("server received %d requests, want %d", calls, len(requests))
+	}
+
+	// Without a sequence in the context the replayer numbers requests itself, and past the
+	// recorded responses a replay fails like a network error
+	req, _ := http.NewRequestWithContext(context.Background(), http.MethodGet, "http://replay.invalid/repos/acme/api/pulls", nil)
+	if _, err := replayer.Do(req); err != nil {
+		t.Fatalf("first replay without sequence error = %v", err)
+	}
+	if _, err := replayer.Do(req); err != nil {
+		t.Fatalf("second replay without sequence error = %v", err)
+	}
+	if _, err := replayer.Do(req); err == nil {
+		t.Error("third replay of a path recorded twice succeeded, want error")
+	}
+}
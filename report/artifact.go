@@ -0,0 +1,283 @@
+package report
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"devops-metrics/bitbucket"
+	"devops-metrics/github"
+	"devops-metrics/jira"
+)
+
+// Artifact is provider-agnostic repository data produced outside this tool, e.g. by a CI job,
+// that metrics can be computed from without calling any API. Items use the same JSON fields
+// as the raw exports and the JSON report.
+type Artifact struct {
+	Commits      []bitbucket.Commit      `json:"commits"`
+	PullRequests []bitbucket.PullRequest `json:"pull_requests"`
+	Deployments  []github.Deployment     `json:"deployments,omitempty"`
+	Stories      []jira.JiraStory        `json:"stories,omitempty"`
+}
+
+// LoadArtifact reads an artifact file. A .csv file holds either commits or PRs, one per row
+// with a header naming the columns; the header tells which (see parseCommitsCSV and
+// parsePRsCSV). A JSON file is either an Artifact object or a bare array of commits, such as
+// a raw commits export.
+func LoadArtifact(path string) (Artifact, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Artifact{}, err
+	}
+
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		artifact, err := parseArtifactCSV(data)
+		if err != nil {
+			return Artifact{}, fmt.Errorf("error parsing %s: %w", path, err)
+		}
+		return artifact, nil
+	}
+
+	var artifact Artifact
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		err = json.Unmarshal(data, &artifact.Commits)
+	} else {
+		err = json.Unmarshal(data, &artifact)
+	}
+	if err != nil {
+		return Artifact{}, fmt.Errorf("error parsing %s: %w", path, err)
+	}
+	return artifact, nil
+}
+
+// LoadArtifacts reads several artifact files, e.g. a commit CSV and a PR CSV, and combines
+// their items into one artifact
+func LoadArtifacts(paths ...string) (Artifact, error) {
+	var combined Artifact
+	for _, path := range paths {
+		artifact, err := LoadArtifact(path)
+		if err != nil {
+			return Artifact{}, err
+		}
+		combined.Commits = append(combined.Commits, artifact.Commits...)
+		combined.PullRequests = append(combined.PullRequests, artifact.PullRequests...)
+		combined.Deployments = append(combined.Deployments, artifact.Deployments...)
+		combined.Stories = append(combined.Stories, artifact.Stories...)
+	}
+	return combined, nil
+}
+
+// csvTable is CSV rows whose columns are named by the header row. Unknown columns are
+// ignored and missing optional columns read as empty.
+type csvTable struct {
+	column map[string]int
+	rows   [][]string
+}
+
+func newCSVTable(data []byte) (csvTable, error) {
+	rows, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil {
+		return csvTable{}, err
+	}
+	t := csvTable{column: make(map[string]int)}
+	if len(rows) == 0 {
+		return t, nil
+	}
+	for i, name := range rows[0] {
+		t.column[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	t.rows = rows[1:]
+	return t, nil
+}
+
+func (t csvTable) has(name string) bool {
+	_, ok := t.column[name]
+	return ok
+}
+
+// require returns an error naming the first of names without a column
+func (t csvTable) require(names ...string) error {
+	for _, name := range names {
+		if !t.has(name) {
+			return fmt.Errorf("missing %q column", name)
+		}
+	}
+	return nil
+}
+
+// line returns the file line of row i, counting the header
+func (t csvTable) line(i int) int {
+	return i + 2
+}
+
+func (t csvTable) field(i int, name string) string {
+	if col, ok := t.column[name]; ok && col < len(t.rows[i]) {
+		return strings.TrimSpace(t.rows[i][col])
+	}
+	return ""
+}
+
+// number returns the integer in column name of row i, 0 when it is empty
+func (t csvTable) number(i int, name string) (int, error) {
+	v := t.field(i, name)
+	if v == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, fmt.Errorf("line %d: invalid %s %q", t.line(i), name, v)
+	}
+	return n, nil
+}
+
+// time returns the RFC 3339 time in column name of row i, nil when it is empty
+func (t csvTable) time(i int, name string) (*time.Time, error) {
+	v := t.field(i, name)
+	if v == "" {
+		return nil, nil
+	}
+	ts, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return nil, fmt.Errorf("line %d: invalid %s %q", t.line(i), name, v)
+	}
+	return &ts, nil
+}
+
+// list returns the ";"-separated names in column name of row i
+func (t csvTable) list(i int, name string) []string {
+	var names []string
+	for _, n := range strings.Split(t.field(i, name), ";") {
+		if n = strings.TrimSpace(n); n != "" {
+			names = append(names, n)
+		}
+	}
+	return names
+}
+
+// parseArtifactCSV reads a commit CSV, recognised by its hash column, or a PR CSV, recognised
+// by its id column
+func parseArtifactCSV(data []byte) (Artifact, error) {
+	t, err := newCSVTable(data)
+	if err != nil {
+		return Artifact{}, err
+	}
+	switch {
+	case len(t.column) == 0:
+		return Artifact{Commits: []bitbucket.Commit{}}, nil
+	case t.has("hash"):
+		commits, err := parseCommitsCSV(t)
+		return Artifact{Commits: commits}, err
+	case t.has("id"):
+		prs, err := parsePRsCSV(t)
+		return Artifact{PullRequests: prs}, err
+	}
+	return Artifact{}, fmt.Errorf(`header has neither a "hash" column (commits) nor an "id" column (PRs)`)
+}
+
+// parseCommitsCSV reads commits with the columns hash, author, date, message, lines_added,
+// lines_deleted and repo. Hash, author and date are required.
+func parseCommitsCSV(t csvTable) ([]bitbucket.Commit, error) {
+	if err := t.require("hash", "author", "date"); err != nil {
+		return nil, err
+	}
+
+	commits := make([]bitbucket.Commit, 0, len(t.rows))
+	for i := range t.rows {
+		date, err := t.time(i, "date")
+		if err != nil {
+			return nil, err
+		}
+		if date == nil {
+			return nil, fmt.Errorf("line %d: missing date", t.line(i))
+		}
+		added, err := t.number(i, "lines_added")
+		if err != nil {
+			return nil, err
+		}
+		deleted, err := t.number(i, "lines_deleted")
+		if err != nil {
+			return nil, err
+		}
+
+		commits = append(commits, bitbucket.Commit{
+			Hash:         t.field(i, "hash"),
+			Author:       t.field(i, "author"),
+			Date:         *date,
+			Message:      t.field(i, "message"),
+			LinesAdded:   added,
+			LinesDeleted: deleted,
+			Repo:         t.field(i, "repo"),
+		})
+	}
+	return commits, nil
+}
+
+// parsePRsCSV reads PRs with the columns id, author, created_at, merged_at, closed_at,
+// first_review_at, lines_changed, comment_count, review_cycles, reviewers, approvers,
+// merged_by, base_branch, title, repo and status. Id, author and created_at are required;
+// reviewers and approvers are separated by ";". Without a status, a PR is MERGED when it has
+// merged_at, CLOSED when it has closed_at and OPEN otherwise.
+func parsePRsCSV(t csvTable) ([]bitbucket.PullRequest, error) {
+	if err := t.require("id", "author", "created_at"); err != nil {
+		return nil, err
+	}
+
+	prs := make([]bitbucket.PullRequest, 0, len(t.rows))
+	for i := range t.rows {
+		pr := bitbucket.PullRequest{
+			ID:         t.field(i, "id"),
+			Author:     t.field(i, "author"),
+			Reviewers:  t.list(i, "reviewers"),
+			Approvers:  t.list(i, "approvers"),
+			MergedBy:   t.field(i, "merged_by"),
+			BaseBranch: t.field(i, "base_branch"),
+			Title:      t.field(i, "title"),
+			Repo:       t.field(i, "repo"),
+			Status:     strings.ToUpper(t.field(i, "status")),
+		}
+		created, err := t.time(i, "created_at")
+		if err != nil {
+			return nil, err
+		}
+		if created == nil {
+			return nil, fmt.Errorf("line %d: missing created_at", t.line(i))
+		}
+		pr.CreatedAt = *created
+		if pr.MergedAt, err = t.time(i, "merged_at"); err != nil {
+			return nil, err
+		}
+		if pr.ClosedAt, err = t.time(i, "closed_at"); err != nil {
+			return nil, err
+		}
+		if pr.FirstReviewAt, err = t.time(i, "first_review_at"); err != nil {
+			return nil, err
+		}
+		if pr.LinesChanged, err = t.number(i, "lines_changed"); err != nil {
+			return nil, err
+		}
+		if pr.CommentCount, err = t.number(i, "comment_count"); err != nil {
+			return nil, err
+		}
+		if pr.ReviewCycles, err = t.number(i, "review_cycles"); err != nil {
+			return nil, err
+		}
+		if pr.Status == "" {
+			switch {
+			case pr.MergedAt != nil:
+				pr.Status = "MERGED"
+			case pr.ClosedAt != nil:
+				pr.Status = "CLOSED"
+			default:
+				pr.Status = "OPEN"
+			}
+		}
+		prs = append(prs, pr)
+	}
+	return prs, nil
+}
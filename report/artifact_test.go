@@ -0,0 +1,146 @@
+package report
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeArtifact writes content to a file with the given name in a temporary directory
+func writeArtifact(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadArtifact(t *testing.T) {
+	tests := []struct {
+		name        string
+		file        string
+		content     string
+		wantCommits int
+		wantPRs     int
+		wantErr     string
+	}{
+		{
+			name:        "json object",
+			file:        "data.json",
+			content:     `{"commits":[{"hash":"a","author":"dev","date":"2026-03-02T10:00:00Z"}],"pull_requests":[{"id":"PR-1","author":"dev","created_at":"2026-03-02T10:00:00Z","status":"OPEN"}]}`,
+			wantCommits: 1,
+			wantPRs:     1,
+		},
+		{
+			name:        "json commit array",
+			file:        "commits.json",
+			content:     `[{"hash":"a","author":"dev","date":"2026-03-02T10:00:00Z"},{"hash":"b","author":"dev","date":"2026-03-03T10:00:00Z"}]`,
+			wantCommits: 2,
+		},
+		{
+			name:        "commit csv",
+			file:        "commits.csv",
+			content:     "hash,author,date,lines_added,extra\na,dev,2026-03-02T10:00:00Z,5,x\nb,ops,2026-03-03T10:00:00+01:00,,y\n",
+			wantCommits: 2,
+		},
+		{
+			name:    "pr csv",
+			file:    "prs.CSV",
+			content: "id,author,created_at,merged_at,reviewers\nPR-1,dev,2026-03-02T10:00:00Z,2026-03-03T10:00:00Z,ops;qa\nPR-2,dev,2026-03-04T10:00:00Z,,\n",
+			wantPRs: 2,
+		},
+		{
+			name:    "unknown csv",
+			file:    "other.csv",
+			content: "name,value\na,1\n",
+			wantErr: "neither",
+		},
+		{
+			name:    "commit csv without date column",
+			file:    "commits.csv",
+			content: "hash,author\na,dev\n",
+			wantErr: `missing "date" column`,
+		},
+		{
+			name:    "pr csv with bad time",
+			file:    "prs.csv",
+			content: "id,author,created_at,merged_at\nPR-1,dev,2026-03-02T10:00:00Z,yesterday\n",
+			wantErr: "line 2: invalid merged_at",
+		},
+		{
+			name:    "commit csv with bad number",
+			file:    "commits.csv",
+			content: "hash,author,date,lines_deleted\na,dev,2026-03-02T10:00:00Z,many\n",
+			wantErr: "line 2: invalid lines_deleted",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			artifact, err := LoadArtifact(writeArtifact(t, tt.file, tt.content))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("LoadArtifact() error = %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadArtifact() error = %v", err)
+			}
+			if len(artifact.Commits) != tt.wantCommits || len(artifact.PullRequests) != tt.wantPRs {
+				t.Errorf("got %d commits and %d PRs, want %d and %d",
+					len(artifact.Commits), len(artifact.PullRequests), tt.wantCommits, tt.wantPRs)
+			}
+		})
+	}
+}
+
+func TestParsePRsCSV(t *testing.T) {
+	path := writeArtifact(t, "prs.csv", "id,author,created_at,merged_at,closed_at,first_review_at,lines_changed,reviewers,approvers,status\n"+
+		"PR-1,dev,2026-03-02T10:00:00Z,2026-03-03T10:00:00Z,,2026-03-02T12:00:00Z,40,ops; qa,ops,\n"+
+		"PR-2,dev,2026-03-04T10:00:00Z,,2026-03-05T10:00:00Z,,,,,\n"+
+		"PR-3,ops,2026-03-06T10:00:00Z,,,,,,,declined\n"+
+		"PR-4,ops,2026-03-07T10:00:00Z,,,,,,,\n")
+	artifact, err := LoadArtifact(path)
+	if err != nil {
+		t.Fatalf("LoadArtifact() error = %v", err)
+	}
+
+	wantStatus := []string{"MERGED", "CLOSED", "DECLINED", "OPEN"}
+	if len(artifact.PullRequests) != len(wantStatus) {
+		t.Fatalf("got %d PRs, want %d", len(artifact.PullRequests), len(wantStatus))
+	}
+	for i, pr := range artifact.PullRequests {
+		if pr.Status != wantStatus[i] {
+			t.Errorf("%s status = %q, want %q", pr.ID, pr.Status, wantStatus[i])
+		}
+	}
+
+	pr := artifact.PullRequests[0]
+	merged := time.Date(2026, 3, 3, 10, 0, 0, 0, time.UTC)
+	if pr.MergedAt == nil || !pr.MergedAt.Equal(merged) || pr.FirstReviewAt == nil || pr.LinesChanged != 40 {
+		t.Errorf("PR-1 = %+v, want merged at %v, a first review and 40 lines", pr, merged)
+	}
+	if strings.Join(pr.Reviewers, ",") != "ops,qa" || strings.Join(pr.Approvers, ",") != "ops" {
+		t.Errorf("PR-1 reviewers, approvers = %v, %v; want [ops qa], [ops]", pr.Reviewers, pr.Approvers)
+	}
+}
+
+func TestLoadArtifactsCombinesFiles(t *testing.T) {
+	commits := writeArtifact(t, "commits.csv", "hash,author,date\na,dev,2026-03-02T10:00:00Z\n")
+	prs := writeArtifact(t, "prs.csv", "id,author,created_at\nPR-1,dev,2026-03-02T10:00:00Z\n")
+
+	artifact, err := LoadArtifacts(commits, prs)
+	if err != nil {
+		t.Fatalf("LoadArtifacts() error = %v", err)
+	}
+	if len(artifact.Commits) != 1 || len(artifact.PullRequests) != 1 {
+		t.Errorf("got %d commits and %d PRs, want 1 and 1", len(artifact.Commits), len(artifact.PullRequests))
+	}
+
+	if _, err := LoadArtifacts(commits, filepath.Join(t.TempDir(), "missing.csv")); err == nil {
+		t.Error("LoadArtifacts() with a missing file succeeded, want error")
+	}
+}
@@ -0,0 +1,123 @@
+package report
+
+import (
+	"encoding/json"
+	"os"
+
+	"devops-metrics/metrics"
+)
+
+// BenchmarkSchemaVersion identifies the layout of BenchmarkExport for benchmarking services
+const BenchmarkSchemaVersion = "1"
+
+// BenchmarkExport is an anonymized, aggregate-only view of TeamMetrics for cross-company
+// benchmarks. It carries no author, assignee, repository, component, environment or PR
+// identifiers and no URLs; the run date is kept to the day and the team size to a band.
+type BenchmarkExport struct {
+	SchemaVersion string `json:"schema_version"`
+	Date          string `json:"date"`      // YYYY-MM-DD of the run
+	TeamSize      string `json:"team_size"` // Band such as "6-10"
+
+	Delivery struct {
+		DeploymentsPerWeek float64 `json:"deployments_per_week"`
+		ChangeFailureRate  float64 `json:"change_failure_rate_percent"`
+	} `json:"delivery"`
+
+	Commits struct {
+		Total                      int     `json:"total"`
+		PerDay                     float64 `json:"per_day"`
+		PerContributor             float64 `json:"per_contributor"`
+		LinesChangedPerContributor float64 `json:"lines_changed_per_contributor"`
+	} `json:"commits"`
+
+	PullRequests struct {
+		Total                 int     `json:"total"`
+		MergeSuccessRate      float64 `json:"merge_success_rate_percent"`
+		AvgCycleTimeHours     float64 `json:"avg_cycle_time_hours"`
+		MedianCycleTimeHours  float64 `json:"median_cycle_time_hours"`
+		P90CycleTimeHours     float64 `json:"p90_cycle_time_hours"`
+		AvgReviewTimeHours    float64 `json:"avg_review_time_hours"`
+		MedianReviewTimeHours float64 `json:"median_review_time_hours"`
+		AvgSizeLines          float64 `json:"avg_size_lines"`
+		AvgReviewCycles       float64 `json:"avg_review_cycles"`
+	} `json:"pull_requests"`
+
+	Issues struct {
+		Total             int     `json:"total"`
+		Completed         int     `json:"completed"`
+		AvgLeadTimeDays   float64 `json:"avg_lead_time_days"`
+		AvgCycleTimeDays  float64 `json:"avg_cycle_time_days"`
+		ThroughputPerWeek float64 `json:"throughput_per_week"`
+	} `json:"issues"`
+}
+
+// teamSizeBand reports headcount as a coarse range so small teams cannot be singled out
+func teamSizeBand(size int) string {
+	switch {
+	case size <= 0:
+		return "unknown"
+	case size <= 5:
+		return "1-5"
+	case size <= 10:
+		return "6-10"
+	case size <= 25:
+		return "11-25"
+	case size <= 50:
+		return "26-50"
+	default:
+		return "51+"
+	}
+}
+
+// NewBenchmarkExport strips m down to the aggregate numbers of the benchmark schema
+func NewBenchmarkExport(m metrics.TeamMetrics) BenchmarkExport {
+	var b BenchmarkExport
+	b.SchemaVersion = BenchmarkSchemaVersion
+	b.Date = m.GeneratedAt.UTC().Format("2006-01-02")
+	b.TeamSize = teamSizeBand(m.PerCapita.TeamSize)
+
+	b.Delivery.DeploymentsPerWeek = m.DeploymentMetrics.DeploymentsPerWeek
+	b.Delivery.ChangeFailureRate = m.ChangeFailure.ChangeFailureRate
+
+	b.Commits.Total = m.CommitMetrics.TotalCommits
+	b.Commits.PerDay = m.CommitMetrics.CommitsPerDay
+	b.Commits.PerContributor = m.PerCapita.CommitsPerContributor
+	b.Commits.LinesChangedPerContributor = m.PerCapita.LinesChangedPerContributor
+
+	b.PullRequests.Total = m.PRMetrics.TotalPRs
+	b.PullRequests.MergeSuccessRate = m.PRMetrics.MergeSuccessRate
+	b.PullRequests.AvgCycleTimeHours = m.PRMetrics.AvgCycleTimeHours
+	b.PullRequests.MedianCycleTimeHours = m.PRMetrics.MedianCycleTimeHours
+	b.PullRequests.P90CycleTimeHours = m.PRMetrics.P90CycleTimeHours
+	b.PullRequests.AvgReviewTimeHours = m.PRMetrics.AvgReviewTimeHours
+	b.PullRequests.MedianReviewTimeHours = m.PRMetrics.MedianReviewTimeHours
+	b.PullRequests.AvgSizeLines = m.PRMetrics.AvgPRSize
+	b.PullRequests.AvgReviewCycles = m.PRMetrics.AvgReviewCycles
+
+	b.Issues.Total = m.JiraMetrics.TotalStories
+	b.Issues.Completed = m.JiraMetrics.CompletedStories
+	b.Issues.AvgLeadTimeDays = m.JiraMetrics.AvgLeadTimeDays
+	b.Issues.AvgCycleTimeDays = m.JiraMetrics.AvgCycleTimeDays
+	b.Issues.ThroughputPerWeek = m.JiraMetrics.Throughput
+
+	return b
+}
+
+// BenchmarkSink writes the anonymized benchmark export to a JSON file
+type BenchmarkSink struct {
+	Path string
+}
+
+// Name identifies the sink in logs
+func (s BenchmarkSink) Name() string {
+	return "benchmark:" + s.Path
+}
+
+// Write exports the anonymized aggregates to the file
+func (s BenchmarkSink) Write(m metrics.TeamMetrics) error {
+	data, err := json.MarshalIndent(NewBenchmarkExport(m), "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.Path, data, 0644)
+}
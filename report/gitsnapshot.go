@@ -0,0 +1,88 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"time"
+
+	"devops-metrics/metrics"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// SnapshotFilename is the file written into the git working tree by CommitSnapshot
+const SnapshotFilename = "metrics.json"
+
+// CommitSnapshot writes the metrics report into the git working tree at repoPath and commits it.
+// It returns false without committing when the report is unchanged from the last snapshot.
+func CommitSnapshot(m metrics.TeamMetrics, repoPath string) (bool, error) {
+	repo, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return false, fmt.Errorf("error opening git repository %s: %w", repoPath, err)
+	}
+
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return false, fmt.Errorf("error opening worktree: %w", err)
+	}
+
+	path := filepath.Join(repoPath, SnapshotFilename)
+	if unchangedSnapshot(m, path) {
+		return false, nil
+	}
+
+	if err := ExportToJSON(m, path); err != nil {
+		return false, fmt.Errorf("error writing snapshot: %w", err)
+	}
+
+	if _, err := worktree.Add(SnapshotFilename); err != nil {
+		return false, fmt.Errorf("error staging snapshot: %w", err)
+	}
+
+	status, err := worktree.Status()
+	if err != nil {
+		return false, fmt.Errorf("error reading worktree status: %w", err)
+	}
+	if status.File(SnapshotFilename).Staging == git.Unmodified {
+		return false, nil
+	}
+
+	message := fmt.Sprintf("metrics: snapshot %s", m.GeneratedAt.UTC().Format(time.RFC3339))
+	_, err = worktree.Commit(message, &git.CommitOptions{
+		Author: &object.Signature{
+			Name:  "devops-metrics",
+			Email: "devops-metrics@localhost",
+			When:  time.Now(),
+		},
+	})
+	if err != nil {
+		return false, fmt.Errorf("error committing snapshot: %w", err)
+	}
+
+	return true, nil
+}
+
+// unchangedSnapshot reports whether the file at path already holds the same metrics,
+// ignoring the generation timestamp which differs on every run
+func unchangedSnapshot(m metrics.TeamMetrics, path string) bool {
+	existing, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	current, err := json.Marshal(m)
+	if err != nil {
+		return false
+	}
+
+	var before, after map[string]interface{}
+	if json.Unmarshal(existing, &before) != nil || json.Unmarshal(current, &after) != nil {
+		return false
+	}
+	delete(before, "generated_at")
+	delete(after, "generated_at")
+	return reflect.DeepEqual(before, after)
+}
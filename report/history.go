@@ -0,0 +1,124 @@
+package report
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"devops-metrics/metrics"
+)
+
+// HistoryEntry is one line of the append-only metrics history: the headline metrics of a run,
+// flattened to the same names used for Prometheus gauges
+type HistoryEntry struct {
+	Timestamp time.Time          `json:"timestamp"`
+	Metrics   map[string]float64 `json:"metrics"`
+}
+
+// HistoryPoint is a single value of one metric in a history series
+type HistoryPoint struct {
+	Timestamp time.Time `json:"timestamp"`
+	Value     float64   `json:"value"`
+}
+
+// historyMu serializes appends from within the process; across processes each entry is
+// written with a single O_APPEND write so lines do not interleave
+var historyMu sync.Mutex
+
+// HistorySink appends one JSON line per run to a history file
+type HistorySink struct {
+	Path string
+}
+
+// Name identifies the sink in logs
+func (s HistorySink) Name() string {
+	return "history:" + s.Path
+}
+
+// Write appends the run's flattened metrics to the history file
+func (s HistorySink) Write(m metrics.TeamMetrics) error {
+	return AppendHistory(s.Path, m)
+}
+
+// HistoryPath returns the file of the first history sink in specs, or "" if there is none
+func HistoryPath(specs []string) string {
+	for _, spec := range specs {
+		if kind, target, ok := strings.Cut(spec, ":"); ok && kind == "history" {
+			return target
+		}
+	}
+	return ""
+}
+
+// AppendHistory appends m as one line to the JSONL history file at path, creating it if needed
+func AppendHistory(path string, m metrics.TeamMetrics) error {
+	entry := HistoryEntry{Timestamp: m.GeneratedAt, Metrics: make(map[string]float64)}
+	for _, metric := range prometheusMetrics(m) {
+		entry.Metrics[metric.name] = metric.value
+	}
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	historyMu.Lock()
+	defer historyMu.Unlock()
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(line); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// LoadHistory reads every entry of the history file at path in file order. Lines that cannot be
+// parsed, such as a partial line from an interrupted write, are skipped.
+func LoadHistory(path string) ([]HistoryEntry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	entries := []HistoryEntry{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var entry HistoryEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading history %s: %w", path, err)
+	}
+	return entries, nil
+}
+
+// HistorySeries extracts one metric's values from entries recorded within [since, until].
+// A zero since or until leaves that end of the range open.
+func HistorySeries(entries []HistoryEntry, metric string, since, until time.Time) []HistoryPoint {
+	series := []HistoryPoint{}
+	for _, entry := range entries {
+		if !since.IsZero() && entry.Timestamp.Before(since) {
+			continue
+		}
+		if !until.IsZero() && entry.Timestamp.After(until) {
+			continue
+		}
+		if value, ok := entry.Metrics[metric]; ok {
+			series = append(series, HistoryPoint{Timestamp: entry.Timestamp, Value: value})
+		}
+	}
+	return series
+}
@@ -0,0 +1,165 @@
+package report
+
+import (
+	_ "embed"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"sort"
+
+	"devops-metrics/metrics"
+)
+
+//go:embed templates/report.html
+var htmlReportTemplate string
+
+var htmlReport = template.Must(template.New("report").Parse(htmlReportTemplate))
+
+// htmlBar is one row of a bar chart; Percent is the bar width relative to the largest value
+type htmlBar struct {
+	Label   string
+	Value   float64
+	Percent float64
+}
+
+// htmlPRAuthor is one row of the per-author pull request table
+type htmlPRAuthor struct {
+	Author         string
+	PRs            int
+	AvgCycleHours  float64
+	AvgReviewHours float64
+	HasCycleTime   bool
+	HasReviewTime  bool
+	CyclePercent   float64
+	ReviewPercent  float64
+}
+
+// htmlReportData is the view model rendered by the HTML template
+type htmlReportData struct {
+	Metrics            metrics.TeamMetrics
+	ShowGit            bool
+	ShowJira           bool
+	CommitsByAuthor    []htmlBar
+	CommitsByWeekday   []htmlBar
+	CommitsByHour      []htmlBar
+	PRsByAuthor        []htmlPRAuthor
+	DeploymentsByEnv   []htmlBar
+	StoriesByAssignee  []htmlBar
+	StoriesByComponent []htmlBar
+}
+
+// ExportToHTML saves metrics as a self-contained HTML dashboard
+func ExportToHTML(m metrics.TeamMetrics, filename string) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return WriteHTML(file, m)
+}
+
+// WriteHTML renders the metrics as an HTML dashboard to w. Charts are plain CSS bars, so the
+// page needs no scripts or external assets.
+func WriteHTML(w io.Writer, m metrics.TeamMetrics) error {
+	data := htmlReportData{
+		Metrics:            m,
+		CommitsByAuthor:    countBars(m.CommitMetrics.CommitsByAuthor),
+		CommitsByWeekday:   weekdayBars(m.CommitMetrics.CommitsByWeekday),
+		CommitsByHour:      hourBars(m.CommitMetrics.CommitsByHour),
+		PRsByAuthor:        prAuthorRows(m.PRMetrics),
+		DeploymentsByEnv:   countBars(m.DeploymentMetrics.DeploymentsByEnvironment),
+		StoriesByAssignee:  countBars(m.JiraMetrics.StoriesByAssignee),
+		StoriesByComponent: countBars(m.JiraMetrics.StoriesByComponent),
+	}
+	data.ShowGit, data.ShowJira = ranProviders(m)
+	return htmlReport.Execute(w, data)
+}
+
+// countBars turns counts into bars ordered by descending count, then label
+func countBars(counts map[string]int) []htmlBar {
+	bars := make([]htmlBar, 0, len(counts))
+	for label, count := range counts {
+		bars = append(bars, htmlBar{Label: label, Value: float64(count)})
+	}
+	sort.Slice(bars, func(i, j int) bool {
+		if bars[i].Value != bars[j].Value {
+			return bars[i].Value > bars[j].Value
+		}
+		return bars[i].Label < bars[j].Label
+	})
+	return scaleBars(bars)
+}
+
+// weekdayBars turns weekday counts into bars in calendar order, Monday first
+func weekdayBars(counts map[string]int) []htmlBar {
+	if len(counts) == 0 {
+		return nil
+	}
+	days := []string{"Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday", "Sunday"}
+	bars := make([]htmlBar, 0, len(days))
+	for _, day := range days {
+		bars = append(bars, htmlBar{Label: day, Value: float64(counts[day])})
+	}
+	return scaleBars(bars)
+}
+
+// hourBars turns hour-of-day counts into 24 bars, midnight first
+func hourBars(counts map[int]int) []htmlBar {
+	if len(counts) == 0 {
+		return nil
+	}
+	bars := make([]htmlBar, 0, 24)
+	for hour := 0; hour < 24; hour++ {
+		bars = append(bars, htmlBar{Label: fmt.Sprintf("%02d:00", hour), Value: float64(counts[hour])})
+	}
+	return scaleBars(bars)
+}
+
+// scaleBars sets each bar's width as a percentage of the largest value
+func scaleBars(bars []htmlBar) []htmlBar {
+	var max float64
+	for _, bar := range bars {
+		if bar.Value > max {
+			max = bar.Value
+		}
+	}
+	if max > 0 {
+		for i := range bars {
+			bars[i].Percent = bars[i].Value / max * 100
+		}
+	}
+	return bars
+}
+
+// prAuthorRows lists each PR author with their average cycle and review times, sorted by author
+func prAuthorRows(pr metrics.PRMetrics) []htmlPRAuthor {
+	var maxCycle, maxReview float64
+	for _, hours := range pr.CycleTimeByAuthor {
+		if hours > maxCycle {
+			maxCycle = hours
+		}
+	}
+	for _, hours := range pr.ReviewTimeByAuthor {
+		if hours > maxReview {
+			maxReview = hours
+		}
+	}
+
+	rows := make([]htmlPRAuthor, 0, len(pr.PRsByAuthor))
+	for author, count := range pr.PRsByAuthor {
+		row := htmlPRAuthor{Author: author, PRs: count}
+		row.AvgCycleHours, row.HasCycleTime = pr.CycleTimeByAuthor[author]
+		row.AvgReviewHours, row.HasReviewTime = pr.ReviewTimeByAuthor[author]
+		if maxCycle > 0 {
+			row.CyclePercent = row.AvgCycleHours / maxCycle * 100
+		}
+		if maxReview > 0 {
+			row.ReviewPercent = row.AvgReviewHours / maxReview * 100
+		}
+		rows = append(rows, row)
+	}
+	sort.Slice(rows, func(i, j int) bool { return rows[i].Author < rows[j].Author })
+	return rows
+}
@@ -0,0 +1,149 @@
+package report
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// NumberFormat controls how numbers are rendered in human-readable outputs (console and CSV).
+// JSON output always uses plain machine formatting.
+type NumberFormat struct {
+	DecimalSeparator   string
+	ThousandsSeparator string
+	CSVDelimiter       rune
+}
+
+// numberFormats maps supported locale names to their number formatting
+var numberFormats = map[string]NumberFormat{
+	"en": {DecimalSeparator: ".", ThousandsSeparator: ",", CSVDelimiter: ','},
+	"de": {DecimalSeparator: ",", ThousandsSeparator: ".", CSVDelimiter: ';'},
+	"fr": {DecimalSeparator: ",", ThousandsSeparator: " ", CSVDelimiter: ';'},
+	"ch": {DecimalSeparator: ".", ThousandsSeparator: "'", CSVDelimiter: ';'},
+}
+
+// DefaultNumberFormat is the plain format used when no locale is configured
+var DefaultNumberFormat = NumberFormat{DecimalSeparator: ".", CSVDelimiter: ','}
+
+// NumberFormatFor returns the number format for a locale such as "de" or "de-DE",
+// falling back to DefaultNumberFormat for empty or unknown locales
+func NumberFormatFor(locale string) NumberFormat {
+	locale = strings.ToLower(strings.TrimSpace(locale))
+	if i := strings.IndexAny(locale, "-_"); i >= 0 {
+		locale = locale[:i]
+	}
+	if nf, ok := numberFormats[locale]; ok {
+		return nf
+	}
+	return DefaultNumberFormat
+}
+
+// Float formats v with the given number of decimal places
+func (nf NumberFormat) Float(v float64, precision int) string {
+	return nf.localize(strconv.FormatFloat(v, 'f', precision, 64))
+}
+
+// Int formats an integer with thousands grouping
+func (nf NumberFormat) Int(n int) string {
+	return nf.localize(strconv.Itoa(n))
+}
+
+// localize rewrites a plain "-1234.56" number string with the configured separators
+func (nf NumberFormat) localize(plain string) string {
+	sign := ""
+	if strings.HasPrefix(plain, "-") {
+		sign, plain = "-", plain[1:]
+	}
+
+	intPart, fracPart := plain, ""
+	if i := strings.IndexByte(plain, '.'); i >= 0 {
+		intPart, fracPart = plain[:i], plain[i+1:]
+	}
+
+	if nf.ThousandsSeparator != "" && len(intPart) > 3 {
+		var b strings.Builder
+		lead := len(intPart) % 3
+		if lead > 0 {
+			b.WriteString(intPart[:lead])
+		}
+		for i := lead; i < len(intPart); i += 3 {
+			if b.Len() > 0 {
+				b.WriteString(nf.ThousandsSeparator)
+			}
+			b.WriteString(intPart[i : i+3])
+		}
+		intPart = b.String()
+	}
+
+	if fracPart == "" {
+		return sign + intPart
+	}
+	return sign + intPart + nf.DecimalSeparator + fracPart
+}
+
+// Sprintf behaves like fmt.Sprintf but renders %d and %f verbs with localized numbers,
+// keeping any width and alignment flags
+func (nf NumberFormat) Sprintf(format string, args ...interface{}) string {
+	var out strings.Builder
+	argIndex := 0
+
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			out.WriteByte(format[i])
+			continue
+		}
+
+		// Find the end of the verb
+		j := i + 1
+		for j < len(format) && strings.IndexByte("+-# 0123456789.", format[j]) >= 0 {
+			j++
+		}
+		if j >= len(format) {
+			out.WriteString(format[i:])
+			break
+		}
+		spec, verb := format[i+1:j], format[j]
+		i = j
+
+		if verb == '%' {
+			out.WriteByte('%')
+			continue
+		}
+		if argIndex >= len(args) {
+			out.WriteString("%" + spec + string(verb))
+			continue
+		}
+		arg := args[argIndex]
+		argIndex++
+
+		width, precision := spec, -1
+		if k := strings.IndexByte(spec, '.'); k >= 0 {
+			width = spec[:k]
+			precision, _ = strconv.Atoi(spec[k+1:])
+		}
+
+		switch v := arg.(type) {
+		case int:
+			if verb == 'd' {
+				out.WriteString(fmt.Sprintf("%"+width+"s", nf.Int(v)))
+				continue
+			}
+		case float64:
+			if verb == 'f' {
+				if precision < 0 {
+					precision = 6
+				}
+				out.WriteString(fmt.Sprintf("%"+width+"s", nf.Float(v, precision)))
+				continue
+			}
+		}
+		out.WriteString(fmt.Sprintf("%"+spec+string(verb), arg))
+	}
+
+	return out.String()
+}
+
+// Printf prints a localized formatted string to stdout
+func (nf NumberFormat) Printf(format string, args ...interface{}) {
+	fmt.Print(nf.Sprintf(format, args...))
+}
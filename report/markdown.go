@@ -0,0 +1,193 @@
+package report
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"devops-metrics/metrics"
+)
+
+// ExportToMarkdown saves metrics as GitHub-flavored Markdown tables
+func ExportToMarkdown(m metrics.TeamMetrics, filename string) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return WriteMarkdown(file, m)
+}
+
+// WriteMarkdown writes one Markdown table per metric category plus the per-author
+// breakdowns to w. Counts are written as integers and rates and durations with two decimals,
+// independent of the number locale, so the tables paste cleanly into wikis.
+func WriteMarkdown(w io.Writer, m metrics.TeamMetrics) error {
+	md := bufio.NewWriter(w)
+	fmt.Fprintln(md, "# DevOps & Productivity Metrics")
+	fmt.Fprintln(md)
+	fmt.Fprintf(md, "Generated %s", m.GeneratedAt.Format("2006-01-02 15:04 MST"))
+	if m.CommitMetrics.DateRange != "" {
+		fmt.Fprintf(md, " · %s", m.CommitMetrics.DateRange)
+	}
+	fmt.Fprintln(md)
+	fmt.Fprintf(md, "\n**Productivity score:** %s / 100\n", mdFloat(m.ProductivityScore))
+	if len(m.Truncated) > 0 {
+		fmt.Fprintf(md, "\n> Results truncated by fetch caps: %s\n", strings.Join(m.Truncated, ", "))
+	}
+
+	ranGit, ranJira := ranProviders(m)
+	if ranGit {
+		writeMarkdownGit(md, m)
+	}
+	if ranJira {
+		writeMarkdownJira(md, m)
+	}
+
+	return md.Flush()
+}
+
+// writeMarkdownGit writes the commit, pull request and deployment tables
+func writeMarkdownGit(md io.Writer, m metrics.TeamMetrics) {
+	c := m.CommitMetrics
+	markdownHeading(md, 2, "Commits")
+	markdownTable(md, []string{"Metric", "Value"}, [][]string{
+		{"Total Commits", mdInt(c.TotalCommits)},
+		{"Commits Per Day", mdFloat(c.CommitsPerDay)},
+		{"Active Days", mdInt(c.ActiveDays)},
+		{"Lines Added", mdInt(c.TotalLinesAdded)},
+		{"Lines Deleted", mdInt(c.TotalLinesDeleted)},
+		{"Avg Commit Gap (hours)", mdFloat(c.AvgCommitGapHours)},
+		{"Conventional Commit Rate (%)", mdFloat(c.ConventionalCommitRate)},
+	})
+	if len(c.CommitsByAuthor) > 0 {
+		markdownHeading(md, 3, "Commits by Author")
+		rows := [][]string{}
+		for _, author := range sortedAuthors(c.CommitsByAuthor) {
+			rows = append(rows, []string{author, mdInt(c.CommitsByAuthor[author])})
+		}
+		markdownTable(md, []string{"Author", "Commits"}, rows)
+	}
+
+	pr := m.PRMetrics
+	markdownHeading(md, 2, "Pull Requests")
+	markdownTable(md, []string{"Metric", "Value"}, [][]string{
+		{"Total PRs", mdInt(pr.TotalPRs)},
+		{"Merged PRs", mdInt(pr.MergedPRs)},
+		{"Open PRs", mdInt(pr.OpenPRs)},
+		{"Merge Success Rate (%)", mdFloat(pr.MergeSuccessRate)},
+		{"Avg Cycle Time (hours)", mdFloat(pr.AvgCycleTimeHours)},
+		{"Median Cycle Time (hours)", mdFloat(pr.MedianCycleTimeHours)},
+		{"P90 Cycle Time (hours)", mdFloat(pr.P90CycleTimeHours)},
+		{"Avg Review Time (hours)", mdFloat(pr.AvgReviewTimeHours)},
+		{"Avg PR Size (lines)", mdFloat(pr.AvgPRSize)},
+		{"Avg Review Cycles", mdFloat(pr.AvgReviewCycles)},
+		{"Avg Review Comments per PR", mdFloat(pr.AvgReviewCommentsPerPR)},
+		{"Self-merged PRs", mdInt(pr.SelfMergedPRs)},
+	})
+	if len(pr.PRsByAuthor) > 0 {
+		markdownHeading(md, 3, "PRs by Author")
+		rows := [][]string{}
+		for _, author := range sortedAuthors(pr.PRsByAuthor) {
+			rows = append(rows, []string{author, mdInt(pr.PRsByAuthor[author]),
+				mdOptionalFloat(pr.CycleTimeByAuthor, author), mdOptionalFloat(pr.ReviewTimeByAuthor, author)})
+		}
+		markdownTable(md, []string{"Author", "PRs", "Avg Cycle Time (hours)", "Avg Review Time (hours)"}, rows)
+	}
+
+	markdownHeading(md, 2, "Deployments")
+	markdownTable(md, []string{"Metric", "Value"}, [][]string{
+		{"Total Deployments", mdInt(m.DeploymentMetrics.TotalDeployments)},
+		{"Deployments Per Week", mdFloat(m.DeploymentMetrics.DeploymentsPerWeek)},
+		{"Change Failure Rate (%)", mdFloat(m.ChangeFailure.ChangeFailureRate)},
+		{"Failures", mdInt(m.ChangeFailure.Failures)},
+	})
+}
+
+// writeMarkdownJira writes the Jira story tables
+func writeMarkdownJira(md io.Writer, m metrics.TeamMetrics) {
+	j := m.JiraMetrics
+	markdownHeading(md, 2, "Jira Stories")
+	markdownTable(md, []string{"Metric", "Value"}, [][]string{
+		{"Total Stories", mdInt(j.TotalStories)},
+		{"Completed Stories", mdInt(j.CompletedStories)},
+		{"Avg Lead Time (days)", mdFloat(j.AvgLeadTimeDays)},
+		{"Avg Lead Time (business days)", mdFloat(j.AvgLeadTimeBusinessDays)},
+		{"Avg Cycle Time (days)", mdFloat(j.AvgCycleTimeDays)},
+		{"Throughput (per week)", mdFloat(j.Throughput)},
+		{"Estimate Accuracy (%)", mdFloat(j.EstimateAccuracy)},
+		{"Open Stories", mdInt(j.OpenStories)},
+		{"Avg Age of Open Stories (days)", mdFloat(j.AvgAgeOpenDays)},
+		{"Oldest Open Story (days)", mdFloat(j.OldestOpenDays)},
+		{"Stale Stories", mdInt(j.StaleStories.Count)},
+	})
+	if len(j.StoriesByAssignee) > 0 {
+		markdownHeading(md, 3, "Stories by Assignee")
+		rows := [][]string{}
+		for _, assignee := range sortedAuthors(j.StoriesByAssignee) {
+			rows = append(rows, []string{assignee, mdInt(j.StoriesByAssignee[assignee]),
+				mdOptionalFloat(j.ThroughputByAssignee, assignee), mdOptionalFloat(j.AvgLeadTimeByAssignee, assignee)})
+		}
+		markdownTable(md, []string{"Assignee", "Stories", "Throughput (per week)", "Avg Lead Time (days)"}, rows)
+	}
+}
+
+// markdownHeading writes a heading of the given level surrounded by blank lines
+func markdownHeading(md io.Writer, level int, title string) {
+	fmt.Fprintf(md, "\n%s %s\n\n", strings.Repeat("#", level), title)
+}
+
+// markdownTable writes a table with the given header; columns after the first are right-aligned
+func markdownTable(md io.Writer, header []string, rows [][]string) {
+	align := make([]string, len(header))
+	for i := range align {
+		align[i] = "---:"
+	}
+	align[0] = "---"
+
+	fmt.Fprintln(md, markdownRow(header))
+	fmt.Fprintln(md, "|"+strings.Join(align, "|")+"|")
+	for _, row := range rows {
+		fmt.Fprintln(md, markdownRow(row))
+	}
+}
+
+// markdownRow joins cells into a table row, escaping pipes that would split a cell
+func markdownRow(cells []string) string {
+	escaped := make([]string, len(cells))
+	for i, cell := range cells {
+		escaped[i] = strings.ReplaceAll(cell, "|", `\|`)
+	}
+	return "| " + strings.Join(escaped, " | ") + " |"
+}
+
+// sortedAuthors returns the keys of counts in alphabetical order
+func sortedAuthors(counts map[string]int) []string {
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// mdInt formats a count
+func mdInt(v int) string {
+	return fmt.Sprintf("%d", v)
+}
+
+// mdFloat formats a rate or duration with two decimals
+func mdFloat(v float64) string {
+	return fmt.Sprintf("%.2f", v)
+}
+
+// mdOptionalFloat formats values[key], or a dash when there is no value for key
+func mdOptionalFloat(values map[string]float64, key string) string {
+	if v, ok := values[key]; ok {
+		return mdFloat(v)
+	}
+	return "–"
+}
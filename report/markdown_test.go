@@ -0,0 +1,125 @@
+package report
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"devops-metrics/metrics"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// markdownFixture returns team metrics with every table populated, from the given providers
+func markdownFixture(providers ...string) metrics.TeamMetrics {
+	return metrics.TeamMetrics{
+		Providers:         providers,
+		GeneratedAt:       time.Date(2026, 3, 31, 17, 45, 0, 0, time.UTC),
+		ProductivityScore: 72.456,
+		CommitMetrics: metrics.CommitMetrics{
+			TotalCommits:           42,
+			CommitsPerDay:          1.5,
+			ActiveDays:             18,
+			TotalLinesAdded:        3120,
+			TotalLinesDeleted:      877,
+			AvgCommitGapHours:      5.125,
+			ConventionalCommitRate: 83.333,
+			CommitsByAuthor:        map[string]int{"bob": 12, "alice": 30},
+			DateRange:              "2026-03-01 to 2026-03-31",
+		},
+		PRMetrics: metrics.PRMetrics{
+			TotalPRs:               9,
+			MergedPRs:              7,
+			OpenPRs:                2,
+			MergeSuccessRate:       77.7777,
+			AvgCycleTimeHours:      20.5,
+			MedianCycleTimeHours:   18,
+			P90CycleTimeHours:      40.25,
+			AvgReviewTimeHours:     3.333,
+			AvgPRSize:              140,
+			AvgReviewCycles:        1.2,
+			AvgReviewCommentsPerPR: 2.5,
+			SelfMergedPRs:          1,
+			PRsByAuthor:            map[string]int{"alice": 6, "bob": 3},
+			CycleTimeByAuthor:      map[string]float64{"alice": 22, "bob": 16.5},
+			ReviewTimeByAuthor:     map[string]float64{"alice": 3},
+		},
+		DeploymentMetrics: metrics.DeploymentMetrics{TotalDeployments: 5, DeploymentsPerWeek: 1.25},
+		ChangeFailure:     metrics.ChangeFailureMetrics{ChangeFailureRate: 20, Failures: 1},
+		JiraMetrics: metrics.JiraMetrics{
+			TotalStories:            14,
+			CompletedStories:        10,
+			AvgLeadTimeDays:         6.4,
+			AvgLeadTimeBusinessDays: 4.6,
+			AvgCycleTimeDays:        3.1,
+			Throughput:              2.5,
+			EstimateAccuracy:        88.888,
+			OpenStories:             4,
+			AvgAgeOpenDays:          12,
+			OldestOpenDays:          30.5,
+			StaleStories:            metrics.StaleStories{Count: 1},
+			StoriesByAssignee:       map[string]int{"Unassigned": 2, "Alice Doe": 12},
+			ThroughputByAssignee:    map[string]float64{"Alice Doe": 2.5},
+			AvgLeadTimeByAssignee:   map[string]float64{"Alice Doe": 6.4},
+		},
+	}
+}
+
+func TestWriteMarkdownGolden(t *testing.T) {
+	truncated := markdownFixture("github")
+	truncated.Truncated = []string{"github:prs"}
+	truncated.CommitMetrics.CommitsByAuthor["carol | ops"] = 1
+
+	tests := []struct {
+		name string
+		m    metrics.TeamMetrics
+	}{
+		{"all_providers", markdownFixture("github", "jira")},
+		{"git_only", markdownFixture("bitbucket")},
+		{"jira_only", markdownFixture("jira")},
+		{"truncated", truncated},
+		{"empty", metrics.TeamMetrics{Providers: []string{"github"}, GeneratedAt: time.Date(2026, 3, 31, 0, 0, 0, 0, time.UTC)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := WriteMarkdown(&buf, tt.m); err != nil {
+				t.Fatalf("WriteMarkdown() error = %v", err)
+			}
+
+			golden := filepath.Join("testdata", tt.name+".md")
+			if *update {
+				if err := os.WriteFile(golden, buf.Bytes(), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("reading golden file (run with -update to create it): %v", err)
+			}
+			if !bytes.Equal(buf.Bytes(), want) {
+				t.Errorf("WriteMarkdown() output differs from %s:\n%s", golden, buf.String())
+			}
+		})
+	}
+}
+
+func TestExportToMarkdownMatchesWriteMarkdown(t *testing.T) {
+	m := markdownFixture("github", "jira")
+	path := filepath.Join(t.TempDir(), "metrics.md")
+	if err := ExportToMarkdown(m, path); err != nil {
+		t.Fatalf("ExportToMarkdown() error = %v", err)
+	}
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var want bytes.Buffer
+	WriteMarkdown(&want, m)
+	if !bytes.Equal(got, want.Bytes()) {
+		t.Errorf("file content differs from WriteMarkdown output")
+	}
+}
@@ -0,0 +1,146 @@
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"devops-metrics/metrics"
+)
+
+// prometheusMetric is one gauge in the exposition output
+type prometheusMetric struct {
+	name  string
+	help  string
+	value float64
+}
+
+// prometheusMetrics lists the headline gauges exported to Prometheus
+func prometheusMetrics(m metrics.TeamMetrics) []prometheusMetric {
+	return []prometheusMetric{
+		{"devops_productivity_score", "Weighted productivity score from 0 to 100", m.ProductivityScore},
+		{"devops_commits_total", "Commits in the analysis window", float64(m.CommitMetrics.TotalCommits)},
+		{"devops_lines_added_total", "Lines added in the analysis window", float64(m.CommitMetrics.TotalLinesAdded)},
+		{"devops_lines_deleted_total", "Lines deleted in the analysis window", float64(m.CommitMetrics.TotalLinesDeleted)},
+		{"devops_prs_total", "Pull requests in the analysis window", float64(m.PRMetrics.TotalPRs)},
+		{"devops_prs_merged", "Merged pull requests", float64(m.PRMetrics.MergedPRs)},
+		{"devops_prs_open", "Open pull requests", float64(m.PRMetrics.OpenPRs)},
+		{"devops_pr_cycle_time_hours", "Average PR cycle time in hours", m.PRMetrics.AvgCycleTimeHours},
+		{"devops_pr_review_time_hours", "Average time to first review in hours", m.PRMetrics.AvgReviewTimeHours},
+		{"devops_pr_cycle_time_median_hours", "Median PR cycle time in hours", m.PRMetrics.MedianCycleTimeHours},
+		{"devops_pr_cycle_time_p90_hours", "90th percentile PR cycle time in hours", m.PRMetrics.P90CycleTimeHours},
+		{"devops_pr_merge_success_ratio", "Share of PRs that were merged", m.PRMetrics.MergeSuccessRate / 100},
+		{"devops_prs_idle", "Open pull requests without a recent update", float64(m.PRMetrics.StalePRs.IdleCount)},
+		{"devops_prs_aged", "Open pull requests older than the maximum PR age", float64(m.PRMetrics.StalePRs.AgedCount)},
+		{"devops_stories_total", "Jira stories in the analysis window", float64(m.JiraMetrics.TotalStories)},
+		{"devops_stories_completed", "Completed Jira stories", float64(m.JiraMetrics.CompletedStories)},
+		{"devops_story_lead_time_days", "Average Jira lead time in days", m.JiraMetrics.AvgLeadTimeDays},
+		{"devops_story_cycle_time_days", "Average Jira cycle time in days", m.JiraMetrics.AvgCycleTimeDays},
+		{"devops_story_throughput_per_week", "Completed stories per week", m.JiraMetrics.Throughput},
+		{"devops_stories_open", "Jira stories that are not done", float64(m.JiraMetrics.OpenStories)},
+		{"devops_story_open_age_days", "Average age of open Jira stories in days", m.JiraMetrics.AvgAgeOpenDays},
+		{"devops_stories_stale", "Open stories without a recent status change", float64(m.JiraMetrics.StaleStories.Count)},
+	}
+}
+
+// WritePrometheus writes the metrics in the Prometheus text exposition format
+func WritePrometheus(w io.Writer, m metrics.TeamMetrics) error {
+	for _, metric := range prometheusMetrics(m) {
+		if _, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s gauge\n%s %g\n",
+			metric.name, metric.help, metric.name, metric.name, metric.value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PushgatewaySink pushes metrics to a Prometheus Pushgateway. Pushes use PUT on the
+// job/grouping-key path, so repeated runs replace the previous values instead of accumulating.
+type PushgatewaySink struct {
+	URL      string            // Pushgateway base URL, e.g. http://pushgateway:9091
+	Job      string            // job label
+	Grouping map[string]string // Additional grouping labels, e.g. instance
+}
+
+// defaultPushgatewayJob is the job label used when the sink spec does not set one
+const defaultPushgatewayJob = "devops_metrics"
+
+// newPushgatewaySink parses a target such as http://pg:9091?job=metrics&instance=team-a;
+// query parameters other than job become grouping labels
+func newPushgatewaySink(target string) (PushgatewaySink, error) {
+	u, err := url.Parse(target)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return PushgatewaySink{}, fmt.Errorf("invalid pushgateway URL %q", target)
+	}
+
+	sink := PushgatewaySink{Job: defaultPushgatewayJob, Grouping: make(map[string]string)}
+	for key, values := range u.Query() {
+		if len(values) == 0 || values[0] == "" {
+			continue
+		}
+		if key == "job" {
+			sink.Job = values[0]
+		} else {
+			sink.Grouping[key] = values[0]
+		}
+	}
+
+	u.RawQuery = ""
+	sink.URL = strings.TrimSuffix(u.String(), "/")
+	return sink, nil
+}
+
+// Name identifies the sink in logs
+func (s PushgatewaySink) Name() string {
+	return "pushgateway:" + s.pushURL()
+}
+
+// pushURL builds /metrics/job/<job>/<label>/<value>... with labels in a stable order
+func (s PushgatewaySink) pushURL() string {
+	var b strings.Builder
+	b.WriteString(s.URL)
+	b.WriteString("/metrics/job/")
+	b.WriteString(url.PathEscape(s.Job))
+
+	labels := make([]string, 0, len(s.Grouping))
+	for label := range s.Grouping {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+	for _, label := range labels {
+		b.WriteString("/" + url.PathEscape(label) + "/" + url.PathEscape(s.Grouping[label]))
+	}
+	return b.String()
+}
+
+// Write replaces the metrics group on the Pushgateway
+func (s PushgatewaySink) Write(m metrics.TeamMetrics) error {
+	var body bytes.Buffer
+	if err := WritePrometheus(&body, m); err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("PUT", s.pushURL(), &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "text/plain; version=0.0.4")
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("pushgateway unreachable: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("pushgateway rejected push with status %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
@@ -4,10 +4,12 @@ import (
 	"encoding/csv"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"sort"
-	"strconv"
 	"strings"
+
+	"devops-metrics/fetchstats"
 	"devops-metrics/metrics"
 )
 
@@ -20,55 +22,228 @@ func ExportToJSON(metrics metrics.TeamMetrics, filename string) error {
 	return os.WriteFile(filename, data, 0644)
 }
 
-// ExportToCSV saves metrics to a CSV file
-func ExportToCSV(metrics metrics.TeamMetrics, filename string) error {
+// ExportCommits saves raw (optionally PR-enriched) commits to a JSON file
+func ExportCommits(commits []metrics.EnrichedCommit, filename string) error {
+	data, err := json.MarshalIndent(commits, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, data, 0644)
+}
+
+// ExportTrends saves the weekly trend series to a JSON file
+func ExportTrends(trends []metrics.WeeklyMetrics, filename string) error {
+	data, err := json.MarshalIndent(trends, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, data, 0644)
+}
+
+// ExportToCSV saves metrics to a CSV file using the given number format
+func ExportToCSV(metrics metrics.TeamMetrics, filename string, nf NumberFormat) error {
 	file, err := os.Create(filename)
 	if err != nil {
 		return err
 	}
 	defer file.Close()
 
-	writer := csv.NewWriter(file)
-	defer writer.Flush()
+	return WriteCSV(file, metrics, nf)
+}
+
+// WriteCSV writes the metrics as CSV rows to w using the given number format
+func WriteCSV(w io.Writer, metrics metrics.TeamMetrics, nf NumberFormat) error {
+	writer := csv.NewWriter(w)
+	writer.Comma = nf.CSVDelimiter
 
 	writer.Write([]string{"Metric Category", "Metric Name", "Value"})
 
-	writer.Write([]string{"Commits", "Total Commits", strconv.Itoa(metrics.CommitMetrics.TotalCommits)})
-	writer.Write([]string{"Commits", "Commits Per Day", fmt.Sprintf("%.2f", metrics.CommitMetrics.CommitsPerDay)})
-	writer.Write([]string{"Commits", "Active Days", strconv.Itoa(metrics.CommitMetrics.ActiveDays)})
-	writer.Write([]string{"Commits", "Lines Added", strconv.Itoa(metrics.CommitMetrics.TotalLinesAdded)})
-	writer.Write([]string{"Commits", "Lines Deleted", strconv.Itoa(metrics.CommitMetrics.TotalLinesDeleted)})
-
-	writer.Write([]string{"Pull Requests", "Total PRs", strconv.Itoa(metrics.PRMetrics.TotalPRs)})
-	writer.Write([]string{"Pull Requests", "Merged PRs", strconv.Itoa(metrics.PRMetrics.MergedPRs)})
-	writer.Write([]string{"Pull Requests", "Avg Cycle Time (hours)", fmt.Sprintf("%.2f", metrics.PRMetrics.AvgCycleTimeHours)})
-	writer.Write([]string{"Pull Requests", "Avg Review Time (hours)", fmt.Sprintf("%.2f", metrics.PRMetrics.AvgReviewTimeHours)})
-	writer.Write([]string{"Pull Requests", "Merge Success Rate (%)", fmt.Sprintf("%.2f", metrics.PRMetrics.MergeSuccessRate)})
-
-	writer.Write([]string{"Jira Stories", "Total Stories", strconv.Itoa(metrics.JiraMetrics.TotalStories)})
-	writer.Write([]string{"Jira Stories", "Completed Stories", strconv.Itoa(metrics.JiraMetrics.CompletedStories)})
-	writer.Write([]string{"Jira Stories", "Avg Lead Time (days)", fmt.Sprintf("%.2f", metrics.JiraMetrics.AvgLeadTimeDays)})
-	writer.Write([]string{"Jira Stories", "Avg Cycle Time (days)", fmt.Sprintf("%.2f", metrics.JiraMetrics.AvgCycleTimeDays)})
-	writer.Write([]string{"Jira Stories", "Throughput (per week)", fmt.Sprintf("%.2f", metrics.JiraMetrics.Throughput)})
-	writer.Write([]string{"Jira Stories", "Estimate Accuracy (%)", fmt.Sprintf("%.2f", metrics.JiraMetrics.EstimateAccuracy)})
-
-	return nil
+	writer.Write([]string{"Summary", "Productivity Score (0-100)", nf.Float(metrics.ProductivityScore, 1)})
+
+	writer.Write([]string{"Commits", "Total Commits", nf.Int(metrics.CommitMetrics.TotalCommits)})
+	writer.Write([]string{"Commits", "Commits Per Day", nf.Float(metrics.CommitMetrics.CommitsPerDay, 2)})
+	writer.Write([]string{"Commits", "Active Days", nf.Int(metrics.CommitMetrics.ActiveDays)})
+	writer.Write([]string{"Commits", "Lines Added", nf.Int(metrics.CommitMetrics.TotalLinesAdded)})
+	writer.Write([]string{"Commits", "Lines Deleted", nf.Int(metrics.CommitMetrics.TotalLinesDeleted)})
+	writer.Write([]string{"Commits", "Lines Ignored", nf.Int(metrics.CommitMetrics.TotalLinesIgnored)})
+	writer.Write([]string{"Commits", "Avg Commit Gap (hours)", nf.Float(metrics.CommitMetrics.AvgCommitGapHours, 2)})
+	writer.Write([]string{"Commits", "Conventional Commit Rate (%)", nf.Float(metrics.CommitMetrics.ConventionalCommitRate, 2)})
+	for _, commitType := range sortedAuthors(metrics.CommitMetrics.CommitsByType) {
+		writer.Write([]string{"Commits by Type", commitType, nf.Int(metrics.CommitMetrics.CommitsByType[commitType])})
+	}
+
+	writer.Write([]string{"Pull Requests", "Total PRs", nf.Int(metrics.PRMetrics.TotalPRs)})
+	writer.Write([]string{"Pull Requests", "Merged PRs", nf.Int(metrics.PRMetrics.MergedPRs)})
+	writer.Write([]string{"Pull Requests", "Lines Ignored", nf.Int(metrics.PRMetrics.TotalLinesIgnored)})
+	writer.Write([]string{"Pull Requests", "Avg Cycle Time (hours)", nf.Float(metrics.PRMetrics.AvgCycleTimeHours, 2)})
+	writer.Write([]string{"Pull Requests", "Median Cycle Time (hours)", nf.Float(metrics.PRMetrics.MedianCycleTimeHours, 2)})
+	writer.Write([]string{"Pull Requests", "P90 Cycle Time (hours)", nf.Float(metrics.PRMetrics.P90CycleTimeHours, 2)})
+	writer.Write([]string{"Pull Requests", "Avg Branch Lifetime (hours)", nf.Float(metrics.PRMetrics.AvgBranchLifetimeHours, 2)})
+	writer.Write([]string{"Pull Requests", "Avg Time in Draft (hours)", nf.Float(metrics.PRMetrics.AvgTimeInDraftHours, 2)})
+	writer.Write([]string{"Pull Requests", "Avg Review Time (hours)", nf.Float(metrics.PRMetrics.AvgReviewTimeHours, 2)})
+	writer.Write([]string{"Pull Requests", "Median Review Time (hours)", nf.Float(metrics.PRMetrics.MedianReviewTimeHours, 2)})
+	writer.Write([]string{"Pull Requests", "P90 Review Time (hours)", nf.Float(metrics.PRMetrics.P90ReviewTimeHours, 2)})
+	writer.Write([]string{"Pull Requests", "Merge Success Rate (%)", nf.Float(metrics.PRMetrics.MergeSuccessRate, 2)})
+	writer.Write([]string{"Pull Requests", "Avg Review Cycles", nf.Float(metrics.PRMetrics.AvgReviewCycles, 2)})
+	writer.Write([]string{"Pull Requests", "Total Review Comments", nf.Int(metrics.PRMetrics.TotalReviewComments)})
+	writer.Write([]string{"Pull Requests", "Avg Review Comments per PR", nf.Float(metrics.PRMetrics.AvgReviewCommentsPerPR, 2)})
+	writer.Write([]string{"Pull Requests", "PRs With Excessive Review Cycles", nf.Int(len(metrics.PRMetrics.HighReviewCyclePRs))})
+	writer.Write([]string{"Pull Requests", "Self-Merged PRs", nf.Int(metrics.PRMetrics.SelfMergedPRs)})
+	writer.Write([]string{"Pull Requests", "Merged Below Required Approvals", nf.Int(metrics.PRMetrics.UnderReviewedPRs)})
+	writer.Write([]string{"Pull Requests", "Idle Open PRs", nf.Int(metrics.PRMetrics.StalePRs.IdleCount)})
+	writer.Write([]string{"Pull Requests", "Aged Open PRs", nf.Int(metrics.PRMetrics.StalePRs.AgedCount)})
+	prAuthors := make([]string, 0, len(metrics.PRMetrics.CycleTimeByAuthor))
+	for author := range metrics.PRMetrics.CycleTimeByAuthor {
+		prAuthors = append(prAuthors, author)
+	}
+	sort.Strings(prAuthors)
+	for _, author := range prAuthors {
+		writer.Write([]string{"PR Cycle Time by Author (hours)", author, nf.Float(metrics.PRMetrics.CycleTimeByAuthor[author], 2)})
+	}
+	reviewAuthors := make([]string, 0, len(metrics.PRMetrics.ReviewTimeByAuthor))
+	for author := range metrics.PRMetrics.ReviewTimeByAuthor {
+		reviewAuthors = append(reviewAuthors, author)
+	}
+	sort.Strings(reviewAuthors)
+	for _, author := range reviewAuthors {
+		writer.Write([]string{"PR Review Time by Author (hours)", author, nf.Float(metrics.PRMetrics.ReviewTimeByAuthor[author], 2)})
+	}
+
+	for _, b := range metrics.PRMetrics.SizeVsReview.Buckets {
+		writer.Write([]string{"PR Size vs Review", b.Label + " Avg Review Time (hours)", nf.Float(b.AvgReviewTimeHours, 2)})
+		writer.Write([]string{"PR Size vs Review", b.Label + " Avg Comments", nf.Float(b.AvgComments, 2)})
+	}
+	writer.Write([]string{"PR Size vs Review", "Size/Review Time Correlation", nf.Float(metrics.PRMetrics.SizeVsReview.ReviewTimeCorrelation, 2)})
+	writer.Write([]string{"PR Size vs Review", "Size/Comments Correlation", nf.Float(metrics.PRMetrics.SizeVsReview.CommentsCorrelation, 2)})
+
+	if il := metrics.IssueLinkage; il != nil {
+		writer.Write([]string{"Issue Linkage", "Commits With Ticket", nf.Int(il.CommitsWithTicket)})
+		writer.Write([]string{"Issue Linkage", "Linked Commits", nf.Int(il.LinkedCommits)})
+		writer.Write([]string{"Issue Linkage", "Linked Via Renamed Key", nf.Int(il.LinkedViaAlias)})
+	}
+
+	if pl := metrics.CommitPRLinkage; pl != nil {
+		writer.Write([]string{"PR Coverage", "Commits In PRs", nf.Int(pl.CommitsInPRs)})
+		writer.Write([]string{"PR Coverage", "Orphan Commits", nf.Int(pl.OrphanCommits)})
+		writer.Write([]string{"PR Coverage", "PR Coverage Rate (%)", nf.Float(pl.PRCoverageRate, 2)})
+	}
+
+	if rt := metrics.ReviewTeam; rt != nil {
+		writer.Write([]string{"Review Team", "PRs Reviewed", nf.Int(rt.PRsReviewed)})
+		writer.Write([]string{"Review Team", "Avg Turnaround (hours)", nf.Float(rt.AvgTurnaroundHours, 2)})
+		writer.Write([]string{"Review Team", "Avg Cycle Time (hours)", nf.Float(rt.AvgCycleTimeHours, 2)})
+	}
+
+	for _, t := range metrics.Teams {
+		writer.Write([]string{"Team " + t.Team, "Commits", nf.Int(t.Commits)})
+		writer.Write([]string{"Team " + t.Team, "PRs", nf.Int(t.PRs)})
+		writer.Write([]string{"Team " + t.Team, "Avg Cycle Time (hours)", nf.Float(t.AvgCycleTimeHours, 2)})
+	}
+
+	writer.Write([]string{"Org Rollup", "Unique Commits", nf.Int(metrics.OrgRollup.TotalCommits)})
+	writer.Write([]string{"Org Rollup", "Person Active Days", nf.Int(metrics.OrgRollup.PersonActiveDays)})
+	writer.Write([]string{"Org Rollup", "Commits Per Active Day", nf.Float(metrics.OrgRollup.CommitsPerActiveDay, 2)})
+	writer.Write([]string{"Org Rollup", "Shared Contributors", nf.Int(metrics.OrgRollup.SharedContributors)})
+	writer.Write([]string{"Per Capita", "Team Size", nf.Int(metrics.PerCapita.TeamSize)})
+	writer.Write([]string{"Per Capita", "Commits Per Contributor", nf.Float(metrics.PerCapita.CommitsPerContributor, 2)})
+	writer.Write([]string{"Per Capita", "PRs Per Contributor", nf.Float(metrics.PerCapita.PRsPerContributor, 2)})
+	writer.Write([]string{"Per Capita", "Merged PRs Per Contributor", nf.Float(metrics.PerCapita.MergedPRsPerContributor, 2)})
+	writer.Write([]string{"Per Capita", "Completed Stories Per Contributor", nf.Float(metrics.PerCapita.CompletedStoriesPerContributor, 2)})
+
+	writer.Write([]string{"Deployments", "Total Deployments", nf.Int(metrics.DeploymentMetrics.TotalDeployments)})
+	writer.Write([]string{"Deployments", "Deployments Per Week", nf.Float(metrics.DeploymentMetrics.DeploymentsPerWeek, 2)})
+	writer.Write([]string{"Deployments", "Change Failure Rate (%)", nf.Float(metrics.ChangeFailure.ChangeFailureRate, 2)})
+	writer.Write([]string{"Deployments", "Failures", nf.Int(metrics.ChangeFailure.Failures)})
+
+	writer.Write([]string{"Jira Stories", "Total Stories", nf.Int(metrics.JiraMetrics.TotalStories)})
+	writer.Write([]string{"Jira Stories", "Completed Stories", nf.Int(metrics.JiraMetrics.CompletedStories)})
+	writer.Write([]string{"Jira Stories", "Avg Lead Time (days)", nf.Float(metrics.JiraMetrics.AvgLeadTimeDays, 2)})
+	writer.Write([]string{"Jira Stories", "Avg Lead Time (business days)", nf.Float(metrics.JiraMetrics.AvgLeadTimeBusinessDays, 2)})
+	writer.Write([]string{"Jira Stories", "Avg Cycle Time (days)", nf.Float(metrics.JiraMetrics.AvgCycleTimeDays, 2)})
+	writer.Write([]string{"Jira Stories", "Throughput (per week)", nf.Float(metrics.JiraMetrics.Throughput, 2)})
+	writer.Write([]string{"Jira Stories", "Estimate Accuracy (%)", nf.Float(metrics.JiraMetrics.EstimateAccuracy, 2)})
+	writer.Write([]string{"Jira Stories", "Estimate Accuracy Sample Size", nf.Int(metrics.JiraMetrics.AccuracySample)})
+	writer.Write([]string{"Jira Stories", "Open Stories", nf.Int(metrics.JiraMetrics.OpenStories)})
+	writer.Write([]string{"Jira Stories", "Avg Age of Open Stories (days)", nf.Float(metrics.JiraMetrics.AvgAgeOpenDays, 2)})
+	writer.Write([]string{"Jira Stories", "Oldest Open Story (days)", nf.Float(metrics.JiraMetrics.OldestOpenDays, 2)})
+	writer.Write([]string{"Jira Stories", "Stale Stories", nf.Int(metrics.JiraMetrics.StaleStories.Count)})
+	for _, issueType := range sortedAuthors(metrics.JiraMetrics.StoriesByType) {
+		writer.Write([]string{"Jira Issue Types", issueType + " Issues", nf.Int(metrics.JiraMetrics.StoriesByType[issueType])})
+		writer.Write([]string{"Jira Issue Types", issueType + " Throughput (per week)", nf.Float(metrics.JiraMetrics.ThroughputByType[issueType], 2)})
+		writer.Write([]string{"Jira Issue Types", issueType + " Avg Lead Time (days)", nf.Float(metrics.JiraMetrics.AvgLeadTimeByType[issueType], 2)})
+	}
+	for _, label := range sortedAuthors(metrics.JiraMetrics.StoriesByLabel) {
+		writer.Write([]string{"Jira Labels", label + " Stories", nf.Int(metrics.JiraMetrics.StoriesByLabel[label])})
+		writer.Write([]string{"Jira Labels", label + " Completed", nf.Int(metrics.JiraMetrics.CompletedByLabel[label])})
+	}
+	if sp := metrics.JiraMetrics.Sprint; sp != nil {
+		writer.Write([]string{"Jira Sprint", "Sprint", sp.Name})
+		writer.Write([]string{"Jira Sprint", "Committed Points", nf.Float(sp.CommittedPoints, 2)})
+		writer.Write([]string{"Jira Sprint", "Completed Points", nf.Float(sp.CompletedPoints, 2)})
+		writer.Write([]string{"Jira Sprint", "Completion Rate (%)", nf.Float(sp.CompletionRate, 2)})
+		writer.Write([]string{"Jira Sprint", "Velocity", nf.Float(sp.Velocity, 2)})
+		writer.Write([]string{"Jira Sprint", "Carryover Stories", nf.Int(sp.CarryoverCount)})
+	}
+	if st := metrics.JiraMetrics.Subtasks; st != nil {
+		writer.Write([]string{"Jira Sub-tasks", "Total (" + st.Mode + ")", nf.Int(st.Total)})
+		writer.Write([]string{"Jira Sub-tasks", "Completed", nf.Int(st.Completed)})
+	}
+	if len(metrics.Truncated) > 0 {
+		writer.Write([]string{"Run", "Truncated Fetches", strings.Join(metrics.Truncated, " ")})
+	}
+
+	writer.Flush()
+	return writer.Error()
 }
 
-// PrintMetricsSummary displays a formatted summary to the console
-func PrintMetricsSummary(metrics metrics.TeamMetrics) {
+// PrintMetricsSummary displays a formatted summary to the console. When metrics.Providers
+// is set, sections of providers that did not run are left out.
+func PrintMetricsSummary(metrics metrics.TeamMetrics, nf NumberFormat) {
 	fmt.Println("\n" + strings.Repeat("=", 60))
 	fmt.Println("DEVOPS & PRODUCTIVITY METRICS REPORT")
 	fmt.Println(strings.Repeat("=", 60))
+	if len(metrics.Truncated) > 0 {
+		fmt.Printf("⚠️  Results truncated by fetch caps: %s\n", strings.Join(metrics.Truncated, ", "))
+	}
+	nf.Printf("Productivity Score: %.1f / 100\n", metrics.ProductivityScore)
+
+	ranGit, ranJira := ranProviders(metrics)
+	if ranGit {
+		printGitSections(metrics, nf)
+		printDeploymentSection(metrics, nf)
+	}
+	if ranJira {
+		printJiraSection(metrics, nf)
+	}
+
+	fmt.Println("\n" + strings.Repeat("=", 60))
+}
 
+// ranProviders reports whether git providers and Jira were fetched from. Both are assumed
+// to have run when the providers are unknown.
+func ranProviders(metrics metrics.TeamMetrics) (ranGit, ranJira bool) {
+	ranGit, ranJira = len(metrics.Providers) == 0, len(metrics.Providers) == 0
+	for _, provider := range metrics.Providers {
+		if provider == "jira" {
+			ranJira = true
+		} else {
+			ranGit = true
+		}
+	}
+	return ranGit, ranJira
+}
+
+// printGitSections prints the commit, pull request, review and contributor sections
+func printGitSections(metrics metrics.TeamMetrics, nf NumberFormat) {
 	fmt.Println("\n📊 COMMIT METRICS")
 	fmt.Println(strings.Repeat("-", 60))
-	fmt.Printf("Total Commits: %d\n", metrics.CommitMetrics.TotalCommits)
-	fmt.Printf("Commits Per Day: %.2f\n", metrics.CommitMetrics.CommitsPerDay)
-	fmt.Printf("Active Days: %d\n", metrics.CommitMetrics.ActiveDays)
-	fmt.Printf("Lines Added: %d | Lines Deleted: %d\n",
-		metrics.CommitMetrics.TotalLinesAdded, metrics.CommitMetrics.TotalLinesDeleted)
-	fmt.Printf("Date Range: %s\n", metrics.CommitMetrics.DateRange)
+	nf.Printf("Total Commits: %d\n", metrics.CommitMetrics.TotalCommits)
+	nf.Printf("Commits Per Day: %.2f\n", metrics.CommitMetrics.CommitsPerDay)
+	nf.Printf("Active Days: %d\n", metrics.CommitMetrics.ActiveDays)
+	nf.Printf("Lines Added: %d | Lines Deleted: %d | Lines Ignored: %d\n",
+		metrics.CommitMetrics.TotalLinesAdded, metrics.CommitMetrics.TotalLinesDeleted, metrics.CommitMetrics.TotalLinesIgnored)
+	nf.Printf("Date Range: %s\n", metrics.CommitMetrics.DateRange)
+	nf.Printf("Avg Gap Between Commits: %.2f hours\n", metrics.CommitMetrics.AvgCommitGapHours)
 
 	fmt.Println("\nCommits by Author:")
 	authors := make([]string, 0, len(metrics.CommitMetrics.CommitsByAuthor))
@@ -77,29 +252,284 @@ func PrintMetricsSummary(metrics metrics.TeamMetrics) {
 	}
 	sort.Strings(authors)
 	for _, author := range authors {
-		fmt.Printf("  - %s: %d commits\n", author, metrics.CommitMetrics.CommitsByAuthor[author])
+		if gap, ok := metrics.CommitMetrics.CommitGapByAuthor[author]; ok {
+			nf.Printf("  - %s: %d commits (gap avg %.1fh, median %.1fh)\n", author,
+				metrics.CommitMetrics.CommitsByAuthor[author], gap.AvgHours, gap.MedianHours)
+		} else {
+			nf.Printf("  - %s: %d commits\n", author, metrics.CommitMetrics.CommitsByAuthor[author])
+		}
+	}
+
+	nf.Printf("\nConventional Commit Rate: %.1f%%\n", metrics.CommitMetrics.ConventionalCommitRate)
+	fmt.Println("Commits by Type:")
+	for _, commitType := range sortedAuthors(metrics.CommitMetrics.CommitsByType) {
+		nf.Printf("  - %s: %d commits\n", commitType, metrics.CommitMetrics.CommitsByType[commitType])
 	}
 
 	fmt.Println("\n🔀 PULL REQUEST METRICS")
 	fmt.Println(strings.Repeat("-", 60))
-	fmt.Printf("Total PRs: %d (Merged: %d, Closed: %d, Open: %d)\n",
+	nf.Printf("Total PRs: %d (Merged: %d, Closed: %d, Open: %d)\n",
 		metrics.PRMetrics.TotalPRs, metrics.PRMetrics.MergedPRs,
 		metrics.PRMetrics.ClosedPRs, metrics.PRMetrics.OpenPRs)
-	fmt.Printf("Avg Cycle Time: %.2f hours\n", metrics.PRMetrics.AvgCycleTimeHours)
-	fmt.Printf("Avg Review Time: %.2f hours\n", metrics.PRMetrics.AvgReviewTimeHours)
-	fmt.Printf("Avg PR Size: %.0f lines\n", metrics.PRMetrics.AvgPRSize)
-	fmt.Printf("Merge Success Rate: %.2f%%\n", metrics.PRMetrics.MergeSuccessRate)
+	nf.Printf("Avg Cycle Time: %.2f hours (median %.2f, p90 %.2f)\n", metrics.PRMetrics.AvgCycleTimeHours,
+		metrics.PRMetrics.MedianCycleTimeHours, metrics.PRMetrics.P90CycleTimeHours)
+	if metrics.PRMetrics.AvgBranchLifetimeHours > 0 {
+		nf.Printf("Avg Branch Lifetime (first commit to merge): %.2f hours\n", metrics.PRMetrics.AvgBranchLifetimeHours)
+	}
+	if metrics.PRMetrics.AvgTimeInDraftHours > 0 {
+		nf.Printf("Avg Time in Draft: %.2f hours\n", metrics.PRMetrics.AvgTimeInDraftHours)
+	}
+	nf.Printf("Avg Review Time: %.2f hours (median %.2f, p90 %.2f)\n", metrics.PRMetrics.AvgReviewTimeHours,
+		metrics.PRMetrics.MedianReviewTimeHours, metrics.PRMetrics.P90ReviewTimeHours)
+	nf.Printf("Avg PR Size: %.0f lines (%d lines in ignored files excluded)\n",
+		metrics.PRMetrics.AvgPRSize, metrics.PRMetrics.TotalLinesIgnored)
+	nf.Printf("Merge Success Rate: %.2f%%\n", metrics.PRMetrics.MergeSuccessRate)
+	nf.Printf("Avg Review Cycles: %.2f\n", metrics.PRMetrics.AvgReviewCycles)
+	if metrics.PRMetrics.TotalReviewComments > 0 {
+		nf.Printf("Review Comments: %d (%.2f per PR)\n", metrics.PRMetrics.TotalReviewComments, metrics.PRMetrics.AvgReviewCommentsPerPR)
+	}
+	if len(metrics.PRMetrics.HighReviewCyclePRs) > 0 {
+		nf.Printf("PRs With Excessive Review Cycles: %s\n", strings.Join(metrics.PRMetrics.HighReviewCyclePRs, ", "))
+	}
+	nf.Printf("Self-Merged PRs (no other approver): %d\n", metrics.PRMetrics.SelfMergedPRs)
+	if len(metrics.PRMetrics.SelfMergedPRIDs) > 0 {
+		nf.Printf("  %s\n", strings.Join(metrics.PRMetrics.SelfMergedPRIDs, ", "))
+	}
+	if len(metrics.PRMetrics.UnderReviewedPRIDs) > 0 {
+		nf.Printf("Merged Below Required Approvals: %d\n  %s\n", metrics.PRMetrics.UnderReviewedPRs,
+			strings.Join(metrics.PRMetrics.UnderReviewedPRIDs, ", "))
+	}
+	if stale := metrics.PRMetrics.StalePRs; stale.IdleCount > 0 {
+		nf.Printf("Idle Open PRs (no update in %d+ days): %d\n  %s\n", stale.IdleThresholdDays, stale.IdleCount,
+			strings.Join(stale.IdleIDs, ", "))
+	}
+	if stale := metrics.PRMetrics.StalePRs; stale.AgedCount > 0 {
+		nf.Printf("Aged Open PRs (opened %d+ days ago): %d, oldest %s at %.1f days\n  %s\n", stale.AgeThresholdDays, stale.AgedCount,
+			stale.OldestID, stale.OldestAgeDays, strings.Join(stale.AgedIDs, ", "))
+	}
 
+	if len(metrics.PRMetrics.CycleTimeByAuthor) > 0 || len(metrics.PRMetrics.ReviewTimeByAuthor) > 0 {
+		fmt.Println("\nPRs by Author:")
+		nf.Printf("  %-20s %6s %15s %15s\n", "Author", "PRs", "Avg Cycle (h)", "Avg Review (h)")
+		prAuthors := make([]string, 0, len(metrics.PRMetrics.PRsByAuthor))
+		for author := range metrics.PRMetrics.PRsByAuthor {
+			prAuthors = append(prAuthors, author)
+		}
+		sort.Strings(prAuthors)
+		for _, author := range prAuthors {
+			nf.Printf("  %-20s %6d %15.1f %15.1f\n", author, metrics.PRMetrics.PRsByAuthor[author],
+				metrics.PRMetrics.CycleTimeByAuthor[author], metrics.PRMetrics.ReviewTimeByAuthor[author])
+		}
+	}
+
+	fmt.Println("\nPR Size vs Review:")
+	nf.Printf("  %-4s %-12s %6s %14s %13s\n", "Size", "Lines", "PRs", "Avg Review (h)", "Avg Comments")
+	for _, b := range metrics.PRMetrics.SizeVsReview.Buckets {
+		lines := fmt.Sprintf("%d-%d", b.MinLines, b.MaxLines)
+		if b.MaxLines == 0 {
+			lines = fmt.Sprintf("%d+", b.MinLines)
+		}
+		nf.Printf("  %-4s %-12s %6d %14.2f %13.2f\n", b.Label, lines, b.PRCount, b.AvgReviewTimeHours, b.AvgComments)
+	}
+	nf.Printf("  Correlation (size vs review time): %.2f | (size vs comments): %.2f\n",
+		metrics.PRMetrics.SizeVsReview.ReviewTimeCorrelation, metrics.PRMetrics.SizeVsReview.CommentsCorrelation)
+
+	if il := metrics.IssueLinkage; il != nil {
+		fmt.Println("\n🔗 COMMIT-ISSUE LINKAGE")
+		fmt.Println(strings.Repeat("-", 60))
+		nf.Printf("Commits Referencing a Ticket: %d\n", il.CommitsWithTicket)
+		nf.Printf("Linked to Fetched Stories: %d (%d via renamed keys)\n", il.LinkedCommits, il.LinkedViaAlias)
+		if len(il.UnmatchedKeys) > 0 {
+			nf.Printf("Unmatched Keys: %d\n", len(il.UnmatchedKeys))
+		}
+	}
+
+	if pl := metrics.CommitPRLinkage; pl != nil {
+		fmt.Println("\n🔀 PR COVERAGE")
+		fmt.Println(strings.Repeat("-", 60))
+		nf.Printf("Commits Landed Through a Merged PR: %d (%.2f%%)\n", pl.CommitsInPRs, pl.PRCoverageRate)
+		nf.Printf("  by hash: %d | by message reference: %d | by author and time: %d\n",
+			pl.LinkedByHash, pl.LinkedByMessage, pl.LinkedByAuthor)
+		nf.Printf("Orphan Commits (outside any PR): %d\n", pl.OrphanCommits)
+	}
+
+	if rt := metrics.ReviewTeam; rt != nil {
+		fmt.Println("\n👥 REVIEW TEAM")
+		fmt.Println(strings.Repeat("-", 60))
+		nf.Printf("PRs Reviewed by Team: %d (%.2f%% of all PRs)\n", rt.PRsReviewed, rt.ShareOfAllPRsPct)
+		nf.Printf("Avg Turnaround: %.2f hours | Avg Cycle Time: %.2f hours\n", rt.AvgTurnaroundHours, rt.AvgCycleTimeHours)
+		for _, member := range rt.Members {
+			nf.Printf("  - %s: %d PRs reviewed\n", member, rt.ReviewsByMember[member])
+		}
+	}
+
+	if len(metrics.Teams) > 0 {
+		fmt.Println("\n🏢 TEAMS")
+		fmt.Println(strings.Repeat("-", 60))
+		for _, t := range metrics.Teams {
+			nf.Printf("%s (%d members): %d commits, %d PRs (%d merged), avg cycle time %.2f hours\n",
+				t.Team, len(t.Members), t.Commits, t.PRs, t.MergedPRs, t.AvgCycleTimeHours)
+		}
+	}
+
+	if edges := metrics.ReviewGraph.Edges; len(edges) > 0 {
+		fmt.Println("\nTop Review Pairs (reviewer → author):")
+		for i, edge := range edges {
+			if i == 5 {
+				break
+			}
+			nf.Printf("  - %s → %s: %d PRs\n", edge.Reviewer, edge.Author, edge.Reviews)
+		}
+	}
+
+	fmt.Println("\n🏢 ORG ROLLUP (deduplicated across repos)")
+	fmt.Println(strings.Repeat("-", 60))
+	nf.Printf("Unique Commits: %d | Person Active Days: %d\n",
+		metrics.OrgRollup.TotalCommits, metrics.OrgRollup.PersonActiveDays)
+	nf.Printf("Commits Per Active Day: %.2f\n", metrics.OrgRollup.CommitsPerActiveDay)
+	nf.Printf("Shared Contributors: %d\n", metrics.OrgRollup.SharedContributors)
+	for _, c := range metrics.OrgRollup.Contributors {
+		nf.Printf("  - %s: %d commits over %d active days (%.2f/day) in %s\n",
+			c.Author, c.Commits, c.ActiveDays, c.CommitsPerActiveDay, strings.Join(c.Repos, ", "))
+	}
+
+	pc := metrics.PerCapita
+	nf.Printf("\nPer Contributor (team size %d, %s): %.2f commits, %.2f PRs (%.2f merged), %.2f completed stories\n",
+		pc.TeamSize, strings.ReplaceAll(pc.TeamSizeSource, "_", " "), pc.CommitsPerContributor,
+		pc.PRsPerContributor, pc.MergedPRsPerContributor, pc.CompletedStoriesPerContributor)
+}
+
+// printDeploymentSection prints deployment frequency and change failure rate
+func printDeploymentSection(metrics metrics.TeamMetrics, nf NumberFormat) {
+	fmt.Println("\n🚀 DEPLOYMENT METRICS")
+	fmt.Println(strings.Repeat("-", 60))
+	nf.Printf("Total Deployments: %d | Deployments Per Week: %.2f\n",
+		metrics.DeploymentMetrics.TotalDeployments, metrics.DeploymentMetrics.DeploymentsPerWeek)
+	environments := make([]string, 0, len(metrics.DeploymentMetrics.DeploymentsByEnvironment))
+	for environment := range metrics.DeploymentMetrics.DeploymentsByEnvironment {
+		environments = append(environments, environment)
+	}
+	sort.Strings(environments)
+	for _, environment := range environments {
+		nf.Printf("  - %s: %d deployments\n", environment, metrics.DeploymentMetrics.DeploymentsByEnvironment[environment])
+	}
+	cf := metrics.ChangeFailure
+	nf.Printf("Change Failure Rate: %.2f%% (%d failures / %d changes, %d merged PRs reverted or hotfixed within 7 days)\n",
+		cf.ChangeFailureRate, cf.Failures, cf.Changes, cf.FailedPRs)
+}
+
+// printJiraSection prints the Jira story metrics
+func printJiraSection(metrics metrics.TeamMetrics, nf NumberFormat) {
 	fmt.Println("\n📋 JIRA STORY METRICS")
 	fmt.Println(strings.Repeat("-", 60))
-	fmt.Printf("Total Stories: %d (Completed: %d)\n",
+	nf.Printf("Total Stories: %d (Completed: %d)\n",
 		metrics.JiraMetrics.TotalStories, metrics.JiraMetrics.CompletedStories)
-	fmt.Printf("Avg Lead Time: %.2f days\n", metrics.JiraMetrics.AvgLeadTimeDays)
-	fmt.Printf("Avg Cycle Time: %.2f days\n", metrics.JiraMetrics.AvgCycleTimeDays)
-	fmt.Printf("Throughput: %.2f stories/week\n", metrics.JiraMetrics.Throughput)
-	fmt.Printf("Avg Estimate: %.2f | Avg Actual: %.2f\n",
+	nf.Printf("Avg Lead Time: %.2f days (%.2f business days)\n", metrics.JiraMetrics.AvgLeadTimeDays, metrics.JiraMetrics.AvgLeadTimeBusinessDays)
+	nf.Printf("Avg Cycle Time: %.2f days\n", metrics.JiraMetrics.AvgCycleTimeDays)
+	nf.Printf("Throughput: %.2f stories/week\n", metrics.JiraMetrics.Throughput)
+	nf.Printf("Avg Estimate: %.2f | Avg Actual: %.2f\n",
 		metrics.JiraMetrics.AvgEstimate, metrics.JiraMetrics.AvgActualEffort)
-	fmt.Printf("Estimate Accuracy: %.2f%%\n", metrics.JiraMetrics.EstimateAccuracy)
+	nf.Printf("Estimate Accuracy: %.2f%% (%d completed stories with estimate and actual)\n",
+		metrics.JiraMetrics.EstimateAccuracy, metrics.JiraMetrics.AccuracySample)
 
-	fmt.Println("\n" + strings.Repeat("=", 60))
-}
\ No newline at end of file
+	if len(metrics.JiraMetrics.ThroughputByAssignee) > 0 {
+		fmt.Println("\nThroughput by Assignee:")
+		lowConfidence := make(map[string]bool)
+		for _, assignee := range metrics.JiraMetrics.LowConfidenceAssignees {
+			lowConfidence[assignee] = true
+		}
+		assignees := make([]string, 0, len(metrics.JiraMetrics.ThroughputByAssignee))
+		for assignee := range metrics.JiraMetrics.ThroughputByAssignee {
+			assignees = append(assignees, assignee)
+		}
+		sort.Strings(assignees)
+		for _, assignee := range assignees {
+			note := ""
+			if lowConfidence[assignee] {
+				note = " (low confidence)"
+			}
+			nf.Printf("  - %s: %.2f stories/week, avg lead time %.2f days%s\n", assignee,
+				metrics.JiraMetrics.ThroughputByAssignee[assignee], metrics.JiraMetrics.AvgLeadTimeByAssignee[assignee], note)
+		}
+	}
+
+	if len(metrics.JiraMetrics.StoriesByComponent) > 0 {
+		fmt.Println("\nStories by Component:")
+		components := make([]string, 0, len(metrics.JiraMetrics.StoriesByComponent))
+		for component := range metrics.JiraMetrics.StoriesByComponent {
+			components = append(components, component)
+		}
+		sort.Strings(components)
+		for _, component := range components {
+			nf.Printf("  - %s: %d stories, avg lead time %.2f days\n", component,
+				metrics.JiraMetrics.StoriesByComponent[component], metrics.JiraMetrics.AvgLeadTimeByComponent[component])
+		}
+	}
+
+	if sp := metrics.JiraMetrics.Sprint; sp != nil {
+		nf.Printf("\nSprint %s (%s): %d of %d stories completed\n", sp.Name, sp.State, sp.CompletedStories, sp.CommittedStories)
+		nf.Printf("  Points: %.1f committed, %.1f completed (%.2f%%), velocity %.1f\n",
+			sp.CommittedPoints, sp.CompletedPoints, sp.CompletionRate, sp.Velocity)
+		if sp.CarryoverCount > 0 {
+			nf.Printf("  Carried over: %d (%s)\n", sp.CarryoverCount, strings.Join(sp.CarryoverKeys, ", "))
+		}
+	}
+
+	if len(metrics.JiraMetrics.StoriesByType) > 0 {
+		fmt.Println("\nStories by Issue Type:")
+		for _, issueType := range sortedAuthors(metrics.JiraMetrics.StoriesByType) {
+			nf.Printf("  - %s: %d issues, %.2f/week, avg lead time %.2f days\n", issueType,
+				metrics.JiraMetrics.StoriesByType[issueType], metrics.JiraMetrics.ThroughputByType[issueType],
+				metrics.JiraMetrics.AvgLeadTimeByType[issueType])
+		}
+	}
+
+	if len(metrics.JiraMetrics.StoriesByLabel) > 0 {
+		fmt.Println("\nStories by Label:")
+		for _, label := range sortedAuthors(metrics.JiraMetrics.StoriesByLabel) {
+			nf.Printf("  - %s: %d stories, %d completed\n", label,
+				metrics.JiraMetrics.StoriesByLabel[label], metrics.JiraMetrics.CompletedByLabel[label])
+		}
+	}
+
+	if j := metrics.JiraMetrics; j.OpenStories > 0 {
+		nf.Printf("\nOpen Stories: %d, avg age %.1f days, oldest %.1f days\n", j.OpenStories, j.AvgAgeOpenDays, j.OldestOpenDays)
+	}
+	if stale := metrics.JiraMetrics.StaleStories; stale.Count > 0 {
+		nf.Printf("\nStale Stories (no status change in %d+ days): %d, oldest %s idle %.1f days\n",
+			stale.ThresholdDays, stale.Count, stale.OldestKey, stale.OldestIdleDays)
+	}
+	if st := metrics.JiraMetrics.Subtasks; st != nil {
+		nf.Printf("\nSub-tasks (%s): %d, %d completed", st.Mode, st.Total, st.Completed)
+		if st.Mode == "rollup" {
+			nf.Printf(", %d rolled up into parent stories", st.RolledUp)
+		}
+		if st.Mode == "separate" {
+			nf.Printf(", avg actual effort %.2f", st.AvgActualEffort)
+		}
+		fmt.Println()
+	}
+}
+
+// PrintFetchStats displays per-provider API request statistics
+func PrintFetchStats(stats map[string]fetchstats.ProviderStats) {
+	if len(stats) == 0 {
+		return
+	}
+
+	fmt.Println("\n📡 FETCH STATS")
+	fmt.Println(strings.Repeat("-", 60))
+	providers := make([]string, 0, len(stats))
+	for provider := range stats {
+		providers = append(providers, provider)
+	}
+	sort.Strings(providers)
+	for _, provider := range providers {
+		s := stats[provider]
+		fmt.Printf("%s: %d requests (%d errors, %d retries), %.1f KB, %.0f ms total / %.0f ms avg\n",
+			provider, s.Requests, s.Errors, s.Retries, float64(s.Bytes)/1024, s.TotalLatencyMs, s.AvgLatencyMs)
+		if len(s.Truncated) > 0 {
+			fmt.Printf("  truncated at cap: %s\n", strings.Join(s.Truncated, ", "))
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package report
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"devops-metrics/metrics"
+)
+
+func TestWriteCSVLabels(t *testing.T) {
+	m := metrics.TeamMetrics{JiraMetrics: metrics.JiraMetrics{
+		StoriesByLabel:   map[string]int{"tech-debt": 3, "bug": 4, "(none)": 1},
+		CompletedByLabel: map[string]int{"bug": 2, "tech-debt": 1},
+	}}
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, m, DefaultNumberFormat); err != nil {
+		t.Fatalf("WriteCSV() error = %v", err)
+	}
+
+	var got []string
+	for _, line := range strings.Split(buf.String(), "\n") {
+		if strings.HasPrefix(line, "Jira Labels,") {
+			got = append(got, line)
+		}
+	}
+	want := []string{
+		"Jira Labels,(none) Stories,1",
+		"Jira Labels,(none) Completed,0",
+		"Jira Labels,bug Stories,4",
+		"Jira Labels,bug Completed,2",
+		"Jira Labels,tech-debt Stories,3",
+		"Jira Labels,tech-debt Completed,1",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("label rows =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
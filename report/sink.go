@@ -0,0 +1,179 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"devops-metrics/metrics"
+)
+
+// Sink is an output destination for computed metrics
+type Sink interface {
+	Name() string
+	Write(m metrics.TeamMetrics) error
+}
+
+// DefaultSinks reproduces the original file-only output when no sinks are configured
+var DefaultSinks = []string{"file:metrics.json", "file:metrics.csv"}
+
+// NewSink builds a sink from a "kind:target" spec, e.g. "file:metrics.csv",
+// "slack:https://hooks.slack.com/...", "http:https://dashboard.internal/ingest",
+// "pushgateway:http://pushgateway:9091?job=devops_metrics&instance=team-a",
+// "history:metrics-history.jsonl" or "benchmark:benchmark.json"
+func NewSink(spec string, nf NumberFormat, httpOptions HTTPOptions) (Sink, error) {
+	kind, target, ok := strings.Cut(spec, ":")
+	if !ok || target == "" {
+		return nil, fmt.Errorf("invalid sink %q: expected kind:target", spec)
+	}
+
+	switch kind {
+	case "file":
+		return FileSink{Path: target, Format: nf}, nil
+	case "http", "http-post":
+		return HTTPSink{URL: target, Options: httpOptions}, nil
+	case "slack":
+		return SlackSink{WebhookURL: target, Format: nf}, nil
+	case "history":
+		return HistorySink{Path: target}, nil
+	case "benchmark":
+		return BenchmarkSink{Path: target}, nil
+	case "pushgateway", "prometheus-pushgateway":
+		return newPushgatewaySink(target)
+	default:
+		return nil, fmt.Errorf("invalid sink %q: unknown kind %q", spec, kind)
+	}
+}
+
+// WriteAll fans metrics out to every sink, returning the errors of the sinks that failed
+// keyed by sink name. A failing sink does not stop the others.
+func WriteAll(sinks []Sink, m metrics.TeamMetrics) map[string]error {
+	errs := make(map[string]error)
+	for _, sink := range sinks {
+		if err := sink.Write(m); err != nil {
+			errs[sink.Name()] = err
+		}
+	}
+	return errs
+}
+
+// FileSink writes a JSON, CSV, HTML, Markdown or Excel report, chosen by the file extension
+type FileSink struct {
+	Path   string
+	Format NumberFormat
+}
+
+// Name identifies the sink in logs
+func (s FileSink) Name() string {
+	return "file:" + s.Path
+}
+
+// Write exports the metrics to the file
+func (s FileSink) Write(m metrics.TeamMetrics) error {
+	switch strings.ToLower(filepath.Ext(s.Path)) {
+	case ".csv":
+		return ExportToCSV(m, s.Path, s.Format)
+	case ".html", ".htm":
+		return ExportToHTML(m, s.Path)
+	case ".md", ".markdown":
+		return ExportToMarkdown(m, s.Path)
+	case ".xlsx":
+		return ExportToXLSX(m, s.Path)
+	}
+	return ExportToJSON(m, s.Path)
+}
+
+// HTTPOptions configures requests made by HTTPSink
+type HTTPOptions struct {
+	Headers map[string]string // Extra request headers, e.g. Authorization
+	Retries int               // Additional attempts after a network error, 429 or 5xx response
+	Timeout time.Duration     // Per-attempt timeout; 30s when zero
+}
+
+// HTTPSink POSTs the metrics JSON to an arbitrary endpoint
+type HTTPSink struct {
+	URL     string
+	Options HTTPOptions
+}
+
+// Name identifies the sink in logs
+func (s HTTPSink) Name() string {
+	return "http:" + s.URL
+}
+
+// Write posts the metrics as JSON, retrying transient failures with exponential backoff,
+// and prints the endpoint's final response status
+func (s HTTPSink) Write(m metrics.TeamMetrics) error {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+
+	timeout := s.Options.Timeout
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	client := &http.Client{Timeout: timeout}
+	const baseDelay = 1 * time.Second
+
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 {
+			time.Sleep(time.Duration(baseDelay.Nanoseconds() * (1 << (attempt - 1))))
+		}
+
+		req, err := http.NewRequest(http.MethodPost, s.URL, bytes.NewReader(data))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		for name, value := range s.Options.Headers {
+			req.Header.Set(name, value)
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			if attempt < s.Options.Retries {
+				continue
+			}
+			return fmt.Errorf("after %d attempts: %w", attempt+1, err)
+		}
+		resp.Body.Close()
+
+		retryable := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+		if retryable && attempt < s.Options.Retries {
+			continue
+		}
+
+		slog.Info("Sink responded", "sink", s.Name(), "status", resp.StatusCode, "attempt", attempt+1)
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			return fmt.Errorf("unexpected status %d", resp.StatusCode)
+		}
+		return nil
+	}
+}
+
+// PostToWebhook POSTs the metrics JSON to url, sending options.Headers and retrying network
+// errors, 429 and 5xx responses options.Retries times
+func PostToWebhook(m metrics.TeamMetrics, url string, options HTTPOptions) error {
+	return HTTPSink{URL: url, Options: options}.Write(m)
+}
+
+// postJSON POSTs a JSON body and treats any non-2xx response as an error
+func postJSON(url string, data []byte) error {
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Post(url, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
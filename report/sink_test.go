@@ -0,0 +1,65 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPostToWebhook(t *testing.T) {
+	tests := []struct {
+		name         string
+		statuses     []int // Status of each response in turn; the last repeats
+		retries      int
+		wantRequests int
+		wantErr      bool
+	}{
+		{"accepted", []int{http.StatusAccepted}, 0, 1, false},
+		{"retried after a server error", []int{http.StatusServiceUnavailable, http.StatusOK}, 1, 2, false},
+		{"retries exhausted", []int{http.StatusInternalServerError}, 0, 1, true},
+		{"client error is not retried", []int{http.StatusBadRequest}, 2, 1, true},
+	}
+	m := markdownFixture("github", "jira")
+	want, err := json.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests int
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				status := tt.statuses[min(requests, len(tt.statuses)-1)]
+				requests++
+				if r.Method != http.MethodPost {
+					t.Errorf("method = %s, want POST", r.Method)
+				}
+				if got := r.Header.Get("Authorization"); got != "Bearer secret" {
+					t.Errorf("Authorization = %q, want the configured header", got)
+				}
+				if got := r.Header.Get("Content-Type"); got != "application/json" {
+					t.Errorf("Content-Type = %q, want application/json", got)
+				}
+				// Every attempt carries the full payload
+				body, _ := io.ReadAll(r.Body)
+				if !bytes.Equal(body, want) {
+					t.Errorf("payload = %s\nwant %s", body, want)
+				}
+				w.WriteHeader(status)
+			}))
+			defer srv.Close()
+
+			options := HTTPOptions{Headers: map[string]string{"Authorization": "Bearer secret"}, Retries: tt.retries}
+			err := PostToWebhook(m, srv.URL, options)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("PostToWebhook() error = %v, want error %v", err, tt.wantErr)
+			}
+			if requests != tt.wantRequests {
+				t.Errorf("made %d requests, want %d", requests, tt.wantRequests)
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package report
+
+import (
+	"encoding/json"
+
+	"devops-metrics/metrics"
+)
+
+// slackBlock is one Block Kit layout block; only the fields used by the digest are modelled
+type slackBlock struct {
+	Type     string      `json:"type"`
+	Text     *slackText  `json:"text,omitempty"`
+	Fields   []slackText `json:"fields,omitempty"`
+	Elements []slackText `json:"elements,omitempty"`
+}
+
+// slackText is a Block Kit text object: plain_text or mrkdwn
+type slackText struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// slackMessage is an incoming-webhook payload. Text is the fallback shown in notifications
+// and by clients that cannot render blocks.
+type slackMessage struct {
+	Text   string       `json:"text"`
+	Blocks []slackBlock `json:"blocks"`
+}
+
+// SlackSink posts a metrics digest to a Slack incoming webhook
+type SlackSink struct {
+	WebhookURL string
+	Format     NumberFormat
+}
+
+// Name identifies the sink in logs; the webhook URL is a secret and is not included
+func (s SlackSink) Name() string {
+	return "slack"
+}
+
+// Write posts the digest to Slack
+func (s SlackSink) Write(m metrics.TeamMetrics) error {
+	data, err := json.Marshal(slackDigest(m, s.Format))
+	if err != nil {
+		return err
+	}
+	return postJSON(s.WebhookURL, data)
+}
+
+// PostToSlack posts the headline numbers as a Block Kit message to an incoming webhook
+func PostToSlack(m metrics.TeamMetrics, webhookURL string) error {
+	return SlackSink{WebhookURL: webhookURL, Format: DefaultNumberFormat}.Write(m)
+}
+
+// slackDigest lays the headline numbers out as a header, the date range and one section of
+// two-column fields per category. Without commits there is no date range, and its context
+// block is left out, as Slack rejects empty text.
+func slackDigest(m metrics.TeamMetrics, nf NumberFormat) slackMessage {
+	c, p, j := m.CommitMetrics, m.PRMetrics, m.JiraMetrics
+	field := func(label, value string) slackText {
+		return slackText{Type: "mrkdwn", Text: "*" + label + "*\n" + value}
+	}
+	section := func(title string, fields ...slackText) slackBlock {
+		return slackBlock{Type: "section", Text: &slackText{Type: "mrkdwn", Text: "*" + title + "*"}, Fields: fields}
+	}
+
+	title := "DevOps metrics"
+	blocks := []slackBlock{{Type: "header", Text: &slackText{Type: "plain_text", Text: "DevOps metrics digest"}}}
+	if c.DateRange != "" {
+		title += " (" + c.DateRange + ")"
+		blocks = append(blocks, slackBlock{Type: "context", Elements: []slackText{{Type: "mrkdwn", Text: c.DateRange}}})
+	}
+
+	return slackMessage{
+		Text: nf.Sprintf("%s: %d commits, %d merged PRs, avg cycle time %.1fh, %.2f stories/week",
+			title, c.TotalCommits, p.MergedPRs, p.AvgCycleTimeHours, j.Throughput),
+		Blocks: append(blocks,
+			section("Commits",
+				field("Total commits", nf.Int(c.TotalCommits)),
+				field("Commits per day", nf.Float(c.CommitsPerDay, 1)),
+			),
+			slackBlock{Type: "divider"},
+			section("Pull Requests",
+				field("Merged PRs", nf.Int(p.MergedPRs)+" of "+nf.Int(p.TotalPRs)),
+				field("Avg cycle time", nf.Float(p.AvgCycleTimeHours, 1)+" hours"),
+			),
+			slackBlock{Type: "divider"},
+			section("Jira",
+				field("Throughput", nf.Float(j.Throughput, 2)+" stories/week"),
+				field("Completed stories", nf.Int(j.CompletedStories)),
+			),
+		),
+	}
+}
@@ -0,0 +1,81 @@
+package report
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"devops-metrics/metrics"
+)
+
+func TestPostToSlackPayload(t *testing.T) {
+	tests := []struct {
+		name       string
+		dateRange  string
+		wantTypes  []string
+		wantPrefix string
+	}{
+		{
+			name:       "with date range",
+			dateRange:  "2026-03-01 to 2026-03-31",
+			wantTypes:  []string{"header", "context", "section", "divider", "section", "divider", "section"},
+			wantPrefix: "DevOps metrics (2026-03-01 to 2026-03-31): 12 commits",
+		},
+		{
+			name:       "no commits",
+			wantTypes:  []string{"header", "section", "divider", "section", "divider", "section"},
+			wantPrefix: "DevOps metrics: 0 commits",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got slackMessage
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if ct := r.Header.Get("Content-Type"); ct != "application/json" {
+					t.Errorf("Content-Type = %q, want application/json", ct)
+				}
+				if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+					t.Errorf("decoding payload: %v", err)
+				}
+				w.Write([]byte("ok"))
+			}))
+			defer srv.Close()
+
+			m := metrics.TeamMetrics{}
+			m.CommitMetrics.DateRange = tt.dateRange
+			if tt.dateRange != "" {
+				m.CommitMetrics.TotalCommits = 12
+			}
+			if err := PostToSlack(m, srv.URL); err != nil {
+				t.Fatalf("PostToSlack() error = %v", err)
+			}
+
+			if !strings.HasPrefix(got.Text, tt.wantPrefix) {
+				t.Errorf("Text = %q, want prefix %q", got.Text, tt.wantPrefix)
+			}
+			var types []string
+			for _, b := range got.Blocks {
+				types = append(types, b.Type)
+				if b.Type == "context" && (len(b.Elements) != 1 || b.Elements[0].Text != tt.dateRange) {
+					t.Errorf("context elements = %+v, want the date range", b.Elements)
+				}
+			}
+			if strings.Join(types, ",") != strings.Join(tt.wantTypes, ",") {
+				t.Errorf("block types = %v, want %v", types, tt.wantTypes)
+			}
+		})
+	}
+}
+
+func TestPostToSlackRejected(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "invalid_blocks", http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	if err := PostToSlack(metrics.TeamMetrics{}, srv.URL); err == nil {
+		t.Error("PostToSlack() succeeded on a 400 response, want error")
+	}
+}
@@ -0,0 +1,179 @@
+package report
+
+import (
+	"github.com/xuri/excelize/v2"
+
+	"devops-metrics/metrics"
+)
+
+// xlsxDecimal is the built-in "0.00" number format
+const xlsxDecimal = 2
+
+// ExportToXLSX saves metrics as an Excel workbook with Commits, PullRequests, Jira and Authors
+// sheets. Values are written as numbers, not text, so they can be summed and charted.
+func ExportToXLSX(m metrics.TeamMetrics, filename string) error {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	header, err := f.NewStyle(&excelize.Style{
+		Font: &excelize.Font{Bold: true},
+		Fill: excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{"DDEBF7"}},
+	})
+	if err != nil {
+		return err
+	}
+	decimal, err := f.NewStyle(&excelize.Style{NumFmt: xlsxDecimal})
+	if err != nil {
+		return err
+	}
+	x := xlsxWriter{f: f, header: header, decimal: decimal}
+
+	c := m.CommitMetrics
+	commitRows := [][]any{
+		{"Total Commits", c.TotalCommits},
+		{"Commits Per Day", c.CommitsPerDay},
+		{"Active Days", c.ActiveDays},
+		{"Lines Added", c.TotalLinesAdded},
+		{"Lines Deleted", c.TotalLinesDeleted},
+		{"Lines Ignored", c.TotalLinesIgnored},
+		{"Avg Commit Gap (hours)", c.AvgCommitGapHours},
+		{"Conventional Commit Rate (%)", c.ConventionalCommitRate},
+	}
+	for _, commitType := range sortedAuthors(c.CommitsByType) {
+		commitRows = append(commitRows, []any{"Type: " + commitType, c.CommitsByType[commitType]})
+	}
+	// The default sheet is renamed rather than deleted, so the workbook always has one
+	if err := f.SetSheetName("Sheet1", "Commits"); err != nil {
+		return err
+	}
+	if err := x.table("Commits", []string{"Metric", "Value"}, commitRows); err != nil {
+		return err
+	}
+
+	p := m.PRMetrics
+	if err := x.table("PullRequests", []string{"Metric", "Value"}, [][]any{
+		{"Total PRs", p.TotalPRs},
+		{"Merged PRs", p.MergedPRs},
+		{"Avg Cycle Time (hours)", p.AvgCycleTimeHours},
+		{"Median Cycle Time (hours)", p.MedianCycleTimeHours},
+		{"P90 Cycle Time (hours)", p.P90CycleTimeHours},
+		{"Avg Review Time (hours)", p.AvgReviewTimeHours},
+		{"Median Review Time (hours)", p.MedianReviewTimeHours},
+		{"P90 Review Time (hours)", p.P90ReviewTimeHours},
+		{"Merge Success Rate (%)", p.MergeSuccessRate},
+		{"Avg Review Cycles", p.AvgReviewCycles},
+		{"Total Review Comments", p.TotalReviewComments},
+		{"Avg Review Comments per PR", p.AvgReviewCommentsPerPR},
+		{"Self-Merged PRs", p.SelfMergedPRs},
+		{"Merged Below Required Approvals", p.UnderReviewedPRs},
+		{"Idle Open PRs", p.StalePRs.IdleCount},
+		{"Aged Open PRs", p.StalePRs.AgedCount},
+	}); err != nil {
+		return err
+	}
+
+	j := m.JiraMetrics
+	if err := x.table("Jira", []string{"Metric", "Value"}, [][]any{
+		{"Total Stories", j.TotalStories},
+		{"Completed Stories", j.CompletedStories},
+		{"Avg Lead Time (days)", j.AvgLeadTimeDays},
+		{"Avg Lead Time (business days)", j.AvgLeadTimeBusinessDays},
+		{"Avg Cycle Time (days)", j.AvgCycleTimeDays},
+		{"Throughput (per week)", j.Throughput},
+		{"Estimate Accuracy (%)", j.EstimateAccuracy},
+		{"Open Stories", j.OpenStories},
+		{"Avg Age of Open Stories (days)", j.AvgAgeOpenDays},
+		{"Oldest Open Story (days)", j.OldestOpenDays},
+		{"Stale Stories", j.StaleStories.Count},
+	}); err != nil {
+		return err
+	}
+
+	// One row per person found in any per-author breakdown
+	seen := make(map[string]int)
+	for author, n := range c.CommitsByAuthor {
+		seen[author] += n
+	}
+	for author, n := range p.PRsByAuthor {
+		seen[author] += n
+	}
+	for author, n := range j.StoriesByAssignee {
+		seen[author] += n
+	}
+	var authorRows [][]any
+	for _, author := range sortedAuthors(seen) {
+		authorRows = append(authorRows, []any{
+			author,
+			c.CommitsByAuthor[author],
+			p.PRsByAuthor[author],
+			p.CycleTimeByAuthor[author],
+			p.ReviewTimeByAuthor[author],
+			j.StoriesByAssignee[author],
+			j.ThroughputByAssignee[author],
+			j.AvgLeadTimeByAssignee[author],
+		})
+	}
+	if err := x.table("Authors", []string{
+		"Author", "Commits", "PRs", "Avg PR Cycle Time (hours)", "Avg Review Time (hours)",
+		"Stories", "Throughput (per week)", "Avg Lead Time (days)",
+	}, authorRows); err != nil {
+		return err
+	}
+
+	return f.SaveAs(filename)
+}
+
+// xlsxWriter writes formatted tables into a workbook
+type xlsxWriter struct {
+	f       *excelize.File
+	header  int
+	decimal int
+}
+
+// table writes a header row and rows to sheet, creating it when needed. The header is bold
+// and frozen, and float cells get two decimals.
+func (x xlsxWriter) table(sheet string, headers []string, rows [][]any) error {
+	if idx, _ := x.f.GetSheetIndex(sheet); idx < 0 {
+		if _, err := x.f.NewSheet(sheet); err != nil {
+			return err
+		}
+	}
+
+	headerRow := make([]any, len(headers))
+	for i, h := range headers {
+		headerRow[i] = h
+	}
+	if err := x.f.SetSheetRow(sheet, "A1", &headerRow); err != nil {
+		return err
+	}
+	last, _ := excelize.CoordinatesToCellName(len(headers), 1)
+	if err := x.f.SetCellStyle(sheet, "A1", last, x.header); err != nil {
+		return err
+	}
+
+	for i, row := range rows {
+		start, _ := excelize.CoordinatesToCellName(1, i+2)
+		if err := x.f.SetSheetRow(sheet, start, &row); err != nil {
+			return err
+		}
+		for col, value := range row {
+			if _, ok := value.(float64); ok {
+				cell, _ := excelize.CoordinatesToCellName(col+1, i+2)
+				if err := x.f.SetCellStyle(sheet, cell, cell, x.decimal); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	lastCol, _ := excelize.ColumnNumberToName(len(headers))
+	if err := x.f.SetColWidth(sheet, "A", "A", 34); err != nil {
+		return err
+	}
+	if len(headers) > 1 {
+		if err := x.f.SetColWidth(sheet, "B", lastCol, 16); err != nil {
+			return err
+		}
+	}
+	return x.f.SetPanes(sheet, &excelize.Panes{Freeze: true, YSplit: 1, TopLeftCell: "A2", ActivePane: "bottomLeft"})
+}
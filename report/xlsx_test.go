@@ -0,0 +1,61 @@
+package report
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+)
+
+func TestExportToXLSX(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "metrics.xlsx")
+	if err := ExportToXLSX(markdownFixture("github", "jira"), path); err != nil {
+		t.Fatalf("ExportToXLSX() error = %v", err)
+	}
+
+	f, err := excelize.OpenFile(path)
+	if err != nil {
+		t.Fatalf("opening workbook: %v", err)
+	}
+	defer f.Close()
+
+	if got := fmt.Sprint(f.GetSheetList()); got != "[Commits PullRequests Jira Authors]" {
+		t.Errorf("sheets = %s, want [Commits PullRequests Jira Authors]", got)
+	}
+
+	tests := []struct {
+		sheet, cell string
+		want        string
+	}{
+		{"Commits", "A1", "Metric"},
+		{"Commits", "B2", "42"},
+		{"Commits", "B3", "1.50"}, // Floats get two decimals
+		{"PullRequests", "A2", "Total PRs"},
+		{"PullRequests", "B3", "7"},
+		{"Jira", "B3", "10"},
+		{"Authors", "A1", "Author"},
+		{"Authors", "A2", "Alice Doe"}, // Names from every breakdown, sorted
+		{"Authors", "B2", "0"},
+		{"Authors", "F2", "12"},
+		{"Authors", "G2", "2.50"},
+		{"Authors", "A3", "Unassigned"},
+		{"Authors", "A4", "alice"},
+		{"Authors", "B4", "30"},
+		{"Authors", "C4", "6"},
+		{"Authors", "D4", "22.00"},
+		{"Authors", "A5", "bob"},
+		{"Authors", "E5", "0.00"}, // bob has no reviewed PRs
+	}
+	for _, tt := range tests {
+		t.Run(tt.sheet+"!"+tt.cell, func(t *testing.T) {
+			got, err := f.GetCellValue(tt.sheet, tt.cell)
+			if err != nil {
+				t.Fatalf("GetCellValue() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("%s!%s = %q, want %q", tt.sheet, tt.cell, got, tt.want)
+			}
+		})
+	}
+}
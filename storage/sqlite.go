@@ -0,0 +1,134 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"devops-metrics/metrics"
+
+	_ "modernc.org/sqlite" // CGo-free SQLite driver, registered as "sqlite"
+)
+
+// Store persists TeamMetrics snapshots in a SQLite database so trends can be tracked
+// without re-fetching history
+type Store struct {
+	db *sql.DB
+}
+
+// migrations bring the schema up to date; entry i moves the database from user_version i
+// to i+1. Append new steps, never edit released ones.
+var migrations = []string{
+	`CREATE TABLE snapshots (
+		id           INTEGER PRIMARY KEY AUTOINCREMENT,
+		generated_at INTEGER NOT NULL, -- Unix milliseconds
+		providers    TEXT    NOT NULL, -- Comma-separated provider tags
+		metrics      TEXT    NOT NULL  -- TeamMetrics as JSON
+	);
+	CREATE INDEX snapshots_generated_at ON snapshots (generated_at);`,
+}
+
+// Open opens the snapshot database at path, creating it and migrating its schema as needed
+func Open(path string) (*Store, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening snapshot database: %w", err)
+	}
+	// SQLite allows one writer; a single connection avoids "database is locked" errors
+	db.SetMaxOpenConns(1)
+
+	store := &Store{db: db}
+	if err := store.migrate(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return store, nil
+}
+
+// Close closes the database
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// migrate applies the migrations the database has not seen yet, each in its own transaction
+func (s *Store) migrate() error {
+	var version int
+	if err := s.db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		return fmt.Errorf("error reading snapshot schema version: %w", err)
+	}
+	if version > len(migrations) {
+		return fmt.Errorf("snapshot database schema version %d is newer than this build supports (%d)", version, len(migrations))
+	}
+
+	for ; version < len(migrations); version++ {
+		tx, err := s.db.Begin()
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(migrations[version]); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("error migrating snapshot database to version %d: %w", version+1, err)
+		}
+		// PRAGMA does not accept bound parameters
+		if _, err := tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", version+1)); err != nil {
+			tx.Rollback()
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SaveSnapshot stores one run's metrics, keyed by its GeneratedAt time and tagged with its providers
+func (s *Store) SaveSnapshot(m metrics.TeamMetrics) error {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.Exec("INSERT INTO snapshots (generated_at, providers, metrics) VALUES (?, ?, ?)",
+		m.GeneratedAt.UnixMilli(), strings.Join(m.Providers, ","), string(data))
+	if err != nil {
+		return fmt.Errorf("error saving snapshot: %w", err)
+	}
+	return nil
+}
+
+// LoadSnapshots returns the snapshots generated within [from, to], oldest first. A zero
+// from or to leaves that end of the range open.
+func (s *Store) LoadSnapshots(from, to time.Time) ([]metrics.TeamMetrics, error) {
+	query := "SELECT metrics FROM snapshots WHERE 1 = 1"
+	var args []interface{}
+	if !from.IsZero() {
+		query += " AND generated_at >= ?"
+		args = append(args, from.UnixMilli())
+	}
+	if !to.IsZero() {
+		query += " AND generated_at <= ?"
+		args = append(args, to.UnixMilli())
+	}
+	query += " ORDER BY generated_at, id"
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error loading snapshots: %w", err)
+	}
+	defer rows.Close()
+
+	snapshots := []metrics.TeamMetrics{}
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		var m metrics.TeamMetrics
+		if err := json.Unmarshal([]byte(data), &m); err != nil {
+			return nil, fmt.Errorf("error parsing snapshot: %w", err)
+		}
+		snapshots = append(snapshots, m)
+	}
+	return snapshots, rows.Err()
+}
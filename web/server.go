@@ -2,40 +2,100 @@ package web
 
 import (
 	"encoding/json"
-	"log"
+	"fmt"
+	"log/slog"
 	"net/http"
+	"os"
+	"strconv"
+	"sync"
 	"time"
 
+	"devops-metrics/azuredevops"
 	"devops-metrics/bitbucket"
+	"devops-metrics/cache"
 	"devops-metrics/config"
+	"devops-metrics/fetchstats"
 	"devops-metrics/github"
+	"devops-metrics/gitlab"
 	"devops-metrics/jira"
+	"devops-metrics/logging"
 	"devops-metrics/metrics"
+	"devops-metrics/replay"
+	"devops-metrics/report"
+	"devops-metrics/storage"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/cors"
 )
 
 // Server handles HTTP requests
 type Server struct {
 	Router *chi.Mux
 	config config.Config
+
+	statsMu        sync.Mutex
+	fetchStats     *fetchstats.Recorder
+	lastFetchStats map[string]fetchstats.ProviderStats
+	startedAt      time.Time
+
+	metricsCache *cache.LRU
+}
+
+// cachedMetrics is a computed /api/metrics result kept in the server's metrics cache
+type cachedMetrics struct {
+	teamMetrics metrics.TeamMetrics
+	periods     []metrics.PeriodMetrics
+	weekly      []metrics.WeeklyMetrics
+	counts      map[string]int
+
+	// Fetched data, kept for per-author lookups
+	commits []bitbucket.Commit
+	prs     []bitbucket.PullRequest
+	stories []jira.JiraStory
 }
 
 // NewServer creates a new web server
 func NewServer() *Server {
-	s := &Server{}
+	s := &Server{
+		fetchStats: fetchstats.NewRecorder(),
+		startedAt:  time.Now(),
+	}
 
 	// Load configuration
-	cfg, err := config.LoadConfig("config.json")
+	configFile := config.FindConfigFile()
+	cfg, err := config.LoadConfig(configFile)
+	if logErr := logging.Setup(cfg.LogFormat, cfg.LogLevel); logErr != nil {
+		logging.Setup(logging.FormatText, "")
+		slog.Warn("Invalid logging configuration, using text output", "error", logErr)
+	}
 	if err != nil {
-		log.Printf("Warning: Could not load config.json, trying environment variables: %v", err)
+		slog.Warn("Could not load config file, trying environment variables", "file", configFile, "error", err)
 	}
 	s.config = cfg
 
+	cacheSize := cfg.MetricsCacheSize
+	if cacheSize <= 0 {
+		cacheSize = config.DefaultMetricsCacheSize
+	}
+	cacheTTL := cfg.CacheTTLSeconds
+	if cacheTTL <= 0 {
+		cacheTTL = config.DefaultCacheTTLSeconds
+	}
+	s.metricsCache = cache.NewLRU(cacheSize, time.Duration(cacheTTL)*time.Second)
+
 	// Validate configuration
 	if cfg.BitbucketURL == "" || cfg.JiraURL == "" {
-		log.Fatal("❌ Configuration Error! Please set BITBUCKET_* and JIRA_* environment variables or create config.json")
+		slog.Error("Configuration error: set BITBUCKET_* and JIRA_* environment variables or create config.json")
+		os.Exit(1)
+	}
+	warnings, err := cfg.Validate()
+	if err != nil {
+		slog.Error("Configuration error", "error", err)
+		os.Exit(1)
+	}
+	for _, warning := range warnings {
+		slog.Warn(warning)
 	}
 
 	s.setupRoutes()
@@ -49,16 +109,34 @@ func (s *Server) setupRoutes() {
 	r.Use(middleware.Logger)
 	r.Use(middleware.Recoverer)
 	r.Use(middleware.Timeout(2 * time.Minute)) // 2 minute timeout for API requests
+	r.Use(replaySequence)
 
 	// Health check endpoint
 	r.Get("/health", s.healthCheck)
 
 	// API endpoints
 	r.Route("/api", func(r chi.Router) {
+		// Let browser frontends on other origins call the API; preflight requests are answered here
+		if len(s.config.AllowedOrigins) > 0 {
+			r.Use(cors.Handler(cors.Options{
+				AllowedOrigins: s.config.AllowedOrigins,
+				AllowedMethods: []string{http.MethodGet, http.MethodOptions},
+				AllowedHeaders: []string{"Accept", "Authorization", "Content-Type"},
+				MaxAge:         300,
+			}))
+		}
 		r.Get("/bitbucket/metrics", s.getBitbucketMetrics)
 		r.Get("/github/metrics", s.getGitHubMetrics)
 		r.Get("/jira/metrics", s.getJiraMetrics)
+		r.Get("/jira/sprint/{id}", s.getJiraSprint)
 		r.Get("/metrics", s.getAllMetrics)
+		r.Get("/metrics/csv", s.getMetricsCSV)
+		r.Get("/metrics/diagnostics", s.getDiagnostics)
+		r.Get("/metrics/trends", s.getTrends)
+		r.Get("/report.html", s.getReportHTML)
+		r.Get("/trends", s.getWeeklyTrends)
+		r.Get("/history", s.getHistory)
+		r.Get("/authors/{name}", s.getAuthor)
 	})
 
 	s.Router = r
@@ -77,27 +155,34 @@ func (s *Server) healthCheck(w http.ResponseWriter, r *http.Request) {
 // getBitbucketMetrics calculates and returns Bitbucket metrics
 func (s *Server) getBitbucketMetrics(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
+	cfg, ok := s.requestConfig(w, r)
+	if !ok {
+		return
+	}
+	ctx := r.Context()
+	recorder := fetchstats.NewRecorder()
+	defer s.recordFetchStats(recorder)
 
-	bbClient := bitbucket.NewClient(s.config)
+	bbClient := bitbucket.NewClient(cfg).WithStats(recorder)
 
 	// Fetch Bitbucket data
-	commits, err := bbClient.FetchCommits()
+	commits, err := bbClient.FetchCommits(ctx)
 	if err != nil {
-		log.Printf("❌ Error fetching commits: %v", err)
+		slog.Error("Error fetching commits", "provider", "bitbucket", "error", err)
 		http.Error(w, "Error fetching commits", http.StatusInternalServerError)
 		return
 	}
 
-	prs, err := bbClient.FetchPRs()
+	prs, err := bbClient.FetchPRs(ctx)
 	if err != nil {
-		log.Printf("❌ Error fetching PRs: %v", err)
+		slog.Error("Error fetching pull requests", "provider", "bitbucket", "error", err)
 		http.Error(w, "Error fetching PRs", http.StatusInternalServerError)
 		return
 	}
 
 	// Calculate Bitbucket metrics
-	commitMetrics := metrics.CalculateCommitMetrics(commits)
-	prMetrics := metrics.CalculatePRMetrics(prs)
+	commitMetrics := metrics.CalculateCommitMetrics(commits, cfg)
+	prMetrics := metrics.CalculatePRMetrics(prs, cfg)
 
 	response := map[string]interface{}{
 		"status": "success",
@@ -109,7 +194,8 @@ func (s *Server) getBitbucketMetrics(w http.ResponseWriter, r *http.Request) {
 			"commits": len(commits),
 			"prs":     len(prs),
 		},
-		"timestamp": time.Now().UTC(),
+		"fetch_stats": recorder.Snapshot(),
+		"timestamp":   time.Now().UTC(),
 	}
 
 	w.WriteHeader(http.StatusOK)
@@ -119,24 +205,37 @@ func (s *Server) getBitbucketMetrics(w http.ResponseWriter, r *http.Request) {
 // getGitHubMetrics calculates and returns GitHub metrics
 func (s *Server) getGitHubMetrics(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
+	cfg, ok := s.requestConfig(w, r)
+	if !ok {
+		return
+	}
+	ctx := r.Context()
+	recorder := fetchstats.NewRecorder()
+	defer s.recordFetchStats(recorder)
 
-	ghClient := github.NewClient(s.config)
+	ghClient := github.NewClient(cfg).WithStats(recorder)
 
 	// Fetch GitHub data
-	commits, err := ghClient.FetchCommits()
+	commits, err := ghClient.FetchCommits(ctx)
 	if err != nil {
-		log.Printf("❌ Error fetching GitHub commits: %v", err)
+		slog.Error("Error fetching commits", "provider", "github", "error", err)
 		http.Error(w, "Error fetching GitHub commits", http.StatusInternalServerError)
 		return
 	}
 
-	prs, err := ghClient.FetchPRs()
+	prs, err := ghClient.FetchPRs(ctx)
 	if err != nil {
-		log.Printf("❌ Error fetching GitHub PRs: %v", err)
+		slog.Error("Error fetching pull requests", "provider", "github", "error", err)
 		http.Error(w, "Error fetching GitHub PRs", http.StatusInternalServerError)
 		return
 	}
 
+	deployments, err := ghClient.FetchDeployments(ctx)
+	if err != nil {
+		slog.Error("Error fetching deployments", "provider", "github", "error", err)
+		deployments = []github.Deployment{}
+	}
+
 	// Convert to Bitbucket format for metrics calculation
 	bbCommits := make([]bitbucket.Commit, len(commits))
 	for i, c := range commits {
@@ -147,39 +246,59 @@ func (s *Server) getGitHubMetrics(w http.ResponseWriter, r *http.Request) {
 			Message:      c.Message,
 			LinesAdded:   c.LinesAdded,
 			LinesDeleted: c.LinesDeleted,
+			LinesIgnored: c.LinesIgnored,
+			Repo:         c.Repo,
 		}
 	}
 
 	bbPRs := make([]bitbucket.PullRequest, len(prs))
 	for i, p := range prs {
 		bbPRs[i] = bitbucket.PullRequest{
-			ID:            p.ID,
-			Author:        p.Author,
-			CreatedAt:     p.CreatedAt,
-			MergedAt:      p.MergedAt,
-			ClosedAt:      p.ClosedAt,
-			FirstReviewAt: p.FirstReviewAt,
-			LinesChanged:  p.LinesChanged,
-			Reviewers:     p.Reviewers,
-			Status:        p.Status,
+			ID:             p.ID,
+			Author:         p.Author,
+			CreatedAt:      p.CreatedAt,
+			MergedAt:       p.MergedAt,
+			ClosedAt:       p.ClosedAt,
+			FirstReviewAt:  p.FirstReviewAt,
+			LinesChanged:   p.LinesChanged,
+			LinesIgnored:   p.LinesIgnored,
+			Reviewers:      p.Reviewers,
+			CommentCount:   p.CommentCount,
+			ReviewCycles:   p.ReviewCycles,
+			Approvers:      p.Approvers,
+			MergedBy:       p.MergedBy,
+			DraftHours:     p.DraftHours,
+			ReviewComments: p.ReviewComments,
+			BaseBranch:     p.BaseBranch,
+			Title:          p.Title,
+			Labels:         p.Labels,
+			CommitHashes:   p.CommitHashes,
+			Repo:           p.Repo,
+			UpdatedAt:      p.UpdatedAt,
+			FirstCommitAt:  p.FirstCommitAt,
+			Status:         p.Status,
 		}
 	}
 
 	// Calculate GitHub metrics
-	commitMetrics := metrics.CalculateCommitMetrics(bbCommits)
-	prMetrics := metrics.CalculatePRMetrics(bbPRs)
+	commitMetrics := metrics.CalculateCommitMetrics(bbCommits, cfg)
+	prMetrics := metrics.CalculatePRMetrics(bbPRs, cfg)
+	deploymentMetrics := metrics.CalculateDeploymentMetrics(deployments)
 
 	response := map[string]interface{}{
 		"status": "success",
 		"data": map[string]interface{}{
-			"commit_metrics": commitMetrics,
-			"pr_metrics":     prMetrics,
+			"commit_metrics":     commitMetrics,
+			"pr_metrics":         prMetrics,
+			"deployment_metrics": deploymentMetrics,
 		},
 		"stats": map[string]int{
-			"commits": len(commits),
-			"prs":     len(prs),
+			"commits":     len(commits),
+			"prs":         len(prs),
+			"deployments": len(deployments),
 		},
-		"timestamp": time.Now().UTC(),
+		"fetch_stats": recorder.Snapshot(),
+		"timestamp":   time.Now().UTC(),
 	}
 
 	w.WriteHeader(http.StatusOK)
@@ -189,19 +308,26 @@ func (s *Server) getGitHubMetrics(w http.ResponseWriter, r *http.Request) {
 // getJiraMetrics calculates and returns Jira metrics
 func (s *Server) getJiraMetrics(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
+	cfg, ok := s.requestConfig(w, r)
+	if !ok {
+		return
+	}
+	ctx := r.Context()
+	recorder := fetchstats.NewRecorder()
+	defer s.recordFetchStats(recorder)
 
-	jClient := jira.NewClient(s.config)
+	jClient := jira.NewClient(cfg).WithStats(recorder)
 
 	// Fetch Jira data
-	stories, err := jClient.FetchIssues()
+	stories, err := jClient.FetchIssues(ctx)
 	if err != nil {
-		log.Printf("❌ Error fetching Jira issues: %v", err)
+		slog.Error("Error fetching issues", "provider", "jira", "project", cfg.JiraProject, "error", err)
 		http.Error(w, "Error fetching Jira issues", http.StatusInternalServerError)
 		return
 	}
 
 	// Calculate Jira metrics
-	jiraMetrics := metrics.CalculateJiraMetrics(stories)
+	jiraMetrics := metrics.CalculateJiraMetrics(stories, cfg)
 
 	response := map[string]interface{}{
 		"status": "success",
@@ -211,45 +337,335 @@ func (s *Server) getJiraMetrics(w http.ResponseWriter, r *http.Request) {
 		"stats": map[string]int{
 			"stories": len(stories),
 		},
-		"timestamp": time.Now().UTC(),
+		"fetch_stats": recorder.Snapshot(),
+		"timestamp":   time.Now().UTC(),
 	}
 
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(response)
 }
 
+// getJiraSprint returns the metrics of one sprint, fetching its issues instead of the
+// analysis window's
+func (s *Server) getJiraSprint(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	cfg, ok := s.requestConfig(w, r)
+	if !ok {
+		return
+	}
+	ctx := r.Context()
+	recorder := fetchstats.NewRecorder()
+	defer s.recordFetchStats(recorder)
+
+	cfg.JiraSprint = chi.URLParam(r, "id")
+	stories, err := jira.NewClient(cfg).WithStats(recorder).FetchIssues(ctx)
+	if err != nil {
+		slog.Error("Error fetching sprint issues", "provider", "jira", "sprint", cfg.JiraSprint, "error", err)
+		http.Error(w, "Error fetching Jira issues", http.StatusInternalServerError)
+		return
+	}
+
+	jiraMetrics := metrics.CalculateJiraMetrics(stories, cfg)
+	if jiraMetrics.Sprint == nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status": "error",
+			"error":  fmt.Sprintf("no issues found in sprint %q", cfg.JiraSprint),
+		})
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status": "success",
+		"data": map[string]interface{}{
+			"sprint":       jiraMetrics.Sprint,
+			"jira_metrics": jiraMetrics,
+		},
+		"stats": map[string]int{
+			"stories": len(stories),
+		},
+		"fetch_stats": recorder.Snapshot(),
+		"timestamp":   time.Now().UTC(),
+	})
+}
+
 // getAllMetrics calculates and returns all metrics
 func (s *Server) getAllMetrics(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
+	period := r.URL.Query().Get("period")
+	if period != "" && !metrics.ValidPeriod(period) {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status": "error",
+			"error":  "invalid period: use month or quarter",
+		})
+		return
+	}
+	cfg, ok := s.requestConfig(w, r)
+	if !ok {
+		return
+	}
+
+	result, fetchStats, cached := s.loadAllMetrics(r, cfg, period)
+	s.writeAllMetrics(w, result, fetchStats, cached)
+}
+
+// getMetricsCSV serves the combined metrics as a downloadable CSV report
+func (s *Server) getMetricsCSV(w http.ResponseWriter, r *http.Request) {
+	period := r.URL.Query().Get("period")
+	if period != "" && !metrics.ValidPeriod(period) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status": "error",
+			"error":  "invalid period: use month or quarter",
+		})
+		return
+	}
+	cfg, ok := s.requestConfig(w, r)
+	if !ok {
+		return
+	}
+
+	result, _, _ := s.loadAllMetrics(r, cfg, period)
+
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", `attachment; filename="metrics.csv"`)
+	if err := report.WriteCSV(w, result.teamMetrics, report.NumberFormatFor(s.config.NumberLocale)); err != nil {
+		slog.Error("Error writing CSV report", "error", err)
+	}
+}
+
+// getReportHTML serves the combined metrics as an HTML dashboard
+func (s *Server) getReportHTML(w http.ResponseWriter, r *http.Request) {
+	period := r.URL.Query().Get("period")
+	if period != "" && !metrics.ValidPeriod(period) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status": "error",
+			"error":  "invalid period: use month or quarter",
+		})
+		return
+	}
+	cfg, ok := s.requestConfig(w, r)
+	if !ok {
+		return
+	}
+
+	result, _, _ := s.loadAllMetrics(r, cfg, period)
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := report.WriteHTML(w, result.teamMetrics); err != nil {
+		slog.Error("Error writing HTML report", "error", err)
+	}
+}
+
+// getWeeklyTrends serves per-ISO-week commit, PR and story metrics for the analysis window.
+// ?weeks=N returns only the last N weeks and, without an explicit window, analyzes N weeks.
+func (s *Server) getWeeklyTrends(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	q := r.URL.Query()
+	weeks := 0
+	if raw := q.Get("weeks"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"status": "error",
+				"error":  "invalid weeks: use a positive number",
+			})
+			return
+		}
+		weeks = n
+	}
+
+	cfg, ok := s.requestConfig(w, r)
+	if !ok {
+		return
+	}
+	if weeks > 0 && q.Get("days") == "" && q.Get("since") == "" && q.Get("until") == "" {
+		cfg.DaysToAnalyze = weeks * 7
+	}
+
+	result, _, cached := s.loadAllMetrics(r, cfg, "")
+	trends := result.weekly
+	if weeks > 0 && len(trends) > weeks {
+		trends = trends[len(trends)-weeks:]
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status": "success",
+		"cached": cached,
+		"weeks":  trends,
+	})
+}
+
+// getAuthor serves one person's commits, PRs and assigned stories with their individual
+// metrics, matching the name under any of its AuthorAliases. It shares the /api/metrics cache.
+func (s *Server) getAuthor(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	cfg, ok := s.requestConfig(w, r)
+	if !ok {
+		return
+	}
+
+	name := chi.URLParam(r, "name")
+	result, _, cached := s.loadAllMetrics(r, cfg, "")
+	author := metrics.CalculateAuthorMetrics(name, result.commits, result.prs, result.stories, cfg)
+	if !author.HasActivity() {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status": "error",
+			"error":  fmt.Sprintf("no activity for author %q in the analysis window", name),
+		})
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":    "success",
+		"data":      author,
+		"cached":    cached,
+		"timestamp": time.Now().UTC(),
+	})
+}
+
+// metricsViews are routes that render the /api/metrics result in another form, so they share
+// its cache entries
+var metricsViews = map[string]bool{
+	"/api/metrics/csv":    true,
+	"/api/report.html":    true,
+	"/api/authors/{name}": true,
+}
+
+// metricsCacheKey identifies a loadAllMetrics result by the route and the effective analysis
+// window and period rather than the raw query, so handlers that adjust cfg (such as ?weeks=N)
+// get their own entries and parameters that don't change the result share one. A relative
+// window is keyed by its length, as its dates move with every request.
+func metricsCacheKey(r *http.Request, cfg config.Config, period string) string {
+	route := r.URL.Path
+	if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+		route = rctx.RoutePattern()
+	}
+	if metricsViews[route] {
+		route = "/api/metrics"
+	}
+	window := fmt.Sprintf("days=%d", cfg.DaysToAnalyze)
+	if !cfg.WindowStart.IsZero() || !cfg.WindowEnd.IsZero() {
+		window = fmt.Sprintf("since=%s&until=%s", cfg.WindowStart.Format(time.RFC3339), cfg.WindowEnd.Format(time.RFC3339))
+	}
+	return fmt.Sprintf("%s?%s&period=%s", route, window, period)
+}
+
+// loadAllMetrics fetches and computes the combined metrics for a request using cfg, serving
+// them from the metrics cache when possible unless the request has ?refresh=true. It also
+// returns this request's fetch stats and whether the result came from the cache.
+func (s *Server) loadAllMetrics(r *http.Request, cfg config.Config, period string) (cachedMetrics, map[string]fetchstats.ProviderStats, bool) {
+	// A forced refresh replaces the entry for the same window, so refresh is not part of the key
+	refresh := r.URL.Query().Get("refresh") == "true"
+	cacheKey := metricsCacheKey(r, cfg, period)
+	if !refresh {
+		if cached, ok := s.metricsCache.Get(cacheKey); ok {
+			return cached.(cachedMetrics), map[string]fetchstats.ProviderStats{}, true
+		}
+	}
+
+	// Upstream calls are bound to the request, so client disconnects and the timeout middleware abort them
+	ctx := r.Context()
+	recorder := fetchstats.NewRecorder()
+	defer s.recordFetchStats(recorder)
+
 	var commits []bitbucket.Commit
 	var prs []bitbucket.PullRequest
 	var stories []jira.JiraStory
+	var deployments []github.Deployment
+	fetchFailed := false
+
+	hasBitbucket := cfg.BitbucketURL != ""
+	hasGitHub := cfg.GitHubURL != ""
+	hasGitLab := cfg.GitLabProjectID != ""
+	hasAzure := cfg.AzureOrg != ""
+	azureRepo := cfg.AzureProject + "/" + cfg.AzureRepo
+
+	// Skip repositories excluded by name
+	if hasBitbucket {
+		bbRepo := cfg.BitbucketProject + "/" + cfg.BitbucketRepo
+		if reason := cfg.RepoNameExclusionReason(bbRepo); reason != "" {
+			slog.Info("Skipping repository", "provider", "bitbucket", "repo", bbRepo, "reason", reason)
+			hasBitbucket = false
+		}
+	}
+	if hasGitHub {
+		ghRepo := cfg.GitHubOwner + "/" + cfg.GitHubRepo
+		if reason := cfg.RepoNameExclusionReason(ghRepo); reason != "" {
+			slog.Info("Skipping repository", "provider", "github", "repo", ghRepo, "reason", reason)
+			hasGitHub = false
+		}
+	}
+	if hasGitLab {
+		if reason := cfg.RepoNameExclusionReason(cfg.GitLabProjectID); reason != "" {
+			slog.Info("Skipping repository", "provider", "gitlab", "repo", cfg.GitLabProjectID, "reason", reason)
+			hasGitLab = false
+		}
+	}
+	if hasAzure {
+		if reason := cfg.RepoNameExclusionReason(azureRepo); reason != "" {
+			slog.Info("Skipping repository", "provider", "azuredevops", "repo", azureRepo, "reason", reason)
+			hasAzure = false
+		}
+	}
+
+	// Skip archived/forked repositories when configured
+	if cfg.ExcludeArchived || cfg.ExcludeForks {
+		if hasBitbucket {
+			info, err := bitbucket.NewClient(cfg).WithStats(recorder).FetchRepoInfo(ctx)
+			if err != nil {
+				slog.Warn("Could not check repository flags", "provider", "bitbucket", "error", err)
+			} else if reason := cfg.RepoExclusionReason(info.Archived, info.Fork); reason != "" {
+				slog.Info("Skipping repository", "provider", "bitbucket", "repo", info.Name, "reason", reason)
+				hasBitbucket = false
+			}
+		}
+		if hasGitHub {
+			info, err := github.NewClient(cfg).WithStats(recorder).FetchRepoInfo(ctx)
+			if err != nil {
+				slog.Warn("Could not check repository flags", "provider", "github", "error", err)
+			} else if reason := cfg.RepoExclusionReason(info.Archived, info.Fork); reason != "" {
+				slog.Info("Skipping repository", "provider", "github", "repo", info.Name, "reason", reason)
+				hasGitHub = false
+			}
+		}
+	}
 
 	// Fetch Bitbucket data
-	if s.config.BitbucketURL != "" {
-		bbClient := bitbucket.NewClient(s.config)
-		bbCommits, err := bbClient.FetchCommits()
+	if hasBitbucket {
+		bbClient := bitbucket.NewClient(cfg).WithStats(recorder)
+		bbCommits, err := bbClient.FetchCommits(ctx)
 		if err != nil {
-			log.Printf("❌ Error fetching Bitbucket commits: %v", err)
+			slog.Error("Error fetching commits", "provider", "bitbucket", "error", err)
+			fetchFailed = true
 		} else {
 			commits = append(commits, bbCommits...)
 		}
 
-		bbPRs, err := bbClient.FetchPRs()
+		bbPRs, err := bbClient.FetchPRs(ctx)
 		if err != nil {
-			log.Printf("❌ Error fetching Bitbucket PRs: %v", err)
+			slog.Error("Error fetching pull requests", "provider", "bitbucket", "error", err)
+			fetchFailed = true
 		} else {
 			prs = append(prs, bbPRs...)
 		}
 	}
 
 	// Fetch GitHub data
-	if s.config.GitHubURL != "" {
-		ghClient := github.NewClient(s.config)
-		ghCommits, err := ghClient.FetchCommits()
+	if hasGitHub {
+		ghClient := github.NewClient(cfg).WithStats(recorder)
+		ghCommits, err := ghClient.FetchCommits(ctx)
 		if err != nil {
-			log.Printf("❌ Error fetching GitHub commits: %v", err)
+			slog.Error("Error fetching commits", "provider", "github", "error", err)
+			fetchFailed = true
 		} else {
 			// Convert GitHub commits to Bitbucket format
 			for _, c := range ghCommits {
@@ -260,16 +676,137 @@ func (s *Server) getAllMetrics(w http.ResponseWriter, r *http.Request) {
 					Message:      c.Message,
 					LinesAdded:   c.LinesAdded,
 					LinesDeleted: c.LinesDeleted,
+					LinesIgnored: c.LinesIgnored,
+					Repo:         c.Repo,
 				})
 			}
 		}
 
-		ghPRs, err := ghClient.FetchPRs()
+		ghPRs, err := ghClient.FetchPRs(ctx)
 		if err != nil {
-			log.Printf("❌ Error fetching GitHub PRs: %v", err)
+			slog.Error("Error fetching pull requests", "provider", "github", "error", err)
+			fetchFailed = true
 		} else {
 			// Convert GitHub PRs to Bitbucket format
 			for _, p := range ghPRs {
+				prs = append(prs, bitbucket.PullRequest{
+					ID:             p.ID,
+					Author:         p.Author,
+					CreatedAt:      p.CreatedAt,
+					MergedAt:       p.MergedAt,
+					ClosedAt:       p.ClosedAt,
+					FirstReviewAt:  p.FirstReviewAt,
+					LinesChanged:   p.LinesChanged,
+					LinesIgnored:   p.LinesIgnored,
+					Reviewers:      p.Reviewers,
+					CommentCount:   p.CommentCount,
+					ReviewCycles:   p.ReviewCycles,
+					Approvers:      p.Approvers,
+					MergedBy:       p.MergedBy,
+					DraftHours:     p.DraftHours,
+					ReviewComments: p.ReviewComments,
+					BaseBranch:     p.BaseBranch,
+					Title:          p.Title,
+					Labels:         p.Labels,
+					CommitHashes:   p.CommitHashes,
+					Repo:           p.Repo,
+					UpdatedAt:      p.UpdatedAt,
+					FirstCommitAt:  p.FirstCommitAt,
+					Status:         p.Status,
+				})
+			}
+		}
+
+		deployments, err = ghClient.FetchDeployments(ctx)
+		if err != nil {
+			slog.Error("Error fetching deployments", "provider", "github", "error", err)
+			fetchFailed = true
+			deployments = []github.Deployment{}
+		}
+	}
+
+	// Fetch GitLab data
+	if hasGitLab {
+		glClient := gitlab.NewClient(cfg).WithStats(recorder)
+		glCommits, err := glClient.FetchCommits(ctx)
+		if err != nil {
+			slog.Error("Error fetching commits", "provider", "gitlab", "repo", cfg.GitLabProjectID, "error", err)
+			fetchFailed = true
+		} else {
+			// Convert GitLab commits to Bitbucket format
+			for _, c := range glCommits {
+				commits = append(commits, bitbucket.Commit{
+					Hash:         c.Hash,
+					Author:       c.Author,
+					Date:         c.Date,
+					Message:      c.Message,
+					LinesAdded:   c.LinesAdded,
+					LinesDeleted: c.LinesDeleted,
+					Repo:         c.Repo,
+				})
+			}
+		}
+
+		glPRs, err := glClient.FetchPRs(ctx)
+		if err != nil {
+			slog.Error("Error fetching merge requests", "provider", "gitlab", "repo", cfg.GitLabProjectID, "error", err)
+			fetchFailed = true
+		} else {
+			// Convert GitLab merge requests to Bitbucket format
+			for _, p := range glPRs {
+				prs = append(prs, bitbucket.PullRequest{
+					ID:            p.ID,
+					Author:        p.Author,
+					CreatedAt:     p.CreatedAt,
+					MergedAt:      p.MergedAt,
+					ClosedAt:      p.ClosedAt,
+					FirstReviewAt: p.FirstReviewAt,
+					LinesChanged:  p.LinesChanged,
+					Reviewers:     p.Reviewers,
+					CommentCount:  p.CommentCount,
+					Approvers:     p.Approvers,
+					MergedBy:      p.MergedBy,
+					BaseBranch:    p.BaseBranch,
+					Title:         p.Title,
+					Labels:        p.Labels,
+					CommitHashes:  p.CommitHashes,
+					Repo:          p.Repo,
+					UpdatedAt:     p.UpdatedAt,
+					Status:        p.Status,
+				})
+			}
+		}
+	}
+
+	// Fetch Azure DevOps data
+	if hasAzure {
+		azClient := azuredevops.NewClient(cfg).WithStats(recorder)
+		azCommits, err := azClient.FetchCommits(ctx)
+		if err != nil {
+			slog.Error("Error fetching commits", "provider", "azuredevops", "repo", azureRepo, "error", err)
+			fetchFailed = true
+		} else {
+			// Convert Azure DevOps commits to Bitbucket format
+			for _, c := range azCommits {
+				commits = append(commits, bitbucket.Commit{
+					Hash:         c.Hash,
+					Author:       c.Author,
+					Date:         c.Date,
+					Message:      c.Message,
+					LinesAdded:   c.LinesAdded,
+					LinesDeleted: c.LinesDeleted,
+					Repo:         c.Repo,
+				})
+			}
+		}
+
+		azPRs, err := azClient.FetchPRs(ctx)
+		if err != nil {
+			slog.Error("Error fetching pull requests", "provider", "azuredevops", "repo", azureRepo, "error", err)
+			fetchFailed = true
+		} else {
+			// Convert Azure DevOps pull requests to Bitbucket format
+			for _, p := range azPRs {
 				prs = append(prs, bitbucket.PullRequest{
 					ID:            p.ID,
 					Author:        p.Author,
@@ -279,6 +816,15 @@ func (s *Server) getAllMetrics(w http.ResponseWriter, r *http.Request) {
 					FirstReviewAt: p.FirstReviewAt,
 					LinesChanged:  p.LinesChanged,
 					Reviewers:     p.Reviewers,
+					CommentCount:  p.CommentCount,
+					Approvers:     p.Approvers,
+					MergedBy:      p.MergedBy,
+					BaseBranch:    p.BaseBranch,
+					Title:         p.Title,
+					Labels:        p.Labels,
+					CommitHashes:  p.CommitHashes,
+					Repo:          p.Repo,
+					UpdatedAt:     p.UpdatedAt,
 					Status:        p.Status,
 				})
 			}
@@ -286,55 +832,289 @@ func (s *Server) getAllMetrics(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Fetch Jira data
-	if s.config.JiraURL != "" {
-		jClient := jira.NewClient(s.config)
+	if cfg.JiraURL != "" {
+		jClient := jira.NewClient(cfg).WithStats(recorder)
 		var err error
-		stories, err = jClient.FetchIssues()
+		stories, err = jClient.FetchIssues(ctx)
 		if err != nil {
-			log.Printf("❌ Error fetching Jira issues: %v", err)
+			slog.Error("Error fetching issues", "provider", "jira", "project", cfg.JiraProject, "error", err)
+			fetchFailed = true
 			stories = []jira.JiraStory{}
 		}
 	}
 
+	// Assign authors to teams from GitHub org membership, falling back to author_teams
+	if cfg.GitHubURL != "" && len(cfg.GitHubTeams) > 0 {
+		cfg = cfg.WithTeamMemberships(github.NewClient(cfg).WithStats(recorder).FetchTeamMemberships(ctx, cfg.GitHubTeams))
+	}
+
 	// Calculate all metrics
-	teamMetrics := metrics.CalculateTeamMetrics(commits, prs, stories)
+	result := cachedMetrics{
+		teamMetrics: metrics.CalculateTeamMetrics(commits, prs, stories, deployments, cfg),
+		counts: map[string]int{
+			"commits":     len(commits),
+			"prs":         len(prs),
+			"stories":     len(stories),
+			"deployments": len(deployments),
+		},
+		commits: commits,
+		prs:     prs,
+		stories: stories,
+	}
+	result.teamMetrics.Truncated = recorder.TruncatedFetches()
+	result.weekly = metrics.CalculateWeeklyTrends(commits, prs, stories, 0, cfg)
+	if period != "" {
+		// period was validated above, so this cannot fail
+		result.periods, _ = metrics.CalculatePeriodMetrics(commits, prs, stories, deployments, cfg, period)
+	}
+	// Fetches cut short by a cancelled request return partial data, and a failed fetch would
+	// be served as empty for the whole TTL; neither must be cached
+	if ctx.Err() == nil && !fetchFailed {
+		s.metricsCache.Set(cacheKey, result)
+	}
+
+	return result, recorder.Snapshot(), false
+}
+
+// replaySequence numbers each request's recorded or replayed responses on their own, so every
+// fresh load replays from the first saved response, even while other requests are served
+func replaySequence(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(replay.WithSequence(r.Context())))
+	})
+}
+
+// requestConfig returns the config for a request, with the analysis window overridden by
+// ?days=N or ?since=YYYY-MM-DD&until=YYYY-MM-DD. On invalid parameters it writes a 400 JSON
+// error and returns false.
+func (s *Server) requestConfig(w http.ResponseWriter, r *http.Request) (config.Config, bool) {
+	q := r.URL.Query()
+	cfg, err := s.config.WithWindow(q.Get("days"), q.Get("since"), q.Get("until"))
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status": "error",
+			"error":  err.Error(),
+		})
+		return cfg, false
+	}
+	return cfg, true
+}
 
+// writeAllMetrics encodes a computed /api/metrics result as the JSON response
+func (s *Server) writeAllMetrics(w http.ResponseWriter, result cachedMetrics, fetchStats map[string]fetchstats.ProviderStats, cached bool) {
 	// Generate reports
-	jsonData, err := json.Marshal(teamMetrics)
+	jsonData, err := json.Marshal(result.teamMetrics)
 	if err != nil {
 		http.Error(w, "Error generating JSON", http.StatusInternalServerError)
 		return
 	}
 
 	response := map[string]interface{}{
-		"status": "success",
-		"data":   teamMetrics,
-		"stats": map[string]int{
-			"commits": len(commits),
-			"prs":     len(prs),
-			"stories": len(stories),
-		},
-		"timestamp": time.Now().UTC(),
+		"status":      "success",
+		"data":        result.teamMetrics,
+		"stats":       result.counts,
+		"fetch_stats": fetchStats,
+		"cached":      cached,
+		"timestamp":   time.Now().UTC(),
 		"export": map[string]string{
 			"json": string(jsonData),
 		},
 	}
 
+	if result.periods != nil {
+		response["periods"] = result.periods
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// recordFetchStats folds a finished request's fetch stats into the server-wide diagnostics
+func (s *Server) recordFetchStats(recorder *fetchstats.Recorder) {
+	s.fetchStats.Merge(recorder)
+
+	s.statsMu.Lock()
+	defer s.statsMu.Unlock()
+	s.lastFetchStats = recorder.Snapshot()
+}
+
+// getTrends serves the run history written by the history sink, either as whole entries or,
+// with ?metric=, as one metric's series. ?since= and ?until= (YYYY-MM-DD) bound the range.
+func (s *Server) getTrends(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	writeError := func(status int, message string) {
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status": "error",
+			"error":  message,
+		})
+	}
+
+	path := report.HistoryPath(s.config.Sinks)
+	if path == "" {
+		writeError(http.StatusNotFound, "no history sink configured")
+		return
+	}
+
+	var since, until time.Time
+	if v := r.URL.Query().Get("since"); v != "" {
+		t, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			writeError(http.StatusBadRequest, "invalid since: use YYYY-MM-DD")
+			return
+		}
+		since = t
+	}
+	if v := r.URL.Query().Get("until"); v != "" {
+		t, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			writeError(http.StatusBadRequest, "invalid until: use YYYY-MM-DD")
+			return
+		}
+		// Include the whole final day
+		until = t.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	}
+
+	entries, err := report.LoadHistory(path)
+	if err != nil {
+		writeError(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	var data interface{}
+	if metric := r.URL.Query().Get("metric"); metric != "" {
+		data = report.HistorySeries(entries, metric, since, until)
+	} else {
+		filtered := []report.HistoryEntry{}
+		for _, entry := range entries {
+			if (since.IsZero() || !entry.Timestamp.Before(since)) && (until.IsZero() || !entry.Timestamp.After(until)) {
+				filtered = append(filtered, entry)
+			}
+		}
+		data = filtered
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":    "success",
+		"data":      data,
+		"timestamp": time.Now().UTC(),
+	})
+}
+
+// getHistory returns the metric snapshots saved by -save runs, oldest first, optionally
+// limited to ?since=YYYY-MM-DD&until=YYYY-MM-DD (both days inclusive)
+func (s *Server) getHistory(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	writeError := func(status int, message string) {
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status": "error",
+			"error":  message,
+		})
+	}
+
+	var since, until time.Time
+	if v := r.URL.Query().Get("since"); v != "" {
+		t, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			writeError(http.StatusBadRequest, "invalid since: use YYYY-MM-DD")
+			return
+		}
+		since = t
+	}
+	if v := r.URL.Query().Get("until"); v != "" {
+		t, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			writeError(http.StatusBadRequest, "invalid until: use YYYY-MM-DD")
+			return
+		}
+		// Include the whole final day
+		until = t.AddDate(0, 0, 1).Add(-time.Millisecond)
+	}
+
+	// Reading must not create an empty database where no run was ever saved
+	path := s.config.SnapshotPath()
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		writeError(http.StatusNotFound, "no snapshots saved: run the CLI with -save")
+		return
+	}
+
+	store, err := storage.Open(path)
+	if err != nil {
+		writeError(http.StatusInternalServerError, err.Error())
+		return
+	}
+	defer store.Close()
+
+	snapshots, err := store.LoadSnapshots(since, until)
+	if err != nil {
+		writeError(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":    "success",
+		"data":      snapshots,
+		"timestamp": time.Now().UTC(),
+	})
+}
+
+// getDiagnostics returns API request statistics for the last fetch and since startup
+func (s *Server) getDiagnostics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	s.statsMu.Lock()
+	last := s.lastFetchStats
+	s.statsMu.Unlock()
+	if last == nil {
+		last = map[string]fetchstats.ProviderStats{}
+	}
+
+	response := map[string]interface{}{
+		"status": "success",
+		"data": map[string]interface{}{
+			"last_fetch_stats":  last,
+			"total_fetch_stats": s.fetchStats.Snapshot(),
+			"uptime_seconds":    time.Since(s.startedAt).Seconds(),
+			"metrics_cache":     s.metricsCache.Stats(),
+		},
+		"timestamp": time.Now().UTC(),
+	}
+
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(response)
 }
 
+// endpoints lists the routes announced when the server starts
+var endpoints = [][2]string{
+	{"GET /health", "Health check"},
+	{"GET /api/bitbucket/metrics", "Bitbucket metrics"},
+	{"GET /api/jira/metrics", "Jira metrics"},
+	{"GET /api/jira/sprint/{id}", "One sprint's commitment, completion and velocity"},
+	{"GET /api/metrics", "All metrics"},
+	{"GET /api/metrics/diagnostics", "API request statistics"},
+	{"GET /api/metrics/csv", "Download CSV report"},
+	{"GET /api/report.html", "HTML dashboard"},
+	{"GET /api/trends", "Weekly trends"},
+	{"GET /api/history", "Saved metric snapshots"},
+	{"GET /api/authors/{name}", "One author's activity and metrics"},
+}
+
 // Start starts the web server
 func (s *Server) Start(port string) {
-	log.Printf("🚀 Starting DevOps Metrics API Server on port %s", port)
-	log.Printf("📊 Available endpoints:")
-	log.Printf("   GET /health - Health check")
-	log.Printf("   GET /api/bitbucket/metrics - Bitbucket metrics")
-	log.Printf("   GET /api/jira/metrics - Jira metrics")
-	log.Printf("   GET /api/metrics - All metrics")
-	log.Printf("   GET /api/metrics/csv - Download CSV report")
+	slog.Info("Starting DevOps Metrics API Server", "port", port)
+	for _, endpoint := range endpoints {
+		slog.Info("Available endpoint", "route", endpoint[0], "description", endpoint[1])
+	}
 
 	if err := http.ListenAndServe(":"+port, s.Router); err != nil {
-		log.Fatal("❌ Failed to start server:", err)
+		slog.Error("Failed to start server", "error", err)
+		os.Exit(1)
 	}
-}
\ No newline at end of file
+}
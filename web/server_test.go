@@ -0,0 +1,266 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"devops-metrics/cache"
+	"devops-metrics/config"
+	"devops-metrics/fetchstats"
+)
+
+// newTestServer returns a server analyzing a GitHub repository served by an empty fake API,
+// and a counter of the commit listings the fake has answered
+func newTestServer(t *testing.T, ttl time.Duration) (*Server, *atomic.Int32) {
+	t.Helper()
+	var fetches atomic.Int32
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/branches") {
+			fetches.Add(1)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte("[]"))
+	}))
+	t.Cleanup(api.Close)
+	return newServerFor(api, ttl), &fetches
+}
+
+// newServerFor returns a server analyzing the acme/api GitHub repository served by api
+func newServerFor(api *httptest.Server, ttl time.Duration) *Server {
+	s := &Server{
+		config: config.Config{
+			GitHubURL:     api.URL,
+			GitHubOwner:   "acme",
+			GitHubRepo:    "api",
+			DaysToAnalyze: 30,
+		},
+		fetchStats:   fetchstats.NewRecorder(),
+		startedAt:    time.Now(),
+		metricsCache: cache.NewLRU(config.DefaultMetricsCacheSize, ttl),
+	}
+	s.setupRoutes()
+	return s
+}
+
+// getCached requests target and reports the response's "cached" flag
+func getCached(t *testing.T, s *Server, target string) bool {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	s.Router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET %s = %d: %s", target, rec.Code, rec.Body.String())
+	}
+	var body struct {
+		Cached bool `json:"cached"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("GET %s: decoding response: %v", target, err)
+	}
+	return body.Cached
+}
+
+func TestMetricsCacheKeys(t *testing.T) {
+	s, fetches := newTestServer(t, time.Minute)
+
+	// Requests run in order against one server, so each step sees the entries of those before it
+	steps := []struct {
+		target      string
+		wantCached  bool
+		wantFetches int32
+	}{
+		{"/api/metrics", false, 1},
+		{"/api/metrics", true, 1},
+		{"/api/metrics?days=30", true, 1},       // Same effective window as the default
+		{"/api/metrics?days=30&x=1", true, 1},   // Unrelated parameters share the entry
+		{"/api/metrics?days=7", false, 2},       // Another window
+		{"/api/metrics?period=month", false, 3}, // Another period
+		{"/api/trends", false, 4},               // Another route
+		{"/api/trends?weeks=2", false, 5},       // ?weeks=N narrows the window to 14 days
+		{"/api/trends?weeks=2", true, 5},
+		{"/api/trends?days=14", true, 5},
+		{"/api/metrics?since=2026-03-01&until=2026-03-31", false, 6},
+		{"/api/metrics?since=2026-03-01&until=2026-03-31", true, 6},
+		{"/api/metrics?refresh=true", false, 7},
+		{"/api/metrics", true, 7},
+	}
+	for _, step := range steps {
+		if cached := getCached(t, s, step.target); cached != step.wantCached {
+			t.Errorf("GET %s cached = %v, want %v", step.target, cached, step.wantCached)
+		}
+		if n := fetches.Load(); n != step.wantFetches {
+			t.Errorf("after GET %s: %d fetches, want %d", step.target, n, step.wantFetches)
+		}
+	}
+}
+
+func TestMetricsViewsShareCache(t *testing.T) {
+	s, fetches := newTestServer(t, time.Minute)
+
+	steps := []struct {
+		target      string
+		wantFetches int32
+	}{
+		{"/api/metrics", 1},
+		{"/api/metrics/csv", 1},
+		{"/api/report.html", 1},
+		{"/api/authors/alice", 1}, // 404 as the fake has no activity, but served from the entry
+		{"/api/metrics/csv?period=month", 2},
+		{"/api/metrics?period=month", 2},
+		{"/api/trends", 3}, // Trends have their own entries
+	}
+	for _, step := range steps {
+		s.Router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, step.target, nil))
+		if n := fetches.Load(); n != step.wantFetches {
+			t.Errorf("after GET %s: %d fetches, want %d", step.target, n, step.wantFetches)
+		}
+	}
+}
+
+func TestMetricsCacheExpiry(t *testing.T) {
+	s, fetches := newTestServer(t, 50*time.Millisecond)
+
+	if getCached(t, s, "/api/metrics") {
+		t.Error("first request was served from the cache")
+	}
+	if !getCached(t, s, "/api/metrics") {
+		t.Error("second request within the TTL was not served from the cache")
+	}
+	time.Sleep(60 * time.Millisecond)
+	if getCached(t, s, "/api/metrics") {
+		t.Error("request after the TTL was served from the cache")
+	}
+	if n := fetches.Load(); n != 2 {
+		t.Errorf("%d fetches, want 2", n)
+	}
+}
+
+func TestMetricsCacheSkipsFailedFetches(t *testing.T) {
+	var failing atomic.Bool
+	failing.Store(true)
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failing.Load() && strings.HasSuffix(r.URL.Path, "/branches") {
+			http.Error(w, `{"message":"Bad credentials"}`, http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte("[]"))
+	}))
+	defer api.Close()
+	s := newServerFor(api, time.Minute)
+
+	// A failed provider fetch is served, but not cached as if the repository were empty
+	if getCached(t, s, "/api/metrics") {
+		t.Error("first request was served from the cache")
+	}
+	if getCached(t, s, "/api/metrics") {
+		t.Error("request after a failed fetch was served from the cache")
+	}
+
+	failing.Store(false)
+	if getCached(t, s, "/api/metrics") {
+		t.Error("first successful fetch was served from the cache")
+	}
+	if !getCached(t, s, "/api/metrics") {
+		t.Error("request after a successful fetch was not served from the cache")
+	}
+}
+
+func TestGetMetricsCSV(t *testing.T) {
+	date := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/branches"):
+			w.Write([]byte(`[{"name":"main"}]`))
+		case strings.HasSuffix(r.URL.Path, "/commits"):
+			w.Write([]byte(`[{"sha":"abc123","author":{"login":"dev"},"commit":{"author":{"date":"` + date + `","name":"Dev"},"message":"feat: add endpoint"}}]`))
+		default:
+			w.Write([]byte("[]"))
+		}
+	}))
+	defer api.Close()
+
+	s, _ := newTestServer(t, time.Minute)
+	s.config.GitHubURL = api.URL
+
+	rec := httptest.NewRecorder()
+	s.Router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/metrics/csv", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body.String())
+	}
+	if got := rec.Header().Get("Content-Type"); got != "text/csv" {
+		t.Errorf("Content-Type = %q, want text/csv", got)
+	}
+	if got, want := rec.Header().Get("Content-Disposition"), `attachment; filename="metrics.csv"`; got != want {
+		t.Errorf("Content-Disposition = %q, want %q", got, want)
+	}
+	body := rec.Body.String()
+	for _, row := range []string{"Metric Category,Metric Name,Value\n", "Commits,Total Commits,1\n", "Commits by Type,feat,1\n"} {
+		if !strings.Contains(body, row) {
+			t.Errorf("CSV has no row %q:\n%s", row, body)
+		}
+	}
+}
+
+func TestCORSPreflight(t *testing.T) {
+	tests := []struct {
+		name        string
+		allowed     []string
+		path        string
+		origin      string
+		wantOrigin  string
+		wantMethods string
+	}{
+		{"allowed origin", []string{"https://dash.example.com"}, "/api/metrics", "https://dash.example.com", "https://dash.example.com", "GET"},
+		{"wildcard", []string{"*"}, "/api/trends", "https://other.example.com", "*", "GET"},
+		{"other origin", []string{"https://dash.example.com"}, "/api/metrics", "https://evil.example.com", "", ""},
+		{"disabled by default", nil, "/api/metrics", "https://dash.example.com", "", ""},
+		{"only api routes", []string{"https://dash.example.com"}, "/health", "https://dash.example.com", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _ := newTestServer(t, time.Minute)
+			s.config.AllowedOrigins = tt.allowed
+			s.setupRoutes()
+
+			req := httptest.NewRequest(http.MethodOptions, tt.path, nil)
+			req.Header.Set("Origin", tt.origin)
+			req.Header.Set("Access-Control-Request-Method", http.MethodGet)
+			rec := httptest.NewRecorder()
+			s.Router.ServeHTTP(rec, req)
+
+			if got := rec.Header().Get("Access-Control-Allow-Origin"); got != tt.wantOrigin {
+				t.Errorf("Access-Control-Allow-Origin = %q, want %q", got, tt.wantOrigin)
+			}
+			if got := rec.Header().Get("Access-Control-Allow-Methods"); got != tt.wantMethods {
+				t.Errorf("Access-Control-Allow-Methods = %q, want %q", got, tt.wantMethods)
+			}
+			if tt.wantOrigin != "" && rec.Code != http.StatusOK {
+				t.Errorf("preflight status = %d, want %d", rec.Code, http.StatusOK)
+			}
+		})
+	}
+}
+
+func TestCORSSimpleRequest(t *testing.T) {
+	s, _ := newTestServer(t, time.Minute)
+	s.config.AllowedOrigins = []string{"https://dash.example.com"}
+	s.setupRoutes()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/metrics", nil)
+	req.Header.Set("Origin", "https://dash.example.com")
+	rec := httptest.NewRecorder()
+	s.Router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET /api/metrics = %d: %s", rec.Code, rec.Body.String())
+	}
+	if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "https://dash.example.com" {
+		t.Errorf("Access-Control-Allow-Origin = %q, want the request origin", got)
+	}
+}